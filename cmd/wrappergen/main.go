@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/digitalmonsters/go-common/wrappergen"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	specPath := flag.String("spec", "wrapper.json", "path to wrapper spec")
+	outDir := flag.String("out", "", "output directory, directory of spec by default")
+
+	flag.Parse()
+
+	spec, err := wrappergen.LoadSpec(*specPath)
+
+	if err != nil {
+		fail(err)
+	}
+
+	if len(*outDir) == 0 {
+		*outDir = filepath.Dir(*specPath)
+	}
+
+	if err = wrappergen.GenerateToDir(spec, *outDir); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	_, _ = fmt.Fprintf(os.Stderr, "wrappergen: %v\n", err)
+
+	os.Exit(1)
+}
@@ -8,6 +8,17 @@ import (
 	"strings"
 )
 
+// RequestTimeoutHeader carries remaining time budget in milliseconds between services
+const RequestTimeoutHeader = "X-Request-Timeout-Ms"
+
+const (
+	// CorrelationIdHeader carries id of the original request through all services it reaches
+	CorrelationIdHeader = "X-Correlation-Id"
+	// OriginUserIdHeader carries id of the user of the original request. It is informational only,
+	// services authorize users by their tokens.
+	OriginUserIdHeader = "X-Origin-User-Id"
+)
+
 type ContentEncodingType string
 
 const (
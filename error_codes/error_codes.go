@@ -18,10 +18,14 @@ const (
 	InvalidMethodPermission     ErrorCode = 401
 	Forbidden                   ErrorCode = 403
 	NotAllowed                  ErrorCode = 405
+	TooManyRequests             ErrorCode = 429
 	GenericMappingError         ErrorCode = -32700
+	InvalidRpcRequestError      ErrorCode = -32600
 	GenericDuplicateError       ErrorCode = 409
 	GenericNotFoundError        ErrorCode = 404
 	InvalidContentLength        ErrorCode = 413
+	UnsupportedMediaType        ErrorCode = 415
+	ServiceUnavailable          ErrorCode = 503
 	CommandNotFoundError        ErrorCode = -32601
 	GenericTimeoutError         ErrorCode = 502
 	GenericPanicError           ErrorCode = -32603
@@ -48,13 +52,27 @@ const (
 	TokenomicsReceivingUserWithoutKyc ErrorCode = 1501
 )
 
-var TokenomicsNotEnoughBalanceError = errors.New("user doesn't have enough money to execute operation")
-var TokenomicsErrorCannotProceedWithoutKyc = errors.New("You cannot proceed without KYC")
-var TokenomicsErrorReceivingUserWithoutKyc = errors.New("You cannot tip user without KYC")
+const (
+	BusinessCodeMaxThresholdWithoutKycExceeded = 2
+	BusinessCodeAccountNotConnectedToDevice    = 3
+)
+
+var MaxThresholdWithoutKycExceededError = RegisterError("max threshold without kyc exceeded",
+	GenericValidationError, BusinessCodeMaxThresholdWithoutKycExceeded, "max_threshold_without_kyc_exceeded")
+var AccountNotConnectedToDeviceError = RegisterError("account is not connected to that device_id",
+	GenericValidationError, BusinessCodeAccountNotConnectedToDevice, "account_not_connected_to_device")
+var TokenomicsNotEnoughBalanceError = RegisterError("user doesn't have enough money to execute operation",
+	GenericValidationError, int(TokenomicsNotEnoughBalance), "tokenomics_not_enough_balance")
+var TokenomicsErrorCannotProceedWithoutKyc = RegisterError("You cannot proceed without KYC",
+	GenericValidationError, int(KYCRequiredError), "kyc_required")
+var TokenomicsErrorReceivingUserWithoutKyc = RegisterError("You cannot tip user without KYC",
+	GenericValidationError, int(TokenomicsReceivingUserWithoutKyc), "tokenomics_receiving_user_without_kyc")
 
 type ErrorWithCode struct {
-	error error
-	code  ErrorCode
+	error        error
+	code         ErrorCode
+	businessCode int
+	data         map[string]interface{}
 }
 
 func NewErrorWithCode(err error, code ErrorCode) ErrorWithCode {
@@ -63,8 +81,9 @@ func NewErrorWithCode(err error, code ErrorCode) ErrorWithCode {
 	}
 
 	return ErrorWithCode{
-		error: err,
-		code:  code,
+		error:        err,
+		code:         code,
+		businessCode: getBusinessCodeWithMessageFallback(err),
 	}
 }
 
@@ -74,6 +93,13 @@ func NewErrorWithCodeRef(err error, code ErrorCode) *ErrorWithCode {
 	return &val
 }
 
+func NewErrorWithCodeAndData(err error, code ErrorCode, data map[string]interface{}) *ErrorWithCode {
+	val := NewErrorWithCode(err, code)
+	val.data = data
+
+	return &val
+}
+
 func (e *ErrorWithCode) GetCode() ErrorCode {
 	return e.code
 }
@@ -97,6 +123,18 @@ func (e *ErrorWithCode) GetError() error {
 	return e.error
 }
 
+func (e *ErrorWithCode) GetBusinessCode() int {
+	return e.businessCode
+}
+
+func (e *ErrorWithCode) GetTranslationKey() string {
+	return GetTranslationKey(e.businessCode)
+}
+
+func (e *ErrorWithCode) GetData() map[string]interface{} {
+	return e.data
+}
+
 type SimpleException struct {
 	Message        string           `json:"message"`
 	StackTrace     string           `json:"stack_trace"`
@@ -0,0 +1,168 @@
+package error_codes
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+	"sync"
+)
+
+type BusinessCodeCarrier interface {
+	GetBusinessCode() int
+}
+
+// RegisteredError is a sentinel error with a stable business code. errors.Is matches any error carrying the same
+// business code, so errors decoded from remote services compare equal to the local sentinel.
+type RegisteredError struct {
+	message        string
+	httpStatus     ErrorCode
+	businessCode   int
+	translationKey string
+}
+
+var registryMutex sync.RWMutex
+var registry = map[int]*RegisteredError{}
+var registryByMessage = map[string]*RegisteredError{}
+
+func RegisterError(message string, httpStatus ErrorCode, businessCode int, translationKey string) *RegisteredError {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if existing, ok := registry[businessCode]; ok {
+		panic(fmt.Sprintf("business code [%v] is already registered for error [%v]", businessCode, existing.message))
+	}
+
+	e := &RegisteredError{
+		message:        message,
+		httpStatus:     httpStatus,
+		businessCode:   businessCode,
+		translationKey: translationKey,
+	}
+
+	registry[businessCode] = e
+	registryByMessage[strings.ToLower(message)] = e
+
+	return e
+}
+
+func GetRegisteredError(businessCode int) (*RegisteredError, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	e, ok := registry[businessCode]
+
+	return e, ok
+}
+
+// Deprecated: services should return registered errors. Message text matching is kept for one release for services
+// which still return plain errors.New("max threshold without kyc exceeded") and alike
+func GetRegisteredErrorByMessage(message string) (*RegisteredError, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	e, ok := registryByMessage[strings.ToLower(message)]
+
+	return e, ok
+}
+
+// GetTranslationKey returns translation key of registered error with businessCode or empty string
+func GetTranslationKey(businessCode int) string {
+	if businessCode == 0 {
+		return ""
+	}
+
+	if e, ok := GetRegisteredError(businessCode); ok {
+		return e.translationKey
+	}
+
+	return ""
+}
+
+func (e *RegisteredError) Error() string {
+	return e.message
+}
+
+func (e *RegisteredError) Is(target error) bool {
+	if t, ok := target.(BusinessCodeCarrier); ok {
+		return t.GetBusinessCode() == e.businessCode
+	}
+
+	return false
+}
+
+func (e *RegisteredError) GetBusinessCode() int {
+	return e.businessCode
+}
+
+func (e *RegisteredError) GetHttpStatus() ErrorCode {
+	return e.httpStatus
+}
+
+func (e *RegisteredError) GetTranslationKey() string {
+	return e.translationKey
+}
+
+// businessError is an error received from remote service with business code attached
+type businessError struct {
+	message      string
+	businessCode int
+}
+
+func NewBusinessError(message string, businessCode int) error {
+	return &businessError{
+		message:      message,
+		businessCode: businessCode,
+	}
+}
+
+func (e *businessError) Error() string {
+	return e.message
+}
+
+func (e *businessError) GetBusinessCode() int {
+	return e.businessCode
+}
+
+func (e *businessError) Is(target error) bool {
+	if t, ok := target.(BusinessCodeCarrier); ok {
+		return t.GetBusinessCode() == e.businessCode
+	}
+
+	return false
+}
+
+func GetBusinessCode(err error) int {
+	var carrier BusinessCodeCarrier
+
+	if err != nil && errors.As(err, &carrier) {
+		return carrier.GetBusinessCode()
+	}
+
+	return 0
+}
+
+// getBusinessCodeWithMessageFallback also matches message of plain errors with registered ones, see GetRegisteredErrorByMessage
+func getBusinessCodeWithMessageFallback(err error) int {
+	if businessCode := GetBusinessCode(err); businessCode != 0 || err == nil {
+		return businessCode
+	}
+
+	if registered, ok := GetRegisteredErrorByMessage(err.Error()); ok {
+		return registered.businessCode
+	}
+
+	return 0
+}
+
+// NewErrorFromRegistered uses http status of registered error found in the chain, falls back to GenericServerError
+func NewErrorFromRegistered(err error) *ErrorWithCode {
+	code := GenericServerError
+
+	if businessCode := getBusinessCodeWithMessageFallback(err); businessCode != 0 {
+		if registered, ok := GetRegisteredError(businessCode); ok {
+			code = registered.GetHttpStatus()
+		}
+	}
+
+	return NewErrorWithCodeRef(err, code)
+}
@@ -0,0 +1,55 @@
+package error_codes
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegisteredErrorIs(t *testing.T) {
+	wrapped := errors.Wrap(TokenomicsNotEnoughBalanceError, "can not write off tokens")
+
+	assert.True(t, errors.Is(wrapped, TokenomicsNotEnoughBalanceError))
+	assert.False(t, errors.Is(wrapped, TokenomicsErrorReceivingUserWithoutKyc))
+	assert.Equal(t, int(TokenomicsNotEnoughBalance), GetBusinessCode(wrapped))
+
+	remote := errors.WithStack(NewBusinessError("service [x] replied", int(TokenomicsNotEnoughBalance)))
+
+	assert.True(t, errors.Is(remote, TokenomicsNotEnoughBalanceError))
+	assert.Equal(t, 0, GetBusinessCode(errors.New("plain")))
+}
+
+func TestErrorWithCodeBusinessCode(t *testing.T) {
+	e := NewErrorFromRegistered(errors.Wrap(TokenomicsErrorCannotProceedWithoutKyc, "tip"))
+
+	assert.Equal(t, GenericValidationError, e.GetCode())
+	assert.Equal(t, int(KYCRequiredError), e.GetBusinessCode())
+
+	assert.Equal(t, 0, NewErrorWithCodeRef(errors.New("plain"), GenericServerError).GetBusinessCode())
+	assert.Equal(t, GenericServerError, NewErrorFromRegistered(errors.New("plain")).GetCode())
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterError("duplicate", GenericValidationError, BusinessCodeAccountNotConnectedToDevice, "")
+	})
+}
+
+func TestErrorWithCodeTranslationKey(t *testing.T) {
+	e := NewErrorFromRegistered(errors.Wrap(TokenomicsNotEnoughBalanceError, "tip"))
+
+	assert.Equal(t, "tokenomics_not_enough_balance", e.GetTranslationKey())
+	assert.Equal(t, "", NewErrorWithCodeRef(errors.New("plain"), GenericServerError).GetTranslationKey())
+}
+
+func TestBusinessCodeMessageFallback(t *testing.T) {
+	e := NewErrorWithCodeRef(errors.New("max threshold without kyc exceeded"), GenericValidationError)
+
+	assert.Equal(t, BusinessCodeMaxThresholdWithoutKycExceeded, e.GetBusinessCode())
+	assert.Equal(t, "max_threshold_without_kyc_exceeded", e.GetTranslationKey())
+
+	e = NewErrorFromRegistered(errors.New("Account is not connected to that device_id"))
+
+	assert.Equal(t, BusinessCodeAccountNotConnectedToDevice, e.GetBusinessCode())
+	assert.Equal(t, GenericValidationError, e.GetCode())
+}
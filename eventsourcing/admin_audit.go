@@ -0,0 +1,48 @@
+package eventsourcing
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type AdminAuditStatus string
+
+const (
+	AdminAuditStatusSuccess = AdminAuditStatus("success")
+	AdminAuditStatusError   = AdminAuditStatus("error")
+	AdminAuditStatusDenied  = AdminAuditStatus("denied") // authorization failed, admin id is the claimed one
+)
+
+type AdminAuditEvent struct {
+	AdminId      int64            `json:"admin_id"`
+	RbacObj      string           `json:"rbac_obj"`
+	AccessLevel  string           `json:"access_level"`
+	Endpoint     string           `json:"endpoint"`
+	Method       string           `json:"method"`
+	Params       json.RawMessage  `json:"params"`
+	Status       AdminAuditStatus `json:"status"`
+	ErrorCode    int              `json:"error_code"`
+	ErrorMessage string           `json:"error_message,omitempty"`
+	Ip           string           `json:"ip"`
+	TraceId      string           `json:"trace_id,omitempty"`
+	StartedAt    time.Time        `json:"started_at"`
+	FinishedAt   time.Time        `json:"finished_at"`
+	Diffs        []AdminAuditDiff `json:"diffs,omitempty"`
+}
+
+// AdminAuditDiff keeps only fields which were changed by the command
+type AdminAuditDiff struct {
+	Entity   string                           `json:"entity"`
+	EntityId string                           `json:"entity_id"`
+	Changes  map[string]AdminAuditFieldChange `json:"changes"`
+}
+
+type AdminAuditFieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+func (e AdminAuditEvent) GetPublishKey() string {
+	return fmt.Sprintf("%v", e.AdminId)
+}
@@ -0,0 +1,13 @@
+package eventsourcing
+
+import "fmt"
+
+// AdminPermissionsChangedEvent is published when roles or permissions of the admin are changed.
+// Zero AdminId means role definitions were changed and all admins are affected.
+type AdminPermissionsChangedEvent struct {
+	AdminId int64 `json:"admin_id"`
+}
+
+func (e AdminPermissionsChangedEvent) GetPublishKey() string {
+	return fmt.Sprint(e.AdminId)
+}
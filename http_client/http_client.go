@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/redact"
 	"github.com/imroc/req/v3"
 	"github.com/rs/zerolog/log"
 	"go.elastic.co/apm"
@@ -105,12 +106,12 @@ func NewHttpClient() *HttpClient {
 			if forceLog {
 				var rawBodyRequest []byte
 
-				rawBodyResponse := response.Bytes()
+				rawBodyResponse := redact.Json(response.Bytes())
 
 				if r, err := io.ReadAll(response.Request.RawRequest.Body); err != nil {
 					log.Ctx(ctx).Err(err).Send()
 				} else {
-					rawBodyRequest = r
+					rawBodyRequest = redact.Json(r)
 				}
 
 				if data, err := json.Marshal(map[string]interface{}{
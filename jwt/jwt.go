@@ -0,0 +1,389 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+type Algorithm string
+
+const (
+	RS256 = Algorithm("RS256")
+	ES256 = Algorithm("ES256")
+	HS256 = Algorithm("HS256")
+)
+
+const defaultClockSkew = 1 * time.Minute
+
+var (
+	ErrMalformedToken       = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("unsupported token algorithm")
+	ErrUnknownKey           = errors.New("unknown token key")
+	ErrInvalidSignature     = errors.New("invalid token signature")
+	ErrTokenExpired         = errors.New("token expired")
+	ErrTokenNotValidYet     = errors.New("token is not valid yet")
+	ErrInvalidIssuer        = errors.New("invalid token issuer")
+	ErrInvalidAudience      = errors.New("invalid token audience")
+)
+
+type Config struct {
+	Keys KeySource
+	// Algorithms are allowed algorithms, RS256 and ES256 by default. HS256 should be listed explicitly
+	// and its secret configured with NewStaticKeys
+	Algorithms []Algorithm
+	// Issuer is checked when not empty
+	Issuer string
+	// Audience is checked when not empty, token should contain at least one of them
+	Audience  []string
+	ClockSkew time.Duration
+}
+
+type Verifier struct {
+	cfg Config
+	now func() time.Time
+}
+
+func NewVerifier(cfg Config) *Verifier {
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = []Algorithm{RS256, ES256}
+	}
+
+	if cfg.ClockSkew <= 0 {
+		cfg.ClockSkew = defaultClockSkew
+	}
+
+	return &Verifier{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+type header struct {
+	Algorithm Algorithm `json:"alg"`
+	KeyId     string    `json:"kid"`
+}
+
+// Claims are decoded with json.Number for numeric values
+type Claims map[string]interface{}
+
+func (c Claims) String(name string) string {
+	v, _ := c[name].(string)
+
+	return v
+}
+
+func (c Claims) Int64(name string) (int64, bool) {
+	switch v := c[name].(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, true
+		}
+
+		if f, err := v.Float64(); err == nil {
+			return int64(f), true
+		}
+	case string:
+		if i, err := json.Number(v).Int64(); err == nil {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func (c Claims) Bool(name string) (bool, bool) {
+	v, ok := c[name].(bool)
+
+	return v, ok
+}
+
+func (c Claims) Time(name string) (time.Time, bool) {
+	if v, ok := c.Int64(name); ok {
+		return time.Unix(v, 0), true
+	}
+
+	return time.Time{}, false
+}
+
+func (c Claims) Issuer() string {
+	return c.String("iss")
+}
+
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// Audience handles both string and array forms of "aud"
+func (c Claims) Audience() []string {
+	switch v := c["aud"].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var result []string
+
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+
+		return result
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, target interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+
+	if err != nil {
+		return ErrMalformedToken
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	if err = decoder.Decode(target); err != nil {
+		return ErrMalformedToken
+	}
+
+	return nil
+}
+
+// Verify checks signature, "exp", "nbf", issuer and audience. Token without "exp" is rejected.
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var h header
+
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, err
+	}
+
+	if !v.isAllowed(h.Algorithm) {
+		return nil, errors.Wrap(ErrUnsupportedAlgorithm, string(h.Algorithm))
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	keys, err := v.cfg.Keys.GetKeys(ctx, h.KeyId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	knownKey := false
+
+	for _, key := range keys {
+		if !key.supports(h.Algorithm) {
+			continue
+		}
+
+		knownKey = true
+
+		if verifySignature(h.Algorithm, key.Material, signed, signature) {
+			verified = true
+
+			break
+		}
+	}
+
+	if !knownKey {
+		return nil, errors.Wrap(ErrUnknownKey, h.KeyId)
+	}
+
+	if !verified {
+		return nil, ErrInvalidSignature
+	}
+
+	var claims Claims
+
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	if err = v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) isAllowed(alg Algorithm) bool {
+	for _, a := range v.cfg.Algorithms {
+		if a == alg {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v *Verifier) validateClaims(claims Claims) error {
+	now := v.now()
+
+	exp, ok := claims.Time("exp")
+
+	if !ok {
+		return errors.Wrap(ErrMalformedToken, "missing exp")
+	}
+
+	if now.After(exp.Add(v.cfg.ClockSkew)) {
+		return ErrTokenExpired
+	}
+
+	if nbf, ok := claims.Time("nbf"); ok && now.Add(v.cfg.ClockSkew).Before(nbf) {
+		return ErrTokenNotValidYet
+	}
+
+	if len(v.cfg.Issuer) > 0 && claims.Issuer() != v.cfg.Issuer {
+		return errors.Wrap(ErrInvalidIssuer, claims.Issuer())
+	}
+
+	if len(v.cfg.Audience) > 0 {
+		matched := false
+
+		for _, aud := range claims.Audience() {
+			for _, expected := range v.cfg.Audience {
+				if aud == expected {
+					matched = true
+				}
+			}
+		}
+
+		if !matched {
+			return errors.Wrap(ErrInvalidAudience, strings.Join(claims.Audience(), ","))
+		}
+	}
+
+	return nil
+}
+
+func verifySignature(alg Algorithm, material interface{}, signed []byte, signature []byte) bool {
+	digest := sha256.Sum256(signed)
+
+	switch alg {
+	case RS256:
+		key, ok := material.(*rsa.PublicKey)
+
+		return ok && rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case ES256:
+		key, ok := material.(*ecdsa.PublicKey)
+
+		if !ok || key.Curve != elliptic.P256() || len(signature) != 64 {
+			return false
+		}
+
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+
+		return ecdsa.Verify(key, digest[:], r, s)
+	case HS256:
+		secret, ok := material.([]byte)
+
+		if !ok || len(secret) == 0 {
+			return false
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+
+		return hmac.Equal(mac.Sum(nil), signature)
+	}
+
+	return false
+}
+
+// Sign creates a token, mostly for tests and local development. Key material should be *rsa.PrivateKey for RS256,
+// *ecdsa.PrivateKey for ES256 and []byte for HS256.
+func Sign(alg Algorithm, keyId string, material interface{}, claims map[string]interface{}) (string, error) {
+	h := map[string]string{"alg": string(alg), "typ": "JWT"}
+
+	if len(keyId) > 0 {
+		h["kid"] = keyId
+	}
+
+	headerJson, err := json.Marshal(h)
+
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	claimsJson, err := json.Marshal(claims)
+
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(headerJson) + "." + base64.RawURLEncoding.EncodeToString(claimsJson)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+
+	switch alg {
+	case RS256:
+		key, ok := material.(*rsa.PrivateKey)
+
+		if !ok {
+			return "", errors.New("RS256 requires *rsa.PrivateKey")
+		}
+
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:]); err != nil {
+			return "", errors.WithStack(err)
+		}
+	case ES256:
+		key, ok := material.(*ecdsa.PrivateKey)
+
+		if !ok || key.Curve != elliptic.P256() {
+			return "", errors.New("ES256 requires P-256 *ecdsa.PrivateKey")
+		}
+
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	case HS256:
+		secret, ok := material.([]byte)
+
+		if !ok {
+			return "", errors.New("HS256 requires []byte secret")
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	default:
+		return "", errors.New(fmt.Sprintf("unsupported algorithm %v", alg))
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,252 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub": "12",
+		"iss": "auth",
+		"aud": []string{"api", "web"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifyAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	secret := []byte("secret")
+
+	verifier := NewVerifier(Config{
+		Keys: NewStaticKeys(
+			Key{Id: "rsa", Material: &rsaKey.PublicKey},
+			Key{Id: "ec", Material: &ecKey.PublicKey},
+			Key{Id: "hmac", Material: secret},
+		),
+		Algorithms: []Algorithm{RS256, ES256, HS256},
+		Issuer:     "auth",
+		Audience:   []string{"api"},
+	})
+
+	for alg, key := range map[Algorithm]interface{}{RS256: rsaKey, ES256: ecKey, HS256: secret} {
+		kid := map[Algorithm]string{RS256: "rsa", ES256: "ec", HS256: "hmac"}[alg]
+
+		token, err := Sign(alg, kid, key, validClaims())
+		assert.Nil(t, err)
+
+		claims, err := verifier.Verify(context.Background(), token)
+		assert.Nil(t, err, alg)
+		assert.Equal(t, "12", claims.Subject())
+
+		userId, ok := claims.Int64("sub")
+		assert.True(t, ok)
+		assert.Equal(t, int64(12), userId)
+
+		// tampered payload
+		tampered := token[:len(token)-3] + "AAA"
+
+		_, err = verifier.Verify(context.Background(), tampered)
+		assert.NotNil(t, err, alg)
+	}
+}
+
+func TestVerifySymmetricKeysAreOptIn(t *testing.T) {
+	secret := []byte("secret")
+
+	token, err := Sign(HS256, "hmac", secret, validClaims())
+	assert.Nil(t, err)
+
+	_, err = NewVerifier(Config{Keys: NewStaticKeys(Key{Id: "hmac", Material: secret})}).
+		Verify(context.Background(), token)
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	keys, err := ParseJwks([]byte(fmt.Sprintf(`{"keys":[{"kty":"oct","kid":"hmac","k":"%v"}]}`,
+		base64.RawURLEncoding.EncodeToString(secret))))
+	assert.Nil(t, err)
+	assert.Empty(t, keys)
+
+	_, err = NewVerifier(Config{Keys: NewStaticKeys(keys...), Algorithms: []Algorithm{HS256}}).
+		Verify(context.Background(), token)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestVerifyRejectsKeyConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	verifier := NewVerifier(Config{
+		Keys:       NewStaticKeys(Key{Id: "rsa", Material: &rsaKey.PublicKey}),
+		Algorithms: []Algorithm{RS256, HS256},
+	})
+
+	// HS256 signed with public key bytes must not be accepted by rsa key
+	token, err := Sign(HS256, "rsa", rsaKey.PublicKey.N.Bytes(), validClaims())
+	assert.Nil(t, err)
+
+	_, err = verifier.Verify(context.Background(), token)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1","exp":99999999999}`))
+
+	_, err = verifier.Verify(context.Background(), header+"."+payload+".")
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	_, err = verifier.Verify(context.Background(), "abc")
+	assert.ErrorIs(t, err, ErrMalformedToken)
+}
+
+func TestVerifyClaims(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+
+	verifier := NewVerifier(Config{
+		Keys:       NewStaticKeys(Key{Material: secret}),
+		Algorithms: []Algorithm{HS256},
+		Issuer:     "auth",
+		Audience:   []string{"api"},
+		ClockSkew:  30 * time.Second,
+	})
+
+	cases := []struct {
+		modify func(claims map[string]interface{})
+		err    error
+	}{
+		{func(claims map[string]interface{}) { claims["exp"] = now.Add(-10 * time.Second).Unix() }, nil},
+		{func(claims map[string]interface{}) { claims["exp"] = now.Add(-time.Minute).Unix() }, ErrTokenExpired},
+		{func(claims map[string]interface{}) { delete(claims, "exp") }, ErrMalformedToken},
+		{func(claims map[string]interface{}) { claims["nbf"] = now.Add(10 * time.Second).Unix() }, nil},
+		{func(claims map[string]interface{}) { claims["nbf"] = now.Add(time.Minute).Unix() }, ErrTokenNotValidYet},
+		{func(claims map[string]interface{}) { claims["iss"] = "other" }, ErrInvalidIssuer},
+		{func(claims map[string]interface{}) { claims["aud"] = "api" }, nil},
+		{func(claims map[string]interface{}) { claims["aud"] = []string{"web"} }, ErrInvalidAudience},
+	}
+
+	for i, c := range cases {
+		claims := validClaims()
+		c.modify(claims)
+
+		token, err := Sign(HS256, "", secret, claims)
+		assert.Nil(t, err)
+
+		_, err = verifier.Verify(context.Background(), token)
+
+		if c.err == nil {
+			assert.Nil(t, err, i)
+		} else {
+			assert.ErrorIs(t, err, c.err, i)
+		}
+	}
+}
+
+func rsaJwks(kid string, key *rsa.PublicKey) string {
+	return fmt.Sprintf(`{"kty":"RSA","kid":"%v","use":"sig","n":"%v","e":"%v"}`, kid,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()))
+}
+
+func TestJwksRotation(t *testing.T) {
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	var fetches int32
+	var rotated int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+
+		ecJwk := fmt.Sprintf(`{"kty":"EC","kid":"ec","crv":"P-256","x":"%v","y":"%v"}`,
+			base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+			base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))))
+
+		if atomic.LoadInt32(&rotated) == 1 {
+			_, _ = fmt.Fprintf(w, `{"keys":[%v,%v]}`, rsaJwks("new", &newKey.PublicKey), ecJwk)
+
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `{"keys":[%v,%v,{"kty":"RSA","kid":"enc","use":"enc"}]}`,
+			rsaJwks("old", &oldKey.PublicKey), ecJwk)
+	}))
+	defer srv.Close()
+
+	source := NewJwksKeySource(srv.URL, JwksConfig{MinRefreshInterval: time.Hour})
+	jwksSource := source.(*jwksKeySource)
+	verifier := NewVerifier(Config{Keys: source})
+
+	oldToken, _ := Sign(RS256, "old", oldKey, validClaims())
+	newToken, _ := Sign(RS256, "new", newKey, validClaims())
+	ecToken, _ := Sign(ES256, "ec", ecKey, validClaims())
+
+	_, err := verifier.Verify(context.Background(), oldToken)
+	assert.Nil(t, err)
+
+	_, err = verifier.Verify(context.Background(), ecToken)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	atomic.StoreInt32(&rotated, 1)
+
+	// unknown kid, but refetch is limited by MinRefreshInterval
+	_, err = verifier.Verify(context.Background(), newToken)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	jwksSource.now = func() time.Time {
+		return time.Now().Add(time.Hour)
+	}
+
+	_, err = verifier.Verify(context.Background(), newToken)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestJwksServesStaleKeysOnError(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	var failing int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `{"keys":[%v]}`, rsaJwks("k", &key.PublicKey))
+	}))
+	defer srv.Close()
+
+	source := NewJwksKeySource(srv.URL, JwksConfig{})
+	jwksSource := source.(*jwksKeySource)
+	verifier := NewVerifier(Config{Keys: source})
+
+	token, _ := Sign(RS256, "k", key, validClaims())
+
+	_, err := verifier.Verify(context.Background(), token)
+	assert.Nil(t, err)
+
+	atomic.StoreInt32(&failing, 1)
+
+	jwksSource.now = func() time.Time {
+		return time.Now().Add(time.Hour)
+	}
+
+	_, err = verifier.Verify(context.Background(), token)
+	assert.Nil(t, err)
+	assert.NotNil(t, jwksSource.lastFetchErr)
+}
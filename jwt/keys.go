@@ -0,0 +1,345 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJwksRefreshInterval    = 10 * time.Minute
+	defaultJwksMinRefreshInterval = 30 * time.Second
+	defaultJwksRequestTimeout     = 5 * time.Second
+)
+
+// Key material is *rsa.PublicKey, *ecdsa.PublicKey or []byte secret. Empty Algorithm allows any algorithm
+// matching the material type.
+type Key struct {
+	Id        string
+	Algorithm Algorithm
+	Material  interface{}
+}
+
+func (k Key) supports(alg Algorithm) bool {
+	if len(k.Algorithm) > 0 && k.Algorithm != alg {
+		return false
+	}
+
+	switch k.Material.(type) {
+	case *rsa.PublicKey:
+		return alg == RS256
+	case *ecdsa.PublicKey:
+		return alg == ES256
+	case []byte:
+		return alg == HS256
+	}
+
+	return false
+}
+
+type KeySource interface {
+	// GetKeys returns keys with such id, or all keys when token has no "kid"
+	GetKeys(ctx context.Context, keyId string) ([]Key, error)
+}
+
+func filterKeys(keys []Key, keyId string) []Key {
+	if len(keyId) == 0 {
+		return keys
+	}
+
+	var result []Key
+
+	for _, key := range keys {
+		if key.Id == keyId {
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
+
+type staticKeys struct {
+	keys []Key
+}
+
+func NewStaticKeys(keys ...Key) KeySource {
+	return &staticKeys{
+		keys: keys,
+	}
+}
+
+func (s *staticKeys) GetKeys(_ context.Context, keyId string) ([]Key, error) {
+	return filterKeys(s.keys, keyId), nil
+}
+
+// ParsePublicKeyPem parses PKIX public key or certificate, result can be used as Key.Material
+func ParsePublicKeyPem(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, errors.New("no pem block found")
+	}
+
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return cert.PublicKey, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return key, nil
+}
+
+type JwksConfig struct {
+	// RefreshInterval is how long fetched key set is used before it is fetched again
+	RefreshInterval time.Duration
+	// MinRefreshInterval limits fetches caused by tokens with unknown "kid"
+	MinRefreshInterval time.Duration
+	HttpClient         *http.Client
+}
+
+// jwksKeySource keeps serving the last fetched keys when refresh fails
+type jwksKeySource struct {
+	url          string
+	cfg          JwksConfig
+	mutex        sync.RWMutex
+	fetchMutex   sync.Mutex
+	keys         []Key
+	fetchedAt    time.Time
+	lastFetchAt  time.Time
+	lastFetchErr error
+	now          func() time.Time
+}
+
+func NewJwksKeySource(url string, cfg JwksConfig) KeySource {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaultJwksRefreshInterval
+	}
+
+	if cfg.MinRefreshInterval <= 0 {
+		cfg.MinRefreshInterval = defaultJwksMinRefreshInterval
+	}
+
+	if cfg.HttpClient == nil {
+		cfg.HttpClient = &http.Client{Timeout: defaultJwksRequestTimeout}
+	}
+
+	return &jwksKeySource{
+		url: url,
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+func (j *jwksKeySource) cached(keyId string) ([]Key, bool) {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	if j.fetchedAt.IsZero() || j.now().Sub(j.fetchedAt) > j.cfg.RefreshInterval {
+		return nil, false
+	}
+
+	keys := filterKeys(j.keys, keyId)
+
+	return keys, len(keys) > 0
+}
+
+func (j *jwksKeySource) GetKeys(ctx context.Context, keyId string) ([]Key, error) {
+	if keys, ok := j.cached(keyId); ok {
+		return keys, nil
+	}
+
+	j.fetchMutex.Lock()
+	defer j.fetchMutex.Unlock()
+
+	if keys, ok := j.cached(keyId); ok { // fetched by another request while waiting
+		return keys, nil
+	}
+
+	j.mutex.RLock()
+	canFetch := j.lastFetchAt.IsZero() || j.now().Sub(j.lastFetchAt) >= j.cfg.MinRefreshInterval
+	j.mutex.RUnlock()
+
+	if canFetch {
+		keys, err := j.fetch(ctx)
+
+		j.mutex.Lock()
+		j.lastFetchAt = j.now()
+		j.lastFetchErr = err
+
+		if err == nil {
+			j.keys = keys
+			j.fetchedAt = j.lastFetchAt
+		}
+
+		j.mutex.Unlock()
+	}
+
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	if len(j.keys) == 0 && j.lastFetchErr != nil {
+		return nil, j.lastFetchErr
+	}
+
+	return filterKeys(j.keys, keyId), nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+func (j *jwksKeySource) fetch(ctx context.Context) ([]Key, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	resp, err := j.cfg.HttpClient.Do(req)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "can not fetch jwks")
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(fmt.Sprintf("can not fetch jwks, status code %v", resp.StatusCode))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	if err != nil {
+		return nil, errors.Wrap(err, "can not read jwks")
+	}
+
+	return ParseJwks(body)
+}
+
+// ParseJwks skips keys of unsupported types, encryption keys and symmetric "oct" keys, as anyone who can read
+// the key set could sign tokens with them
+func ParseJwks(data []byte) ([]Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, errors.Wrap(err, "can not parse jwks")
+	}
+
+	var keys []Key
+
+	for _, item := range set.Keys {
+		if item.Use == "enc" {
+			continue
+		}
+
+		material, err := item.material()
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "can not parse jwk %v", item.Kid)
+		}
+
+		if material == nil {
+			continue
+		}
+
+		keys = append(keys, Key{
+			Id:        item.Kid,
+			Algorithm: Algorithm(item.Alg),
+			Material:  material,
+		})
+	}
+
+	return keys, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (j jwk) material() (interface{}, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(j.E)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if j.Crv != "P-256" {
+			return nil, nil
+		}
+
+		x, err := decodeBigInt(j.X)
+
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(j.Y)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("ec point is not on curve")
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+
+	return nil, nil
+}
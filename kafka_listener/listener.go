@@ -7,6 +7,7 @@ import (
 	"github.com/cenkalti/backoff/v4"
 	"github.com/digitalmonsters/go-common/apm_helper"
 	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/redact"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
@@ -570,7 +571,7 @@ func kafkaMessageToFancyLog(messages []kafka.Message) []string {
 	var r []string
 
 	for _, m := range messages {
-		r = append(r, string(m.Value))
+		r = append(r, string(redact.Json(m.Value)))
 	}
 
 	return r
@@ -1,26 +1,33 @@
 package ops
 
 import (
+	"context"
 	"fmt"
 	fastRouter "github.com/fasthttp/router"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"sync/atomic"
 	"time"
 )
 
 type PrivateHttpServer struct {
-	srv        *fasthttp.Server
-	realRouter *fastRouter.Router
-	ready      bool
-	healthy    bool
+	srv          *fasthttp.Server
+	realRouter   *fastRouter.Router
+	ready        int32
+	healthy      int32
+	inFlight     int64
+	shuttingDown int32
+	drainDelay   time.Duration
 }
 
 func NewPrivateHttpServer() *PrivateHttpServer {
 	h := &PrivateHttpServer{
 		realRouter: fastRouter.New(),
-		healthy:    true,
+		healthy:    1,
 	}
 
 	h.registerHttpReadinessCheck()
@@ -34,9 +41,16 @@ func (r *PrivateHttpServer) registerMetrics() {
 	r.realRouter.GET("/metrics", fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler()))
 }
 
+// Handle registers additional ops handler, should be called before StartAsync
+func (r *PrivateHttpServer) Handle(method string, path string, handler fasthttp.RequestHandler) *PrivateHttpServer {
+	r.realRouter.Handle(method, path, handler)
+
+	return r
+}
+
 func (r *PrivateHttpServer) registerHttpReadinessCheck() {
 	r.realRouter.GET("/readiness", func(ctx *fasthttp.RequestCtx) {
-		if r.ready {
+		if atomic.LoadInt32(&r.ready) == 1 {
 			ctx.Response.SetStatusCode(200)
 		} else {
 			ctx.Response.SetStatusCode(500)
@@ -46,7 +60,7 @@ func (r *PrivateHttpServer) registerHttpReadinessCheck() {
 
 func (r *PrivateHttpServer) registerHttpHealthCheck() {
 	r.realRouter.GET("/health", func(ctx *fasthttp.RequestCtx) {
-		if r.healthy {
+		if atomic.LoadInt32(&r.healthy) == 1 {
 			ctx.Response.SetStatusCode(200)
 		} else {
 			ctx.Response.SetStatusCode(500)
@@ -54,12 +68,24 @@ func (r *PrivateHttpServer) registerHttpHealthCheck() {
 	})
 }
 
+// WithShutdownDrainDelay keeps serving requests for delay after readiness is set to not ready,
+// so balancers have time to notice it before the listener is closed
+func (r *PrivateHttpServer) WithShutdownDrainDelay(delay time.Duration) *PrivateHttpServer {
+	r.drainDelay = delay
+
+	return r
+}
+
 func (r *PrivateHttpServer) Ready() {
-	r.ready = true
+	atomic.StoreInt32(&r.ready, 1)
+}
+
+func (r *PrivateHttpServer) NotReady() {
+	atomic.StoreInt32(&r.ready, 0)
 }
 
 func (r *PrivateHttpServer) UnHealthy() {
-	r.healthy = false
+	atomic.StoreInt32(&r.healthy, 0)
 
 	time.Sleep(7 * time.Second)
 }
@@ -69,18 +95,110 @@ func (r *PrivateHttpServer) StartAsync(port int) *PrivateHttpServer {
 		return r
 	}
 
+	handler := fasthttp.CompressHandlerBrotliLevel(r.realRouter.Handler,
+		fasthttp.CompressDefaultCompression, fasthttp.CompressDefaultCompression)
+
 	r.srv = &fasthttp.Server{
-		Handler: fasthttp.CompressHandlerBrotliLevel(r.realRouter.Handler,
-			fasthttp.CompressDefaultCompression, fasthttp.CompressDefaultCompression),
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			atomic.AddInt64(&r.inFlight, 1)
+			defer atomic.AddInt64(&r.inFlight, -1)
+
+			handler(ctx)
+		},
 	}
 
 	go func() {
 		log.Info().Msgf("Private http Server started on port [%v]", port)
 
-		if err := r.srv.ListenAndServe(fmt.Sprintf("0.0.0.0:%v", port)); err != nil {
+		if err := r.srv.ListenAndServe(fmt.Sprintf("0.0.0.0:%v", port)); err != nil &&
+			atomic.LoadInt32(&r.shuttingDown) == 0 {
 			panic(err)
 		}
 	}()
 
 	return r
 }
+
+// Shutdown marks server as not ready, waits for drain delay, stops accepting connections and waits for
+// in-flight requests until ctx is done
+func (r *PrivateHttpServer) Shutdown(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&r.shuttingDown, 0, 1) {
+		return nil
+	}
+
+	r.NotReady()
+
+	if r.drainDelay > 0 {
+		select {
+		case <-time.After(r.drainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	log.Info().Msgf("Private http Server shutting down. In-flight requests [%v]", atomic.LoadInt64(&r.inFlight))
+
+	if r.srv == nil {
+		return nil
+	}
+
+	srvDone := make(chan error, 1)
+
+	go func() {
+		srvDone <- r.srv.Shutdown()
+	}()
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	var srvErr error
+	srvClosed := false
+
+	// fasthttp Shutdown does not track hijacked connections, so in-flight counter is checked as well
+	for {
+		if srvClosed && atomic.LoadInt64(&r.inFlight) == 0 {
+			return srvErr
+		}
+
+		select {
+		case srvErr = <-srvDone:
+			srvClosed = true
+		case <-ticker.C:
+		case <-ctx.Done():
+			return errors.New(fmt.Sprintf("private http server shutdown interrupted with [%v] in-flight requests",
+				atomic.LoadInt64(&r.inFlight)))
+		}
+	}
+}
+
+type PrivateHttpServerSubApplication struct {
+	server          *PrivateHttpServer
+	port            int
+	shutdownTimeout time.Duration
+}
+
+// NewPrivateHttpServerSubApplication allows to register private server in application.BaseApplication
+func NewPrivateHttpServerSubApplication(server *PrivateHttpServer, port int,
+	shutdownTimeout time.Duration) *PrivateHttpServerSubApplication {
+	return &PrivateHttpServerSubApplication{
+		server:          server,
+		port:            port,
+		shutdownTimeout: shutdownTimeout,
+	}
+}
+
+func (s *PrivateHttpServerSubApplication) Init(subAppLogger zerolog.Logger) error {
+	s.server.StartAsync(s.port)
+
+	return nil
+}
+
+func (s *PrivateHttpServerSubApplication) Name() string {
+	return "private_http_server"
+}
+
+func (s *PrivateHttpServerSubApplication) Close() error {
+	ctx, cancelFn := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancelFn()
+
+	return s.server.Shutdown(ctx)
+}
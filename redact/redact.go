@@ -0,0 +1,267 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+const Mask = "[REDACTED]"
+
+// Policy is immutable, every With* method returns a copy. Field names are matched case-insensitive
+// and without "_" and "-", so "access_token" also matches "accessToken".
+type Policy struct {
+	fields map[string]struct{}
+	paths  [][]string
+}
+
+var defaultPolicy atomic.Value
+
+func init() {
+	defaultPolicy.Store(NewPolicy().WithFields("password", "new_password", "old_password", "passwd", "token",
+		"access_token", "refresh_token", "id_token", "jwt", "api_key", "secret", "client_secret", "authorization",
+		"email", "wallet_address", "private_key", "seed_phrase", "mnemonic", "otp", "pin", "card_number", "cvv"))
+}
+
+func Default() *Policy {
+	return defaultPolicy.Load().(*Policy)
+}
+
+func SetDefault(policy *Policy) {
+	defaultPolicy.Store(policy)
+}
+
+// Json applies default policy
+func Json(body []byte) []byte {
+	return Default().Json(body)
+}
+
+func NewPolicy() *Policy {
+	return &Policy{
+		fields: map[string]struct{}{},
+	}
+}
+
+func normalizeFieldName(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+func (p *Policy) clone() *Policy {
+	c := &Policy{
+		fields: make(map[string]struct{}, len(p.fields)),
+		paths:  append([][]string{}, p.paths...),
+	}
+
+	for k := range p.fields {
+		c.fields[k] = struct{}{}
+	}
+
+	return c
+}
+
+// WithFields masks value of the field with such name at any depth
+func (p *Policy) WithFields(names ...string) *Policy {
+	c := p.clone()
+
+	for _, name := range names {
+		c.fields[normalizeFieldName(name)] = struct{}{}
+	}
+
+	return c
+}
+
+// WithPaths masks value at dot separated json path, like "data.user.phone". Arrays are transparent,
+// so "items.token" matches token of every item. "*" matches any key.
+func (p *Policy) WithPaths(paths ...string) *Policy {
+	c := p.clone()
+
+	for _, path := range paths {
+		if len(path) > 0 {
+			c.paths = append(c.paths, strings.Split(path, "."))
+		}
+	}
+
+	return c
+}
+
+// WithStructTags adds paths of fields tagged with `redact:"true"` in samples. prefix is the path where the struct
+// is placed in logged body, for example "result" for rpc responses.
+func (p *Policy) WithStructTags(prefix string, samples ...interface{}) *Policy {
+	var paths []string
+
+	for _, sample := range samples {
+		if sample == nil {
+			continue
+		}
+
+		collectTaggedPaths(reflect.TypeOf(sample), prefix, map[reflect.Type]bool{}, &paths)
+	}
+
+	return p.WithPaths(paths...)
+}
+
+func collectTaggedPaths(t reflect.Type, prefix string, visited map[reflect.Type]bool, paths *[]string) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || visited[t] {
+		return
+	}
+
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+
+		if tag := field.Tag.Get("json"); len(tag) > 0 {
+			if tag == "-" {
+				continue
+			}
+
+			if jsonName := strings.Split(tag, ",")[0]; len(jsonName) > 0 {
+				name = jsonName
+			}
+		}
+
+		path := name
+
+		if field.Anonymous && len(field.Tag.Get("json")) == 0 {
+			path = prefix
+		} else if len(prefix) > 0 {
+			path = prefix + "." + name
+		}
+
+		if field.Tag.Get("redact") == "true" {
+			*paths = append(*paths, path)
+
+			continue
+		}
+
+		collectTaggedPaths(field.Type, path, visited, paths)
+	}
+}
+
+func (p *Policy) Merge(other *Policy) *Policy {
+	if other == nil {
+		return p
+	}
+
+	c := p.clone()
+
+	for k := range other.fields {
+		c.fields[k] = struct{}{}
+	}
+
+	c.paths = append(c.paths, other.paths...)
+
+	return c
+}
+
+func (p *Policy) IsEmpty() bool {
+	return len(p.fields) == 0 && len(p.paths) == 0
+}
+
+// Json returns body with masked values. Body which is not a json object or array is returned as is.
+func (p *Policy) Json(body []byte) []byte {
+	trimmed := bytes.TrimSpace(body)
+
+	if p == nil || p.IsEmpty() || len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return body
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+
+	var value interface{}
+
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+
+	if !p.redactValue(value, nil) {
+		return body
+	}
+
+	result, err := json.Marshal(value)
+
+	if err != nil {
+		return body
+	}
+
+	return result
+}
+
+// Value redacts already decoded json value (maps and slices) in place
+func (p *Policy) Value(value interface{}) interface{} {
+	if p != nil && !p.IsEmpty() {
+		p.redactValue(value, nil)
+	}
+
+	return value
+}
+
+func (p *Policy) redactValue(value interface{}, path []string) (changed bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			itemPath := append(append([]string{}, path...), key)
+
+			if p.shouldMask(key, itemPath) {
+				v[key] = Mask
+				changed = true
+
+				continue
+			}
+
+			if p.redactValue(item, itemPath) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if p.redactValue(item, path) {
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+func (p *Policy) shouldMask(key string, path []string) bool {
+	if _, ok := p.fields[normalizeFieldName(key)]; ok {
+		return true
+	}
+
+	for _, rule := range p.paths {
+		if len(rule) != len(path) {
+			continue
+		}
+
+		matched := true
+
+		for i := range rule {
+			if rule[i] != "*" && rule[i] != path[i] {
+				matched = false
+
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
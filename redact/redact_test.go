@@ -0,0 +1,69 @@
+package redact
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func decode(t *testing.T, body []byte) map[string]interface{} {
+	var result map[string]interface{}
+
+	assert.Nil(t, json.Unmarshal(body, &result))
+
+	return result
+}
+
+func TestFieldRules(t *testing.T) {
+	body := Default().Json([]byte(`{"user":{"email":"a@b.c","name":"john"},"accessToken":"x","items":[{"Password":"1","id":1}]}`))
+
+	result := decode(t, body)
+
+	assert.Equal(t, Mask, result["user"].(map[string]interface{})["email"])
+	assert.Equal(t, "john", result["user"].(map[string]interface{})["name"])
+	assert.Equal(t, Mask, result["accessToken"])
+	assert.Equal(t, Mask, result["items"].([]interface{})[0].(map[string]interface{})["Password"])
+}
+
+func TestNumbersKeepPrecision(t *testing.T) {
+	assert.Equal(t, `{"id":9007199254740993,"token":"[REDACTED]"}`,
+		string(Default().Json([]byte(`{"id":9007199254740993,"token":"t"}`))))
+}
+
+func TestPathRules(t *testing.T) {
+	policy := NewPolicy().WithPaths("data.phone", "list.*.value")
+
+	result := decode(t, policy.Json([]byte(`{"data":{"phone":"123","other":{"phone":"456"}},"list":[{"a":{"value":1}}]}`)))
+
+	assert.Equal(t, Mask, result["data"].(map[string]interface{})["phone"])
+	assert.Equal(t, "456", result["data"].(map[string]interface{})["other"].(map[string]interface{})["phone"])
+	assert.Equal(t, Mask, result["list"].([]interface{})[0].(map[string]interface{})["a"].(map[string]interface{})["value"])
+}
+
+type taggedInner struct {
+	Secret string `json:"card" redact:"true"`
+	Next   *taggedInner
+}
+
+type taggedRequest struct {
+	Phone string        `json:"phone" redact:"true"`
+	Name  string        `json:"name"`
+	Inner []taggedInner `json:"inner"`
+}
+
+func TestStructTags(t *testing.T) {
+	policy := NewPolicy().WithStructTags("params", taggedRequest{})
+
+	result := decode(t, policy.Json([]byte(`{"params":{"phone":"1","name":"n","inner":[{"card":"4111","Next":{"card":"1"}}]}}`)))
+	params := result["params"].(map[string]interface{})
+
+	assert.Equal(t, Mask, params["phone"])
+	assert.Equal(t, "n", params["name"])
+	assert.Equal(t, Mask, params["inner"].([]interface{})[0].(map[string]interface{})["card"])
+}
+
+func TestNotJson(t *testing.T) {
+	assert.Equal(t, "plain text", string(Default().Json([]byte("plain text"))))
+	assert.Equal(t, `{"broken"`, string(Default().Json([]byte(`{"broken"`))))
+	assert.Equal(t, ` {"name": 1} `, string(Default().Json([]byte(` {"name": 1} `))))
+}
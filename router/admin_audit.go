@@ -0,0 +1,298 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/redact"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const adminAuditWriteTimeout = 5 * time.Second
+
+type AdminAuditSink interface {
+	Write(ctx context.Context, event eventsourcing.AdminAuditEvent) error
+}
+
+// WithAdminAudit publishes an event for every AdminCommand and LegacyAdminCommand execution to kafka
+func (r *HttpRouter) WithAdminAudit(publisher eventsourcing.Publisher[eventsourcing.AdminAuditEvent]) *HttpRouter {
+	return r.WithAdminAuditSink(NewKafkaAdminAuditSink(publisher))
+}
+
+// WithAdminAuditSink adds a sink, event is written to all of them
+func (r *HttpRouter) WithAdminAuditSink(sink AdminAuditSink) *HttpRouter {
+	r.adminAuditSinks = append(r.adminAuditSinks, sink)
+
+	return r
+}
+
+type kafkaAdminAuditSink struct {
+	publisher eventsourcing.Publisher[eventsourcing.AdminAuditEvent]
+}
+
+func NewKafkaAdminAuditSink(publisher eventsourcing.Publisher[eventsourcing.AdminAuditEvent]) AdminAuditSink {
+	return &kafkaAdminAuditSink{
+		publisher: publisher,
+	}
+}
+
+// Write does not wait for the batch to be flushed, failures are logged
+func (s *kafkaAdminAuditSink) Write(ctx context.Context, event eventsourcing.AdminAuditEvent) error {
+	ch := s.publisher.Publish(ctx, event)
+
+	if ch == nil {
+		return nil
+	}
+
+	go func() {
+		for err := range ch {
+			if err != nil {
+				log.Err(err).Str("method", event.Method).Int64("admin_id", event.AdminId).
+					Msg("can not publish admin audit event")
+			}
+		}
+	}()
+
+	return nil
+}
+
+type adminAuditState struct {
+	mutex      sync.Mutex
+	diffs      []eventsourcing.AdminAuditDiff
+	policy     *redact.Policy
+	adminId    int64
+	authorized bool
+}
+
+// setAuthorized is called by AuthorizationMiddleware, requests which never reach it are audited as denied
+func (s *adminAuditState) setAuthorized(adminId int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.adminId = adminId
+	s.authorized = true
+}
+
+func claimedAdminId(executionData MethodExecutionData) int64 {
+	if executionData.httpCtx == nil {
+		return 0
+	}
+
+	adminId, _ := strconv.ParseInt(string(executionData.httpCtx.Request.Header.Peek("Admin-Id")), 10, 64)
+
+	return adminId
+}
+
+// AddAuditDiff attaches changed fields of the entity to the admin audit event. before is nil for created entities
+// and after is nil for deleted ones. Does nothing outside of audited admin commands.
+func (m MethodExecutionData) AddAuditDiff(entity string, entityId interface{}, before interface{}, after interface{}) {
+	if m.audit == nil {
+		return
+	}
+
+	diff, err := buildAuditDiff(entity, fmt.Sprint(entityId), before, after, m.audit.policy)
+
+	if err != nil {
+		apm_helper.LogError(err, m.Context)
+
+		return
+	}
+
+	m.audit.mutex.Lock()
+	m.audit.diffs = append(m.audit.diffs, diff)
+	m.audit.mutex.Unlock()
+}
+
+func auditFields(value interface{}, policy *redact.Policy) (map[string]json.RawMessage, error) {
+	if value == nil {
+		return map[string]json.RawMessage{}, nil
+	}
+
+	b, err := json.Marshal(value)
+
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	b = policy.Json(b)
+
+	fields := map[string]json.RawMessage{}
+
+	if err = json.Unmarshal(b, &fields); err != nil { // not an object
+		return map[string]json.RawMessage{"value": b}, nil
+	}
+
+	return fields, nil
+}
+
+func buildAuditDiff(entity string, entityId string, before interface{}, after interface{},
+	policy *redact.Policy) (eventsourcing.AdminAuditDiff, error) {
+	diff := eventsourcing.AdminAuditDiff{
+		Entity:   entity,
+		EntityId: entityId,
+		Changes:  map[string]eventsourcing.AdminAuditFieldChange{},
+	}
+
+	beforeFields, err := auditFields(before, policy)
+
+	if err != nil {
+		return diff, err
+	}
+
+	afterFields, err := auditFields(after, policy)
+
+	if err != nil {
+		return diff, err
+	}
+
+	var keys []string
+
+	for k := range beforeFields {
+		keys = append(keys, k)
+	}
+
+	for k := range afterFields {
+		if _, ok := beforeFields[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if !bytes.Equal(beforeFields[k], afterFields[k]) {
+			diff.Changes[k] = eventsourcing.AdminAuditFieldChange{
+				Before: beforeFields[k],
+				After:  afterFields[k],
+			}
+		}
+	}
+
+	return diff, nil
+}
+
+func isAuditedCommand(cmd ICommand) bool {
+	switch cmd.(type) {
+	case *AdminCommand, *LegacyAdminCommand:
+		return true
+	}
+
+	return false
+}
+
+// redactAuditParams applies command policy, which has rpc paths prefixed with "params"
+func redactAuditParams(request []byte, policy *redact.Policy) json.RawMessage {
+	if len(bytes.TrimSpace(request)) == 0 {
+		return nil
+	}
+
+	if !json.Valid(request) {
+		b, _ := json.Marshal(string(request))
+
+		return b
+	}
+
+	var wrapped struct {
+		Params json.RawMessage `json:"params"`
+	}
+
+	if err := json.Unmarshal(policy.Json([]byte(fmt.Sprintf(`{"params":%s}`, request))), &wrapped); err != nil {
+		return nil
+	}
+
+	return wrapped.Params
+}
+
+// AdminAuditMiddleware should run before AuthorizationMiddleware, so rejected attempts are audited too.
+// Admin id is set by AuthorizationMiddleware, rejected attempts have the claimed one
+func (r *HttpRouter) AdminAuditMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (resp interface{}, err *error_codes.ErrorWithCode) {
+			if len(r.adminAuditSinks) == 0 || !isAuditedCommand(executionData.command) {
+				return next(request, executionData)
+			}
+
+			state := &adminAuditState{
+				policy:  getRedactPolicy(executionData.command),
+				adminId: claimedAdminId(executionData),
+			}
+
+			executionData.audit = state
+			startedAt := time.Now().UTC()
+			finished := false
+
+			defer func() {
+				if !finished { // panic, it is recovered by RecoveryMiddleware
+					r.writeAdminAudit(request, executionData, state, startedAt,
+						error_codes.NewErrorWithCodeRef(errors.New("command panicked"), error_codes.GenericPanicError))
+				}
+			}()
+
+			resp, err = next(request, executionData)
+			finished = true
+
+			r.writeAdminAudit(request, executionData, state, startedAt, err)
+
+			return resp, err
+		}
+	}
+}
+
+func (r *HttpRouter) writeAdminAudit(request []byte, executionData MethodExecutionData, state *adminAuditState,
+	startedAt time.Time, cmdErr *error_codes.ErrorWithCode) {
+	cmd := executionData.command
+
+	state.mutex.Lock()
+	diffs := state.diffs
+	adminId := state.adminId
+	authorized := state.authorized
+	state.mutex.Unlock()
+
+	event := eventsourcing.AdminAuditEvent{
+		AdminId:     adminId,
+		RbacObj:     cmd.GetObj(),
+		AccessLevel: cmd.AccessLevel().ToString(),
+		Endpoint:    string(executionData.endpointType),
+		Method:      cmd.GetMethodName(),
+		Params:      redactAuditParams(request, state.policy),
+		Status:      eventsourcing.AdminAuditStatusSuccess,
+		Ip:          executionData.UserIp,
+		StartedAt:   startedAt,
+		FinishedAt:  time.Now().UTC(),
+		Diffs:       diffs,
+	}
+
+	if executionData.ApmTransaction != nil {
+		event.TraceId = executionData.ApmTransaction.TraceContext().Trace.String()
+	}
+
+	if cmdErr != nil {
+		event.Status = eventsourcing.AdminAuditStatusError
+
+		if !authorized {
+			event.Status = eventsourcing.AdminAuditStatusDenied
+		}
+
+		event.ErrorCode = int(cmdErr.GetCode())
+		event.ErrorMessage = cmdErr.GetMessage()
+	}
+
+	// request context can be already cancelled by timeout, but the event should still be stored
+	ctx, cancelFn := context.WithTimeout(context.Background(), adminAuditWriteTimeout)
+	defer cancelFn()
+
+	for _, sink := range r.adminAuditSinks {
+		if err := sink.Write(ctx, event); err != nil {
+			apm_helper.LogError(errors.Wrap(err, "can not write admin audit event"), executionData.Context)
+		}
+	}
+}
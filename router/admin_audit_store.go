@@ -0,0 +1,66 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// AdminAuditPostgresSchema should be applied by service migrations before using NewPostgresAdminAuditSink
+const AdminAuditPostgresSchema = `create table if not exists admin_audit_events
+(
+    id            bigserial primary key,
+    admin_id      bigint    not null,
+    rbac_obj      text      not null default '',
+    access_level  text      not null default '',
+    endpoint      text      not null default '',
+    method        text      not null,
+    params        jsonb,
+    status        text      not null,
+    error_code    integer   not null default 0,
+    error_message text      not null default '',
+    ip            text      not null default '',
+    trace_id      text      not null default '',
+    diffs         jsonb,
+    started_at    timestamp not null,
+    finished_at   timestamp not null
+);
+create index if not exists admin_audit_events_admin_id_idx on admin_audit_events (admin_id, started_at);
+create index if not exists admin_audit_events_method_idx on admin_audit_events (method, started_at);`
+
+type postgresAdminAuditSink struct {
+	db *gorm.DB
+}
+
+func NewPostgresAdminAuditSink(db *gorm.DB) AdminAuditSink {
+	return &postgresAdminAuditSink{
+		db: db,
+	}
+}
+
+func (s *postgresAdminAuditSink) Write(ctx context.Context, event eventsourcing.AdminAuditEvent) error {
+	var params interface{}
+	var diffs interface{}
+
+	if len(event.Params) > 0 {
+		params = string(event.Params)
+	}
+
+	if len(event.Diffs) > 0 {
+		b, err := json.Marshal(event.Diffs)
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		diffs = string(b)
+	}
+
+	return errors.WithStack(s.db.WithContext(ctx).Exec(`insert into admin_audit_events (admin_id, rbac_obj, access_level,
+		endpoint, method, params, status, error_code, error_message, ip, trace_id, diffs, started_at, finished_at)
+		values (?, ?, ?, ?, ?, ?::jsonb, ?, ?, ?, ?, ?, ?::jsonb, ?, ?)`, event.AdminId, event.RbacObj, event.AccessLevel,
+		event.Endpoint, event.Method, params, event.Status, event.ErrorCode, event.ErrorMessage, event.Ip, event.TraceId,
+		diffs, event.StartedAt, event.FinishedAt).Error)
+}
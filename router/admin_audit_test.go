@@ -0,0 +1,131 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/redact"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+type testAdminAuditSink struct {
+	mutex  sync.Mutex
+	events []eventsourcing.AdminAuditEvent
+}
+
+func (s *testAdminAuditSink) Write(ctx context.Context, event eventsourcing.AdminAuditEvent) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.events = append(s.events, event)
+
+	return nil
+}
+
+type auditTestRequest struct {
+	UserId   int64  `json:"user_id"`
+	Password string `json:"password"`
+	Phone    string `json:"phone" redact:"true"`
+}
+
+type auditTestUser struct {
+	Id     int64  `json:"id"`
+	Name   string `json:"name"`
+	Banned bool   `json:"banned"`
+	Email  string `json:"email"`
+}
+
+var adminHeaders = map[string]string{"X-Ext-Authz-Check-Result": "allowed", "Admin-Id": "7"}
+
+func TestAdminAuditEvent(t *testing.T) {
+	sink := &testAdminAuditSink{}
+
+	r := NewRouter("", nil).WithAdminAuditSink(sink)
+
+	endpoint := r.GetRpcAdminEndpoint()
+
+	assert.Nil(t, endpoint.RegisterRpcCommand(NewTypedAdminCommand("ban_user",
+		func(request auditTestRequest, executionData MethodExecutionData) (bool, *error_codes.ErrorWithCode) {
+			executionData.AddAuditDiff("user", request.UserId,
+				auditTestUser{Id: request.UserId, Name: "john", Email: "a@b.c"},
+				auditTestUser{Id: request.UserId, Name: "john", Banned: true, Email: "c@d.e"})
+
+			return true, nil
+		}, common.AccessLevelPublic, "users")))
+
+	assert.Nil(t, endpoint.RegisterRpcCommand(NewAdminCommand("fail",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			return nil, error_codes.NewErrorWithCodeRef(errors.New("not found"), error_codes.GenericNotFoundError)
+		}, common.AccessLevelPublic, "users")))
+
+	assert.Nil(t, endpoint.RegisterRpcCommand(NewAdminCommand("panic",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			panic("boom")
+		}, common.AccessLevelPublic, "users")))
+
+	doRequest(r, "POST", "/rpc-admin", `{"method":"ban_user","params":{"user_id":5,"password":"p","phone":"123"},"id":"1"}`,
+		adminHeaders)
+	doRequest(r, "POST", "/rpc-admin", `{"method":"fail","params":{"id":1},"id":"2"}`, adminHeaders)
+	doRequest(r, "POST", "/rpc-admin", `{"method":"panic","id":"3"}`, adminHeaders)
+	doRequest(r, "POST", "/rpc-admin", `{"method":"fail","id":"4"}`, map[string]string{"Admin-Id": "8"}) // not authorized
+
+	assert.Len(t, sink.events, 4)
+
+	event := sink.events[0]
+
+	assert.Equal(t, int64(7), event.AdminId)
+	assert.Equal(t, "users", event.RbacObj)
+	assert.Equal(t, "ban_user", event.Method)
+	assert.Equal(t, string(EndpointRpcAdmin), event.Endpoint)
+	assert.Equal(t, eventsourcing.AdminAuditStatusSuccess, event.Status)
+	assert.JSONEq(t, `{"user_id":5,"password":"[REDACTED]","phone":"[REDACTED]"}`, string(event.Params))
+	assert.False(t, event.FinishedAt.Before(event.StartedAt))
+
+	assert.Len(t, event.Diffs, 1)
+	assert.Equal(t, "5", event.Diffs[0].EntityId)
+
+	changes, _ := json.Marshal(event.Diffs[0].Changes)
+	assert.JSONEq(t, `{"banned":{"before":false,"after":true}}`, string(changes))
+
+	assert.Equal(t, eventsourcing.AdminAuditStatusError, sink.events[1].Status)
+	assert.Equal(t, int(error_codes.GenericNotFoundError), sink.events[1].ErrorCode)
+
+	assert.Equal(t, eventsourcing.AdminAuditStatusError, sink.events[2].Status)
+	assert.Equal(t, int(error_codes.GenericPanicError), sink.events[2].ErrorCode)
+
+	assert.Equal(t, eventsourcing.AdminAuditStatusDenied, sink.events[3].Status)
+	assert.Equal(t, int64(8), sink.events[3].AdminId)
+	assert.Equal(t, "fail", sink.events[3].Method)
+}
+
+func TestAdminAuditDiffCreatedEntity(t *testing.T) {
+	diff, err := buildAuditDiff("user", "1", nil, auditTestUser{Id: 1, Name: "n"}, redact.Default())
+
+	assert.Nil(t, err)
+	assert.Len(t, diff.Changes, 4)
+	assert.Nil(t, diff.Changes["name"].Before)
+	assert.Equal(t, `"n"`, string(diff.Changes["name"].After))
+}
+
+func TestKafkaAdminAuditSink(t *testing.T) {
+	published := make(chan eventsourcing.AdminAuditEvent, 1)
+
+	sink := NewKafkaAdminAuditSink(&eventsourcing.PublisherMock[eventsourcing.AdminAuditEvent]{
+		PublishFn: func(ctx context.Context, messages ...eventsourcing.AdminAuditEvent) chan error {
+			ch := make(chan error, 1)
+
+			published <- messages[0]
+			close(ch)
+
+			return ch
+		},
+	})
+
+	assert.Nil(t, sink.Write(context.Background(), eventsourcing.AdminAuditEvent{AdminId: 3, Method: "m"}))
+	assert.Equal(t, "3", (<-published).GetPublishKey())
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/go-common/error_codes"
 	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/digitalmonsters/go-common/swagger"
 	"github.com/digitalmonsters/go-common/translation"
 	"github.com/digitalmonsters/go-common/wrappers/auth"
 	"github.com/digitalmonsters/go-common/wrappers/auth_go"
@@ -24,6 +25,8 @@ type AdminCommand struct {
 	requireIdentityValidation bool
 	allowBanned               bool
 	obj                       string
+	apiDescription            *swagger.ApiDescription
+	commandOptions
 }
 
 func NewAdminCommand(methodName string, fn CommandFunc, accessLevel common.AccessLevel, rbacObj string) ICommand {
@@ -98,15 +101,37 @@ func (a AdminCommand) CanExecute(httpCtx *fasthttp.RequestCtx, ctx context.Conte
 		return currentUserId, false, false, language, nil
 	}
 
-	ch := <-authWrapper.CheckAdminPermissions(currentUserId, a.obj, apm.TransactionFromContext(ctx), false)
+	hasAccess := false
 
-	if ch.Error != nil {
-		return 0, false, false, language, &rpc.ExtendedLocalRpcError{
-			RpcError: *ch.Error,
+	if checker, ok := authWrapper.(AdminAccessChecker); ok { // local rbac
+		allowed, err := checker.CheckAdminAccess(ctx, currentUserId, a.obj, a.accessLevel)
+
+		if err != nil {
+			return 0, false, false, language, &rpc.ExtendedLocalRpcError{
+				RpcError: rpc.RpcError{
+					Code:        error_codes.GenericServerError,
+					Message:     err.Error(),
+					Hostname:    hostName,
+					ServiceName: hostName,
+				},
+				LocalHandlingError: err,
+			}
+		}
+
+		hasAccess = allowed
+	} else {
+		ch := <-authWrapper.CheckAdminPermissions(currentUserId, a.obj, apm.TransactionFromContext(ctx), false)
+
+		if ch.Error != nil {
+			return 0, false, false, language, &rpc.ExtendedLocalRpcError{
+				RpcError: *ch.Error,
+			}
 		}
+
+		hasAccess = ch.Resp.HasAccess
 	}
 
-	if ch.Resp.HasAccess {
+	if hasAccess {
 		return currentUserId, false, false, language, nil
 	}
 
@@ -158,3 +183,7 @@ func (a AdminCommand) GetMethodName() string {
 func (a AdminCommand) GetFn() CommandFunc {
 	return a.fn
 }
+
+func (a AdminCommand) getApiDescription() *swagger.ApiDescription {
+	return a.apiDescription
+}
@@ -20,6 +20,7 @@ type LegacyAdminCommand struct {
 	fn                        CommandFunc
 	requireIdentityValidation bool
 	allowBanned               bool
+	commandOptions
 }
 
 func NewLegacyAdminCommand(methodName string, fn CommandFunc) ICommand {
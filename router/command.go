@@ -5,6 +5,7 @@ import (
 	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/go-common/error_codes"
 	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/digitalmonsters/go-common/swagger"
 	"github.com/digitalmonsters/go-common/translation"
 	"github.com/digitalmonsters/go-common/wrappers/auth_go"
 	"github.com/pkg/errors"
@@ -35,6 +36,8 @@ type Command struct {
 	fn                        CommandFunc
 	requireIdentityValidation bool
 	allowBanned               bool
+	apiDescription            *swagger.ApiDescription
+	commandOptions
 }
 
 func (c *Command) Execute(request []byte, data MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
@@ -83,6 +86,10 @@ func (c Command) GetFn() CommandFunc {
 	return c.fn
 }
 
+func (c Command) getApiDescription() *swagger.ApiDescription {
+	return c.apiDescription
+}
+
 func (c Command) CanExecute(httpCtx *fasthttp.RequestCtx, ctx context.Context, auth auth_go.IAuthGoWrapper, userValidator UserExecutorValidator) (int64, bool, bool, translation.Language, *rpc.ExtendedLocalRpcError) {
 	return publicCanExecuteLogic(httpCtx, c.requireIdentityValidation, c.allowBanned, userValidator)
 }
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"github.com/digitalmonsters/go-common/redact"
+	"time"
+)
+
+// commandOptions is embedded into every command type and keeps optional per-command behaviour
+type commandOptions struct {
+	middlewares    []Middleware
+	rateLimits     []RateLimit
+	timeout        time.Duration
+	cachePolicy    *CachePolicy
+	idempotencyTtl time.Duration
+	redactPolicy   *redact.Policy
+}
+
+type commandOptionsHolder interface {
+	getCommandOptions() *commandOptions
+}
+
+func (o *commandOptions) getCommandOptions() *commandOptions {
+	return o
+}
+
+func getCommandOptions(cmd ICommand) commandOptions {
+	if holder, ok := cmd.(commandOptionsHolder); ok {
+		return *holder.getCommandOptions()
+	}
+
+	return commandOptions{}
+}
@@ -0,0 +1,516 @@
+package router
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/ops"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const commandStatsWindow = 512
+
+// commandStats keeps last commandStatsWindow durations for percentiles
+type commandStats struct {
+	calls     int64
+	errors    int64
+	mutex     sync.Mutex
+	durations []time.Duration
+	next      int
+}
+
+func (s *commandStats) record(duration time.Duration, isError bool) {
+	atomic.AddInt64(&s.calls, 1)
+
+	if isError {
+		atomic.AddInt64(&s.errors, 1)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.durations) < commandStatsWindow {
+		s.durations = append(s.durations, duration)
+
+		return
+	}
+
+	s.durations[s.next] = duration
+	s.next = (s.next + 1) % commandStatsWindow
+}
+
+func (s *commandStats) percentile(p float64) time.Duration {
+	s.mutex.Lock()
+	sorted := append([]time.Duration{}, s.durations...)
+	s.mutex.Unlock()
+
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
+}
+
+type disabledCommand struct {
+	reason string
+	until  time.Time
+}
+
+type commandRegistry struct {
+	stats    sync.Map
+	mutex    sync.RWMutex
+	disabled map[string]disabledCommand
+}
+
+func newCommandRegistry() *commandRegistry {
+	return &commandRegistry{
+		disabled: map[string]disabledCommand{},
+	}
+}
+
+// commandKey uses the same method name as metrics, so rest commands are "GET /path/{id}"
+func commandKey(endpoint EndpointType, cmd ICommand) string {
+	return fmt.Sprintf("%v %v", endpoint, getMetricLabels(endpoint, cmd)["method"])
+}
+
+func (c *commandRegistry) getStats(key string) *commandStats {
+	stats, _ := c.stats.LoadOrStore(key, &commandStats{})
+
+	return stats.(*commandStats)
+}
+
+func (c *commandRegistry) record(endpoint EndpointType, cmd ICommand, rpcResponse rpc.RpcResponse) {
+	if cmd == nil {
+		return
+	}
+
+	c.getStats(commandKey(endpoint, cmd)).record(time.Duration(rpcResponse.TotalTimingMs)*time.Millisecond,
+		rpcResponse.Error != nil)
+}
+
+func (c *commandRegistry) getDisabled(key string) (disabledCommand, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	disabled, ok := c.disabled[key]
+
+	if ok && !disabled.until.IsZero() && time.Now().After(disabled.until) {
+		return disabledCommand{}, false
+	}
+
+	return disabled, ok
+}
+
+func (c *commandRegistry) checkDisabled(endpoint EndpointType, cmd ICommand) *error_codes.ErrorWithCode {
+	disabled, ok := c.getDisabled(commandKey(endpoint, cmd))
+
+	if !ok {
+		return nil
+	}
+
+	return error_codes.NewErrorWithCodeRef(errors.New(fmt.Sprintf("command is temporarily disabled. %v",
+		disabled.reason)), error_codes.ServiceUnavailable)
+}
+
+type CommandInfo struct {
+	Endpoint                  string  `json:"endpoint"`
+	Method                    string  `json:"method"`
+	AccessLevel               string  `json:"access_level"`
+	Obj                       string  `json:"obj"`
+	RequireIdentityValidation bool    `json:"require_identity_validation"`
+	AllowBanned               bool    `json:"allow_banned"`
+	Timeout                   string  `json:"timeout"`
+	Calls                     int64   `json:"calls"`
+	Errors                    int64   `json:"errors"`
+	P95Ms                     float64 `json:"p95_ms"`
+	Disabled                  bool    `json:"disabled"`
+	DisabledReason            string  `json:"disabled_reason,omitempty"`
+	DisabledUntil             string  `json:"disabled_until,omitempty"`
+}
+
+type endpointCommand struct {
+	endpoint EndpointType
+	cmd      ICommand
+}
+
+func (r *HttpRouter) getAllCommands() []endpointCommand {
+	var result []endpointCommand
+
+	for _, e := range []struct {
+		endpointType EndpointType
+		endpoint     IRpcEndpoint
+	}{
+		{EndpointRpcPublic, r.rpcEndpointPublic},
+		{EndpointRpcAdmin, r.rpcEndpointAdmin},
+		{EndpointRpcAdminLegacy, r.rpcEndpointAdminLegacy},
+		{EndpointRpcService, r.rpcEndpointService},
+	} {
+		if e.endpoint == nil {
+			continue
+		}
+
+		for _, cmd := range e.endpoint.GetRegisteredCommands() {
+			result = append(result, endpointCommand{endpoint: e.endpointType, cmd: cmd})
+		}
+	}
+
+	for _, cmd := range r.restCommands {
+		result = append(result, endpointCommand{endpoint: EndpointRest, cmd: cmd})
+	}
+
+	for _, cmd := range r.streamCommands {
+		result = append(result, endpointCommand{endpoint: EndpointRest, cmd: cmd})
+	}
+
+	return result
+}
+
+// GetCommands returns every registered rpc, rest and stream command with live counters
+func (r *HttpRouter) GetCommands() []CommandInfo {
+	var result []CommandInfo
+
+	for _, item := range r.getAllCommands() {
+		key := commandKey(item.endpoint, item.cmd)
+		stats := r.commands.getStats(key)
+
+		info := CommandInfo{
+			Endpoint:                  string(item.endpoint),
+			Method:                    getMetricLabels(item.endpoint, item.cmd)["method"],
+			AccessLevel:               item.cmd.AccessLevel().ToString(),
+			Obj:                       item.cmd.GetObj(),
+			RequireIdentityValidation: item.cmd.RequireIdentityValidation(),
+			AllowBanned:               item.cmd.AllowBanned(),
+			Timeout:                   r.getCommandTimeout(item.cmd, item.endpoint).String(),
+			Calls:                     atomic.LoadInt64(&stats.calls),
+			Errors:                    atomic.LoadInt64(&stats.errors),
+			P95Ms:                     float64(stats.percentile(0.95)) / float64(time.Millisecond),
+		}
+
+		if disabled, ok := r.commands.getDisabled(key); ok {
+			info.Disabled = true
+			info.DisabledReason = disabled.reason
+
+			if !disabled.until.IsZero() {
+				info.DisabledUntil = disabled.until.UTC().Format(time.RFC3339)
+			}
+		}
+
+		result = append(result, info)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Endpoint != result[j].Endpoint {
+			return result[i].Endpoint < result[j].Endpoint
+		}
+
+		return result[i].Method < result[j].Method
+	})
+
+	return result
+}
+
+func (r *HttpRouter) findCommand(endpoint EndpointType, method string) (ICommand, bool) {
+	for _, item := range r.getAllCommands() {
+		if item.endpoint == endpoint && strings.EqualFold(getMetricLabels(item.endpoint, item.cmd)["method"], method) {
+			return item.cmd, true
+		}
+	}
+
+	return nil, false
+}
+
+// DisableCommand makes command return 503 until EnableCommand is called or duration passes. Zero duration
+// disables the command until it is enabled. method is "GET /path/{id}" for rest commands.
+func (r *HttpRouter) DisableCommand(endpoint EndpointType, method string, reason string,
+	duration time.Duration) error {
+	cmd, ok := r.findCommand(endpoint, method)
+
+	if !ok {
+		return errors.New(fmt.Sprintf("command [%v %v] not found", endpoint, method))
+	}
+
+	disabled := disabledCommand{reason: reason}
+
+	if duration > 0 {
+		disabled.until = time.Now().Add(duration)
+	}
+
+	r.commands.mutex.Lock()
+	r.commands.disabled[commandKey(endpoint, cmd)] = disabled
+	r.commands.mutex.Unlock()
+
+	return nil
+}
+
+func (r *HttpRouter) EnableCommand(endpoint EndpointType, method string) error {
+	cmd, ok := r.findCommand(endpoint, method)
+
+	if !ok {
+		return errors.New(fmt.Sprintf("command [%v %v] not found", endpoint, method))
+	}
+
+	r.commands.mutex.Lock()
+	delete(r.commands.disabled, commandKey(endpoint, cmd))
+	r.commands.mutex.Unlock()
+
+	return nil
+}
+
+// CommandToggleMiddleware rejects commands disabled with DisableCommand
+func (r *HttpRouter) CommandToggleMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			if err := r.commands.checkDisabled(executionData.endpointType, executionData.command); err != nil {
+				return nil, err
+			}
+
+			return next(request, executionData)
+		}
+	}
+}
+
+const (
+	debugTokenHeader   = "X-Debug-Token"
+	debugSessionCookie = "debug_session"
+)
+
+// RegisterCommandsDebug adds /debug/commands page to the private server. Page is json by default and html
+// with ?format=html. POST with endpoint, method, action (disable or enable), reason and duration (like "15m")
+// form values toggles the command. Token is required in X-Debug-Token header, for example
+//
+//	curl -H "X-Debug-Token: $TOKEN" -d "endpoint=rpc&method=tip&action=disable&duration=15m" ops:8081/debug/commands
+//
+// In a browser html page asks for the token once and keeps a session cookie, its forms carry csrf field.
+func (r *HttpRouter) RegisterCommandsDebug(server *ops.PrivateHttpServer, token string) error {
+	if len(token) == 0 {
+		return errors.New("token is required for /debug/commands")
+	}
+
+	handler := r.CommandsDebugHandler(token)
+
+	server.Handle(fasthttp.MethodGet, "/debug/commands", handler)
+	server.Handle(fasthttp.MethodPost, "/debug/commands", handler)
+
+	return nil
+}
+
+// CommandsDebugHandler rejects all requests when token is empty
+func (r *HttpRouter) CommandsDebugHandler(token string) fasthttp.RequestHandler {
+	return debugTokenProtected(token, func(httpCtx *fasthttp.RequestCtx) {
+		if httpCtx.IsPost() {
+			r.toggleCommandFromDebug(httpCtx)
+
+			return
+		}
+
+		commands := r.GetCommands()
+
+		if string(httpCtx.QueryArgs().Peek("format")) == "html" {
+			httpCtx.Response.Header.SetContentType("text/html; charset=utf-8")
+			httpCtx.Response.SetBodyString(renderCommandsHtml(commands, debugTokenDerived(token, "csrf")))
+
+			return
+		}
+
+		if commands == nil {
+			commands = []CommandInfo{}
+		}
+
+		b, _ := json.Marshal(commands)
+
+		httpCtx.Response.Header.SetContentType("application/json")
+		httpCtx.Response.SetBodyRaw(b)
+	})
+}
+
+// debugTokenDerived is used for session cookie and csrf field, so the token itself is not stored in a browser
+func debugTokenDerived(token string, purpose string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte(purpose))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// debugTokenProtected accepts token in X-Debug-Token header or session cookie, so it does not leak to access logs.
+// Session is started by posting token form field. POST from other origin is rejected, and POST with session
+// cookie also needs csrf form field, so browser forms of other sites can not change the state.
+func debugTokenProtected(token string, handler fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(httpCtx *fasthttp.RequestCtx) {
+		if len(token) == 0 {
+			httpCtx.Error("forbidden", fasthttp.StatusForbidden)
+
+			return
+		}
+
+		if !httpCtx.IsGet() && !isSameOrigin(httpCtx) {
+			httpCtx.Error("cross origin request", fasthttp.StatusForbidden)
+
+			return
+		}
+
+		if subtle.ConstantTimeCompare(httpCtx.Request.Header.Peek(debugTokenHeader), []byte(token)) == 1 {
+			handler(httpCtx)
+
+			return
+		}
+
+		if subtle.ConstantTimeCompare(httpCtx.Request.Header.Cookie(debugSessionCookie),
+			[]byte(debugTokenDerived(token, "session"))) == 1 {
+			if !httpCtx.IsGet() && subtle.ConstantTimeCompare(httpCtx.PostArgs().Peek("csrf"),
+				[]byte(debugTokenDerived(token, "csrf"))) != 1 {
+				httpCtx.Error("invalid csrf token", fasthttp.StatusForbidden)
+
+				return
+			}
+
+			handler(httpCtx)
+
+			return
+		}
+
+		if httpCtx.IsPost() && subtle.ConstantTimeCompare(httpCtx.PostArgs().Peek("token"), []byte(token)) == 1 {
+			cookie := fasthttp.AcquireCookie()
+			defer fasthttp.ReleaseCookie(cookie)
+
+			cookie.SetKey(debugSessionCookie)
+			cookie.SetValue(debugTokenDerived(token, "session"))
+			cookie.SetPath("/")
+			cookie.SetHTTPOnly(true)
+			cookie.SetSameSite(fasthttp.CookieSameSiteStrictMode)
+
+			httpCtx.Response.Header.SetCookie(cookie)
+			httpCtx.Redirect(string(httpCtx.RequestURI()), fasthttp.StatusSeeOther)
+
+			return
+		}
+
+		if httpCtx.IsGet() && string(httpCtx.QueryArgs().Peek("format")) == "html" {
+			httpCtx.SetStatusCode(fasthttp.StatusForbidden)
+			httpCtx.Response.Header.SetContentType("text/html; charset=utf-8")
+			httpCtx.Response.SetBodyString("<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"/>" +
+				"<title>Login</title></head>\n<body>\n<form method=\"post\">" +
+				"<input type=\"password\" name=\"token\" placeholder=\"debug token\"/><button>login</button></form>\n" +
+				"</body>\n</html>")
+
+			return
+		}
+
+		httpCtx.Error("forbidden", fasthttp.StatusForbidden)
+	}
+}
+
+func isSameOrigin(httpCtx *fasthttp.RequestCtx) bool {
+	if site := string(httpCtx.Request.Header.Peek("Sec-Fetch-Site")); len(site) > 0 && site != "same-origin" &&
+		site != "none" {
+		return false
+	}
+
+	origin := httpCtx.Request.Header.Peek(fasthttp.HeaderOrigin)
+
+	if len(origin) == 0 {
+		return true
+	}
+
+	uri := fasthttp.AcquireURI()
+	defer fasthttp.ReleaseURI(uri)
+
+	if err := uri.Parse(nil, origin); err != nil {
+		return false
+	}
+
+	return string(uri.Host()) == string(httpCtx.Host())
+}
+
+func (r *HttpRouter) toggleCommandFromDebug(httpCtx *fasthttp.RequestCtx) {
+	args := httpCtx.PostArgs()
+	endpoint := EndpointType(args.Peek("endpoint"))
+	method := string(args.Peek("method"))
+
+	var err error
+
+	switch string(args.Peek("action")) {
+	case "disable":
+		var duration time.Duration
+
+		if raw := string(args.Peek("duration")); len(raw) > 0 {
+			if duration, err = time.ParseDuration(raw); err != nil {
+				httpCtx.Error("invalid duration", fasthttp.StatusBadRequest)
+
+				return
+			}
+		}
+
+		err = r.DisableCommand(endpoint, method, string(args.Peek("reason")), duration)
+	case "enable":
+		err = r.EnableCommand(endpoint, method)
+	default:
+		httpCtx.Error("action should be disable or enable", fasthttp.StatusBadRequest)
+
+		return
+	}
+
+	if err != nil {
+		httpCtx.Error(err.Error(), fasthttp.StatusNotFound)
+
+		return
+	}
+
+	if string(httpCtx.QueryArgs().Peek("format")) == "html" {
+		httpCtx.Redirect(string(httpCtx.RequestURI()), fasthttp.StatusSeeOther)
+
+		return
+	}
+
+	httpCtx.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+func renderCommandsHtml(commands []CommandInfo, csrf string) string {
+	var b strings.Builder
+
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"/><title>Commands</title></head>\n<body>\n")
+	b.WriteString("<table border=\"1\" cellpadding=\"4\">\n<tr><th>endpoint</th><th>method</th><th>access level</th>")
+	b.WriteString("<th>obj</th><th>identity</th><th>allow banned</th><th>timeout</th><th>calls</th><th>errors</th>")
+	b.WriteString("<th>p95 ms</th><th>status</th></tr>\n")
+
+	for _, c := range commands {
+		status := "enabled"
+		action := "disable"
+		inputs := "<input name=\"reason\" placeholder=\"reason\"/><input name=\"duration\" placeholder=\"15m\" size=\"4\"/>"
+
+		if c.Disabled {
+			status = "disabled " + c.DisabledUntil + " " + c.DisabledReason
+			action = "enable"
+			inputs = ""
+		}
+
+		b.WriteString(fmt.Sprintf("<tr><td>%v</td><td>%v</td><td>%v</td><td>%v</td><td>%v</td><td>%v</td>"+
+			"<td>%v</td><td>%v</td><td>%v</td><td>%.1f</td><td>%v <form method=\"post\" style=\"display:inline\">"+
+			"<input type=\"hidden\" name=\"endpoint\" value=\"%v\"/><input type=\"hidden\" name=\"method\" value=\"%v\"/>"+
+			"<input type=\"hidden\" name=\"action\" value=\"%v\"/><input type=\"hidden\" name=\"csrf\" value=\"%v\"/>"+
+			"%v<button>%v</button></form></td></tr>\n",
+			html.EscapeString(c.Endpoint), html.EscapeString(c.Method), c.AccessLevel, html.EscapeString(c.Obj),
+			c.RequireIdentityValidation, c.AllowBanned, c.Timeout, c.Calls, c.Errors, c.P95Ms,
+			html.EscapeString(status), html.EscapeString(c.Endpoint), html.EscapeString(c.Method), action, csrf, inputs, action))
+	}
+
+	b.WriteString("</table>\n</body>\n</html>")
+
+	return b.String()
+}
@@ -0,0 +1,186 @@
+package router
+
+import (
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func findCommandInfo(commands []CommandInfo, endpoint EndpointType, method string) *CommandInfo {
+	for _, c := range commands {
+		if c.Endpoint == string(endpoint) && c.Method == method {
+			return &c
+		}
+	}
+
+	return nil
+}
+
+func TestCommandsRegistry(t *testing.T) {
+	r := newTestRouter(t)
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return "ok", nil
+	}, "/registry-test/{id}", MethodGet).Build()))
+
+	doRequest(r, "POST", "/rpc-service", `{"method":"echo","params":{},"id":"1"}`, nil)
+	doRequest(r, "POST", "/rpc-service", `{"method":"fail","id":"1"}`, nil)
+	doRequest(r, "POST", "/rpc-service", `{"method":"fail","id":"1"}`, nil)
+
+	commands := r.GetCommands()
+
+	fail := findCommandInfo(commands, EndpointRpcService, "fail")
+	assert.NotNil(t, fail)
+	assert.Equal(t, int64(2), fail.Calls)
+	assert.Equal(t, int64(2), fail.Errors)
+	assert.Equal(t, "public", fail.AccessLevel)
+	assert.Equal(t, "1m0s", fail.Timeout)
+
+	assert.NotNil(t, findCommandInfo(commands, EndpointRest, "GET /registry-test/{id}"))
+
+	assert.NotNil(t, r.DisableCommand(EndpointRpcService, "missing", "", 0))
+	assert.Nil(t, r.DisableCommand(EndpointRpcService, "echo", "incident", time.Minute))
+	assert.Nil(t, r.DisableCommand(EndpointRest, "get /registry-test/{id}", "incident", 0))
+
+	var resp struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	ctx := doRequest(r, "POST", "/rpc-service", `{"method":"echo","params":{},"id":"1"}`, nil)
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, int(error_codes.ServiceUnavailable), resp.Error.Code)
+	assert.Contains(t, resp.Error.Message, "incident")
+
+	ctx = doRequest(r, "GET", "/registry-test/1", "", nil)
+	assert.Equal(t, fasthttp.StatusServiceUnavailable, ctx.Response.StatusCode())
+
+	echo := findCommandInfo(r.GetCommands(), EndpointRpcService, "echo")
+	assert.True(t, echo.Disabled)
+	assert.NotEmpty(t, echo.DisabledUntil)
+
+	assert.Nil(t, r.EnableCommand(EndpointRpcService, "echo"))
+
+	ctx = doRequest(r, "POST", "/rpc-service", `{"method":"echo","params":{},"id":"1"}`, nil)
+	resp.Error = nil
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Nil(t, resp.Error)
+}
+
+func TestCommandsDebugHandler(t *testing.T) {
+	r := newTestRouter(t)
+	handler := r.CommandsDebugHandler("secret")
+
+	do := func(method string, uri string, body string, token string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI(uri)
+
+		if len(body) > 0 {
+			ctx.Request.Header.SetContentType("application/x-www-form-urlencoded")
+			ctx.Request.SetBodyString(body)
+		}
+
+		if len(token) > 0 {
+			ctx.Request.Header.Set("X-Debug-Token", token)
+		}
+
+		handler(ctx)
+
+		return ctx
+	}
+
+	assert.Equal(t, fasthttp.StatusForbidden, do("GET", "/debug/commands", "", "wrong").Response.StatusCode())
+	assert.Equal(t, fasthttp.StatusForbidden, do("GET", "/debug/commands?token=secret", "", "").Response.StatusCode())
+
+	var commands []CommandInfo
+
+	assert.Nil(t, json.Unmarshal(do("GET", "/debug/commands", "", "secret").Response.Body(), &commands))
+	assert.Len(t, commands, 3)
+
+	ctx := do("POST", "/debug/commands", "endpoint=rpc-service&method=panic&action=disable&reason=boom&duration=5m",
+		"secret")
+	assert.Equal(t, fasthttp.StatusNoContent, ctx.Response.StatusCode())
+	assert.True(t, findCommandInfo(r.GetCommands(), EndpointRpcService, "panic").Disabled)
+
+	ctx = do("POST", "/debug/commands", "endpoint=rpc-service&method=panic&action=disable&duration=x", "secret")
+	assert.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode())
+
+	page := string(do("GET", "/debug/commands?format=html", "", "secret").Response.Body())
+	assert.True(t, strings.Contains(page, "<table"))
+	assert.True(t, strings.Contains(page, "disabled"))
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.SetRequestURI("http://ops.local/debug/commands")
+	ctx.Request.Header.Set("X-Debug-Token", "secret")
+	ctx.Request.Header.Set("Origin", "https://evil.io")
+	handler(ctx)
+	assert.Equal(t, fasthttp.StatusForbidden, ctx.Response.StatusCode())
+
+	ctx = &fasthttp.RequestCtx{}
+	r.CommandsDebugHandler("")(ctx)
+	assert.Equal(t, fasthttp.StatusForbidden, ctx.Response.StatusCode())
+}
+
+func TestCommandsDebugHtmlSession(t *testing.T) {
+	r := newTestRouter(t)
+	handler := r.CommandsDebugHandler("secret")
+
+	do := func(method string, body string, cookie string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI("http://ops.local/debug/commands?format=html")
+
+		if len(body) > 0 {
+			ctx.Request.Header.SetContentType("application/x-www-form-urlencoded")
+			ctx.Request.SetBodyString(body)
+		}
+
+		if len(cookie) > 0 {
+			ctx.Request.Header.SetCookie(debugSessionCookie, cookie)
+		}
+
+		handler(ctx)
+
+		return ctx
+	}
+
+	ctx := do("GET", "", "")
+	assert.Equal(t, fasthttp.StatusForbidden, ctx.Response.StatusCode())
+	assert.Contains(t, string(ctx.Response.Body()), `name="token"`)
+
+	assert.Equal(t, fasthttp.StatusForbidden, do("POST", "token=wrong", "").Response.StatusCode())
+
+	ctx = do("POST", "token=secret", "")
+	assert.Equal(t, fasthttp.StatusSeeOther, ctx.Response.StatusCode())
+
+	cookie := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(cookie)
+
+	cookie.SetKey(debugSessionCookie)
+	assert.True(t, ctx.Response.Header.Cookie(cookie))
+	assert.True(t, cookie.HTTPOnly())
+	assert.NotContains(t, string(cookie.Value()), "secret")
+
+	session := string(cookie.Value())
+	page := string(do("GET", "", session).Response.Body())
+	csrf := debugTokenDerived("secret", "csrf")
+
+	assert.Contains(t, page, "<table")
+	assert.Contains(t, page, csrf)
+
+	ctx = do("POST", "endpoint=rpc-service&method=panic&action=disable", session)
+	assert.Equal(t, fasthttp.StatusForbidden, ctx.Response.StatusCode())
+	assert.False(t, findCommandInfo(r.GetCommands(), EndpointRpcService, "panic").Disabled)
+
+	ctx = do("POST", "endpoint=rpc-service&method=panic&action=disable&csrf="+csrf, session)
+	assert.Equal(t, fasthttp.StatusSeeOther, ctx.Response.StatusCode())
+	assert.True(t, findCommandInfo(r.GetCommands(), EndpointRpcService, "panic").Disabled)
+}
@@ -0,0 +1,29 @@
+package router
+
+import (
+	"context"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"github.com/valyala/fasthttp"
+	"go.elastic.co/apm"
+)
+
+const requestIdHeader = "X-Request-Id"
+
+// withCorrelationId adds correlation id to ctx, wrappers forward it to other services. It is taken from
+// common.CorrelationIdHeader of the caller, X-Request-Id of the gateway or apm trace id of the request.
+func withCorrelationId(httpCtx *fasthttp.RequestCtx, ctx context.Context) context.Context {
+	correlationId := string(httpCtx.Request.Header.Peek(common.CorrelationIdHeader))
+
+	if len(correlationId) == 0 {
+		correlationId = string(httpCtx.Request.Header.Peek(requestIdHeader))
+	}
+
+	if len(correlationId) == 0 {
+		if tx := apm.TransactionFromContext(ctx); tx != nil {
+			correlationId = tx.TraceContext().Trace.String()
+		}
+	}
+
+	return wrappers.WithCorrelationId(ctx, correlationId)
+}
@@ -0,0 +1,231 @@
+package router
+
+import (
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/valyala/fasthttp"
+	"go.elastic.co/apm/module/apmhttp"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CorsOrigins struct {
+	Origins []string // exact "https://app.example.com", wildcard subdomain "https://*.example.com" or "*" for any
+	Regexps []string
+}
+
+type CorsPolicy struct {
+	CorsOrigins
+	EnvironmentOrigins map[boilerplate.Environment]CorsOrigins // appended to CorsOrigins for current environment
+	AllowedMethods     []string
+	AllowedHeaders     []string // "*" reflects Access-Control-Request-Headers
+	ExposedHeaders     []string
+	AllowCredentials   bool
+	MaxAge             time.Duration
+}
+
+type compiledCorsPolicy struct {
+	anyOrigin        bool
+	exactOrigins     map[string]bool
+	wildcardOrigins  [][2]string
+	regexpOrigins    []*regexp.Regexp
+	allowedMethods   string
+	allowedHeaders   string
+	reflectHeaders   bool
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+// DefaultCorsPolicy allows only origins from CORS_ALLOWED_ORIGINS (comma separated). Local, dev and ci environments
+// additionally allow localhost origins with any port.
+func DefaultCorsPolicy(env boilerplate.Environment) CorsPolicy {
+	policy := CorsPolicy{
+		AllowedMethods: []string{"POST", "GET", "OPTIONS", "PATCH", "DELETE"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "Authorization-Admin", "Accept-Language",
+			"device-id", apmhttp.W3CTraceparentHeader, common.RequestTimeoutHeader,
+			common.CorrelationIdHeader, IdempotencyKeyHeader},
+		ExposedHeaders:   []string{fasthttp.HeaderRetryAfter, IdempotentReplayedHeader},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); len(origin) > 0 {
+			policy.Origins = append(policy.Origins, origin)
+		}
+	}
+
+	switch env {
+	case boilerplate.Local, boilerplate.Dev, boilerplate.Ci:
+		policy.Origins = append(policy.Origins, localhostCorsOrigins...)
+	}
+
+	return policy
+}
+
+var localhostCorsOrigins = []string{"http://localhost", "http://localhost:*", "http://127.0.0.1", "http://127.0.0.1:*"}
+
+func (r *HttpRouter) WithCorsPolicy(policy CorsPolicy) *HttpRouter {
+	r.corsPolicy = compileCorsPolicy(policy, boilerplate.GetCurrentEnvironment())
+
+	return r
+}
+
+func (r *HttpRouter) WithEndpointCorsPolicy(endpoint EndpointType, policy CorsPolicy) *HttpRouter {
+	r.endpointCorsPolicies[endpoint] = compileCorsPolicy(policy, boilerplate.GetCurrentEnvironment())
+
+	return r
+}
+
+// compileCorsPolicy panics for "*" origin with credentials, as any site could send requests with user cookies
+func compileCorsPolicy(policy CorsPolicy, env boilerplate.Environment) *compiledCorsPolicy {
+	c := &compiledCorsPolicy{
+		exactOrigins:     map[string]bool{},
+		allowedMethods:   strings.Join(policy.AllowedMethods, ", "),
+		exposedHeaders:   strings.Join(policy.ExposedHeaders, ", "),
+		allowCredentials: policy.AllowCredentials,
+	}
+
+	origins := append([]string{}, policy.Origins...)
+	regexps := append([]string{}, policy.Regexps...)
+
+	if envOrigins, ok := policy.EnvironmentOrigins[env]; ok {
+		origins = append(origins, envOrigins.Origins...)
+		regexps = append(regexps, envOrigins.Regexps...)
+	}
+
+	for _, origin := range origins {
+		origin = strings.ToLower(origin)
+
+		if origin == "*" {
+			c.anyOrigin = true
+		} else if idx := strings.Index(origin, "*"); idx >= 0 {
+			c.wildcardOrigins = append(c.wildcardOrigins, [2]string{origin[:idx], origin[idx+1:]})
+		} else {
+			c.exactOrigins[origin] = true
+		}
+	}
+
+	if c.anyOrigin && c.allowCredentials {
+		panic("cors policy can not allow credentials for any origin")
+	}
+
+	for _, re := range regexps {
+		// origin should match the whole pattern, not a part of it
+		c.regexpOrigins = append(c.regexpOrigins, regexp.MustCompile("^(?:"+re+")$"))
+	}
+
+	var headers []string
+
+	for _, h := range policy.AllowedHeaders {
+		if h == "*" {
+			c.reflectHeaders = true
+		} else {
+			headers = append(headers, h)
+		}
+	}
+
+	c.allowedHeaders = strings.Join(headers, ", ")
+
+	if policy.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(policy.MaxAge.Seconds()))
+	}
+
+	return c
+}
+
+func (c *compiledCorsPolicy) isOriginAllowed(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+
+	if c.exactOrigins[origin] {
+		return true
+	}
+
+	for _, w := range c.wildcardOrigins {
+		if len(origin) > len(w[0])+len(w[1]) && strings.HasPrefix(origin, w[0]) && strings.HasSuffix(origin, w[1]) {
+			if sub := origin[len(w[0]) : len(origin)-len(w[1])]; !strings.ContainsAny(sub, "/:") {
+				return true
+			}
+		}
+	}
+
+	for _, re := range c.regexpOrigins {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *HttpRouter) getCorsPolicy(endpoint EndpointType) *compiledCorsPolicy {
+	if policy, ok := r.endpointCorsPolicies[endpoint]; ok {
+		return policy
+	}
+
+	return r.corsPolicy
+}
+
+func (r *HttpRouter) setCors(ctx *fasthttp.RequestCtx, endpoint EndpointType) {
+	policy := r.getCorsPolicy(endpoint)
+
+	ctx.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderOrigin)
+
+	origin := string(ctx.Request.Header.Peek(fasthttp.HeaderOrigin))
+
+	if len(origin) == 0 || !policy.isOriginAllowed(origin) {
+		return
+	}
+
+	ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowOrigin, origin)
+
+	if policy.allowCredentials {
+		ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowCredentials, "true")
+	}
+
+	if len(policy.exposedHeaders) > 0 {
+		ctx.Response.Header.Set(fasthttp.HeaderAccessControlExposeHeaders, policy.exposedHeaders)
+	}
+}
+
+func (r *HttpRouter) handlePreflight(ctx *fasthttp.RequestCtx, endpoint EndpointType) {
+	policy := r.getCorsPolicy(endpoint)
+
+	ctx.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderOrigin)
+	ctx.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderAccessControlRequestMethod)
+	ctx.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderAccessControlRequestHeaders)
+	ctx.Response.SetStatusCode(fasthttp.StatusNoContent)
+
+	origin := string(ctx.Request.Header.Peek(fasthttp.HeaderOrigin))
+
+	if len(origin) == 0 || !policy.isOriginAllowed(origin) {
+		return
+	}
+
+	ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowOrigin, origin)
+	ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowMethods, policy.allowedMethods)
+
+	if policy.allowCredentials {
+		ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowCredentials, "true")
+	}
+
+	if policy.reflectHeaders {
+		if requested := ctx.Request.Header.Peek(fasthttp.HeaderAccessControlRequestHeaders); len(requested) > 0 {
+			ctx.Response.Header.SetBytesV(fasthttp.HeaderAccessControlAllowHeaders, requested)
+		}
+	} else if len(policy.allowedHeaders) > 0 {
+		ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowHeaders, policy.allowedHeaders)
+	}
+
+	if len(policy.maxAge) > 0 {
+		ctx.Response.Header.Set(fasthttp.HeaderAccessControlMaxAge, policy.maxAge)
+	}
+}
@@ -0,0 +1,97 @@
+package router
+
+import (
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"testing"
+	"time"
+)
+
+func TestCorsOriginMatching(t *testing.T) {
+	policy := compileCorsPolicy(CorsPolicy{
+		CorsOrigins: CorsOrigins{
+			Origins: []string{"https://app.example.com", "https://*.example.org"},
+			Regexps: []string{`^https://pr-\d+\.preview\.dev$`, `https://qa-\d+\.example\.net`},
+		},
+		EnvironmentOrigins: map[boilerplate.Environment]CorsOrigins{
+			boilerplate.Dev: {Origins: []string{"http://localhost:3000"}},
+		},
+	}, boilerplate.Prod)
+
+	assert.True(t, policy.isOriginAllowed("https://app.example.com"))
+	assert.True(t, policy.isOriginAllowed("https://APP.example.com"))
+	assert.True(t, policy.isOriginAllowed("https://a.b.example.org"))
+	assert.True(t, policy.isOriginAllowed("https://pr-15.preview.dev"))
+
+	assert.False(t, policy.isOriginAllowed("https://example.org"))
+	assert.False(t, policy.isOriginAllowed("https://evil.com/.example.org"))
+	assert.False(t, policy.isOriginAllowed("https://app.example.com.evil.com"))
+	assert.False(t, policy.isOriginAllowed("http://localhost:3000"))
+	assert.True(t, policy.isOriginAllowed("https://qa-1.example.net"))
+	assert.False(t, policy.isOriginAllowed("https://qa-1.example.net.evil.com"))
+	assert.False(t, policy.isOriginAllowed("https://evil.com?https://qa-1.example.net"))
+
+	assert.False(t, compileCorsPolicy(DefaultCorsPolicy(boilerplate.Prod), boilerplate.Prod).
+		isOriginAllowed("https://any.site"))
+
+	devPolicy := DefaultCorsPolicy(boilerplate.Dev)
+
+	assert.NotContains(t, devPolicy.Origins, "*")
+	assert.NotContains(t, devPolicy.AllowedHeaders, "*")
+	assert.Contains(t, devPolicy.AllowedHeaders, IdempotencyKeyHeader)
+	assert.Contains(t, devPolicy.ExposedHeaders, IdempotentReplayedHeader)
+
+	dev := compileCorsPolicy(devPolicy, boilerplate.Dev)
+
+	assert.True(t, dev.isOriginAllowed("http://localhost:3000"))
+	assert.False(t, dev.isOriginAllowed("https://any.site"))
+	assert.False(t, dev.isOriginAllowed("http://localhost:3000/.evil.com"))
+
+	assert.Panics(t, func() {
+		compileCorsPolicy(CorsPolicy{CorsOrigins: CorsOrigins{Origins: []string{"*"}}, AllowCredentials: true},
+			boilerplate.Prod)
+	})
+	assert.Panics(t, func() {
+		compileCorsPolicy(CorsPolicy{EnvironmentOrigins: map[boilerplate.Environment]CorsOrigins{
+			boilerplate.Dev: {Origins: []string{"*"}}}, AllowCredentials: true}, boilerplate.Dev)
+	})
+	assert.True(t, compileCorsPolicy(CorsPolicy{CorsOrigins: CorsOrigins{Origins: []string{"*"}}}, boilerplate.Prod).
+		isOriginAllowed("https://any.site"))
+}
+
+func TestCorsHeaders(t *testing.T) {
+	r := newTestRouter(t).WithCorsPolicy(CorsPolicy{
+		CorsOrigins:      CorsOrigins{Origins: []string{"https://app.example.com"}},
+		AllowedMethods:   []string{"POST", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           time.Minute,
+	}).WithEndpointCorsPolicy(EndpointRpcService, CorsPolicy{})
+
+	r.GetRpcPublicEndpoint()
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(NewServiceCommand("ping",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			return "pong", nil
+		}, false)))
+
+	ctx := doRequest(r, "OPTIONS", "/rpc", "", map[string]string{"Origin": "https://app.example.com"})
+	assert.Equal(t, fasthttp.StatusNoContent, ctx.Response.StatusCode())
+	assert.Equal(t, "https://app.example.com", string(ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowOrigin)))
+	assert.Equal(t, "true", string(ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowCredentials)))
+	assert.Equal(t, "POST, OPTIONS", string(ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowMethods)))
+	assert.Equal(t, "Content-Type", string(ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowHeaders)))
+	assert.Equal(t, "60", string(ctx.Response.Header.Peek(fasthttp.HeaderAccessControlMaxAge)))
+
+	ctx = doRequest(r, "OPTIONS", "/rpc", "", map[string]string{"Origin": "https://evil.com"})
+	assert.Equal(t, fasthttp.StatusNoContent, ctx.Response.StatusCode())
+	assert.Empty(t, ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowOrigin))
+	assert.Empty(t, ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowCredentials))
+
+	ctx = doRequest(r, "POST", "/rpc-service", `{"method":"ping","id":"1"}`,
+		map[string]string{"Origin": "https://app.example.com"})
+	assert.Empty(t, ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, fasthttp.HeaderOrigin, string(ctx.Response.Header.Peek(fasthttp.HeaderVary)))
+}
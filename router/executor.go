@@ -3,8 +3,10 @@ package router
 import (
 	"context"
 	"fmt"
+	"github.com/digitalmonsters/go-common/error_codes"
 	"github.com/digitalmonsters/go-common/translation"
 	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
 	"go.elastic.co/apm"
 )
 
@@ -19,6 +21,12 @@ type MethodExecutionData struct {
 	Language       translation.Language
 	FullUrl        string
 	getUserValueFn func(key string) interface{}
+	httpCtx        *fasthttp.RequestCtx
+	command        ICommand
+	endpointType   EndpointType
+	idempotency    *idempotencyState
+	multipart      *MultipartReader
+	audit          *adminAuditState
 }
 
 func (m MethodExecutionData) GetUserValue(key string) interface{} {
@@ -29,6 +37,28 @@ func (m MethodExecutionData) GetUserValue(key string) interface{} {
 	return nil
 }
 
+// GetMultipart returns body of commands built with RestCommandBuilder.WithMultipart
+func (m MethodExecutionData) GetMultipart() (*MultipartReader, *error_codes.ErrorWithCode) {
+	if m.multipart == nil {
+		return nil, error_codes.NewErrorWithCodeRef(errors.New("command does not accept multipart body"),
+			error_codes.GenericServerError)
+	}
+
+	return m.multipart, m.multipart.initErr
+}
+
+func (m MethodExecutionData) GetRequestCtx() *fasthttp.RequestCtx {
+	return m.httpCtx
+}
+
+func (m MethodExecutionData) GetCommand() ICommand {
+	return m.command
+}
+
+func (m MethodExecutionData) GetEndpointType() EndpointType {
+	return m.endpointType
+}
+
 type CommandExecutor struct {
 	commands map[string]ICommand
 }
@@ -0,0 +1,383 @@
+package router
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxRangesCount = 32
+
+var (
+	errInvalidRange = errors.New("invalid range")
+	errNoOverlap    = errors.New("range does not overlap content")
+)
+
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+func (b byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %v-%v/%v", b.start, b.start+b.length-1, size)
+}
+
+func customFileToStreaming(file *rpc.CustomFile) *rpc.StreamingFile {
+	sum := sha256.Sum256(file.Data)
+
+	return &rpc.StreamingFile{
+		Content:                      bytes.NewReader(file.Data),
+		Size:                         int64(len(file.Data)),
+		Filename:                     file.Filename,
+		MimeType:                     file.MimeType,
+		ContentDispositionFirstParam: file.ContentDispositionFirstParam,
+		ETag:                         fmt.Sprintf("\"%v\"", hex.EncodeToString(sum[:16])),
+	}
+}
+
+// parseRange returns errInvalidRange for malformed header, which should be ignored,
+// and errNoOverlap when none of the ranges can be satisfied
+func parseRange(header string, size int64) ([]byteRange, error) {
+	if !strings.HasPrefix(header, "bytes=") {
+		return nil, errInvalidRange
+	}
+
+	var ranges []byteRange
+
+	noOverlap := false
+
+	for _, spec := range strings.Split(strings.TrimPrefix(header, "bytes="), ",") {
+		spec = strings.TrimSpace(spec)
+
+		if len(spec) == 0 {
+			continue
+		}
+
+		startStr, endStr, found := strings.Cut(spec, "-")
+
+		if !found {
+			return nil, errInvalidRange
+		}
+
+		startStr, endStr = strings.TrimSpace(startStr), strings.TrimSpace(endStr)
+
+		var r byteRange
+
+		if len(startStr) == 0 { // suffix, last N bytes
+			suffix, err := strconv.ParseInt(endStr, 10, 64)
+
+			if err != nil || suffix < 0 {
+				return nil, errInvalidRange
+			}
+
+			if suffix == 0 || size == 0 {
+				noOverlap = true
+
+				continue
+			}
+
+			if suffix > size {
+				suffix = size
+			}
+
+			r = byteRange{start: size - suffix, length: suffix}
+		} else {
+			start, err := strconv.ParseInt(startStr, 10, 64)
+
+			if err != nil || start < 0 {
+				return nil, errInvalidRange
+			}
+
+			end := size - 1
+
+			if len(endStr) > 0 {
+				if end, err = strconv.ParseInt(endStr, 10, 64); err != nil || end < start {
+					return nil, errInvalidRange
+				}
+			}
+
+			if start >= size {
+				noOverlap = true
+
+				continue
+			}
+
+			if end >= size {
+				end = size - 1
+			}
+
+			r = byteRange{start: start, length: end - start + 1}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 && noOverlap {
+		return nil, errNoOverlap
+	}
+
+	if len(ranges) == 0 || len(ranges) > maxRangesCount {
+		return nil, errInvalidRange
+	}
+
+	return ranges, nil
+}
+
+func ifRangeMatches(httpCtx *fasthttp.RequestCtx, etag string, lastModified time.Time) bool {
+	ifRange := strings.TrimSpace(string(httpCtx.Request.Header.Peek(fasthttp.HeaderIfRange)))
+
+	if len(ifRange) == 0 {
+		return true
+	}
+
+	if strings.HasPrefix(ifRange, "\"") || strings.HasPrefix(ifRange, "W/") { // strong comparison only
+		return len(etag) > 0 && !strings.HasPrefix(etag, "W/") && ifRange == etag
+	}
+
+	t, err := fasthttp.ParseHTTPDate([]byte(ifRange))
+
+	return err == nil && !lastModified.IsZero() && lastModified.Truncate(time.Second).Equal(t)
+}
+
+func isNotModified(httpCtx *fasthttp.RequestCtx, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := httpCtx.Request.Header.Peek(fasthttp.HeaderIfNoneMatch); len(ifNoneMatch) > 0 {
+		return len(etag) > 0 && etagMatches(string(ifNoneMatch), strings.TrimPrefix(etag, "W/"))
+	}
+
+	if ifModifiedSince := httpCtx.Request.Header.Peek(fasthttp.HeaderIfModifiedSince); len(ifModifiedSince) > 0 &&
+		!lastModified.IsZero() {
+		t, err := fasthttp.ParseHTTPDate(ifModifiedSince)
+
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
+
+// willBeCompressed mirrors fasthttp.CompressHandler, which compresses text, application, font and multipart types
+func willBeCompressed(httpCtx *fasthttp.RequestCtx, contentType string) bool {
+	compressible := false
+
+	for _, prefix := range []string{"text/", "application/", "image/svg", "image/x-icon", "font/", "multipart/"} {
+		if strings.HasPrefix(contentType, prefix) {
+			compressible = true
+
+			break
+		}
+	}
+
+	return compressible && (httpCtx.Request.Header.HasAcceptEncoding("br") ||
+		httpCtx.Request.Header.HasAcceptEncoding("gzip") || httpCtx.Request.Header.HasAcceptEncoding("deflate"))
+}
+
+// writeFileResponse returns status code and size of written body. Partial responses are never compressed,
+// as Content-Range refers to the uncompressed content
+func writeFileResponse(httpCtx *fasthttp.RequestCtx, file *rpc.StreamingFile) (int, int64, error) {
+	closer, _ := file.Content.(io.Closer)
+
+	closeContent := func() {
+		if closer != nil {
+			_ = closer.Close()
+		}
+	}
+
+	size := file.Size
+
+	if size <= 0 {
+		end, err := file.Content.Seek(0, io.SeekEnd)
+
+		if err != nil {
+			closeContent()
+
+			return 0, 0, errors.WithStack(err)
+		}
+
+		size = end
+	}
+
+	contentType := file.MimeType
+
+	if len(contentType) == 0 {
+		contentType = "application/octet-stream"
+	}
+
+	etag := file.ETag
+
+	if len(etag) == 0 && !file.LastModified.IsZero() {
+		etag = fmt.Sprintf("\"%x-%x\"", size, file.LastModified.UnixNano())
+	}
+
+	contentDispositionFirstParam := file.ContentDispositionFirstParam
+
+	if len(contentDispositionFirstParam) == 0 {
+		contentDispositionFirstParam = "attachment"
+	}
+
+	header := &httpCtx.Response.Header
+
+	header.Set(fasthttp.HeaderContentDisposition, fmt.Sprintf("%v; filename=\"%v\"", contentDispositionFirstParam,
+		file.Filename))
+	header.Set(fasthttp.HeaderAcceptRanges, "bytes")
+
+	if !file.LastModified.IsZero() {
+		header.SetBytesV(fasthttp.HeaderLastModified, fasthttp.AppendHTTPDate(nil, file.LastModified))
+	}
+
+	var ranges []byteRange
+	var rangeErr error
+
+	if rangeHeader := httpCtx.Request.Header.Peek(fasthttp.HeaderRange); len(rangeHeader) > 0 &&
+		ifRangeMatches(httpCtx, etag, file.LastModified) {
+		ranges, rangeErr = parseRange(string(rangeHeader), size)
+	}
+
+	var total int64
+
+	for _, r := range ranges {
+		total += r.length
+	}
+
+	if total > size { // overlapping ranges would send more than the whole content
+		ranges = nil
+	}
+
+	if len(etag) > 0 {
+		if len(ranges) == 0 && rangeErr != errNoOverlap && willBeCompressed(httpCtx, contentType) {
+			etag = "W/" + strings.TrimPrefix(etag, "W/") // compressed bytes differ from the original
+			header.Add(fasthttp.HeaderVary, fasthttp.HeaderAcceptEncoding)
+		}
+
+		header.Set(fasthttp.HeaderETag, etag)
+	}
+
+	if isNotModified(httpCtx, etag, file.LastModified) {
+		closeContent()
+
+		return fasthttp.StatusNotModified, 0, nil
+	}
+
+	if rangeErr == errNoOverlap {
+		closeContent()
+
+		header.Set(fasthttp.HeaderContentRange, fmt.Sprintf("bytes */%v", size))
+
+		return fasthttp.StatusRequestedRangeNotSatisfiable, 0, nil
+	}
+
+	switch len(ranges) {
+	case 0:
+		header.SetContentType(contentType)
+
+		if _, err := file.Content.Seek(0, io.SeekStart); err != nil {
+			closeContent()
+
+			return 0, 0, errors.WithStack(err)
+		}
+
+		httpCtx.Response.SetBodyStream(&contentReader{Reader: &sectionReader{content: file.Content, left: size},
+			closer: closer}, int(size))
+
+		return fasthttp.StatusOK, size, nil
+	case 1:
+		header.SetContentType(contentType)
+		header.Set(fasthttp.HeaderContentRange, ranges[0].contentRange(size))
+		header.Set(fasthttp.HeaderContentEncoding, "identity")
+
+		httpCtx.Response.SetBodyStream(&contentReader{Reader: &sectionReader{content: file.Content,
+			start: ranges[0].start, left: ranges[0].length, seek: true}, closer: closer}, int(ranges[0].length))
+
+		return fasthttp.StatusPartialContent, ranges[0].length, nil
+	}
+
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+
+	var readers []io.Reader
+	var length int64
+
+	for i, r := range ranges {
+		partHeader := fmt.Sprintf("--%v\r\n%v: %v\r\n%v: %v\r\n\r\n", boundary, fasthttp.HeaderContentType,
+			contentType, fasthttp.HeaderContentRange, r.contentRange(size))
+
+		if i > 0 {
+			partHeader = "\r\n" + partHeader
+		}
+
+		readers = append(readers, strings.NewReader(partHeader),
+			&sectionReader{content: file.Content, start: r.start, left: r.length, seek: true})
+		length += int64(len(partHeader)) + r.length
+	}
+
+	trailer := fmt.Sprintf("\r\n--%v--\r\n", boundary)
+
+	readers = append(readers, strings.NewReader(trailer))
+	length += int64(len(trailer))
+
+	header.SetContentType(fmt.Sprintf("multipart/byteranges; boundary=%v", boundary))
+	header.Set(fasthttp.HeaderContentEncoding, "identity")
+
+	httpCtx.Response.SetBodyStream(&contentReader{Reader: io.MultiReader(readers...), closer: closer}, int(length))
+
+	return fasthttp.StatusPartialContent, length, nil
+}
+
+// sectionReader seeks on the first read, so several sections of the same content can be read one by one
+type sectionReader struct {
+	content io.ReadSeeker
+	start   int64
+	left    int64
+	seek    bool
+}
+
+func (s *sectionReader) Read(p []byte) (int, error) {
+	if s.seek {
+		if _, err := s.content.Seek(s.start, io.SeekStart); err != nil {
+			return 0, err
+		}
+
+		s.seek = false
+	}
+
+	if s.left <= 0 {
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > s.left {
+		p = p[:s.left]
+	}
+
+	n, err := s.content.Read(p)
+	s.left -= int64(n)
+
+	if err == io.EOF && s.left > 0 {
+		return n, io.ErrUnexpectedEOF
+	}
+
+	if err == io.EOF {
+		err = nil
+	}
+
+	return n, err
+}
+
+// contentReader is closed by fasthttp after the body is written
+type contentReader struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (c *contentReader) Close() error {
+	if c.closer == nil {
+		return nil
+	}
+
+	return c.closer.Close()
+}
@@ -0,0 +1,178 @@
+package router
+
+import (
+	"bytes"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"io"
+	"mime"
+	"mime/multipart"
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		header string
+		ranges []byteRange
+		err    error
+	}{
+		{"bytes=0-9", []byteRange{{0, 10}}, nil},
+		{"bytes=5-", []byteRange{{5, 95}}, nil},
+		{"bytes=-10", []byteRange{{90, 10}}, nil},
+		{"bytes=-200", []byteRange{{0, 100}}, nil},
+		{"bytes=90-200", []byteRange{{90, 10}}, nil},
+		{"bytes=0-1, 10-11", []byteRange{{0, 2}, {10, 2}}, nil},
+		{"bytes=100-", nil, errNoOverlap},
+		{"bytes=200-300, 100-", nil, errNoOverlap},
+		{"bytes=200-300, 0-1", []byteRange{{0, 2}}, nil},
+		{"bytes=5-1", nil, errInvalidRange},
+		{"items=0-1", nil, errInvalidRange},
+		{"bytes=abc", nil, errInvalidRange},
+	}
+
+	for _, c := range cases {
+		ranges, err := parseRange(c.header, 100)
+
+		assert.Equal(t, c.err, err, c.header)
+		assert.Equal(t, c.ranges, ranges, c.header)
+	}
+}
+
+type closeTrackingReader struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (c *closeTrackingReader) Close() error {
+	c.closed = true
+
+	return nil
+}
+
+func newFileTestRouter(t *testing.T, fn func() interface{}) *HttpRouter {
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return fn(), nil
+	}, "/file", MethodGet).Build()))
+
+	return r
+}
+
+func TestCustomFileRanges(t *testing.T) {
+	content := []byte("0123456789abcdefghij")
+
+	r := newFileTestRouter(t, func() interface{} {
+		return &rpc.CustomFile{Data: content, Filename: "a.bin", MimeType: "video/mp4"}
+	})
+
+	ctx := doRequest(r, "GET", "/file", "", nil)
+
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, content, ctx.Response.Body())
+	assert.Equal(t, "bytes", string(ctx.Response.Header.Peek(fasthttp.HeaderAcceptRanges)))
+	assert.Equal(t, `attachment; filename="a.bin"`, string(ctx.Response.Header.Peek(fasthttp.HeaderContentDisposition)))
+
+	etag := string(ctx.Response.Header.Peek(fasthttp.HeaderETag))
+	assert.NotEmpty(t, etag)
+
+	ctx = doRequest(r, "GET", "/file", "", map[string]string{"Range": "bytes=2-5"})
+
+	assert.Equal(t, 206, ctx.Response.StatusCode())
+	assert.Equal(t, "2345", string(ctx.Response.Body()))
+	assert.Equal(t, "bytes 2-5/20", string(ctx.Response.Header.Peek(fasthttp.HeaderContentRange)))
+	assert.Equal(t, "identity", string(ctx.Response.Header.Peek(fasthttp.HeaderContentEncoding)))
+
+	ctx = doRequest(r, "GET", "/file", "", map[string]string{"Range": "bytes=50-"})
+
+	assert.Equal(t, 416, ctx.Response.StatusCode())
+	assert.Equal(t, "bytes */20", string(ctx.Response.Header.Peek(fasthttp.HeaderContentRange)))
+
+	ctx = doRequest(r, "GET", "/file", "", map[string]string{"Range": "bytes=2-5", "If-Range": `"other"`})
+
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, content, ctx.Response.Body())
+
+	ctx = doRequest(r, "GET", "/file", "", map[string]string{"Range": "bytes=-3", "If-Range": etag})
+
+	assert.Equal(t, 206, ctx.Response.StatusCode())
+	assert.Equal(t, "hij", string(ctx.Response.Body()))
+
+	ctx = doRequest(r, "GET", "/file", "", map[string]string{"If-None-Match": etag})
+
+	assert.Equal(t, 304, ctx.Response.StatusCode())
+	assert.Empty(t, ctx.Response.Body())
+}
+
+func TestStreamingFileMultiRange(t *testing.T) {
+	reader := &closeTrackingReader{Reader: bytes.NewReader([]byte("0123456789abcdefghij"))}
+
+	r := newFileTestRouter(t, func() interface{} {
+		return &rpc.StreamingFile{Content: reader, MimeType: "audio/mpeg", Filename: "a.mp3"}
+	})
+
+	ctx := doRequest(r, "GET", "/file", "", map[string]string{"Range": "bytes=0-1,10-12"})
+
+	assert.Equal(t, 206, ctx.Response.StatusCode())
+
+	body := ctx.Response.Body()
+
+	assert.Equal(t, len(body), ctx.Response.Header.ContentLength())
+	assert.True(t, reader.closed)
+
+	mediaType, params, err := mime.ParseMediaType(string(ctx.Response.Header.ContentType()))
+	assert.Nil(t, err)
+	assert.Equal(t, "multipart/byteranges", mediaType)
+
+	partsReader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+
+	var parts []string
+	var contentRanges []string
+
+	for {
+		part, err := partsReader.NextPart()
+
+		if err == io.EOF {
+			break
+		}
+
+		assert.Nil(t, err)
+
+		data, _ := io.ReadAll(part)
+
+		parts = append(parts, string(data))
+		contentRanges = append(contentRanges, part.Header.Get(fasthttp.HeaderContentRange))
+	}
+
+	assert.Equal(t, []string{"01", "abc"}, parts)
+	assert.Equal(t, []string{"bytes 0-1/20", "bytes 10-12/20"}, contentRanges)
+}
+
+func TestStreamingFileConditional(t *testing.T) {
+	lastModified := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	r := newFileTestRouter(t, func() interface{} {
+		return &rpc.StreamingFile{Content: bytes.NewReader([]byte("text content")), MimeType: "text/plain",
+			LastModified: lastModified}
+	})
+
+	ctx := doRequest(r, "GET", "/file", "", map[string]string{"Accept-Encoding": "gzip"})
+
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "Sun, 01 May 2022 10:00:00 GMT", string(ctx.Response.Header.Peek(fasthttp.HeaderLastModified)))
+	assert.Contains(t, string(ctx.Response.Header.Peek(fasthttp.HeaderETag)), `W/"`)
+
+	ctx = doRequest(r, "GET", "/file", "", map[string]string{"If-Modified-Since": "Sun, 01 May 2022 10:00:00 GMT"})
+
+	assert.Equal(t, 304, ctx.Response.StatusCode())
+
+	ctx = doRequest(r, "GET", "/file", "", map[string]string{"Range": "bytes=0-3",
+		"If-Range": "Sun, 01 May 2022 10:00:00 GMT", "Accept-Encoding": "gzip"})
+
+	assert.Equal(t, 206, ctx.Response.StatusCode())
+	assert.Equal(t, "text", string(ctx.Response.Body()))
+	assert.NotContains(t, string(ctx.Response.Header.Peek(fasthttp.HeaderETag)), `W/"`)
+}
@@ -0,0 +1,189 @@
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"go.elastic.co/apm"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CachePolicy struct {
+	MaxAge    time.Duration // Cache-Control max-age, zero means clients should always revalidate with ETag
+	ServerTtl time.Duration // keeps command result in router response cache, requires WithResponseCache
+}
+
+// ResponseCache is implemented by cache.Service
+type ResponseCache interface {
+	Get(key string, ctx context.Context, apmTransaction *apm.Transaction) (interface{}, error)
+	Set(key string, value interface{}, ctx context.Context, expiration time.Duration, transaction *apm.Transaction) error
+}
+
+// WithCachePolicy enables ETag and Cache-Control headers for GET command
+func (r RestCommandBuilder) WithCachePolicy(policy CachePolicy) RestCommandBuilder {
+	r.cmd.cachePolicy = &policy
+
+	return r
+}
+
+func (r *HttpRouter) WithResponseCache(responseCache ResponseCache) *HttpRouter {
+	r.responseCache = responseCache
+
+	return r
+}
+
+func isCacheableMethod(httpCtx *fasthttp.RequestCtx) bool {
+	return httpCtx != nil && (httpCtx.IsGet() || httpCtx.IsHead())
+}
+
+// ResponseCacheMiddleware should run after AuthorizationMiddleware, as user id is a part of the key
+func (r *HttpRouter) ResponseCacheMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			policy := getCommandOptions(executionData.command).cachePolicy
+
+			if policy == nil || policy.ServerTtl <= 0 || r.responseCache == nil || !isCacheableMethod(executionData.httpCtx) {
+				return next(request, executionData)
+			}
+
+			key := buildResponseCacheKey(executionData)
+
+			if cached, err := r.responseCache.Get(key, executionData.Context, executionData.ApmTransaction); err == nil {
+				if data, ok := decodeCachedResponse(cached); ok {
+					return data, nil
+				}
+			}
+
+			resp, err := next(request, executionData)
+
+			if err != nil {
+				return resp, err
+			}
+
+			switch resp.(type) {
+			case *rpc.CustomFile, *rpc.StreamingFile: // cached json can not be written as a file
+				return resp, nil
+			}
+
+			if marshalled, marshalErr := json.Marshal(resp); marshalErr != nil {
+				apm_helper.LogError(errors.WithStack(marshalErr), executionData.Context)
+			} else if setErr := r.responseCache.Set(key, marshalled, executionData.Context, policy.ServerTtl,
+				executionData.ApmTransaction); setErr != nil {
+				apm_helper.LogError(errors.Wrap(setErr, "can not store response in cache"), executionData.Context)
+			}
+
+			return resp, nil
+		}
+	}
+}
+
+// local cache returns stored bytes, redis returns them as json (base64) string
+func decodeCachedResponse(cached interface{}) (json.RawMessage, bool) {
+	switch v := cached.(type) {
+	case []byte:
+		return v, true
+	case string:
+		var data []byte
+
+		if err := json.Unmarshal([]byte(v), &data); err != nil {
+			return nil, false
+		}
+
+		return data, true
+	}
+
+	return nil, false
+}
+
+func buildResponseCacheKey(executionData MethodExecutionData) string {
+	httpCtx := executionData.httpCtx
+
+	var args []string
+
+	httpCtx.QueryArgs().VisitAll(func(key, value []byte) {
+		args = append(args, fmt.Sprintf("%s=%s", key, value))
+	})
+
+	sort.Strings(args)
+
+	return fmt.Sprintf("rest_cache:%s:%s?%v:%v", httpCtx.Method(), httpCtx.Path(), strings.Join(args, "&"),
+		executionData.UserId)
+}
+
+func isUserSpecificResponse(cmd ICommand, httpCtx *fasthttp.RequestCtx) bool {
+	return cmd.RequireIdentityValidation() || cmd.AccessLevel() > common.AccessLevelPublic ||
+		len(httpCtx.Request.Header.Peek(fasthttp.HeaderAuthorization)) > 0 ||
+		len(httpCtx.Request.Header.Peek("User-Id")) > 0
+}
+
+// computeEtag hashes response without fields which are different on every call
+func computeEtag(response genericRestResponse) (string, error) {
+	response.ExecutionTimingMs = 0
+	response.Hostname = ""
+
+	b, err := json.Marshal(response)
+
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	sum := sha256.Sum256(b)
+
+	return fmt.Sprintf("\"%v\"", hex.EncodeToString(sum[:16])), nil
+}
+
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyHttpCaching sets caching headers and returns true when client copy is still valid
+func applyHttpCaching(httpCtx *fasthttp.RequestCtx, cmd ICommand, policy *CachePolicy,
+	response genericRestResponse) (notModified bool, err error) {
+	etag, err := computeEtag(response)
+
+	if err != nil {
+		return false, err
+	}
+
+	visibility := "public"
+
+	if isUserSpecificResponse(cmd, httpCtx) {
+		visibility = "private"
+
+		httpCtx.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderAuthorization)
+	}
+
+	if policy.MaxAge > 0 {
+		httpCtx.Response.Header.Set(fasthttp.HeaderCacheControl,
+			fmt.Sprintf("%v, max-age=%v", visibility, strconv.Itoa(int(policy.MaxAge.Seconds()))))
+	} else {
+		httpCtx.Response.Header.Set(fasthttp.HeaderCacheControl, fmt.Sprintf("%v, no-cache", visibility))
+	}
+
+	httpCtx.Response.Header.Set(fasthttp.HeaderETag, etag)
+
+	if ifNoneMatch := httpCtx.Request.Header.Peek(fasthttp.HeaderIfNoneMatch); len(ifNoneMatch) > 0 {
+		return etagMatches(string(ifNoneMatch), etag), nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,98 @@
+package router
+
+import (
+	"context"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"go.elastic.co/apm"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testResponseCache struct {
+	mutex sync.Mutex
+	items map[string]interface{}
+}
+
+func (c *testResponseCache) Get(key string, ctx context.Context, apmTransaction *apm.Transaction) (interface{}, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if v, ok := c.items[key]; ok {
+		return v, nil
+	}
+
+	return nil, errors.New("key is not found in cache")
+}
+
+func (c *testResponseCache) Set(key string, value interface{}, ctx context.Context, expiration time.Duration,
+	transaction *apm.Transaction) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.items[key] = value
+
+	return nil
+}
+
+func TestRestEtagAndNotModified(t *testing.T) {
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return map[string]string{"name": "static"}, nil
+	}, "/static", MethodGet).WithCachePolicy(CachePolicy{MaxAge: time.Minute}).Build()))
+
+	ctx := doRequest(r, "GET", "/static", "", nil)
+	etag := string(ctx.Response.Header.Peek(fasthttp.HeaderETag))
+
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "public, max-age=60", string(ctx.Response.Header.Peek(fasthttp.HeaderCacheControl)))
+
+	ctx = doRequest(r, "GET", "/static", "", map[string]string{fasthttp.HeaderIfNoneMatch: "W/" + etag})
+	assert.Equal(t, fasthttp.StatusNotModified, ctx.Response.StatusCode())
+	assert.Empty(t, ctx.Response.Body())
+
+	ctx = doRequest(r, "GET", "/static", "", map[string]string{fasthttp.HeaderIfNoneMatch: `"other"`,
+		fasthttp.HeaderAuthorization: "Bearer token"})
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, etag, string(ctx.Response.Header.Peek(fasthttp.HeaderETag)))
+	assert.Equal(t, "private, max-age=60", string(ctx.Response.Header.Peek(fasthttp.HeaderCacheControl)))
+}
+
+func TestRestServerResponseCache(t *testing.T) {
+	calls := 0
+	responseCache := &testResponseCache{items: map[string]interface{}{}}
+
+	r := NewRouter("", nil).WithResponseCache(responseCache)
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		calls += 1
+
+		return map[string]interface{}{"calls": calls}, nil
+	}, "/counter", MethodGet).WithCachePolicy(CachePolicy{ServerTtl: time.Minute}).Build()))
+
+	first := doRequest(r, "GET", "/counter?b=2&a=1", "", nil)
+	second := doRequest(r, "GET", "/counter?a=1&b=2", "", nil)
+	other := doRequest(r, "GET", "/counter?a=2", "", nil)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, string(first.Response.Header.Peek(fasthttp.HeaderETag)),
+		string(second.Response.Header.Peek(fasthttp.HeaderETag)))
+	assert.Contains(t, string(second.Response.Body()), `"calls":1`)
+	assert.Contains(t, string(other.Response.Body()), `"calls":2`)
+	assert.Contains(t, responseCache.items, "rest_cache:GET:/counter?a=1&b=2:0")
+}
+
+func TestDecodeCachedResponse(t *testing.T) {
+	data, ok := decodeCachedResponse(`"eyJhIjoxfQ=="`) // value as returned by redis
+	assert.True(t, ok)
+	assert.Equal(t, `{"a":1}`, string(data))
+
+	data, ok = decodeCachedResponse([]byte(`{"a":1}`))
+	assert.True(t, ok)
+	assert.Equal(t, `{"a":1}`, string(data))
+}
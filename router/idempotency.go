@@ -0,0 +1,240 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	defaultIdempotencyLockTtl = 10 * time.Minute // for commands without timeout
+	idempotencyLockMargin     = time.Minute
+	defaultIdempotencyWait    = 5 * time.Second
+)
+
+type IdempotencyRecord struct {
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+	// Fingerprint is a hash of request params, the key reused with other params is rejected
+	Fingerprint string `json:"fingerprint"`
+	// Owner is a random token of the request which holds the key
+	Owner string `json:"owner"`
+}
+
+type IdempotencyStore interface {
+	// Begin reserves key for owner for lockTtl. When key is already used, its record is returned and acquired is false
+	Begin(ctx context.Context, key string, owner string, lockTtl time.Duration) (existing *IdempotencyRecord,
+		acquired bool, err error)
+	// Complete and Release should change the key only while it is still held by owner
+	Complete(ctx context.Context, key string, owner string, record IdempotencyRecord, ttl time.Duration) error
+	Release(ctx context.Context, key string, owner string) error
+}
+
+var errIdempotencyLockLost = errors.New("idempotency key is held by other request")
+
+// idempotencyState is created per request by the caller of executeAction and filled by IdempotencyMiddleware
+type idempotencyState struct {
+	key         string
+	storeKey    string
+	fingerprint string
+	owner       string
+	ttl         time.Duration
+	acquired    bool
+	replay      *IdempotencyRecord
+}
+
+// newIdempotencyState prefers idempotency_key of rpc call over Idempotency-Key header. Calls of rpc batch
+// do not see the header, see cloneRequestCtxForBatch.
+func newIdempotencyState(httpCtx *fasthttp.RequestCtx, rpcKey string) *idempotencyState {
+	key := rpcKey
+
+	if len(key) == 0 {
+		key = string(httpCtx.Request.Header.Peek(IdempotencyKeyHeader))
+	}
+
+	return &idempotencyState{key: key}
+}
+
+func newIdempotencyOwner() string {
+	b := make([]byte, 16)
+
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// idempotencyLockTtl keeps the key locked while command can still run
+func (r *HttpRouter) idempotencyLockTtl(cmd ICommand, endpoint EndpointType) time.Duration {
+	if timeout := r.getCommandTimeout(cmd, endpoint); timeout > 0 {
+		return timeout + idempotencyLockMargin
+	}
+
+	return defaultIdempotencyLockTtl
+}
+
+func idempotencyFingerprint(request []byte) string {
+	hash := sha256.Sum256(request)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// WithIdempotency makes command honor idempotency_key field of rpc call or Idempotency-Key header.
+// First response is kept for ttl and replayed for requests with the same user, method, key and params.
+func WithIdempotency(cmd ICommand, ttl time.Duration) ICommand {
+	if holder, ok := cmd.(commandOptionsHolder); ok {
+		holder.getCommandOptions().idempotencyTtl = ttl
+	}
+
+	return cmd
+}
+
+func (r RestCommandBuilder) WithIdempotency(ttl time.Duration) RestCommandBuilder {
+	r.cmd.idempotencyTtl = ttl
+
+	return r
+}
+
+// WithIdempotencyStore sets storage for idempotent commands. Concurrent duplicates wait up to waitTimeout
+// for the first request to finish and get 409 after that.
+func (r *HttpRouter) WithIdempotencyStore(store IdempotencyStore, waitTimeout time.Duration) *HttpRouter {
+	r.idempotencyStore = store
+	r.idempotencyWait = waitTimeout
+
+	return r
+}
+
+// IdempotencyMiddleware should run after AuthorizationMiddleware, as user id is a part of the key
+func (r *HttpRouter) IdempotencyMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			state := executionData.idempotency
+			ttl := getCommandOptions(executionData.command).idempotencyTtl
+
+			if state == nil || len(state.key) == 0 || ttl <= 0 || r.idempotencyStore == nil {
+				return next(request, executionData)
+			}
+
+			user := fmt.Sprintf("user:%v", executionData.UserId)
+
+			if executionData.UserId <= 0 {
+				user = fmt.Sprintf("ip:%v", executionData.UserIp)
+			}
+
+			state.storeKey = fmt.Sprintf("idempotency:%v:%v:%v:%v", user, executionData.command.GetHttpMethod(),
+				executionData.command.GetPath(), state.key)
+			state.ttl = ttl
+			state.fingerprint = idempotencyFingerprint(request)
+			state.owner = newIdempotencyOwner()
+
+			lockTtl := r.idempotencyLockTtl(executionData.command, executionData.endpointType)
+			deadline := time.Now().Add(r.idempotencyWait)
+
+			for {
+				existing, acquired, err := r.idempotencyStore.Begin(executionData.Context, state.storeKey, state.owner,
+					lockTtl)
+
+				if err != nil {
+					apm_helper.LogError(errors.Wrap(err, "idempotency store failed"), executionData.Context)
+
+					return next(request, executionData)
+				}
+
+				if acquired {
+					state.acquired = true
+
+					return next(request, executionData)
+				}
+
+				if existing != nil && existing.Completed {
+					if len(existing.Fingerprint) > 0 && existing.Fingerprint != state.fingerprint {
+						return nil, error_codes.NewErrorWithCodeRef(errors.New("idempotency key is already used for other request"),
+							error_codes.GenericValidationError)
+					}
+
+					state.replay = existing
+
+					return nil, nil
+				}
+
+				if time.Now().After(deadline) {
+					return nil, error_codes.NewErrorWithCodeRef(errors.New("request with the same idempotency key is in progress"),
+						error_codes.GenericDuplicateError)
+				}
+
+				select {
+				case <-time.After(50 * time.Millisecond):
+				case <-executionData.Context.Done():
+					return nil, error_codes.NewErrorWithCodeRef(errors.WithStack(executionData.Context.Err()),
+						error_codes.Timeout)
+				}
+			}
+		}
+	}
+}
+
+// replaceRpcResponseId sets id of the current call to the stored rpc response, as retry can use other id
+func replaceRpcResponseId(body []byte, id string) []byte {
+	var response map[string]json.RawMessage
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return body
+	}
+
+	rawId, _ := json.Marshal(id)
+	response["id"] = rawId
+
+	if result, err := json.Marshal(response); err == nil {
+		return result
+	}
+
+	return body
+}
+
+// isIdempotencyStorable keeps failures which can succeed on retry out of the store
+func isIdempotencyStorable(code int) bool {
+	switch code {
+	case int(error_codes.Timeout), int(error_codes.GenericDuplicateError), int(error_codes.TooManyRequests):
+		return false
+	}
+
+	return code > 0 && code < 500
+}
+
+// finishIdempotency stores the response for the acquired key or releases the key, when response should not be replayed
+func (r *HttpRouter) finishIdempotency(ctx context.Context, state *idempotencyState, statusCode int, contentType string,
+	body []byte) {
+	if state == nil || !state.acquired {
+		return
+	}
+
+	var err error
+
+	if isIdempotencyStorable(statusCode) {
+		err = r.idempotencyStore.Complete(ctx, state.storeKey, state.owner, IdempotencyRecord{
+			Completed:   true,
+			StatusCode:  statusCode,
+			ContentType: contentType,
+			Body:        body,
+			Fingerprint: state.fingerprint,
+			Owner:       state.owner,
+		}, state.ttl)
+	} else {
+		err = r.idempotencyStore.Release(ctx, state.storeKey, state.owner)
+	}
+
+	if err != nil {
+		apm_helper.LogError(errors.Wrap(err, "can not finish idempotent request"), ctx)
+	}
+}
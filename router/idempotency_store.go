@@ -0,0 +1,187 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// redisIdempotencyFinishScript changes the key only while it is held by owner. Empty record deletes the key
+var redisIdempotencyFinishScript = redis.NewScript(`
+local raw = redis.call('GET', KEYS[1])
+if not raw then
+	return 0
+end
+if cjson.decode(raw)['owner'] ~= ARGV[1] then
+	return 0
+end
+if ARGV[2] == '' then
+	redis.call('DEL', KEYS[1])
+else
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+end
+return 1
+`)
+
+type redisIdempotencyStore struct {
+	redis *redis.Client
+}
+
+func NewRedisIdempotencyStore(redisConfig boilerplate.RedisConfig) IdempotencyStore {
+	return &redisIdempotencyStore{
+		redis: redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%v:%v", redisConfig.Host, redisConfig.Port),
+			Password: redisConfig.Password,
+			DB:       redisConfig.Db,
+		}),
+	}
+}
+
+func (s *redisIdempotencyStore) Begin(ctx context.Context, key string, owner string,
+	lockTtl time.Duration) (*IdempotencyRecord, bool, error) {
+	inProgress, _ := json.Marshal(IdempotencyRecord{Owner: owner})
+
+	acquired, err := s.redis.SetNX(ctx, key, inProgress, lockTtl).Result()
+
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+
+	if acquired {
+		return nil, true, nil
+	}
+
+	raw, err := s.redis.Get(ctx, key).Bytes()
+
+	if errors.Is(err, redis.Nil) { // expired between calls
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+
+	var record IdempotencyRecord
+
+	if err = json.Unmarshal(raw, &record); err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+
+	return &record, false, nil
+}
+
+func (s *redisIdempotencyStore) Complete(ctx context.Context, key string, owner string, record IdempotencyRecord,
+	ttl time.Duration) error {
+	b, err := json.Marshal(record)
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return s.finish(ctx, key, owner, string(b), ttl)
+}
+
+func (s *redisIdempotencyStore) Release(ctx context.Context, key string, owner string) error {
+	return s.finish(ctx, key, owner, "", 0)
+}
+
+func (s *redisIdempotencyStore) finish(ctx context.Context, key string, owner string, value string,
+	ttl time.Duration) error {
+	changed, err := redisIdempotencyFinishScript.Run(ctx, s.redis, []string{key}, owner, value,
+		ttl.Milliseconds()).Int()
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if changed == 0 {
+		return errors.WithStack(errIdempotencyLockLost)
+	}
+
+	return nil
+}
+
+// IdempotencyPostgresSchema should be applied by service migrations before using NewPostgresIdempotencyStore
+const IdempotencyPostgresSchema = `create table if not exists idempotency_keys
+(
+    key          text primary key,
+    completed    boolean   not null default false,
+    status_code  integer   not null default 0,
+    content_type text      not null default '',
+    body         bytea,
+    expires_at   timestamp not null
+);
+alter table idempotency_keys add column if not exists fingerprint text not null default '';
+alter table idempotency_keys add column if not exists owner text not null default '';
+create index if not exists idempotency_keys_expires_at_idx on idempotency_keys (expires_at);`
+
+type postgresIdempotencyStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresIdempotencyStore(db *gorm.DB) IdempotencyStore {
+	return &postgresIdempotencyStore{
+		db: db,
+	}
+}
+
+func (s *postgresIdempotencyStore) Begin(ctx context.Context, key string, owner string,
+	lockTtl time.Duration) (*IdempotencyRecord, bool, error) {
+	res := s.db.WithContext(ctx).Exec(`insert into idempotency_keys (key, completed, owner, expires_at) values (?, false, ?, ?)
+		on conflict (key) do update set completed = false, status_code = 0, content_type = '', body = null,
+		fingerprint = '', owner = excluded.owner, expires_at = excluded.expires_at
+		where idempotency_keys.expires_at < (now() at time zone 'utc')`,
+		key, owner, time.Now().UTC().Add(lockTtl))
+
+	if res.Error != nil {
+		return nil, false, errors.WithStack(res.Error)
+	}
+
+	if res.RowsAffected > 0 {
+		return nil, true, nil
+	}
+
+	var records []IdempotencyRecord
+
+	if err := s.db.WithContext(ctx).Raw(`select completed, status_code, content_type, body, fingerprint, owner from idempotency_keys
+		where key = ?`, key).Scan(&records).Error; err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+
+	if len(records) == 0 {
+		return nil, false, nil
+	}
+
+	return &records[0], false, nil
+}
+
+func (s *postgresIdempotencyStore) Complete(ctx context.Context, key string, owner string, record IdempotencyRecord,
+	ttl time.Duration) error {
+	res := s.db.WithContext(ctx).Exec(`update idempotency_keys set completed = ?, status_code = ?,
+		content_type = ?, body = ?, fingerprint = ?, expires_at = ? where key = ? and owner = ?`, record.Completed,
+		record.StatusCode, record.ContentType, record.Body, record.Fingerprint, time.Now().UTC().Add(ttl), key, owner)
+
+	return postgresIdempotencyResult(res)
+}
+
+func (s *postgresIdempotencyStore) Release(ctx context.Context, key string, owner string) error {
+	return postgresIdempotencyResult(s.db.WithContext(ctx).Exec(`delete from idempotency_keys where key = ? and owner = ?`,
+		key, owner))
+}
+
+func postgresIdempotencyResult(res *gorm.DB) error {
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return errors.WithStack(errIdempotencyLockLost)
+	}
+
+	return nil
+}
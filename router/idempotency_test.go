@@ -0,0 +1,244 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testIdempotencyStore struct {
+	mutex    sync.Mutex
+	records  map[string]IdempotencyRecord
+	lockTtls []time.Duration
+}
+
+func newTestIdempotencyStore() *testIdempotencyStore {
+	return &testIdempotencyStore{records: map[string]IdempotencyRecord{}}
+}
+
+func (s *testIdempotencyStore) Begin(ctx context.Context, key string, owner string,
+	lockTtl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if record, ok := s.records[key]; ok {
+		return &record, false, nil
+	}
+
+	s.records[key] = IdempotencyRecord{Owner: owner}
+	s.lockTtls = append(s.lockTtls, lockTtl)
+
+	return nil, true, nil
+}
+
+func (s *testIdempotencyStore) Complete(ctx context.Context, key string, owner string, record IdempotencyRecord,
+	ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.records[key].Owner != owner {
+		return errIdempotencyLockLost
+	}
+
+	s.records[key] = record
+
+	return nil
+}
+
+func (s *testIdempotencyStore) Release(ctx context.Context, key string, owner string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.records[key].Owner != owner {
+		return errIdempotencyLockLost
+	}
+
+	delete(s.records, key)
+
+	return nil
+}
+
+func TestRestIdempotencyReplay(t *testing.T) {
+	var calls int32
+
+	r := NewRouter("", nil).WithIdempotencyStore(newTestIdempotencyStore(), 100*time.Millisecond)
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return map[string]interface{}{"call": atomic.AddInt32(&calls, 1)}, nil
+	}, "/tip", MethodPost).WithIdempotency(time.Hour).Build()))
+
+	first := doRequest(r, "POST", "/tip", "{}", map[string]string{IdempotencyKeyHeader: "key-1"})
+	second := doRequest(r, "POST", "/tip", "{}", map[string]string{IdempotencyKeyHeader: "key-1"})
+	other := doRequest(r, "POST", "/tip", "{}", map[string]string{IdempotencyKeyHeader: "key-2"})
+	noKey := doRequest(r, "POST", "/tip", "{}", nil)
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, first.Response.Body(), second.Response.Body())
+	assert.Equal(t, "true", string(second.Response.Header.Peek(IdempotentReplayedHeader)))
+	assert.Empty(t, first.Response.Header.Peek(IdempotentReplayedHeader))
+	assert.Equal(t, "application/json", string(second.Response.Header.ContentType()))
+	assert.NotEqual(t, first.Response.Body(), other.Response.Body())
+	assert.Empty(t, noKey.Response.Header.Peek(IdempotentReplayedHeader))
+}
+
+func TestRpcIdempotencyConcurrentAndFailures(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	store := newTestIdempotencyStore()
+
+	r := NewRouter("", nil).WithIdempotencyStore(store, 50*time.Millisecond)
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(WithIdempotency(NewServiceCommand("transfer",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+
+			return "ok", nil
+		}, false), time.Hour)))
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(WithIdempotency(NewServiceCommand("broken",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			return nil, error_codes.NewErrorWithCodeRef(errors.New("db is down"), error_codes.GenericServerError)
+		}, false), time.Hour)))
+
+	done := make(chan struct{})
+
+	go func() {
+		doRequest(r, "POST", "/rpc-service", `{"method":"transfer","id":"1","idempotency_key":"k"}`, nil)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	var resp rpc.RpcResponseInternal
+
+	ctx := doRequest(r, "POST", "/rpc-service", `{"method":"transfer","id":"1","idempotency_key":"k"}`, nil)
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, error_codes.GenericDuplicateError, resp.Error.Code)
+
+	close(release)
+	<-done
+
+	resp = rpc.RpcResponseInternal{}
+	ctx = doRequest(r, "POST", "/rpc-service", `{"method":"transfer","id":"2","idempotency_key":"k"}`, nil)
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, "2", resp.Id)
+	assert.JSONEq(t, `"ok"`, string(resp.Result))
+	assert.Equal(t, "true", string(ctx.Response.Header.Peek(IdempotentReplayedHeader)))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	doRequest(r, "POST", "/rpc-service", `{"method":"broken","id":"1","idempotency_key":"k"}`, nil)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	assert.Equal(t, 1, len(store.records)) // server error is released to allow retry
+}
+
+func TestRpcBatchIdempotency(t *testing.T) {
+	var calls int32
+
+	r := NewRouter("", nil).WithIdempotencyStore(newTestIdempotencyStore(), 50*time.Millisecond)
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(WithIdempotency(NewServiceCommand("tip",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			return atomic.AddInt32(&calls, 1), nil
+		}, false), time.Hour)))
+
+	batch := `[{"method":"tip","id":"1","params":{"amount":1},"idempotency_key":"a"},
+		{"method":"tip","id":"2","params":{"amount":2},"idempotency_key":"b"}]`
+	headers := map[string]string{IdempotencyKeyHeader: "shared"}
+
+	first := doRequest(r, "POST", "/rpc-service", batch, headers)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Empty(t, first.Response.Header.Peek(IdempotentReplayedHeader))
+
+	second := doRequest(r, "POST", "/rpc-service", strings.NewReplacer(`"id":"1"`, `"id":"11"`, `"id":"2"`, `"id":"12"`).
+		Replace(batch), headers)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, "11,12", string(second.Response.Header.Peek(IdempotentReplayedHeader)))
+
+	var replayed []rpc.RpcResponseInternal
+	assert.Nil(t, json.Unmarshal(second.Response.Body(), &replayed))
+	assert.ElementsMatch(t, []string{"11", "12"}, []string{replayed[0].Id, replayed[1].Id})
+
+	var resp rpc.RpcResponseInternal
+
+	ctx := doRequest(r, "POST", "/rpc-service", `{"method":"tip","id":"3","params":{"amount":5},"idempotency_key":"a"}`,
+		nil)
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, error_codes.GenericValidationError, resp.Error.Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyExpiredLockOwner(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	store := newTestIdempotencyStore()
+
+	r := NewRouter("", nil).WithIdempotencyStore(store, 10*time.Millisecond)
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(WithTimeout(WithIdempotency(NewServiceCommand("transfer",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+
+			return "ok", nil
+		}, false), time.Hour), 5*time.Minute)))
+
+	request := func(done chan struct{}) {
+		doRequest(r, "POST", "/rpc-service", `{"method":"transfer","id":"1","idempotency_key":"k"}`, nil)
+		close(done)
+	}
+
+	first := make(chan struct{})
+	go request(first)
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	store.mutex.Lock()
+	assert.Equal(t, []time.Duration{6 * time.Minute}, store.lockTtls)
+	store.records = map[string]IdempotencyRecord{} // lock of the first request expired
+	store.mutex.Unlock()
+
+	second := make(chan struct{})
+	go request(second)
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	release <- struct{}{}
+	<-first
+
+	store.mutex.Lock()
+	for _, record := range store.records {
+		assert.False(t, record.Completed) // first request does not overwrite lock of the second one
+	}
+	store.mutex.Unlock()
+
+	close(release)
+	<-second
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	assert.Len(t, store.records, 1)
+
+	for _, record := range store.records {
+		assert.True(t, record.Completed)
+	}
+}
@@ -0,0 +1,150 @@
+package router
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// lruCache keeps expired entries until they are evicted, so they can be used as a fallback
+type lruCache[K comparable, V any] struct {
+	mutex      sync.Mutex
+	maxEntries int
+	items      map[K]*list.Element
+	order      *list.List
+	now        func() time.Time
+}
+
+func newLruCache[K comparable, V any](maxEntries int) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		maxEntries: maxEntries,
+		items:      map[K]*list.Element{},
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+func (c *lruCache[K, V]) get(key K, allowExpired bool) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var empty V
+
+	element, ok := c.items[key]
+
+	if !ok {
+		return empty, false
+	}
+
+	entry := element.Value.(*lruEntry[K, V])
+
+	if !allowExpired && c.now().After(entry.expiresAt) {
+		return empty, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return entry.value, true
+}
+
+func (c *lruCache[K, V]) Get(key K) (V, bool) {
+	return c.get(key, false)
+}
+
+func (c *lruCache[K, V]) GetStale(key K) (V, bool) {
+	return c.get(key, true)
+}
+
+func (c *lruCache[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
+
+// Update changes value of existing entry without touching its position and expiration
+func (c *lruCache[K, V]) Update(key K, fn func(value V) V) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.items[key]
+
+	if !ok {
+		return false
+	}
+
+	entry := element.Value.(*lruEntry[K, V])
+	entry.value = fn(entry.value)
+
+	return true
+}
+
+func (c *lruCache[K, V]) Delete(key K) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.items[key]
+
+	if !ok {
+		return false
+	}
+
+	c.order.Remove(element)
+	delete(c.items, key)
+
+	return true
+}
+
+func (c *lruCache[K, V]) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *lruCache[K, V]) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.items = map[K]*list.Element{}
+	c.order.Init()
+}
+
+// Values returns all entries including expired ones, most recently used first
+func (c *lruCache[K, V]) Values() []V {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result := make([]V, 0, c.order.Len())
+
+	for element := c.order.Front(); element != nil; element = element.Next() {
+		result = append(result, element.Value.(*lruEntry[K, V]).value)
+	}
+
+	return result
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"fmt"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"strconv"
+)
+
+const unknownMethodLabel = "unknown"
+
+var commandLabels = []string{"endpoint", "method", "access_level"}
+
+var (
+	commandRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "router_requests_total",
+		Help: "Number of executed router commands",
+	}, commandLabels)
+	commandErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "router_request_errors_total",
+		Help: "Number of router commands finished with error",
+	}, append(append([]string{}, commandLabels...), "code"))
+	commandExecutionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "router_execution_duration_seconds",
+		Help:    "Command function execution time",
+		Buckets: prometheus.DefBuckets,
+	}, commandLabels)
+	commandTotalDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "router_total_duration_seconds",
+		Help:    "Command execution time including middlewares and auth",
+		Buckets: prometheus.DefBuckets,
+	}, commandLabels)
+	commandInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "router_in_flight_requests",
+		Help: "Number of router commands being executed",
+	}, commandLabels)
+	commandRequestSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "router_request_size_bytes",
+		Help:    "Size of command request body",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+	}, commandLabels)
+	commandResponseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "router_response_size_bytes",
+		Help:    "Size of command response body",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+	}, commandLabels)
+)
+
+// getMetricLabels uses route pattern for rest commands, so path parameters do not create new series
+func getMetricLabels(endpoint EndpointType, cmd ICommand) prometheus.Labels {
+	if cmd == nil {
+		return prometheus.Labels{"endpoint": string(endpoint), "method": unknownMethodLabel, "access_level": ""}
+	}
+
+	method := cmd.GetMethodName()
+
+	if endpoint == EndpointRest {
+		method = fmt.Sprintf("%v %v", cmd.GetHttpMethod(), cmd.GetPath())
+	}
+
+	return prometheus.Labels{
+		"endpoint":     string(endpoint),
+		"method":       method,
+		"access_level": cmd.AccessLevel().ToString(),
+	}
+}
+
+func trackCommandInFlight(endpoint EndpointType, cmd ICommand) func() {
+	gauge := commandInFlight.With(getMetricLabels(endpoint, cmd))
+
+	gauge.Inc()
+
+	return gauge.Dec
+}
+
+func recordCommandMetrics(endpoint EndpointType, cmd ICommand, rpcResponse rpc.RpcResponse, requestSize int,
+	responseSize int) {
+	labels := getMetricLabels(endpoint, cmd)
+
+	commandRequests.With(labels).Inc()
+	commandExecutionDuration.With(labels).Observe(float64(rpcResponse.ExecutionTimingMs) / 1000)
+	commandTotalDuration.With(labels).Observe(float64(rpcResponse.TotalTimingMs) / 1000)
+	commandRequestSize.With(labels).Observe(float64(requestSize))
+	commandResponseSize.With(labels).Observe(float64(responseSize))
+
+	if rpcResponse.Error != nil {
+		commandErrors.With(prometheus.Labels{
+			"endpoint":     labels["endpoint"],
+			"method":       labels["method"],
+			"access_level": labels["access_level"],
+			"code":         strconv.Itoa(int(rpcResponse.Error.Code)),
+		}).Inc()
+	}
+}
@@ -0,0 +1,37 @@
+package router
+
+import (
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCommandMetrics(t *testing.T) {
+	r := newTestRouter(t)
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return executionData.GetUserValue("id"), nil
+	}, "/metrics-test/{id}", MethodGet).Build()))
+
+	restLabels := prometheus.Labels{"endpoint": "rest", "method": "GET /metrics-test/{id}", "access_level": "public"}
+	failLabels := prometheus.Labels{"endpoint": "rpc-service", "method": "fail", "access_level": "public"}
+	unknownLabels := prometheus.Labels{"endpoint": "rpc-service", "method": unknownMethodLabel, "access_level": ""}
+
+	restBefore := testutil.ToFloat64(commandRequests.With(restLabels))
+	failErrorsBefore := testutil.ToFloat64(commandErrors.With(prometheus.Labels{"endpoint": "rpc-service",
+		"method": "fail", "access_level": "public", "code": "400"}))
+	unknownBefore := testutil.ToFloat64(commandRequests.With(unknownLabels))
+
+	doRequest(r, "GET", "/metrics-test/1", "", nil)
+	doRequest(r, "GET", "/metrics-test/2", "", nil)
+	doRequest(r, "POST", "/rpc-service", `{"method":"fail","id":"1"}`, nil)
+	doRequest(r, "POST", "/rpc-service", `{"method":"random-name","id":"1"}`, nil)
+
+	assert.Equal(t, restBefore+2, testutil.ToFloat64(commandRequests.With(restLabels)))
+	assert.Equal(t, failErrorsBefore+1, testutil.ToFloat64(commandErrors.With(prometheus.Labels{"endpoint": "rpc-service",
+		"method": "fail", "access_level": "public", "code": "400"})))
+	assert.Equal(t, unknownBefore+1, testutil.ToFloat64(commandRequests.With(unknownLabels)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(commandInFlight.With(failLabels)))
+}
@@ -0,0 +1,195 @@
+package router
+
+import (
+	"fmt"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"github.com/pkg/errors"
+)
+
+type Middleware func(next CommandFunc) CommandFunc
+
+// execution order (outer to inner): built-in -> router-wide -> endpoint-wide -> per-command -> command fn
+
+func (r *HttpRouter) Use(middlewares ...Middleware) *HttpRouter {
+	r.middlewares = append(r.middlewares, middlewares...)
+
+	return r
+}
+
+func (r *HttpRouter) UseRest(middlewares ...Middleware) *HttpRouter {
+	r.restMiddlewares = append(r.restMiddlewares, middlewares...)
+
+	return r
+}
+
+// WithRecoveryMiddleware replaces RecoveryMiddleware, nil disables it. Other built-in middlewares always run.
+func (r *HttpRouter) WithRecoveryMiddleware(middleware Middleware) *HttpRouter {
+	r.recoveryMiddleware = middleware
+
+	return r
+}
+
+// WithAuthorizationMiddleware replaces AuthorizationMiddleware, nil disables it. Replacement should fill
+// UserId of MethodExecutionData, as identity validation, rate limits and idempotency rely on it.
+func (r *HttpRouter) WithAuthorizationMiddleware(middleware Middleware) *HttpRouter {
+	r.authorizationMiddleware = middleware
+
+	return r
+}
+
+func (r *HttpRouter) initBuiltInMiddlewares() {
+	r.recoveryMiddleware = r.RecoveryMiddleware()
+	r.authorizationMiddleware = r.AuthorizationMiddleware()
+	r.beforeAuthMiddlewares = []Middleware{
+		r.CommandToggleMiddleware(),
+		r.TimeoutMiddleware(),
+		r.RequestLoggingMiddleware(),
+		r.AdminAuditMiddleware(),
+	}
+	r.afterAuthMiddlewares = []Middleware{
+		r.IdentityValidationMiddleware(),
+		r.ApmUserMiddleware(),
+		r.RateLimitMiddleware(),
+		r.IdempotencyMiddleware(),
+		r.ResponseCacheMiddleware(),
+	}
+}
+
+func WithMiddlewares(cmd ICommand, middlewares ...Middleware) ICommand {
+	if holder, ok := cmd.(commandOptionsHolder); ok {
+		options := holder.getCommandOptions()
+		options.middlewares = append(options.middlewares, middlewares...)
+	}
+
+	return cmd
+}
+
+func (r *HttpRouter) buildMiddlewareChain(cmd ICommand, endpointMiddlewares []Middleware, fn CommandFunc) CommandFunc {
+	var all []Middleware
+
+	if r.recoveryMiddleware != nil {
+		all = append(all, r.recoveryMiddleware)
+	}
+
+	all = append(all, r.beforeAuthMiddlewares...)
+
+	if r.authorizationMiddleware != nil {
+		all = append(all, r.authorizationMiddleware)
+	}
+
+	all = append(all, r.afterAuthMiddlewares...)
+	all = append(all, r.middlewares...)
+	all = append(all, endpointMiddlewares...)
+
+	if holder, ok := cmd.(commandOptionsHolder); ok {
+		all = append(all, holder.getCommandOptions().middlewares...)
+	}
+
+	for i := len(all) - 1; i >= 0; i-- {
+		fn = all[i](fn)
+	}
+
+	return fn
+}
+
+func (r *HttpRouter) RecoveryMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (resp interface{}, err *error_codes.ErrorWithCode) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					resp = nil
+					err = error_codes.NewErrorWithCodeRef(r.recoveredToError(rec), error_codes.GenericPanicError)
+				}
+			}()
+
+			return next(request, executionData)
+		}
+	}
+}
+
+func (r *HttpRouter) RequestLoggingMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			if executionData.httpCtx != nil {
+				r.logRequestHeaders(executionData.httpCtx, executionData.Context) // in future filter for specific routes
+				r.logUserValues(executionData.httpCtx, executionData.Context)
+			}
+
+			return next(request, executionData)
+		}
+	}
+}
+
+func (r *HttpRouter) AuthorizationMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			if err := r.applyTokenAuth(executionData.httpCtx, executionData.Context,
+				tokenAuthEndpoint(executionData.endpointType, executionData.command), executionData.command); err != nil {
+				return nil, err
+			}
+
+			userId, isGuest, isBanned, language, rpcError := executionData.command.CanExecute(executionData.httpCtx,
+				executionData.Context, r.authGoWrapper, r.userExecutorValidator)
+
+			if rpcError != nil {
+				return nil, rpcErrorToErrorWithCode(rpcError)
+			}
+
+			if executionData.audit != nil {
+				executionData.audit.setAuthorized(userId)
+			}
+
+			executionData.UserId = userId
+			executionData.Context = wrappers.WithUserId(executionData.Context, userId)
+			executionData.IsGuest = isGuest
+			executionData.IsBanned = isBanned
+			executionData.Language = language
+
+			return next(request, executionData)
+		}
+	}
+}
+
+func (r *HttpRouter) IdentityValidationMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			cmd := executionData.command
+
+			if executionData.UserId <= 0 && (cmd.RequireIdentityValidation() || cmd.AccessLevel() > common.AccessLevelPublic) {
+				return nil, error_codes.NewErrorWithCodeRef(errors.New("missing jwt token for auth"),
+					error_codes.MissingJwtToken)
+			}
+
+			return next(request, executionData)
+		}
+	}
+}
+
+func (r *HttpRouter) ApmUserMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			if executionData.UserId > 0 && executionData.ApmTransaction != nil {
+				executionData.ApmTransaction.Context.SetUserID(fmt.Sprint(executionData.UserId))
+			}
+
+			return next(request, executionData)
+		}
+	}
+}
+
+func rpcErrorToErrorWithCode(rpcError *rpc.ExtendedLocalRpcError) *error_codes.ErrorWithCode {
+	err := rpcError.LocalHandlingError
+
+	if err == nil {
+		if rpcError.BusinessCode != 0 {
+			err = error_codes.NewBusinessError(rpcError.Message, rpcError.BusinessCode)
+		} else {
+			err = errors.New(rpcError.Message)
+		}
+	}
+
+	return error_codes.NewErrorWithCodeAndData(err, rpcError.Code, rpcError.Data)
+}
@@ -0,0 +1,104 @@
+package router
+
+import (
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			*calls = append(*calls, name)
+
+			return next(request, executionData)
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+
+	r := NewRouter("", nil).Use(recordingMiddleware("router", &calls))
+
+	r.GetRpcServiceEndpoint().Use(recordingMiddleware("endpoint", &calls))
+
+	cmd := WithMiddlewares(NewServiceCommand("ordered", func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		calls = append(calls, "command")
+
+		return nil, nil
+	}, false), recordingMiddleware("cmd", &calls))
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(cmd))
+
+	doRequest(r, "POST", "/rpc-service", `{"method":"ordered","id":"1"}`, nil)
+
+	assert.Equal(t, []string{"router", "endpoint", "cmd", "command"}, calls)
+}
+
+func TestMiddlewareShortCircuitAndReplaceBuiltIn(t *testing.T) {
+	r := NewRouter("", nil).WithAuthorizationMiddleware(headerAuthMiddleware())
+
+	r.GetRpcServiceEndpoint().Use(func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			if executionData.UserId != 42 {
+				return nil, error_codes.NewErrorWithCodeRef(errors.New("feature disabled"), error_codes.Forbidden)
+			}
+
+			return next(request, executionData)
+		}
+	})
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(NewServiceCommand("user", func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return executionData.UserId, nil
+	}, false)))
+
+	var resp rpc.RpcResponseInternal
+
+	ctx := doRequest(r, "POST", "/rpc-service", `{"method":"user","id":"1"}`, map[string]string{"x-tenant-user": "42"})
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, "42", string(resp.Result))
+
+	resp = rpc.RpcResponseInternal{}
+	ctx = doRequest(r, "POST", "/rpc-service", `{"method":"user","id":"1"}`, nil)
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, error_codes.Forbidden, resp.Error.Code)
+}
+
+func headerAuthMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			if v := executionData.GetRequestCtx().Request.Header.Peek("x-tenant-user"); string(v) == "42" {
+				executionData.UserId = 42
+			}
+
+			return next(request, executionData)
+		}
+	}
+}
+
+func TestReplacedAuthorizationKeepsFeatureMiddlewares(t *testing.T) {
+	r := NewRouter("", nil).WithAuthorizationMiddleware(headerAuthMiddleware()).WithRecoveryMiddleware(nil).
+		WithRateLimiter(NewLocalRateLimiter())
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(WithRateLimits(NewServiceCommand("limited",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			return executionData.UserId, nil
+		}, false), PerUser(1, time.Minute))))
+
+	var resp rpc.RpcResponseInternal
+
+	ctx := doRequest(r, "POST", "/rpc-service", `{"method":"limited","id":"1"}`, map[string]string{"x-tenant-user": "42"})
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, "42", string(resp.Result))
+
+	resp = rpc.RpcResponseInternal{}
+	ctx = doRequest(r, "POST", "/rpc-service", `{"method":"limited","id":"1"}`, map[string]string{"x-tenant-user": "42"})
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, error_codes.TooManyRequests, resp.Error.Code)
+}
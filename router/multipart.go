@@ -0,0 +1,505 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/s3"
+	"github.com/digitalmonsters/go-common/swagger"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultMaxRequestBodySize    = 4 * 1024 * 1024 * 100
+	defaultMultipartMaxFieldSize = 1024 * 1024
+	multipartDrainLimit          = 64 * 1024
+)
+
+var (
+	errRequestBodyTooLarge   = errors.New("request body is too large")
+	errMultipartPartTooLarge = errors.New("multipart part is too large")
+)
+
+type MultipartField struct {
+	Name        string
+	Description string
+	IsFile      bool
+	Required    bool
+}
+
+type MultipartOptions struct {
+	MaxBodySize         int64            // whole request, router WithMaxRequestBodySize is used when zero
+	MaxFileSize         int64            // single file part, MaxBodySize is used when zero
+	MaxFieldSize        int64            // single value part, 1MB when zero
+	AllowedContentTypes []string         // declared content type of file parts, like "image/png" or "image/*". Empty allows any
+	Fields              []MultipartField // documents the form in swagger, required fields are checked by ReadForm
+}
+
+// WithMultipart makes command read multipart/form-data body from MethodExecutionData.GetMultipart
+// instead of buffered json body
+func (r RestCommandBuilder) WithMultipart(options MultipartOptions) RestCommandBuilder {
+	r.cmd.multipart = &options
+
+	desc := swagger.ApiDescription{}
+
+	if r.cmd.apiDescription != nil {
+		desc = *r.cmd.apiDescription
+	}
+
+	desc.Request = nil
+	desc.AdditionalSwaggerParameters = append([]swagger.ParameterDescription{}, desc.AdditionalSwaggerParameters...)
+
+	for _, field := range options.Fields {
+		paramType := "string"
+
+		if field.IsFile {
+			paramType = "file"
+		}
+
+		desc.AdditionalSwaggerParameters = append(desc.AdditionalSwaggerParameters, swagger.ParameterDescription{
+			Name:        field.Name,
+			In:          swagger.ParameterInFormData,
+			Description: field.Description,
+			Required:    field.Required,
+			Type:        paramType,
+		})
+	}
+
+	r.cmd.apiDescription = &desc
+
+	return r
+}
+
+// WithMaxRequestBodySize limits buffered request bodies and multipart bodies of commands without own limit
+func (r *HttpRouter) WithMaxRequestBodySize(size int) *HttpRouter {
+	r.maxRequestBodySize = size
+
+	return r
+}
+
+// readRequestBody buffers streamed request body, so it can not exceed maxRequestBodySize
+func (r *HttpRouter) readRequestBody(httpCtx *fasthttp.RequestCtx) ([]byte, *error_codes.ErrorWithCode) {
+	stream := httpCtx.RequestBodyStream()
+
+	if stream == nil {
+		return httpCtx.PostBody(), nil
+	}
+
+	tooLarge := error_codes.NewErrorWithCodeRef(errors.WithStack(errRequestBodyTooLarge), error_codes.InvalidContentLength)
+
+	if httpCtx.Request.Header.ContentLength() > r.maxRequestBodySize {
+		httpCtx.SetConnectionClose()
+
+		return nil, tooLarge
+	}
+
+	body, err := io.ReadAll(&sizeLimitedReader{reader: stream, left: int64(r.maxRequestBodySize),
+		limitErr: errRequestBodyTooLarge})
+
+	if errors.Is(err, errRequestBodyTooLarge) {
+		httpCtx.SetConnectionClose()
+
+		return nil, tooLarge
+	}
+
+	if err != nil {
+		return nil, error_codes.NewErrorWithCodeRef(errors.Wrap(err, "can not read request body"),
+			error_codes.GenericValidationError)
+	}
+
+	httpCtx.Request.SetBodyRaw(body)
+
+	return body, nil
+}
+
+type sizeLimitedReader struct {
+	reader   io.Reader
+	left     int64
+	limitErr error
+	read     int64
+	failure  error
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, l.limitErr
+	}
+
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+
+	n, err := l.reader.Read(p)
+
+	if int64(n) > l.left {
+		n = int(l.left)
+		l.left = -1
+		l.read += int64(n)
+		l.failure = l.limitErr
+
+		return n, l.limitErr
+	}
+
+	l.left -= int64(n)
+	l.read += int64(n)
+
+	if err != nil && err != io.EOF {
+		l.failure = err
+	}
+
+	return n, err
+}
+
+func multipartError(err error) *error_codes.ErrorWithCode {
+	if errors.Is(err, errRequestBodyTooLarge) || errors.Is(err, errMultipartPartTooLarge) {
+		return error_codes.NewErrorWithCodeRef(errors.WithStack(err), error_codes.InvalidContentLength)
+	}
+
+	return error_codes.NewErrorWithCodeRef(errors.Wrap(err, "can not read multipart body"),
+		error_codes.GenericValidationError)
+}
+
+func isContentTypeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	if err != nil {
+		return false
+	}
+
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+
+		if a == mediaType || (strings.HasSuffix(a, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(a, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MultipartReader reads parts in the order they were sent, directly from the connection
+type MultipartReader struct {
+	options   MultipartOptions
+	body      *sizeLimitedReader
+	reader    *multipart.Reader
+	initErr   *error_codes.ErrorWithCode
+	tempFiles []string
+}
+
+func newMultipartReader(httpCtx *fasthttp.RequestCtx, options MultipartOptions, maxBodySize int) *MultipartReader {
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = int64(maxBodySize)
+	}
+
+	if options.MaxFileSize <= 0 {
+		options.MaxFileSize = options.MaxBodySize
+	}
+
+	if options.MaxFieldSize <= 0 {
+		options.MaxFieldSize = defaultMultipartMaxFieldSize
+	}
+
+	m := &MultipartReader{
+		options: options,
+	}
+
+	mediaType, params, err := mime.ParseMediaType(string(httpCtx.Request.Header.ContentType()))
+
+	if err != nil || mediaType != "multipart/form-data" || len(params["boundary"]) == 0 {
+		m.initErr = error_codes.NewErrorWithCodeRef(errors.New("multipart/form-data content type is expected"),
+			error_codes.UnsupportedMediaType)
+
+		return m
+	}
+
+	if contentLength := httpCtx.Request.Header.ContentLength(); contentLength > 0 && int64(contentLength) > options.MaxBodySize {
+		m.initErr = multipartError(errRequestBodyTooLarge)
+
+		httpCtx.SetConnectionClose()
+
+		return m
+	}
+
+	var stream io.Reader = httpCtx.RequestBodyStream()
+
+	if stream == nil {
+		stream = bytes.NewReader(httpCtx.PostBody())
+	}
+
+	m.body = &sizeLimitedReader{reader: stream, left: options.MaxBodySize, limitErr: errRequestBodyTooLarge}
+	m.reader = multipart.NewReader(m.body, params["boundary"])
+
+	return m
+}
+
+// NextPart returns nil part when body is over. Previous part is discarded.
+func (m *MultipartReader) NextPart() (*MultipartPart, *error_codes.ErrorWithCode) {
+	if m.initErr != nil {
+		return nil, m.initErr
+	}
+
+	part, err := m.reader.NextPart()
+
+	if err == io.EOF {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, multipartError(err)
+	}
+
+	p := &MultipartPart{
+		FieldName:   part.FormName(),
+		FileName:    part.FileName(),
+		ContentType: part.Header.Get(fasthttp.HeaderContentType),
+		owner:       m,
+	}
+
+	limit := m.options.MaxFieldSize
+
+	if p.IsFile() {
+		if len(p.ContentType) == 0 {
+			p.ContentType = "application/octet-stream"
+		}
+
+		if !isContentTypeAllowed(p.ContentType, m.options.AllowedContentTypes) {
+			return nil, error_codes.NewErrorWithCodeAndData(errors.New(fmt.Sprintf("content type [%v] is not allowed",
+				p.ContentType)), error_codes.UnsupportedMediaType, map[string]interface{}{
+				"field":         p.FieldName,
+				"allowed_types": m.options.AllowedContentTypes,
+			})
+		}
+
+		limit = m.options.MaxFileSize
+	}
+
+	p.reader = &sizeLimitedReader{reader: part, left: limit,
+		limitErr: errors.Wrap(errMultipartPartTooLarge, p.FieldName)}
+
+	return p, nil
+}
+
+// ReadForm keeps values in memory and saves files to temp files, which are removed after the command is executed
+func (m *MultipartReader) ReadForm() (*MultipartForm, *error_codes.ErrorWithCode) {
+	form := &MultipartForm{
+		Values: map[string][]string{},
+		Files:  map[string][]*MultipartFile{},
+	}
+
+	for {
+		part, err := m.NextPart()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if part == nil {
+			break
+		}
+
+		if part.IsFile() {
+			file, err := part.SaveToTempFile()
+
+			if err != nil {
+				return nil, err
+			}
+
+			form.Files[part.FieldName] = append(form.Files[part.FieldName], file)
+
+			continue
+		}
+
+		value, err := part.Value()
+
+		if err != nil {
+			return nil, err
+		}
+
+		form.Values[part.FieldName] = append(form.Values[part.FieldName], value)
+	}
+
+	var fieldErrors []FieldValidationError
+
+	for _, field := range m.options.Fields {
+		if !field.Required || len(form.Values[field.Name]) > 0 || len(form.Files[field.Name]) > 0 {
+			continue
+		}
+
+		fieldErrors = append(fieldErrors, FieldValidationError{Field: field.Name, Rule: "required"})
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, error_codes.NewErrorWithCodeAndData(errors.New("request validation failed"),
+			error_codes.GenericValidationError, map[string]interface{}{
+				"fields": fieldErrors,
+			})
+	}
+
+	return form, nil
+}
+
+// close removes temp files and drains the rest of the body. Connection is closed when too much is left unread,
+// as the next request on keep-alive connection can not be parsed otherwise
+func (m *MultipartReader) close(httpCtx *fasthttp.RequestCtx) {
+	for _, path := range m.tempFiles {
+		_ = os.Remove(path)
+	}
+
+	if m.body == nil {
+		return
+	}
+
+	if _, err := io.CopyN(io.Discard, m.body, multipartDrainLimit); err != io.EOF {
+		httpCtx.SetConnectionClose()
+	}
+}
+
+type MultipartPart struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	reader      *sizeLimitedReader
+	owner       *MultipartReader
+}
+
+func (p *MultipartPart) IsFile() bool {
+	return len(p.FileName) > 0
+}
+
+func (p *MultipartPart) Read(b []byte) (int, error) {
+	return p.reader.Read(b)
+}
+
+func (p *MultipartPart) Value() (string, *error_codes.ErrorWithCode) {
+	data, err := io.ReadAll(p.reader)
+
+	if err != nil {
+		return "", multipartError(err)
+	}
+
+	return string(data), nil
+}
+
+func (p *MultipartPart) SaveToTempFile() (*MultipartFile, *error_codes.ErrorWithCode) {
+	f, err := os.CreateTemp("", "upload-*")
+
+	if err != nil {
+		return nil, error_codes.NewErrorWithCodeRef(errors.WithStack(err), error_codes.GenericServerError)
+	}
+
+	p.owner.tempFiles = append(p.owner.tempFiles, f.Name())
+
+	size, err := io.Copy(f, p.reader)
+
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		return nil, error_codes.NewErrorWithCodeRef(errors.WithStack(closeErr), error_codes.GenericServerError)
+	}
+
+	if err != nil {
+		return nil, multipartError(err)
+	}
+
+	return &MultipartFile{
+		FieldName:   p.FieldName,
+		FileName:    p.FileName,
+		ContentType: p.ContentType,
+		Size:        size,
+		Path:        f.Name(),
+	}, nil
+}
+
+// UploadToS3 streams the part without buffering it in memory and returns uploaded size
+func (p *MultipartPart) UploadToS3(uploader s3.IUploader, path string) (int64, *error_codes.ErrorWithCode) {
+	if err := uploader.UploadObjectStream(path, p.reader, p.ContentType); err != nil {
+		if p.reader.failure != nil { // uploader hides the reason
+			return 0, multipartError(p.reader.failure)
+		}
+
+		return 0, error_codes.NewErrorWithCodeRef(errors.Wrap(err, "can not upload file"), error_codes.GenericServerError)
+	}
+
+	return p.reader.read, nil
+}
+
+type MultipartFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Size        int64
+	Path        string
+}
+
+func (f *MultipartFile) Open() (*os.File, error) {
+	file, err := os.Open(f.Path)
+
+	return file, errors.WithStack(err)
+}
+
+func (f *MultipartFile) UploadToS3(uploader s3.IUploader, path string) *error_codes.ErrorWithCode {
+	file, err := f.Open()
+
+	if err != nil {
+		return error_codes.NewErrorWithCodeRef(err, error_codes.GenericServerError)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	if err = uploader.UploadObjectStream(path, file, f.ContentType); err != nil {
+		return error_codes.NewErrorWithCodeRef(errors.Wrap(err, "can not upload file"), error_codes.GenericServerError)
+	}
+
+	return nil
+}
+
+type MultipartForm struct {
+	Values map[string][]string
+	Files  map[string][]*MultipartFile
+}
+
+func (f *MultipartForm) GetValue(name string) string {
+	if values := f.Values[name]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (f *MultipartForm) GetInt64(name string) (int64, *error_codes.ErrorWithCode) {
+	value := f.GetValue(name)
+
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	result, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil {
+		return 0, error_codes.NewErrorWithCodeAndData(errors.Wrap(err, "can not parse form value"),
+			error_codes.GenericValidationError, map[string]interface{}{
+				"fields": []FieldValidationError{{Field: name, Rule: "int"}},
+			})
+	}
+
+	return result, nil
+}
+
+func (f *MultipartForm) GetFile(name string) *MultipartFile {
+	if files := f.Files[name]; len(files) > 0 {
+		return files[0]
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/s3"
+	"github.com/digitalmonsters/go-common/swagger"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"io"
+	"mime/multipart"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func buildMultipartBody(t *testing.T, values map[string]string, files map[string][]byte, fileContentType string) (string, string) {
+	var buf bytes.Buffer
+
+	writer := multipart.NewWriter(&buf)
+
+	for k, v := range values {
+		assert.Nil(t, writer.WriteField(k, v))
+	}
+
+	for name, data := range files {
+		h := make(map[string][]string)
+		h["Content-Disposition"] = []string{fmt.Sprintf(`form-data; name="%v"; filename="%v.bin"`, name, name)}
+		h["Content-Type"] = []string{fileContentType}
+
+		part, err := writer.CreatePart(h)
+		assert.Nil(t, err)
+
+		_, err = part.Write(data)
+		assert.Nil(t, err)
+	}
+
+	assert.Nil(t, writer.Close())
+
+	return buf.String(), writer.FormDataContentType()
+}
+
+func decodeRestResponse(t *testing.T, ctx *fasthttp.RequestCtx) genericRestResponse {
+	var resp genericRestResponse
+
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+
+	return resp
+}
+
+func newMultipartTestRouter(t *testing.T, options MultipartOptions, tempPath *string) *HttpRouter {
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		reader, err := executionData.GetMultipart()
+
+		if err != nil {
+			return nil, err
+		}
+
+		form, err := reader.ReadForm()
+
+		if err != nil {
+			return nil, err
+		}
+
+		userId, err := form.GetInt64("user_id")
+
+		if err != nil {
+			return nil, err
+		}
+
+		file := form.GetFile("avatar")
+		content, _ := os.ReadFile(file.Path)
+
+		*tempPath = file.Path
+
+		return map[string]interface{}{
+			"user_id": userId,
+			"size":    file.Size,
+			"content": string(content),
+			"type":    file.ContentType,
+		}, nil
+	}, "/upload", MethodPost).WithMultipart(options).Build()))
+
+	return r
+}
+
+func TestMultipartReadForm(t *testing.T) {
+	var tempPath string
+
+	r := newMultipartTestRouter(t, MultipartOptions{
+		AllowedContentTypes: []string{"image/*"},
+		Fields: []MultipartField{
+			{Name: "user_id", Required: true},
+			{Name: "avatar", IsFile: true, Required: true},
+		},
+	}, &tempPath)
+
+	body, contentType := buildMultipartBody(t, map[string]string{"user_id": "15"},
+		map[string][]byte{"avatar": []byte("png data")}, "image/png")
+
+	ctx := doRequest(r, "POST", "/upload", body, map[string]string{"Content-Type": contentType})
+
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+
+	resp := decodeRestResponse(t, ctx)
+
+	assert.Equal(t, map[string]interface{}{"user_id": float64(15), "size": float64(8), "content": "png data",
+		"type": "image/png"}, resp.Data)
+
+	_, statErr := os.Stat(tempPath)
+	assert.True(t, os.IsNotExist(statErr))
+
+	body, contentType = buildMultipartBody(t, nil, map[string][]byte{"avatar": []byte("png data")}, "image/png")
+
+	ctx = doRequest(r, "POST", "/upload", body, map[string]string{"Content-Type": contentType})
+
+	assert.Equal(t, 400, ctx.Response.StatusCode())
+	assert.Contains(t, string(ctx.Response.Body()), `"field":"user_id","rule":"required"`)
+}
+
+func TestMultipartLimits(t *testing.T) {
+	var tempPath string
+
+	r := newMultipartTestRouter(t, MultipartOptions{
+		MaxFileSize:         4,
+		AllowedContentTypes: []string{"image/png"},
+	}, &tempPath)
+
+	body, contentType := buildMultipartBody(t, map[string]string{"user_id": "1"},
+		map[string][]byte{"avatar": []byte("data")}, "application/pdf")
+
+	ctx := doRequest(r, "POST", "/upload", body, map[string]string{"Content-Type": contentType})
+
+	assert.Equal(t, int(error_codes.UnsupportedMediaType), ctx.Response.StatusCode())
+
+	body, contentType = buildMultipartBody(t, map[string]string{"user_id": "1"},
+		map[string][]byte{"avatar": []byte("too large")}, "image/png")
+
+	ctx = doRequest(r, "POST", "/upload", body, map[string]string{"Content-Type": contentType})
+
+	assert.Equal(t, int(error_codes.InvalidContentLength), ctx.Response.StatusCode())
+
+	ctx = doRequest(r, "POST", "/upload", `{"user_id":1}`, map[string]string{"Content-Type": "application/json"})
+
+	assert.Equal(t, int(error_codes.UnsupportedMediaType), ctx.Response.StatusCode())
+}
+
+func TestMultipartStreamingUpload(t *testing.T) {
+	var uploaded int64
+
+	uploader := &s3.UploaderMock{
+		UploadObjectStreamFn: func(path string, body io.Reader, contentType string) error {
+			n, err := io.Copy(io.Discard, body)
+			uploaded = n
+
+			return err
+		},
+	}
+
+	r := NewRouter("", nil).WithMaxRequestBodySize(1024)
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		reader, err := executionData.GetMultipart()
+
+		if err != nil {
+			return nil, err
+		}
+
+		part, err := reader.NextPart()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return part.UploadToS3(uploader, "music/track.mp3")
+	}, "/upload", MethodPost).WithMultipart(MultipartOptions{MaxBodySize: 1024 * 1024}).Build()))
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return len(request), nil
+	}, "/json", MethodPost).Build()))
+
+	port := freePort(t)
+	r.StartAsync(port)
+
+	defer func() {
+		_ = r.srv.Shutdown()
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	client := &fasthttp.Client{}
+
+	send := func(path string, body string, contentType string) (int, string) {
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+
+		defer fasthttp.ReleaseRequest(req)
+		defer fasthttp.ReleaseResponse(resp)
+
+		req.SetRequestURI(fmt.Sprintf("http://127.0.0.1:%v%v", port, path))
+		req.Header.SetMethod("POST")
+		req.Header.SetContentType(contentType)
+		req.SetBodyString(body)
+
+		assert.Nil(t, client.DoTimeout(req, resp, 5*time.Second))
+
+		return resp.StatusCode(), string(resp.Body())
+	}
+
+	body, contentType := buildMultipartBody(t, nil, map[string][]byte{"track": bytes.Repeat([]byte("a"), 300*1024)},
+		"audio/mpeg")
+
+	status, respBody := send("/upload", body, contentType)
+
+	assert.Equal(t, 200, status)
+	assert.Equal(t, int64(300*1024), uploaded)
+	assert.Contains(t, respBody, `"data":307200`)
+
+	status, _ = send("/json", strings.Repeat("a", 2048), "application/json")
+	assert.Equal(t, int(error_codes.InvalidContentLength), status)
+
+	status, respBody = send("/json", "abc", "application/json")
+	assert.Equal(t, 200, status)
+	assert.Contains(t, respBody, `"data":3`)
+}
+
+func TestMultipartSwagger(t *testing.T) {
+	cmd := NewRestCommand(nil, "/upload", MethodPost).WithMultipart(MultipartOptions{
+		Fields: []MultipartField{{Name: "avatar", IsFile: true, Required: true}},
+	}).Build()
+
+	doc := swagger.GenerateDoc([]swagger.IApiCommand{cmd}, map[string]swagger.ApiDescription{
+		"/upload": *cmd.getApiDescription(),
+	}, nil)
+
+	method := doc["paths"].(map[string]interface{})["/upload"].(map[string]interface{})["post"].(map[string]interface{})
+
+	assert.Equal(t, []string{"multipart/form-data"}, method["consumes"])
+	assert.Equal(t, "formData", method["parameters"].([]interface{})[0].(map[string]interface{})["in"])
+	assert.Equal(t, "file", method["parameters"].([]interface{})[0].(map[string]interface{})["type"])
+}
@@ -0,0 +1,277 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/swagger"
+	"github.com/patrickmn/go-cache"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type RateLimitKey string
+
+const (
+	RateLimitByUser = RateLimitKey("user") // anonymous requests fall back to ip
+	RateLimitByIp   = RateLimitKey("ip")
+	RateLimitGlobal = RateLimitKey("global")
+)
+
+const retryAfterDataKey = "retry_after_sec"
+
+type RateLimit struct {
+	Key    RateLimitKey
+	Limit  int
+	Period time.Duration
+}
+
+func PerUser(limit int, period time.Duration) RateLimit {
+	return RateLimit{Key: RateLimitByUser, Limit: limit, Period: period}
+}
+
+func PerIp(limit int, period time.Duration) RateLimit {
+	return RateLimit{Key: RateLimitByIp, Limit: limit, Period: period}
+}
+
+func Global(limit int, period time.Duration) RateLimit {
+	return RateLimit{Key: RateLimitGlobal, Limit: limit, Period: period}
+}
+
+type RateLimitBucket struct {
+	Key   string
+	Limit RateLimit
+}
+
+type RateLimiter interface {
+	// Allow consumes one request from every bucket only when all of them have it, so rejected request does not
+	// use quota of other limits. Returns index of the first exceeded bucket and how long the caller should wait
+	Allow(ctx context.Context, buckets []RateLimitBucket) (exceeded int, retryAfter time.Duration, err error)
+}
+
+func activeRateLimitBuckets(buckets []RateLimitBucket) []int {
+	var result []int
+
+	for i, b := range buckets {
+		if b.Limit.Limit > 0 && b.Limit.Period > 0 {
+			result = append(result, i)
+		}
+	}
+
+	return result
+}
+
+// WithRateLimits panics when two limits have the same key and period, as one of them would never apply
+func WithRateLimits(cmd ICommand, limits ...RateLimit) ICommand {
+	if holder, ok := cmd.(commandOptionsHolder); ok {
+		options := holder.getCommandOptions()
+		options.rateLimits = appendRateLimits(options.rateLimits, limits)
+	}
+
+	return cmd
+}
+
+func (r RestCommandBuilder) WithRateLimits(limits ...RateLimit) RestCommandBuilder {
+	r.cmd.rateLimits = appendRateLimits(r.cmd.rateLimits, limits)
+
+	return r
+}
+
+func appendRateLimits(existing []RateLimit, limits []RateLimit) []RateLimit {
+	result := append([]RateLimit{}, existing...)
+
+	for _, limit := range limits {
+		for _, l := range result {
+			if l.Key == limit.Key && l.Period == limit.Period {
+				panic(fmt.Sprintf("duplicate rate limit by [%v] per [%v]", limit.Key, limit.Period))
+			}
+		}
+
+		result = append(result, limit)
+	}
+
+	return result
+}
+
+func (o commandOptions) GetRateLimitDescriptions() []swagger.RateLimitDescription {
+	var result []swagger.RateLimitDescription
+
+	for _, l := range o.rateLimits {
+		result = append(result, swagger.RateLimitDescription{
+			Key:       string(l.Key),
+			Limit:     l.Limit,
+			PeriodSec: int(l.Period.Seconds()),
+		})
+	}
+
+	return result
+}
+
+func (r *HttpRouter) WithRateLimiter(limiter RateLimiter) *HttpRouter {
+	r.rateLimiter = limiter
+
+	return r
+}
+
+// RateLimitMiddleware should run after AuthorizationMiddleware, so user limits are applied to the real user id.
+// Limiter errors are logged and the request is allowed (fail-open).
+func (r *HttpRouter) RateLimitMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			limits := getCommandOptions(executionData.command).rateLimits
+
+			if len(limits) == 0 || r.rateLimiter == nil {
+				return next(request, executionData)
+			}
+
+			methodKey := fmt.Sprintf("%v:%v", executionData.command.GetHttpMethod(), executionData.command.GetPath())
+			buckets := make([]RateLimitBucket, 0, len(limits))
+
+			for _, limit := range limits {
+				buckets = append(buckets, RateLimitBucket{
+					Key:   buildRateLimitKey(methodKey, limit, executionData),
+					Limit: limit,
+				})
+			}
+
+			exceeded, retryAfter, err := r.rateLimiter.Allow(executionData.Context, buckets)
+
+			if err != nil {
+				apm_helper.LogError(errors.Wrap(err, "rate limiter failed"), executionData.Context)
+
+				return next(request, executionData)
+			}
+
+			if exceeded >= 0 {
+				return nil, newTooManyRequestsError(buckets[exceeded].Limit, retryAfter)
+			}
+
+			return next(request, executionData)
+		}
+	}
+}
+
+// buildRateLimitKey includes limit and period, so burst and sustained limits of the same key use own buckets
+func buildRateLimitKey(methodKey string, limit RateLimit, executionData MethodExecutionData) string {
+	prefix := fmt.Sprintf("rl:%v:%v/%v", methodKey, limit.Limit, limit.Period)
+
+	switch limit.Key {
+	case RateLimitByUser:
+		if executionData.UserId > 0 {
+			return fmt.Sprintf("%v:user:%v", prefix, executionData.UserId)
+		}
+
+		return fmt.Sprintf("%v:user_ip:%v", prefix, executionData.UserIp)
+	case RateLimitByIp:
+		return fmt.Sprintf("%v:ip:%v", prefix, executionData.UserIp)
+	default:
+		return fmt.Sprintf("%v:global", prefix)
+	}
+}
+
+func newTooManyRequestsError(limit RateLimit, retryAfter time.Duration) *error_codes.ErrorWithCode {
+	retryAfterSec := int(math.Ceil(retryAfter.Seconds()))
+
+	if retryAfterSec < 1 {
+		retryAfterSec = 1
+	}
+
+	return error_codes.NewErrorWithCodeAndData(errors.New(fmt.Sprintf("rate limit exceeded: %v requests per %v by %v",
+		limit.Limit, limit.Period.String(), limit.Key)), error_codes.TooManyRequests, map[string]interface{}{
+		retryAfterDataKey: retryAfterSec,
+	})
+}
+
+func setRetryAfterHeader(httpCtx *fasthttp.RequestCtx, code error_codes.ErrorCode, data map[string]interface{}) {
+	if code != error_codes.TooManyRequests || data == nil {
+		return
+	}
+
+	if v, ok := data[retryAfterDataKey].(int); ok {
+		httpCtx.Response.Header.Set(fasthttp.HeaderRetryAfter, strconv.Itoa(v))
+	}
+}
+
+type tokenBucket struct {
+	tokens   float64
+	lastFill time.Time
+}
+
+type localRateLimiter struct {
+	mutex   sync.Mutex
+	buckets *cache.Cache
+}
+
+// NewLocalRateLimiter keeps a token bucket per key in process memory. Limits are per pod.
+func NewLocalRateLimiter() RateLimiter {
+	return &localRateLimiter{
+		buckets: cache.New(10*time.Minute, 5*time.Minute),
+	}
+}
+
+func (l *localRateLimiter) Allow(ctx context.Context, buckets []RateLimitBucket) (int, time.Duration, error) {
+	active := activeRateLimitBuckets(buckets)
+
+	if len(active) == 0 {
+		return -1, 0, nil
+	}
+
+	// buckets of one request are checked and consumed together
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	exceeded := -1
+	var retryAfter time.Duration
+
+	refilled := make([]*tokenBucket, len(active))
+
+	for i, idx := range active {
+		limit := buckets[idx].Limit
+		bucket := &tokenBucket{
+			tokens:   float64(limit.Limit),
+			lastFill: now,
+		}
+
+		if v, ok := l.buckets.Get(buckets[idx].Key); ok {
+			bucket = v.(*tokenBucket)
+		}
+
+		ratePerSec := float64(limit.Limit) / limit.Period.Seconds()
+
+		bucket.tokens = math.Min(float64(limit.Limit), bucket.tokens+now.Sub(bucket.lastFill).Seconds()*ratePerSec)
+		bucket.lastFill = now
+
+		if bucket.tokens < 1 {
+			if exceeded < 0 {
+				exceeded = idx
+			}
+
+			if wait := time.Duration((1 - bucket.tokens) / ratePerSec * float64(time.Second)); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+
+		refilled[i] = bucket
+	}
+
+	for i, idx := range active {
+		if exceeded < 0 {
+			refilled[i].tokens -= 1
+		}
+
+		// keep the bucket while it can still be partially empty
+		l.buckets.Set(buckets[idx].Key, refilled[i], buckets[idx].Limit.Period*2)
+	}
+
+	if exceeded >= 0 {
+		return exceeded, retryAfter, nil
+	}
+
+	return -1, 0, nil
+}
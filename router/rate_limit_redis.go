@@ -0,0 +1,103 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// token bucket per key (hash with tokens and last refill time in ms). KEYS are buckets, ARGV are limit and period
+// in ms for every bucket. Tokens are consumed only when every bucket has one.
+// Returns index (0 based) of the first exceeded bucket or -1 and wait time in ms
+var redisRateLimitScript = redis.NewScript(`
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local tokens = {}
+local exceeded = -1
+local wait = 0
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 2 - 1])
+	local rate = limit / tonumber(ARGV[i * 2])
+	local state = redis.call("HMGET", key, "tokens", "ts")
+	local current = tonumber(state[1])
+	local ts = tonumber(state[2])
+	if current == nil or ts == nil then
+		current = limit
+	else
+		current = math.min(limit, current + math.max(0, now - ts) * rate)
+	end
+	tokens[i] = current
+	if current < 1 then
+		if exceeded < 0 then
+			exceeded = i - 1
+		end
+		wait = math.max(wait, math.ceil((1 - current) / rate))
+	end
+end
+if exceeded >= 0 then
+	return {exceeded, wait}
+end
+for i, key in ipairs(KEYS) do
+	redis.call("HSET", key, "tokens", tostring(tokens[i] - 1), "ts", tostring(now))
+	redis.call("PEXPIRE", key, tonumber(ARGV[i * 2]) * 2)
+end
+return {-1, 0}
+`)
+
+type redisRateLimiter struct {
+	redis *redis.Client
+}
+
+// NewRedisRateLimiter shares limits between all pods of the service
+func NewRedisRateLimiter(redisConfig boilerplate.RedisConfig) RateLimiter {
+	return &redisRateLimiter{
+		redis: redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%v:%v", redisConfig.Host, redisConfig.Port),
+			Password: redisConfig.Password,
+			DB:       redisConfig.Db,
+		}),
+	}
+}
+
+func (l *redisRateLimiter) Allow(ctx context.Context, buckets []RateLimitBucket) (int, time.Duration, error) {
+	active := activeRateLimitBuckets(buckets)
+
+	if len(active) == 0 {
+		return -1, 0, nil
+	}
+
+	keys := make([]string, 0, len(active))
+	args := make([]interface{}, 0, len(active)*2)
+
+	for _, idx := range active {
+		keys = append(keys, buckets[idx].Key)
+		args = append(args, buckets[idx].Limit.Limit, buckets[idx].Limit.Period.Milliseconds())
+	}
+
+	res, err := redisRateLimitScript.Run(ctx, l.redis, keys, args...).Result()
+
+	if err != nil {
+		return -1, 0, errors.WithStack(err)
+	}
+
+	values, ok := res.([]interface{})
+
+	if !ok || len(values) != 2 {
+		return -1, 0, errors.New(fmt.Sprintf("unexpected redis rate limit response [%v]", res))
+	}
+
+	exceeded, _ := values[0].(int64)
+	waitMs, _ := values[1].(int64)
+
+	if exceeded < 0 || int(exceeded) >= len(active) {
+		return -1, 0, nil
+	}
+
+	return active[exceeded], time.Duration(waitMs) * time.Millisecond, nil
+}
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"context"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/swagger"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLocalRateLimiter(t *testing.T) {
+	limiter := NewLocalRateLimiter()
+	buckets := []RateLimitBucket{{Key: "key", Limit: PerIp(2, time.Minute)}}
+
+	for i := 0; i < 2; i++ {
+		exceeded, _, err := limiter.Allow(context.TODO(), buckets)
+		assert.Nil(t, err)
+		assert.Equal(t, -1, exceeded)
+	}
+
+	exceeded, retryAfter, err := limiter.Allow(context.TODO(), buckets)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exceeded)
+	assert.True(t, retryAfter > 20*time.Second && retryAfter <= 30*time.Second)
+
+	exceeded, _, _ = limiter.Allow(context.TODO(), []RateLimitBucket{{Key: "other", Limit: PerIp(2, time.Minute)}})
+	assert.Equal(t, -1, exceeded)
+}
+
+func TestLocalRateLimiterRejectedRequestKeepsOtherBuckets(t *testing.T) {
+	limiter := NewLocalRateLimiter()
+	buckets := []RateLimitBucket{
+		{Key: "user", Limit: PerUser(10, time.Minute)},
+		{Key: "global", Limit: Global(1, time.Minute)},
+	}
+
+	exceeded, _, _ := limiter.Allow(context.TODO(), buckets)
+	assert.Equal(t, -1, exceeded)
+
+	for i := 0; i < 5; i++ {
+		exceeded, _, _ = limiter.Allow(context.TODO(), buckets)
+		assert.Equal(t, 1, exceeded)
+	}
+
+	v, ok := limiter.(*localRateLimiter).buckets.Get("user")
+	assert.True(t, ok)
+	assert.True(t, v.(*tokenBucket).tokens < 9.1)
+	assert.True(t, v.(*tokenBucket).tokens >= 9)
+}
+
+func TestRestRateLimit(t *testing.T) {
+	r := NewRouter("", nil)
+
+	cmd := NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return "ok", nil
+	}, "/limited", MethodGet).WithRateLimits(PerIp(1, time.Minute)).Build()
+
+	assert.Nil(t, r.RegisterRestCmd(cmd))
+
+	ctx := doRequest(r, "GET", "/limited", "", map[string]string{"X-Forwarded-For": "1.1.1.1"})
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+
+	ctx = doRequest(r, "GET", "/limited", "", map[string]string{"X-Forwarded-For": "1.1.1.1"})
+	assert.Equal(t, int(error_codes.TooManyRequests), ctx.Response.StatusCode())
+	assert.Equal(t, "60", string(ctx.Response.Header.Peek("Retry-After")))
+
+	ctx = doRequest(r, "GET", "/limited", "", map[string]string{"X-Forwarded-For": "2.2.2.2"})
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+
+	doc := swagger.GenerateDoc([]swagger.IApiCommand{*cmd}, map[string]swagger.ApiDescription{}, nil)
+	method := doc["paths"].(map[string]interface{})["/limited"].(map[string]interface{})["get"].(map[string]interface{})
+
+	assert.Len(t, method["x-rate-limits"], 1)
+}
+
+func TestRestRateLimitBurstAndSustained(t *testing.T) {
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return "ok", nil
+	}, "/burst", MethodGet).WithRateLimits(PerUser(1, time.Minute), PerUser(3, time.Hour)).Build()))
+
+	ctx := doRequest(r, "GET", "/burst", "", map[string]string{"X-Forwarded-For": "1.1.1.1"})
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+
+	ctx = doRequest(r, "GET", "/burst", "", map[string]string{"X-Forwarded-For": "1.1.1.1"})
+	assert.Equal(t, int(error_codes.TooManyRequests), ctx.Response.StatusCode())
+	assert.Contains(t, string(ctx.Response.Body()), "1 requests per 1m0s")
+
+	assert.Panics(t, func() {
+		NewRestCommand(nil, "/duplicate", MethodGet).WithRateLimits(PerUser(2, time.Minute), PerUser(5, time.Minute))
+	})
+}
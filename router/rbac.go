@@ -0,0 +1,320 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/kafka_listener"
+	"github.com/digitalmonsters/go-common/ops"
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/valyala/fasthttp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRbacTtl        = 1 * time.Minute
+	defaultRbacMaxEntries = 10000
+	rbacWildcardObj       = "*"
+)
+
+// AdminAccessChecker is checked by AdminCommand instead of per request CheckAdminPermissions call,
+// when auth wrapper passed to CanExecute implements it
+type AdminAccessChecker interface {
+	CheckAdminAccess(ctx context.Context, adminId int64, obj string, accessLevel common.AccessLevel) (bool, error)
+}
+
+type RbacConfig struct {
+	Ttl        time.Duration
+	MaxEntries int
+	// AllowEmptyObj allows non-public admin commands without rbac obj to admins with "*" permission,
+	// by default they are denied for everyone
+	AllowEmptyObj bool
+}
+
+// RbacEngine caches permission sets of admins and evaluates obj and access level locally.
+// Write access implies read access.
+type RbacEngine struct {
+	authGo auth_go.IAuthGoWrapper
+	cache  *lruCache[int64, auth_go.AdminPermissions]
+	cfg    RbacConfig
+}
+
+func NewRbacEngine(authGo auth_go.IAuthGoWrapper, cfg RbacConfig) *RbacEngine {
+	if cfg.Ttl <= 0 {
+		cfg.Ttl = defaultRbacTtl
+	}
+
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultRbacMaxEntries
+	}
+
+	return &RbacEngine{
+		authGo: authGo,
+		cache:  newLruCache[int64, auth_go.AdminPermissions](cfg.MaxEntries),
+		cfg:    cfg,
+	}
+}
+
+func (e *RbacEngine) GetPermissions(ctx context.Context, adminId int64) (auth_go.AdminPermissions, error) {
+	if permissions, ok := e.cache.Get(adminId); ok {
+		return permissions, nil
+	}
+
+	resp := <-e.authGo.InternalGetAdminPermissions(adminId, ctx, false)
+
+	if resp.Error != nil {
+		return auth_go.AdminPermissions{}, errors.Wrap(resp.Error.ToError(), "can not get admin permissions")
+	}
+
+	permissions := resp.Response
+	permissions.AdminId = adminId
+
+	e.cache.Set(adminId, permissions, e.cfg.Ttl)
+
+	return permissions, nil
+}
+
+// Evaluate returns decision and its reason, it does not make any requests
+func (e *RbacEngine) Evaluate(permissions auth_go.AdminPermissions, obj string,
+	accessLevel common.AccessLevel) (bool, string) {
+	if accessLevel == common.AccessLevelPublic {
+		return true, "public access level"
+	}
+
+	if len(obj) == 0 && !e.cfg.AllowEmptyObj {
+		return false, "command has no rbac obj"
+	}
+
+	if permissions.IsSuperAdmin {
+		return true, "super admin"
+	}
+
+	granted := permissions.Permissions[rbacWildcardObj]
+
+	if len(obj) > 0 && permissions.Permissions[obj] > granted {
+		granted = permissions.Permissions[obj]
+	}
+
+	if granted >= accessLevel {
+		return true, "granted " + granted.ToString()
+	}
+
+	if granted == common.AccessLevelPublic {
+		return false, "no permission for obj"
+	}
+
+	return false, "granted " + granted.ToString()
+}
+
+func (e *RbacEngine) CheckAdminAccess(ctx context.Context, adminId int64, obj string,
+	accessLevel common.AccessLevel) (bool, error) {
+	permissions, err := e.GetPermissions(ctx, adminId)
+
+	if err != nil {
+		return false, err
+	}
+
+	allowed, _ := e.Evaluate(permissions, obj, accessLevel)
+
+	return allowed, nil
+}
+
+// Invalidate drops cached permissions of admins, or of all admins when called without ids
+func (e *RbacEngine) Invalidate(adminIds ...int64) {
+	if len(adminIds) == 0 {
+		e.cache.Clear()
+
+		return
+	}
+
+	for _, adminId := range adminIds {
+		e.cache.Delete(adminId)
+	}
+}
+
+// NewKafkaCommand returns kafka_listener command which applies eventsourcing.AdminPermissionsChangedEvent messages
+func (e *RbacEngine) NewKafkaCommand(fancyName string) kafka_listener.ICommand {
+	return kafka_listener.NewCommand(fancyName, func(executionData kafka_listener.ExecutionData,
+		request ...kafka.Message) []kafka.Message {
+		for _, message := range request {
+			var event eventsourcing.AdminPermissionsChangedEvent
+
+			if err := json.Unmarshal(message.Value, &event); err != nil {
+				apm_helper.LogError(errors.Wrap(err, "can not parse admin permissions event"), executionData.Context)
+
+				continue
+			}
+
+			if event.AdminId > 0 {
+				e.Invalidate(event.AdminId)
+			} else {
+				e.Invalidate()
+			}
+		}
+
+		return request
+	}, false)
+}
+
+// ListenKafka starts listener of admin permission events. Every pod should use own configuration.GroupId,
+// otherwise other pods will keep stale permissions.
+func (e *RbacEngine) ListenKafka(configuration boilerplate.KafkaListenerConfiguration,
+	ctx context.Context) kafka_listener.IKafkaListener {
+	return kafka_listener.NewSingleListener(configuration, e.NewKafkaCommand(configuration.Topic), ctx).ListenAsync()
+}
+
+type rbacAuthGoWrapper struct {
+	auth_go.IAuthGoWrapper
+	engine *RbacEngine
+}
+
+func (w *rbacAuthGoWrapper) CheckAdminAccess(ctx context.Context, adminId int64, obj string,
+	accessLevel common.AccessLevel) (bool, error) {
+	return w.engine.CheckAdminAccess(ctx, adminId, obj, accessLevel)
+}
+
+// WithRbacEngine evaluates admin command permissions locally, RegisterRbacDebug adds its debug page
+func (r *HttpRouter) WithRbacEngine(engine *RbacEngine) *HttpRouter {
+	if wrapped, ok := r.authGoWrapper.(*rbacAuthGoWrapper); ok {
+		r.authGoWrapper = wrapped.IAuthGoWrapper
+	}
+
+	r.authGoWrapper = &rbacAuthGoWrapper{
+		IAuthGoWrapper: r.authGoWrapper,
+		engine:         engine,
+	}
+
+	r.rbacEngine = engine
+
+	return r
+}
+
+type rbacDebugCommand struct {
+	Endpoint    string `json:"endpoint"`
+	Method      string `json:"method"`
+	Obj         string `json:"obj"`
+	AccessLevel string `json:"access_level"`
+	Allowed     *bool  `json:"allowed,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+type rbacDebugPage struct {
+	Ttl           string                     `json:"ttl"`
+	AllowEmptyObj bool                       `json:"allow_empty_obj"`
+	Commands      []rbacDebugCommand         `json:"commands"`
+	Admin         *auth_go.AdminPermissions  `json:"admin,omitempty"`
+	Cached        []auth_go.AdminPermissions `json:"cached,omitempty"`
+}
+
+func (r *HttpRouter) getRbacCommands() []ICommand {
+	var commands []ICommand
+
+	if r.rpcEndpointAdmin != nil {
+		commands = append(commands, r.rpcEndpointAdmin.GetRegisteredCommands()...)
+	}
+
+	for _, cmd := range r.streamCommands {
+		if cmd.isAdmin {
+			commands = append(commands, cmd)
+		}
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].GetMethodName() < commands[j].GetMethodName()
+	})
+
+	return commands
+}
+
+// RegisterRbacDebug adds /debug/rbac page to the private server, token is required in X-Debug-Token header.
+// The page is never served by the public router, it exposes permissions of any admin.
+func (r *HttpRouter) RegisterRbacDebug(server *ops.PrivateHttpServer, token string) error {
+	if len(token) == 0 {
+		return errors.New("token is required for /debug/rbac")
+	}
+
+	server.Handle(fasthttp.MethodGet, "/debug/rbac", r.RbacDebugHandler(token))
+
+	return nil
+}
+
+// RbacDebugHandler rejects all requests when token is empty
+func (r *HttpRouter) RbacDebugHandler(token string) fasthttp.RequestHandler {
+	return debugTokenProtected(token, r.rbacDebugHandler)
+}
+
+// rbacDebugHandler lists admin commands with their rbac obj. With ?admin_id= it also shows permissions
+// of the admin and decision for every command, otherwise cached permission sets.
+func (r *HttpRouter) rbacDebugHandler(httpCtx *fasthttp.RequestCtx) {
+	engine := r.rbacEngine
+
+	if engine == nil {
+		httpCtx.Error("rbac engine is not configured", fasthttp.StatusNotFound)
+
+		return
+	}
+
+	page := rbacDebugPage{
+		Ttl:           engine.cfg.Ttl.String(),
+		AllowEmptyObj: engine.cfg.AllowEmptyObj,
+		Commands:      []rbacDebugCommand{},
+	}
+
+	if adminIdStr := string(httpCtx.QueryArgs().Peek("admin_id")); len(adminIdStr) > 0 {
+		adminId, err := strconv.ParseInt(adminIdStr, 10, 64)
+
+		if err != nil {
+			httpCtx.Error("invalid admin_id", fasthttp.StatusBadRequest)
+
+			return
+		}
+
+		permissions, err := engine.GetPermissions(httpCtx, adminId)
+
+		if err != nil {
+			httpCtx.Error(err.Error(), fasthttp.StatusBadGateway)
+
+			return
+		}
+
+		page.Admin = &permissions
+	} else {
+		page.Cached = engine.cache.Values()
+	}
+
+	for _, cmd := range r.getRbacCommands() {
+		endpoint := EndpointRpcAdmin
+
+		if _, ok := cmd.(*StreamCommand); ok {
+			endpoint = EndpointRest
+		}
+
+		item := rbacDebugCommand{
+			Endpoint:    string(endpoint),
+			Method:      cmd.GetMethodName(),
+			Obj:         cmd.GetObj(),
+			AccessLevel: cmd.AccessLevel().ToString(),
+		}
+
+		if page.Admin != nil {
+			allowed, reason := engine.Evaluate(*page.Admin, cmd.GetObj(), cmd.AccessLevel())
+
+			item.Allowed = &allowed
+			item.Reason = reason
+		}
+
+		page.Commands = append(page.Commands, item)
+	}
+
+	b, _ := json.Marshal(page)
+
+	httpCtx.Response.Header.SetContentType("application/json")
+	httpCtx.Response.SetBodyRaw(b)
+}
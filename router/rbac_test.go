@@ -0,0 +1,138 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/kafka_listener"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"testing"
+)
+
+func newRbacAuthGo(requests *int, permissions map[int64]auth_go.AdminPermissions) *auth_go.AuthGoWrapperMock {
+	return &auth_go.AuthGoWrapperMock{
+		InternalGetAdminPermissionsFn: func(adminId int64, ctx context.Context,
+			forceLog bool) chan wrappers.GenericResponseChan[auth_go.AdminPermissions] {
+			*requests++
+
+			ch := make(chan wrappers.GenericResponseChan[auth_go.AdminPermissions], 1)
+			ch <- wrappers.GenericResponseChan[auth_go.AdminPermissions]{Response: permissions[adminId]}
+			close(ch)
+
+			return ch
+		},
+	}
+}
+
+func TestRbacEvaluate(t *testing.T) {
+	engine := NewRbacEngine(nil, RbacConfig{})
+	permissions := auth_go.AdminPermissions{Permissions: map[string]common.AccessLevel{
+		"users":  common.AccessLevelWrite,
+		"videos": common.AccessLevelRead,
+	}}
+
+	cases := []struct {
+		obj     string
+		level   common.AccessLevel
+		allowed bool
+	}{
+		{"users", common.AccessLevelWrite, true},
+		{"users", common.AccessLevelRead, true},
+		{"videos", common.AccessLevelRead, true},
+		{"videos", common.AccessLevelWrite, false},
+		{"payments", common.AccessLevelRead, false},
+		{"", common.AccessLevelRead, false},
+		{"", common.AccessLevelPublic, true},
+	}
+
+	for _, c := range cases {
+		allowed, _ := engine.Evaluate(permissions, c.obj, c.level)
+		assert.Equal(t, c.allowed, allowed, c)
+	}
+
+	permissions.Permissions["*"] = common.AccessLevelRead
+
+	allowed, _ := engine.Evaluate(permissions, "payments", common.AccessLevelRead)
+	assert.True(t, allowed)
+
+	allowed, reason := engine.Evaluate(auth_go.AdminPermissions{IsSuperAdmin: true}, "", common.AccessLevelWrite)
+	assert.False(t, allowed)
+	assert.Equal(t, "command has no rbac obj", reason)
+
+	allowed, _ = NewRbacEngine(nil, RbacConfig{AllowEmptyObj: true}).Evaluate(permissions, "", common.AccessLevelRead)
+	assert.True(t, allowed)
+}
+
+func TestRbacAdminCommand(t *testing.T) {
+	requests := 0
+	permissions := map[int64]auth_go.AdminPermissions{
+		7: {Roles: []string{"support"}, Permissions: map[string]common.AccessLevel{"users": common.AccessLevelRead}},
+	}
+
+	engine := NewRbacEngine(newRbacAuthGo(&requests, permissions), RbacConfig{})
+	r := NewRouter("", nil).WithRbacEngine(engine)
+
+	fn := func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return "ok", nil
+	}
+
+	assert.Nil(t, r.GetRpcAdminEndpoint().RegisterRpcCommand(NewAdminCommand("get_user", fn,
+		common.AccessLevelRead, "users")))
+	assert.Nil(t, r.GetRpcAdminEndpoint().RegisterRpcCommand(NewAdminCommand("ban_user", fn,
+		common.AccessLevelWrite, "users")))
+
+	result, code := rpcResult(t, doRequest(r, "POST", "/rpc-admin", `{"method":"get_user","id":"1"}`,
+		adminHeaders).Response.Body())
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "ok", result)
+
+	_, code = rpcResult(t, doRequest(r, "POST", "/rpc-admin", `{"method":"ban_user","id":"1"}`,
+		adminHeaders).Response.Body())
+	assert.Equal(t, int(error_codes.InvalidJwtToken), code)
+	assert.Equal(t, 1, requests)
+
+	// role changed
+	permissions[7] = auth_go.AdminPermissions{Permissions: map[string]common.AccessLevel{"users": common.AccessLevelWrite}}
+
+	event, _ := json.Marshal(eventsourcing.AdminPermissionsChangedEvent{AdminId: 7})
+	engine.NewKafkaCommand("rbac").Execute(kafka_listener.ExecutionData{Context: context.Background()},
+		kafka.Message{Value: event})
+
+	_, code = rpcResult(t, doRequest(r, "POST", "/rpc-admin", `{"method":"ban_user","id":"1"}`,
+		adminHeaders).Response.Body())
+	assert.Equal(t, 0, code)
+	assert.Equal(t, 2, requests)
+
+	var page rbacDebugPage
+
+	assert.Equal(t, fasthttp.StatusNotFound, doRequest(r, "GET", "/debug/rbac", "", nil).Response.StatusCode())
+
+	debug := func(uri string, token string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(uri)
+		ctx.Request.Header.Set("X-Debug-Token", token)
+
+		r.RbacDebugHandler("secret")(ctx)
+
+		return ctx
+	}
+
+	assert.Equal(t, fasthttp.StatusForbidden, debug("/debug/rbac", "wrong").Response.StatusCode())
+
+	ctx := debug("/debug/rbac?admin_id=7", "secret")
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &page))
+	assert.Len(t, page.Commands, 2)
+	assert.Equal(t, "ban_user", page.Commands[0].Method)
+	assert.True(t, *page.Commands[0].Allowed)
+	assert.Equal(t, int64(7), page.Admin.AdminId)
+
+	ctx = debug("/debug/rbac", "secret")
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &page))
+	assert.Len(t, page.Cached, 1)
+}
@@ -0,0 +1,49 @@
+package router
+
+import (
+	"github.com/digitalmonsters/go-common/redact"
+)
+
+// WithRedactPolicy adds command specific rules on top of redact.Default() for logged request and response bodies
+func WithRedactPolicy(cmd ICommand, policy *redact.Policy) ICommand {
+	if holder, ok := cmd.(commandOptionsHolder); ok {
+		options := holder.getCommandOptions()
+
+		if options.redactPolicy != nil {
+			policy = options.redactPolicy.Merge(policy)
+		}
+
+		options.redactPolicy = policy
+	}
+
+	return cmd
+}
+
+func (r RestCommandBuilder) WithRedactPolicy(policy *redact.Policy) RestCommandBuilder {
+	if r.cmd.redactPolicy != nil {
+		policy = r.cmd.redactPolicy.Merge(policy)
+	}
+
+	r.cmd.redactPolicy = policy
+
+	return r
+}
+
+func getRedactPolicy(cmd ICommand) *redact.Policy {
+	return redact.Default().Merge(getCommandOptions(cmd).redactPolicy)
+}
+
+// newTypedRedactPolicy collects `redact:"true"` fields of typed command request and response,
+// placed under requestPrefix and responsePrefix of the logged bodies
+func newTypedRedactPolicy[Req any, Resp any](requestPrefix string, responsePrefix string) *redact.Policy {
+	var req Req
+	var resp Resp
+
+	policy := redact.NewPolicy().WithStructTags(requestPrefix, req).WithStructTags(responsePrefix, resp)
+
+	if policy.IsEmpty() {
+		return nil
+	}
+
+	return policy
+}
@@ -0,0 +1,44 @@
+package router
+
+import (
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/redact"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type redactTestRequest struct {
+	Phone string `json:"phone" redact:"true"`
+	Name  string `json:"name"`
+}
+
+type redactTestResponse struct {
+	Address string `json:"address" redact:"true"`
+}
+
+func TestTypedCommandRedactPolicy(t *testing.T) {
+	fn := func(request redactTestRequest, executionData MethodExecutionData) (redactTestResponse, *error_codes.ErrorWithCode) {
+		return redactTestResponse{}, nil
+	}
+
+	rpcPolicy := getRedactPolicy(NewTypedServiceCommand("typed", fn, true))
+
+	assert.Equal(t, `{"method":"typed","params":{"name":"n","password":"[REDACTED]","phone":"[REDACTED]"}}`,
+		string(rpcPolicy.Json([]byte(`{"method":"typed","params":{"phone":"1","name":"n","password":"p"}}`))))
+	assert.Equal(t, `{"result":{"address":"[REDACTED]"}}`, string(rpcPolicy.Json([]byte(`{"result":{"address":"a"}}`))))
+
+	restCmd := NewTypedRestCommand(fn, "/typed", "POST").WithRedactPolicy(redact.NewPolicy().WithFields("name")).Build()
+	restPolicy := getRedactPolicy(restCmd)
+
+	assert.Equal(t, `{"name":"[REDACTED]","phone":"[REDACTED]"}`, string(restPolicy.Json([]byte(`{"phone":"1","name":"n"}`))))
+	assert.Equal(t, `{"data":{"address":"[REDACTED]"}}`, string(restPolicy.Json([]byte(`{"data":{"address":"a"}}`))))
+}
+
+func TestWithRedactPolicy(t *testing.T) {
+	cmd := WithRedactPolicy(NewServiceCommand("plain", nil, true), redact.NewPolicy().WithPaths("params.code"))
+
+	assert.Equal(t, `{"params":{"code":"[REDACTED]","other":{"code":"1"}}}`,
+		string(getRedactPolicy(cmd).Json([]byte(`{"params":{"code":"1","other":{"code":"1"}}}`))))
+	assert.Equal(t, `{"params":{"code":"1"}}`,
+		string(getRedactPolicy(NewServiceCommand("plain", nil, true)).Json([]byte(`{"params":{"code":"1"}}`))))
+}
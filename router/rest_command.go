@@ -5,6 +5,7 @@ import (
 	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/go-common/error_codes"
 	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/digitalmonsters/go-common/swagger"
 	"github.com/digitalmonsters/go-common/translation"
 	"github.com/digitalmonsters/go-common/wrappers/auth_go"
 	"github.com/valyala/fasthttp"
@@ -32,6 +33,9 @@ type RestCommand struct {
 	accessLevel               common.AccessLevel
 	requireIdentityValidation bool
 	allowBanned               bool
+	apiDescription            *swagger.ApiDescription
+	multipart                 *MultipartOptions
+	commandOptions
 }
 
 func (r RestCommand) RequireIdentityValidation() bool {
@@ -80,6 +84,12 @@ func (r RestCommandBuilder) RequireIdentityValidation() RestCommandBuilder {
 	return r
 }
 
+func (r RestCommandBuilder) WithMiddlewares(middlewares ...Middleware) RestCommandBuilder {
+	r.cmd.middlewares = append(append([]Middleware{}, r.cmd.middlewares...), middlewares...)
+
+	return r
+}
+
 func (r RestCommandBuilder) Build() *RestCommand {
 	return &r.cmd
 }
@@ -106,14 +116,20 @@ func (r RestCommand) GetFn() CommandFunc {
 	return r.commandFn
 }
 
+func (r RestCommand) getApiDescription() *swagger.ApiDescription {
+	return r.apiDescription
+}
+
 type genericRestResponse struct {
-	Data              interface{} `json:"data"`
-	Success           bool        `json:"success"`
-	Error             string      `json:"error,omitempty"`
-	Stack             string      `json:"stack,omitempty"`
-	Hostname          string      `json:"hostname"`
-	Code              int         `json:"code"`
-	ExecutionTimingMs int64       `json:"execution_timing"`
+	Data              interface{}            `json:"data"`
+	Success           bool                   `json:"success"`
+	Error             string                 `json:"error,omitempty"`
+	ErrorData         map[string]interface{} `json:"error_data,omitempty"`
+	Stack             string                 `json:"stack,omitempty"`
+	Hostname          string                 `json:"hostname"`
+	Code              int                    `json:"code"`
+	TranslationKey    string                 `json:"translation_key,omitempty"`
+	ExecutionTimingMs int64                  `json:"execution_timing"`
 }
 
 func ToRestResponse(data interface{}, err *error_codes.ErrorWithCode) *genericRestResponse {
@@ -122,7 +138,13 @@ func ToRestResponse(data interface{}, err *error_codes.ErrorWithCode) *genericRe
 	if err != nil {
 		finalResp.Success = false
 		finalResp.Code = int(err.GetCode())
+
+		if businessCode := err.GetBusinessCode(); businessCode != 0 {
+			finalResp.Code = businessCode
+			finalResp.TranslationKey = err.GetTranslationKey()
+		}
 		finalResp.Error = err.GetMessage()
+		finalResp.ErrorData = err.GetData()
 		finalResp.Stack = err.GetStack()
 	} else {
 		finalResp.Success = true
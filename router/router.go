@@ -10,6 +10,8 @@ import (
 	"github.com/digitalmonsters/go-common/error_codes"
 	"github.com/digitalmonsters/go-common/rpc"
 	"github.com/digitalmonsters/go-common/swagger"
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/digitalmonsters/go-common/wrappers"
 	"github.com/digitalmonsters/go-common/wrappers/auth_go"
 	fastRouter "github.com/fasthttp/router"
 	"github.com/pkg/errors"
@@ -22,6 +24,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -38,6 +41,32 @@ type HttpRouter struct {
 	rpcEndpointAdminLegacy   IRpcEndpoint
 	rpcEndpointService       IRpcEndpoint
 	endpointRegistratorMutex sync.Mutex
+	rpcBatchMaxSize          int
+	rpcBatchConcurrency      int
+	recoveryMiddleware       Middleware
+	authorizationMiddleware  Middleware
+	beforeAuthMiddlewares    []Middleware
+	afterAuthMiddlewares     []Middleware
+	middlewares              []Middleware
+	restMiddlewares          []Middleware
+	rateLimiter              RateLimiter
+	endpointTimeouts         map[EndpointType]time.Duration
+	inFlight                 *inFlightTracker
+	readinessProbe           ReadinessProbe
+	shutdownDrainDelay       time.Duration
+	shuttingDown             int32
+	corsPolicy               *compiledCorsPolicy
+	endpointCorsPolicies     map[EndpointType]*compiledCorsPolicy
+	streamCommands           map[string]*StreamCommand
+	streams                  *streamRegistry
+	responseCache            ResponseCache
+	idempotencyStore         IdempotencyStore
+	idempotencyWait          time.Duration
+	maxRequestBodySize       int
+	adminAuditSinks          []AdminAuditSink
+	endpointTokenAuth        map[EndpointType]*TokenAuthConfig
+	rbacEngine               *RbacEngine
+	commands                 *commandRegistry
 }
 
 var hostName string
@@ -60,6 +89,18 @@ func NewRouter(rpcEndpointPath string, auth auth_go.IAuthGoWrapper) *HttpRouter
 		authGoWrapper:            auth,
 		restCommands:             map[string]*RestCommand{},
 		userExecutorValidator:    NewDefaultUserExecutorValidator(auth),
+		rpcBatchMaxSize:          defaultRpcBatchMaxSize,
+		rpcBatchConcurrency:      defaultRpcBatchConcurrency,
+		rateLimiter:              NewLocalRateLimiter(),
+		endpointTimeouts:         defaultEndpointTimeouts(),
+		inFlight:                 newInFlightTracker(),
+		endpointCorsPolicies:     map[EndpointType]*compiledCorsPolicy{},
+		streamCommands:           map[string]*StreamCommand{},
+		streams:                  &streamRegistry{clients: map[*streamClient]struct{}{}},
+		idempotencyWait:          defaultIdempotencyWait,
+		maxRequestBodySize:       defaultMaxRequestBodySize,
+		endpointTokenAuth:        map[EndpointType]*TokenAuthConfig{},
+		commands:                 newCommandRegistry(),
 	}
 
 	if hostname, _ := os.Hostname(); len(hostname) > 0 {
@@ -67,10 +108,16 @@ func NewRouter(rpcEndpointPath string, auth auth_go.IAuthGoWrapper) *HttpRouter
 		hostName = hostname
 	}
 
-	if boilerplate.GetCurrentEnvironment() == boilerplate.Prod {
+	env := boilerplate.GetCurrentEnvironment()
+
+	if env == boilerplate.Prod {
 		h.isProd = true
 	}
 
+	h.corsPolicy = compileCorsPolicy(DefaultCorsPolicy(env), env)
+
+	h.initBuiltInMiddlewares()
+
 	return h
 }
 
@@ -79,11 +126,23 @@ func (r *HttpRouter) WithUserExecutorValidator(userExecutorValidator UserExecuto
 	return r
 }
 
+func (r *HttpRouter) WithRpcBatchLimits(maxBatchSize int, maxConcurrency int) *HttpRouter {
+	if maxBatchSize > 0 {
+		r.rpcBatchMaxSize = maxBatchSize
+	}
+
+	if maxConcurrency > 0 {
+		r.rpcBatchConcurrency = maxConcurrency
+	}
+
+	return r
+}
+
 func (r *HttpRouter) GetRpcAdminLegacyEndpoint() IRpcEndpoint {
 	if r.rpcEndpointAdminLegacy == nil {
 		r.rpcEndpointAdminLegacy = newRpcEndpointPublic()
 
-		r.prepareRpcEndpoint("/rpc-admin-legacy", r.rpcEndpointAdminLegacy, "rpc-admin-legacy")
+		r.prepareRpcEndpoint("/rpc-admin-legacy", r.rpcEndpointAdminLegacy, string(EndpointRpcAdminLegacy))
 	}
 
 	return r.rpcEndpointAdminLegacy
@@ -93,7 +152,7 @@ func (r *HttpRouter) GetRpcPublicEndpoint() IRpcEndpoint {
 	if r.rpcEndpointPublic == nil {
 		r.rpcEndpointPublic = newRpcEndpointPublic()
 
-		r.prepareRpcEndpoint("/rpc", r.rpcEndpointPublic, "rpc")
+		r.prepareRpcEndpoint("/rpc", r.rpcEndpointPublic, string(EndpointRpcPublic))
 	}
 
 	return r.rpcEndpointPublic
@@ -103,7 +162,7 @@ func (r *HttpRouter) GetRpcAdminEndpoint() IRpcEndpoint {
 	if r.rpcEndpointAdmin == nil {
 		r.rpcEndpointAdmin = newRpcEndpointAdmin()
 
-		r.prepareRpcEndpoint("/rpc-admin", r.rpcEndpointAdmin, "rpc-admin")
+		r.prepareRpcEndpoint("/rpc-admin", r.rpcEndpointAdmin, string(EndpointRpcAdmin))
 	}
 
 	return r.rpcEndpointAdmin
@@ -113,19 +172,12 @@ func (r *HttpRouter) GetRpcServiceEndpoint() IRpcEndpoint {
 	if r.rpcEndpointService == nil {
 		r.rpcEndpointService = newRpcEndpointService()
 
-		r.prepareRpcEndpoint("/rpc-service", r.rpcEndpointService, "rpc-service")
+		r.prepareRpcEndpoint("/rpc-service", r.rpcEndpointService, string(EndpointRpcService))
 	}
 
 	return r.rpcEndpointService
 }
 
-func (r *HttpRouter) setCors(ctx *fasthttp.RequestCtx) {
-	ctx.Response.Header.Set("Access-Control-Allow-Credentials", "true")
-	ctx.Response.Header.SetBytesV("Access-Control-Allow-Origin", ctx.Request.Header.Peek("Origin"))
-	ctx.Response.Header.Set("Access-Control-Allow-Headers", "*")
-	ctx.Response.Header.Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PATCH, DELETE")
-}
-
 func (r *HttpRouter) RegisterProfiler() {
 	r.endpointRegistratorMutex.Lock()
 	defer r.endpointRegistratorMutex.Unlock()
@@ -137,6 +189,7 @@ func (r *HttpRouter) RegisterProfiler() {
 func (r *HttpRouter) RegisterDocs(apiDef map[string]swagger.ApiDescription,
 	constants []swagger.ConstantDescription) {
 	routes := map[string][]swagger.IApiCommand{}
+	apiDef = r.getTypedApiDescriptions(apiDef)
 
 	for _, c := range r.GetRestRegisteredCommands() {
 		routes["/swagger"] = append(routes["/swagger"], c)
@@ -213,7 +266,7 @@ func (r *HttpRouter) RegisterRestCmd(targetCmd *RestCommand) error {
 		r.endpointRegistratorMutex.Lock()
 
 		r.realRouter.OPTIONS(targetCmd.path, func(ctx *fasthttp.RequestCtx) {
-			r.setCors(ctx)
+			r.handlePreflight(ctx, EndpointRest)
 		})
 	}()
 
@@ -234,17 +287,24 @@ func (r *HttpRouter) RegisterRestCmd(targetCmd *RestCommand) error {
 
 		executionCtx := boilerplate.CreateCustomContext(ctx, apmTransaction, log.Logger)
 
-		var cancelFn context.CancelFunc
+		defer apmTransaction.End()
 
-		executionCtx, cancelFn = context.WithTimeout(executionCtx, 1*time.Minute)
+		var requestBody []byte
 
-		defer func() {
-			cancelFn()
-		}()
+		if targetCmd.multipart == nil {
+			body, bodyErr := r.readRequestBody(ctx)
 
-		defer apmTransaction.End()
+			if bodyErr != nil {
+				apm_helper.LogError(bodyErr.GetError(), executionCtx)
+
+				r.writeRestError(ctx, bodyErr)
+				r.setCors(ctx, EndpointRest)
+
+				return
+			}
 
-		requestBody := ctx.PostBody()
+			requestBody = body
+		}
 
 		rpcRequest := rpc.RpcRequest{
 			Method:  targetCmd.path,
@@ -254,11 +314,13 @@ func (r *HttpRouter) RegisterRestCmd(targetCmd *RestCommand) error {
 		}
 
 		defer func() {
-			r.setCors(ctx)
+			r.setCors(ctx, EndpointRest)
 		}()
 
 		apm_helper.AddApmDataWithContext(executionCtx, "full_url", string(ctx.URI().FullURI()))
 
+		idempotency := newIdempotencyState(ctx, "")
+
 		rpcResponse, shouldLog := r.executeAction(rpcRequest, targetCmd, ctx, executionCtx, targetCmd.forceLog,
 			func(key string) interface{} {
 				if v := ctx.UserValue(key); v != nil {
@@ -280,7 +342,9 @@ func (r *HttpRouter) RegisterRestCmd(targetCmd *RestCommand) error {
 				}
 
 				return nil
-			})
+			}, EndpointRest, r.restMiddlewares, idempotency)
+
+		ctx.Response.Header.SetContentType("application/json")
 
 		var responseBody []byte
 
@@ -289,8 +353,10 @@ func (r *HttpRouter) RegisterRestCmd(targetCmd *RestCommand) error {
 				return
 			}
 
-			r.logRequestBody(requestBody, executionCtx)
-			r.logResponseBody(responseBody, executionCtx)
+			policy := getRedactPolicy(targetCmd)
+
+			r.logRequestBody(policy.Json(requestBody), executionCtx)
+			r.logResponseBody(policy.Json(responseBody), executionCtx)
 			r.logRpcResponseError(rpcResponse, executionCtx)
 		}()
 
@@ -312,23 +378,24 @@ func (r *HttpRouter) RegisterRestCmd(targetCmd *RestCommand) error {
 			originalCode := int(rpcResponse.Error.Code)
 			restResponse.Success = false
 			restResponse.Error = rpcResponse.Error.Message
+			restResponse.ErrorData = rpcResponse.Error.Data
 			restResponse.Stack = rpcResponse.Error.Stack
 
-			if strings.EqualFold(restResponse.Error, "max threshold without kyc exceeded") {
-				restResponse.Code = 2 // todo find a better way
-			}
-			if strings.EqualFold(restResponse.Error, "account is not connected to that device_id") {
-				restResponse.Code = 3 // todo find a better way
-			}
-			if strings.EqualFold(restResponse.Error, error_codes.TokenomicsNotEnoughBalanceError.Error()) {
-				restResponse.Code = int(error_codes.TokenomicsNotEnoughBalance)
-			}
-			if strings.EqualFold(restResponse.Error, error_codes.TokenomicsErrorCannotProceedWithoutKyc.Error()) {
-				restResponse.Code = int(error_codes.KYCRequiredError)
+			businessCode := rpcResponse.Error.BusinessCode
+
+			if businessCode == 0 {
+				// Deprecated: message matching is kept for one release, see error_codes.GetRegisteredErrorByMessage
+				if registered, ok := error_codes.GetRegisteredErrorByMessage(rpcResponse.Error.Message); ok {
+					businessCode = registered.GetBusinessCode()
+				}
 			}
-			if strings.EqualFold(restResponse.Error, error_codes.TokenomicsErrorReceivingUserWithoutKyc.Error()) {
-				restResponse.Code = int(error_codes.TokenomicsReceivingUserWithoutKyc)
+
+			if businessCode != 0 {
+				restResponse.Code = businessCode
+				restResponse.TranslationKey = error_codes.GetTranslationKey(businessCode)
 			}
+
+			setRetryAfterHeader(ctx, rpcResponse.Error.Code, rpcResponse.Error.Data)
 			if originalCode > 0 {
 				finalStatusCode = originalCode
 			} else {
@@ -344,38 +411,70 @@ func (r *HttpRouter) RegisterRestCmd(targetCmd *RestCommand) error {
 		}
 
 		if d, ok := restResponse.Data.(*rpc.CustomFile); ok {
-			responseBody = d.Data
-			ctx.Response.Header.SetContentType(d.MimeType)
-			contentDispositionFirstParam := d.ContentDispositionFirstParam
+			restResponse.Data = customFileToStreaming(d)
+		}
 
-			if len(contentDispositionFirstParam) == 0 {
-				contentDispositionFirstParam = "attachment"
-			}
+		var streamedSize int64
 
-			ctx.Response.Header.Set(fasthttp.HeaderContentDisposition,
-				fmt.Sprintf("%v; filename=\"%v\"", contentDispositionFirstParam, d.Filename))
-			ctx.Response.Header.Set(fasthttp.HeaderAcceptRanges, "bytes")
+		streamed := false
+
+		if d, ok := restResponse.Data.(*rpc.StreamingFile); ok {
+			streamed = true
+
+			if finalStatusCode, streamedSize, err = writeFileResponse(ctx, d); err != nil {
+				apm_helper.LogError(err, executionCtx)
+
+				finalStatusCode = int(error_codes.GenericServerError)
+			}
 		} else {
 			if responseBody, err = json.Marshal(restResponse); err != nil {
 				log.Err(err).Send()
 			}
+
+			if policy := targetCmd.cachePolicy; policy != nil && finalStatusCode == int(error_codes.None) &&
+				isCacheableMethod(ctx) {
+				if notModified, cacheErr := applyHttpCaching(ctx, targetCmd, policy, restResponse); cacheErr != nil {
+					apm_helper.LogError(cacheErr, executionCtx)
+				} else if notModified {
+					responseBody = nil
+					finalStatusCode = fasthttp.StatusNotModified
+				}
+			}
+		}
+
+		if replay := idempotency.replay; replay != nil {
+			responseBody = replay.Body
+			finalStatusCode = replay.StatusCode
+
+			ctx.Response.Header.SetContentType(replay.ContentType)
+			ctx.Response.Header.Set(IdempotentReplayedHeader, "true")
+		} else if streamed { // streamed content can not be replayed
+			r.finishIdempotency(executionCtx, idempotency, 0, "", nil)
+		} else {
+			r.finishIdempotency(executionCtx, idempotency, finalStatusCode, string(ctx.Response.Header.ContentType()),
+				responseBody)
+		}
+
+		if !streamed {
+			ctx.Response.SetBodyRaw(responseBody)
 		}
 
-		ctx.Response.SetBodyRaw(responseBody)
 		ctx.Response.SetStatusCode(finalStatusCode)
+
+		recordCommandMetrics(EndpointRest, targetCmd, rpcResponse, len(requestBody), len(responseBody)+int(streamedSize))
+		r.commands.record(EndpointRest, targetCmd, rpcResponse)
 	})
 
 	return nil
 }
 
 func (r *HttpRouter) executeAction(rpcRequest rpc.RpcRequest, cmd ICommand, httpCtx *fasthttp.RequestCtx,
-	ctx context.Context, forceLog bool, getUserValue func(key string) interface{}) (rpcResponse rpc.RpcResponse, shouldLog bool) {
-	totalTiming := time.Now()
+	ctx context.Context, forceLog bool, getUserValue func(key string) interface{}, endpointType EndpointType,
+	endpointMiddlewares []Middleware, idempotency *idempotencyState) (rpcResponse rpc.RpcResponse, shouldLog bool) {
+	defer r.inFlight.start(endpointType)()
+	defer trackCommandInFlight(endpointType, cmd)()
 
-	r.logRequestHeaders(httpCtx, ctx) // in future filter for specific routes
-	r.logUserValues(httpCtx, ctx)
-
-	var panicErr error
+	totalTiming := time.Now()
 
 	var executionMs int64
 
@@ -384,26 +483,16 @@ func (r *HttpRouter) executeAction(rpcRequest rpc.RpcRequest, cmd ICommand, http
 	}
 
 	defer func() {
-		httpCtx.Response.Header.SetContentType("application/json")
 		rpcResponse.ExecutionTimingMs = executionMs
 		rpcResponse.TotalTimingMs = time.Since(totalTiming).Milliseconds()
 		rpcResponse.Hostname = r.hostname
 	}()
 
-	defer func() {
+	defer func() { // last resort, in case RecoveryMiddleware was replaced
 		if rec := recover(); rec != nil {
 			shouldLog = true
 
-			switch val := rec.(type) {
-			case error:
-				panicErr = errors.Wrap(val, fmt.Sprintf("panic! %v", val))
-			default:
-				panicErr = errors.New(fmt.Sprintf("panic! default! : %v", val))
-			}
-
-			if panicErr == nil {
-				panicErr = errors.New("panic! and that is really bad")
-			}
+			panicErr := r.recoveredToError(rec)
 
 			rpcResponse.Result = nil
 			rpcResponse.Error = &rpc.ExtendedLocalRpcError{
@@ -426,68 +515,55 @@ func (r *HttpRouter) executeAction(rpcRequest rpc.RpcRequest, cmd ICommand, http
 
 	shouldLog = forceLog
 
-	userId, isGuest, isBanned, language, rpcError := cmd.CanExecute(httpCtx, ctx, r.authGoWrapper, r.userExecutorValidator)
+	var multipartReader *MultipartReader
 
-	if rpcError != nil {
-		rpcResponse.Error = rpcError
+	if restCmd, ok := cmd.(*RestCommand); ok && restCmd.multipart != nil {
+		multipartReader = newMultipartReader(httpCtx, *restCmd.multipart, r.maxRequestBodySize)
 
-		return
+		defer multipartReader.close(httpCtx)
 	}
 
-	if userId <= 0 && (cmd.RequireIdentityValidation() || cmd.AccessLevel() > common.AccessLevelPublic) {
-		err := errors.New("missing jwt token for auth")
-
-		rpcError = &rpc.ExtendedLocalRpcError{
-			RpcError: rpc.RpcError{
-				Code:     error_codes.MissingJwtToken,
-				Message:  err.Error(),
-				Hostname: r.hostname,
-			},
-			LocalHandlingError: err,
-		}
-
-		if !r.isProd {
-			rpcError.Stack = fmt.Sprintf("%+v", err)
-		}
-
-		rpcResponse.Error = rpcError
-
-		return
-	}
-
-	apmTransaction := apm.TransactionFromContext(ctx)
-
-	if userId > 0 {
-		if apmTransaction != nil {
-			apmTransaction.Context.SetUserID(fmt.Sprint(userId))
-		}
-	}
-
-	executionTiming := time.Now()
+	ctx = wrappers.WithLoaderCache(withCorrelationId(httpCtx, ctx))
 
 	executionData := MethodExecutionData{
-		ApmTransaction: apmTransaction,
+		ApmTransaction: apm.TransactionFromContext(ctx),
 		Context:        ctx,
-		UserId:         userId,
-		IsGuest:        isGuest,
-		IsBanned:       isBanned,
-		Language:       language,
 		UserIp:         common.GetRealIp(httpCtx),
 		FullUrl:        httpCtx.URI().String(),
+		Language:       translation.DefaultUserLanguage,
 		getUserValueFn: getUserValue,
+		httpCtx:        httpCtx,
+		command:        cmd,
+		endpointType:   endpointType,
+		idempotency:    idempotency,
+		multipart:      multipartReader,
 	}
 
 	if deviceId := httpCtx.Request.Header.Peek("device-id"); len(deviceId) > 0 {
 		executionData.DeviceId = string(deviceId)
 	}
 
-	if resp, err := cmd.GetFn()(rpcRequest.Params, executionData); err != nil {
+	targetFn := cmd.GetFn()
+
+	fn := r.buildMiddlewareChain(cmd, endpointMiddlewares, func(request []byte, data MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		executionTiming := time.Now()
+
+		defer func() {
+			executionMs = time.Since(executionTiming).Milliseconds()
+		}()
+
+		return targetFn(request, data)
+	})
+
+	if resp, err := fn(rpcRequest.Params, executionData); err != nil {
 		rpcResponse.Error = &rpc.ExtendedLocalRpcError{
 			RpcError: rpc.RpcError{
-				Code:     err.GetCode(),
-				Message:  err.GetMessage(),
-				Data:     nil,
-				Hostname: r.hostname,
+				Code:           err.GetCode(),
+				BusinessCode:   err.GetBusinessCode(),
+				TranslationKey: err.GetTranslationKey(),
+				Message:        err.GetMessage(),
+				Data:           err.GetData(),
+				Hostname:       r.hostname,
 			},
 			LocalHandlingError: err.GetError(),
 		}
@@ -505,158 +581,225 @@ func (r *HttpRouter) executeAction(rpcRequest rpc.RpcRequest, cmd ICommand, http
 		rpcResponse.Result = resp
 	}
 
-	executionMs = time.Since(executionTiming).Milliseconds()
 	return
 }
 
+func (r *HttpRouter) recoveredToError(rec interface{}) error {
+	var panicErr error
+
+	switch val := rec.(type) {
+	case error:
+		panicErr = errors.Wrap(val, fmt.Sprintf("panic! %v", val))
+	default:
+		panicErr = errors.New(fmt.Sprintf("panic! default! : %v", val))
+	}
+
+	if panicErr == nil {
+		panicErr = errors.New("panic! and that is really bad")
+	}
+
+	return panicErr
+}
+
 func (r *HttpRouter) prepareRpcEndpoint(rpcEndpointPath string, endpoint IRpcEndpoint, apmTxType string) {
 	r.endpointRegistratorMutex.Lock()
 	defer r.endpointRegistratorMutex.Unlock()
 
 	r.realRouter.OPTIONS(rpcEndpointPath, func(ctx *fasthttp.RequestCtx) {
-		r.setCors(ctx)
+		r.handlePreflight(ctx, EndpointType(apmTxType))
 	})
 
 	r.realRouter.POST(rpcEndpointPath, func(httpCtx *fasthttp.RequestCtx) {
-		var rpcRequest rpc.RpcRequest
-		var rpcResponse rpc.RpcResponse
-		var shouldLog bool
-		var requestBody []byte
-		var apmTransaction *apm.Transaction
+		defer func() {
+			r.setCors(httpCtx, EndpointType(apmTxType))
+		}()
 
-		if traceHeader := httpCtx.Request.Header.Peek(apmhttp.W3CTraceparentHeader); len(traceHeader) > 0 {
-			traceContext, _ := apmhttp.ParseTraceparentHeader(string(traceHeader))
-			apmTransaction = apm_helper.StartNewApmTransactionWithTraceData(rpcRequest.Method, apmTxType, nil, traceContext)
-		} else {
-			apmTransaction = apm_helper.StartNewApmTransaction(rpcRequest.Method, apmTxType, nil, nil)
+		requestBody, bodyErr := r.readRequestBody(httpCtx)
+
+		if bodyErr != nil {
+			httpCtx.Response.Header.SetContentType("application/json")
+			httpCtx.Response.SetBodyRaw(r.marshalRpcError(bodyErr))
+
+			return
 		}
 
-		innerContext := boilerplate.CreateCustomContext(httpCtx, apmTransaction, log.Logger)
+		if isRpcBatchRequest(requestBody) {
+			r.executeRpcBatch(httpCtx, requestBody, endpoint, apmTxType)
 
-		defer func() {
-			if apmTransaction != nil {
-				apmTransaction.End()
-			}
-		}()
+			return
+		}
 
-		defer func() {
-			r.setCors(httpCtx)
-		}()
+		// single call without id still gets response, only notifications of a batch are skipped
+		rpcResponse, responseBody, _ := r.executeRpcCall(httpCtx, requestBody, endpoint, apmTxType)
 
-		defer func() {
-			var responseBody []byte
+		httpCtx.Response.Header.SetContentType("application/json")
+
+		if rpcResponse.Error != nil {
+			setRetryAfterHeader(httpCtx, rpcResponse.Error.Code, rpcResponse.Error.Data)
+		}
+
+		if len(responseBody) > 0 {
+			httpCtx.Response.SetBodyRaw(responseBody)
+		}
+	})
+}
 
-			if apmTransaction != nil {
-				apmTransaction.Outcome = "success"
+func (r *HttpRouter) executeRpcCall(httpCtx *fasthttp.RequestCtx, requestBody []byte, endpoint IRpcEndpoint,
+	apmTxType string) (rpcResponse rpc.RpcResponse, responseBody []byte, isNotification bool) {
+	var rpcRequest rpc.RpcRequest
+	var shouldLog bool
+	var apmTransaction *apm.Transaction
+	var idempotency *idempotencyState
+	var targetCmd ICommand
+
+	if traceHeader := httpCtx.Request.Header.Peek(apmhttp.W3CTraceparentHeader); len(traceHeader) > 0 {
+		traceContext, _ := apmhttp.ParseTraceparentHeader(string(traceHeader))
+		apmTransaction = apm_helper.StartNewApmTransactionWithTraceData(rpcRequest.Method, apmTxType, nil, traceContext)
+	} else {
+		apmTransaction = apm_helper.StartNewApmTransaction(rpcRequest.Method, apmTxType, nil, nil)
+	}
 
-				if rpcResponse.Error != nil {
-					apmTransaction.Outcome = "failure"
-				}
+	innerContext := boilerplate.CreateCustomContext(httpCtx, apmTransaction, log.Logger)
+
+	defer func() {
+		if apmTransaction != nil {
+			apmTransaction.End()
+		}
+	}()
+
+	defer func() {
+		if apmTransaction != nil {
+			apmTransaction.Outcome = "success"
+
+			if rpcResponse.Error != nil {
+				apmTransaction.Outcome = "failure"
 			}
+		}
 
-			if rpcResponse.Result != nil || rpcResponse.Error != nil {
-				if respBody, err := json.Marshal(rpcResponse); err != nil {
-					shouldLog = true
-					rpcResponse.Result = nil
-
-					innerErr := errors.Wrap(err, "error during response serialization")
-
-					rpcResponse.Error = &rpc.ExtendedLocalRpcError{
-						RpcError: rpc.RpcError{
-							Code:     error_codes.GenericMappingError,
-							Message:  innerErr.Error(),
-							Data:     nil,
-							Hostname: r.hostname,
-						},
-						LocalHandlingError: innerErr,
-					}
-					if !r.isProd {
-						rpcResponse.Error.Stack = fmt.Sprintf("%+v", err)
-					}
+		if rpcResponse.Result != nil || rpcResponse.Error != nil {
+			if respBody, err := json.Marshal(rpcResponse); err != nil {
+				shouldLog = true
+				rpcResponse.Result = nil
+
+				innerErr := errors.Wrap(err, "error during response serialization")
+
+				rpcResponse.Error = &rpc.ExtendedLocalRpcError{
+					RpcError: rpc.RpcError{
+						Code:     error_codes.GenericMappingError,
+						Message:  innerErr.Error(),
+						Data:     nil,
+						Hostname: r.hostname,
+					},
+					LocalHandlingError: innerErr,
+				}
+				if !r.isProd {
+					rpcResponse.Error.Stack = fmt.Sprintf("%+v", err)
+				}
 
-					if respBody, err1 := json.Marshal(rpcResponse); err1 != nil {
-						responseBody = []byte(fmt.Sprintf("that`s really not good || %v", err1.Error()))
-					} else {
-						responseBody = respBody
-					}
+				if respBody, err1 := json.Marshal(rpcResponse); err1 != nil {
+					responseBody = []byte(fmt.Sprintf("that`s really not good || %v", err1.Error()))
 				} else {
 					responseBody = respBody
 				}
-
-				httpCtx.Response.SetBodyRaw(responseBody)
+			} else {
+				responseBody = respBody
 			}
+		}
+
+		if idempotency != nil && idempotency.replay != nil {
+			responseBody = replaceRpcResponseId(idempotency.replay.Body, rpcRequest.Id)
+
+			httpCtx.Response.Header.Set(IdempotentReplayedHeader, "true")
+		} else if idempotency != nil {
+			statusCode := int(error_codes.None)
 
 			if rpcResponse.Error != nil {
-				shouldLog = true
+				statusCode = int(rpcResponse.Error.Code)
 			}
 
-			if shouldLog {
-				r.logRequestBody(requestBody, innerContext)
-				r.logResponseBody(responseBody, innerContext)
-				r.logRpcResponseError(rpcResponse, innerContext)
-			}
-		}()
+			r.finishIdempotency(innerContext, idempotency, statusCode, "application/json", responseBody)
+		}
 
-		requestBody = httpCtx.PostBody()
+		if rpcResponse.Error != nil {
+			shouldLog = true
+		}
 
-		if err := json.Unmarshal(requestBody, &rpcRequest); err != nil {
-			rpcResponse.Error = &rpc.ExtendedLocalRpcError{
-				RpcError: rpc.RpcError{
-					Code:     error_codes.GenericMappingError,
-					Message:  err.Error(),
-					Data:     nil,
-					Hostname: r.hostname,
-				},
-				LocalHandlingError: err,
-			}
+		recordCommandMetrics(EndpointType(apmTxType), targetCmd, rpcResponse, len(requestBody), len(responseBody))
+		r.commands.record(EndpointType(apmTxType), targetCmd, rpcResponse)
 
-			if !r.isProd {
-				rpcResponse.Error.Stack = fmt.Sprintf("%+v", err)
-			}
+		if shouldLog {
+			policy := getRedactPolicy(targetCmd)
 
-			return
+			r.logRequestBody(policy.Json(requestBody), innerContext)
+			r.logResponseBody(policy.Json(responseBody), innerContext)
+			r.logRpcResponseError(rpcResponse, innerContext)
 		}
+	}()
 
-		apmTransaction.Name = rpcRequest.Method
+	if err := json.Unmarshal(requestBody, &rpcRequest); err != nil {
+		rpcResponse.Error = &rpc.ExtendedLocalRpcError{
+			RpcError: rpc.RpcError{
+				Code:     error_codes.GenericMappingError,
+				Message:  err.Error(),
+				Data:     nil,
+				Hostname: r.hostname,
+			},
+			LocalHandlingError: err,
+		}
 
-		cmd, err := endpoint.GetCommand(rpcRequest.Method)
+		if !r.isProd {
+			rpcResponse.Error.Stack = fmt.Sprintf("%+v", err)
+		}
 
-		if err != nil {
-			rpcResponse.Error = &rpc.ExtendedLocalRpcError{
-				RpcError: rpc.RpcError{
-					Code:     error_codes.CommandNotFoundError,
-					Message:  err.Error(),
-					Data:     nil,
-					Hostname: r.hostname,
-				},
-				LocalHandlingError: err,
-			}
+		return
+	}
 
-			if !r.isProd {
-				rpcResponse.Error.Stack = fmt.Sprintf("%+v", err)
-			}
+	isNotification = len(rpcRequest.Id) == 0
+	apmTransaction.Name = rpcRequest.Method
 
-			return
+	cmd, err := endpoint.GetCommand(rpcRequest.Method)
+
+	if err != nil {
+		rpcResponse.Id = rpcRequest.Id
+		rpcResponse.Error = &rpc.ExtendedLocalRpcError{
+			RpcError: rpc.RpcError{
+				Code:     error_codes.CommandNotFoundError,
+				Message:  err.Error(),
+				Data:     nil,
+				Hostname: r.hostname,
+			},
+			LocalHandlingError: err,
 		}
 
-		rpcResponse, shouldLog = r.executeAction(rpcRequest, cmd, httpCtx, innerContext, cmd.ForceLog(), func(key string) interface{} {
-			if v := httpCtx.UserValue(key); v != nil {
-				return v
-			}
+		if !r.isProd {
+			rpcResponse.Error.Stack = fmt.Sprintf("%+v", err)
+		}
 
-			if httpCtx.QueryArgs() != nil {
-				if v := httpCtx.QueryArgs().Peek(key); len(v) > 0 {
-					return string(v)
-				}
-			}
+		return
+	}
+
+	idempotency = newIdempotencyState(httpCtx, rpcRequest.IdempotencyKey)
+	targetCmd = cmd
 
-			if v := httpCtx.Request.Header.Peek(key); len(v) > 0 {
+	rpcResponse, shouldLog = r.executeAction(rpcRequest, cmd, httpCtx, innerContext, cmd.ForceLog(), func(key string) interface{} {
+		if v := httpCtx.UserValue(key); v != nil {
+			return v
+		}
+
+		if httpCtx.QueryArgs() != nil {
+			if v := httpCtx.QueryArgs().Peek(key); len(v) > 0 {
 				return string(v)
 			}
+		}
 
-			return nil
-		})
-	})
+		if v := httpCtx.Request.Header.Peek(key); len(v) > 0 {
+			return string(v)
+		}
+
+		return nil
+	}, EndpointType(apmTxType), endpoint.GetMiddlewares(), idempotency)
+
+	return
 }
 
 func (r *HttpRouter) GET(path string, handler fasthttp.RequestHandler) {
@@ -689,6 +832,51 @@ func (r *HttpRouter) logRpcResponseError(rpcResponse rpc.RpcResponse, ctx contex
 	}
 }
 
+// writeRestError is used for failures before command execution
+func (r *HttpRouter) writeRestError(httpCtx *fasthttp.RequestCtx, err *error_codes.ErrorWithCode) {
+	statusCode := int(err.GetCode())
+
+	if statusCode < 400 || statusCode > 599 {
+		statusCode = fasthttp.StatusInternalServerError
+	}
+
+	resp := ToRestResponse(nil, err)
+	resp.Hostname = r.hostname
+
+	if r.isProd {
+		resp.Stack = ""
+	}
+
+	body, _ := json.Marshal(resp)
+
+	httpCtx.Response.Header.SetContentType("application/json")
+	httpCtx.Response.SetStatusCode(statusCode)
+	httpCtx.Response.SetBodyRaw(body)
+}
+
+// marshalRpcError is used for failures before rpc request is parsed
+func (r *HttpRouter) marshalRpcError(err *error_codes.ErrorWithCode) []byte {
+	rpcResponse := rpc.RpcResponse{
+		JsonRpc: "2.0",
+		Error: &rpc.ExtendedLocalRpcError{
+			RpcError: rpc.RpcError{
+				Code:     err.GetCode(),
+				Message:  err.GetMessage(),
+				Data:     err.GetData(),
+				Hostname: r.hostname,
+			},
+		},
+	}
+
+	if !r.isProd {
+		rpcResponse.Error.Stack = err.GetStack()
+	}
+
+	body, _ := json.Marshal(rpcResponse)
+
+	return body
+}
+
 func (r *HttpRouter) logUserValues(httpCtx *fasthttp.RequestCtx,
 	ctx context.Context) string {
 	var realMethodName string
@@ -740,18 +928,6 @@ func (r *HttpRouter) Handler() func(ctx *fasthttp.RequestCtx) {
 		fasthttp.CompressDefaultCompression)
 }
 
-//func (r *HttpRouter) GetRpcRegisteredCommands() []Command {
-//	var commands []Command
-//
-//	if r.executor.commands != nil {
-//		for _, c := range r.executor.commands {
-//			commands = append(commands, *c)
-//		}
-//	}
-//
-//	return commands
-//}
-
 func (r *HttpRouter) GetRestRegisteredCommands() []RestCommand {
 	var commands []RestCommand
 
@@ -772,13 +948,16 @@ func (r *HttpRouter) StartAsync(port int) *HttpRouter {
 	r.srv = &fasthttp.Server{
 		Handler: fasthttp.CompressHandlerBrotliLevel(r.Handler(),
 			fasthttp.CompressDefaultCompression, fasthttp.CompressDefaultCompression),
-		MaxRequestBodySize: 4 * 1024 * 1024 * 100,
+		MaxRequestBodySize:           r.maxRequestBodySize,
+		StreamRequestBody:            true, // bodies are buffered by readRequestBody, multipart commands read the stream
+		DisablePreParseMultipartForm: true,
 	}
 
 	go func() {
 		log.Info().Msgf("Http Server started on port [%v]", port)
 
-		if err := r.srv.ListenAndServe(fmt.Sprintf("0.0.0.0:%v", port)); err != nil {
+		if err := r.srv.ListenAndServe(fmt.Sprintf("0.0.0.0:%v", port)); err != nil &&
+			atomic.LoadInt32(&r.shuttingDown) == 0 {
 			panic(err)
 		}
 	}()
@@ -0,0 +1,179 @@
+package router
+
+import (
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"testing"
+)
+
+func newTestRouter(t *testing.T) *HttpRouter {
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(NewServiceCommand("echo",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			var req map[string]interface{}
+
+			if err := json.Unmarshal(request, &req); err != nil {
+				return nil, error_codes.NewErrorWithCodeRef(err, error_codes.GenericMappingError)
+			}
+
+			return req, nil
+		}, false)))
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(NewServiceCommand("fail",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			return nil, error_codes.NewErrorWithCodeRef(errors.New("failed"), error_codes.GenericValidationError)
+		}, false)))
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(NewServiceCommand("panic",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			panic("boom")
+		}, false)))
+
+	return r
+}
+
+func doRequest(r *HttpRouter, method string, path string, body string, headers map[string]string) *fasthttp.RequestCtx {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.Header.SetMethod(method)
+	req.SetRequestURI(path)
+	req.SetBodyString(body)
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+
+	r.Router().Handler(ctx)
+
+	return ctx
+}
+
+func TestRpcSingleRequest(t *testing.T) {
+	r := newTestRouter(t)
+
+	ctx := doRequest(r, "POST", "/rpc-service", `{"jsonrpc":"2.0","method":"echo","params":{"a":1},"id":"1"}`, nil)
+
+	var resp rpc.RpcResponseInternal
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, "1", resp.Id)
+	assert.JSONEq(t, `{"a":1}`, string(resp.Result))
+
+	resp = rpc.RpcResponseInternal{}
+	ctx = doRequest(r, "POST", "/rpc-service", `{"jsonrpc":"2.0","method":"echo","params":{"a":1}}`, nil)
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.JSONEq(t, `{"a":1}`, string(resp.Result))
+
+	resp = rpc.RpcResponseInternal{}
+	ctx = doRequest(r, "POST", "/rpc-service", `{"jsonrpc":"2.0","method":"fail","params":{}}`, nil)
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, error_codes.GenericValidationError, resp.Error.Code)
+}
+
+func TestRpcBatchRequest(t *testing.T) {
+	r := newTestRouter(t)
+
+	ctx := doRequest(r, "POST", "/rpc-service", `[
+{"jsonrpc":"2.0","method":"echo","params":{"a":1},"id":"1"},
+{"jsonrpc":"2.0","method":"echo","params":{"a":2}},
+{"jsonrpc":"2.0","method":"fail","params":{},"id":"3"},
+{"jsonrpc":"2.0","method":"panic","params":{},"id":"4"},
+{"jsonrpc":"2.0","method":"missing","params":{},"id":"5"},
+{"jsonrpc":"2.0","method":"echo","params":{"a":6},"id":"6"}
+]`, nil)
+
+	var resp []rpc.RpcResponseInternal
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Len(t, resp, 5)
+
+	byId := map[string]rpc.RpcResponseInternal{}
+	for _, item := range resp {
+		byId[item.Id] = item
+	}
+
+	assert.JSONEq(t, `{"a":1}`, string(byId["1"].Result))
+	assert.Equal(t, error_codes.GenericValidationError, byId["3"].Error.Code)
+	assert.Equal(t, error_codes.GenericPanicError, byId["4"].Error.Code)
+	assert.Equal(t, error_codes.CommandNotFoundError, byId["5"].Error.Code)
+	assert.JSONEq(t, `{"a":6}`, string(byId["6"].Result))
+}
+
+func TestRpcBatchInvalid(t *testing.T) {
+	r := newTestRouter(t).WithRpcBatchLimits(2, 1)
+
+	for _, body := range []string{`[]`, `[{"method":"echo","id":"1"},{"method":"echo","id":"2"},{"method":"echo","id":"3"}]`} {
+		ctx := doRequest(r, "POST", "/rpc-service", body, nil)
+
+		var resp rpc.RpcResponseInternal
+		assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+		assert.Equal(t, error_codes.InvalidRpcRequestError, resp.Error.Code)
+	}
+
+	ctx := doRequest(r, "POST", "/rpc-service", `[{"jsonrpc":"2.0","method":"echo","params":{}}]`, nil)
+	assert.Len(t, ctx.Response.Body(), 0)
+}
+
+func TestRestBusinessCode(t *testing.T) {
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return nil, error_codes.NewErrorFromRegistered(errors.Wrap(error_codes.TokenomicsNotEnoughBalanceError, "tip"))
+	}, "/tip", MethodPost).Build()))
+
+	ctx := doRequest(r, "POST", "/tip", "{}", nil)
+
+	var resp genericRestResponse
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, int(error_codes.TokenomicsNotEnoughBalance), resp.Code)
+	assert.Equal(t, "tokenomics_not_enough_balance", resp.TranslationKey)
+	assert.Equal(t, int(error_codes.GenericValidationError), ctx.Response.StatusCode())
+}
+
+func TestCorrelationIdInContext(t *testing.T) {
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(NewServiceCommand("correlation",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			correlationId, _ := wrappers.CorrelationIdFromContext(executionData.Context)
+
+			return correlationId, nil
+		}, false)))
+
+	for expected, headers := range map[string]map[string]string{
+		"caller":  {common.CorrelationIdHeader: "caller", requestIdHeader: "gateway"},
+		"gateway": {requestIdHeader: "gateway"},
+	} {
+		ctx := doRequest(r, "POST", "/rpc-service", `{"jsonrpc":"2.0","method":"correlation","id":"1"}`, headers)
+
+		var resp rpc.RpcResponseInternal
+		assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+		assert.JSONEq(t, `"`+expected+`"`, string(resp.Result))
+	}
+}
+
+func TestRestBusinessCodeMessageFallback(t *testing.T) {
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.RegisterRestCmd(NewRestCommand(func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return nil, error_codes.NewErrorWithCodeRef(errors.New("max threshold without kyc exceeded"),
+			error_codes.GenericValidationError)
+	}, "/withdraw", MethodPost).Build()))
+
+	ctx := doRequest(r, "POST", "/withdraw", "{}", nil)
+
+	var resp genericRestResponse
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, error_codes.BusinessCodeMaxThresholdWithoutKycExceeded, resp.Code)
+	assert.Equal(t, "max_threshold_without_kyc_exceeded", resp.TranslationKey)
+}
@@ -0,0 +1,171 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultRpcBatchMaxSize     = 50
+	defaultRpcBatchConcurrency = 8
+)
+
+func isRpcBatchRequest(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+func (r *HttpRouter) executeRpcBatch(httpCtx *fasthttp.RequestCtx, requestBody []byte, endpoint IRpcEndpoint,
+	apmTxType string) {
+	httpCtx.Response.Header.SetContentType("application/json")
+
+	var rawRequests []json.RawMessage
+
+	if err := json.Unmarshal(requestBody, &rawRequests); err != nil {
+		r.writeRpcBatchError(httpCtx, error_codes.GenericMappingError, err)
+
+		return
+	}
+
+	if len(rawRequests) == 0 {
+		r.writeRpcBatchError(httpCtx, error_codes.InvalidRpcRequestError, errors.New("rpc batch is empty"))
+
+		return
+	}
+
+	if len(rawRequests) > r.rpcBatchMaxSize {
+		r.writeRpcBatchError(httpCtx, error_codes.InvalidRpcRequestError,
+			errors.New(fmt.Sprintf("rpc batch size [%v] exceeds limit [%v]", len(rawRequests), r.rpcBatchMaxSize)))
+
+		return
+	}
+
+	// fasthttp request headers are not safe for concurrent reads, so every call gets its own copy
+	callContexts := make([]*fasthttp.RequestCtx, len(rawRequests))
+
+	for i := range rawRequests {
+		callContexts[i] = cloneRequestCtxForBatch(httpCtx)
+	}
+
+	responseBodies := make([][]byte, len(rawRequests))
+	replayedIds := make([]string, len(rawRequests))
+	semaphore := make(chan struct{}, r.rpcBatchConcurrency)
+	wg := sync.WaitGroup{}
+
+	for i := range rawRequests {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(index int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			rpcResponse, responseBody, isNotification := r.executeRpcCall(callContexts[index], rawRequests[index],
+				endpoint, apmTxType)
+
+			if !isNotification {
+				responseBodies[index] = responseBody
+			}
+
+			if len(callContexts[index].Response.Header.Peek(IdempotentReplayedHeader)) > 0 {
+				replayedIds[index] = rpcResponse.Id
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	setBatchReplayedHeader(httpCtx, replayedIds)
+
+	var buf bytes.Buffer
+	written := 0
+
+	for _, body := range responseBodies {
+		if len(body) == 0 {
+			continue
+		}
+
+		if written == 0 {
+			buf.WriteByte('[')
+		} else {
+			buf.WriteByte(',')
+		}
+
+		buf.Write(body)
+		written += 1
+	}
+
+	if written == 0 { // batch of notifications only, spec says nothing should be returned
+		return
+	}
+
+	buf.WriteByte(']')
+
+	httpCtx.Response.SetBodyRaw(buf.Bytes())
+}
+
+// setBatchReplayedHeader lists ids of replayed calls in Idempotent-Replayed header of the batch response
+func setBatchReplayedHeader(httpCtx *fasthttp.RequestCtx, replayedIds []string) {
+	var ids []string
+
+	for _, id := range replayedIds {
+		if len(id) > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) > 0 {
+		httpCtx.Response.Header.Set(IdempotentReplayedHeader, strings.Join(ids, ","))
+	}
+}
+
+func (r *HttpRouter) writeRpcBatchError(httpCtx *fasthttp.RequestCtx, code error_codes.ErrorCode, err error) {
+	rpcResponse := rpc.RpcResponse{
+		JsonRpc:  "2.0",
+		Hostname: r.hostname,
+		Error: &rpc.ExtendedLocalRpcError{
+			RpcError: rpc.RpcError{
+				Code:     code,
+				Message:  err.Error(),
+				Hostname: r.hostname,
+			},
+			LocalHandlingError: err,
+		},
+	}
+
+	if !r.isProd {
+		rpcResponse.Error.Stack = fmt.Sprintf("%+v", err)
+	}
+
+	if body, err := json.Marshal(rpcResponse); err == nil {
+		httpCtx.Response.SetBodyRaw(body)
+	}
+}
+
+func cloneRequestCtxForBatch(httpCtx *fasthttp.RequestCtx) *fasthttp.RequestCtx {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	httpCtx.Request.Header.CopyTo(&req.Header)
+	// one header key would be shared by all calls of the batch, calls should use own idempotency_key
+	req.Header.Del(IdempotencyKeyHeader)
+
+	callCtx := &fasthttp.RequestCtx{}
+	callCtx.Init(req, httpCtx.RemoteAddr(), nil)
+
+	httpCtx.VisitUserValues(func(key []byte, value interface{}) {
+		callCtx.SetUserValueBytes(key, value)
+	})
+
+	return callCtx
+}
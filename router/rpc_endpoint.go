@@ -9,10 +9,21 @@ type IRpcEndpoint interface {
 	RegisterRpcCommand(command ICommand) error
 	GetCommand(methodName string) (ICommand, error)
 	GetRegisteredCommands() []ICommand
+	Use(middlewares ...Middleware)
+	GetMiddlewares() []Middleware
 }
 
 type rpcEndpointPublic struct {
-	executor *CommandExecutor
+	executor    *CommandExecutor
+	middlewares []Middleware
+}
+
+func (r *rpcEndpointPublic) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+func (r rpcEndpointPublic) GetMiddlewares() []Middleware {
+	return r.middlewares
 }
 
 func newRpcEndpointPublic() *rpcEndpointPublic {
@@ -44,7 +55,16 @@ func (r rpcEndpointPublic) GetCommand(methodName string) (ICommand, error) {
 }
 
 type rpcEndpointAdmin struct {
-	executor *CommandExecutor
+	executor    *CommandExecutor
+	middlewares []Middleware
+}
+
+func (r *rpcEndpointAdmin) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+func (r rpcEndpointAdmin) GetMiddlewares() []Middleware {
+	return r.middlewares
 }
 
 func newRpcEndpointAdmin() *rpcEndpointAdmin {
@@ -74,7 +94,16 @@ func (r rpcEndpointAdmin) GetCommand(methodName string) (ICommand, error) {
 }
 
 type rpcEndpointService struct {
-	executor *CommandExecutor
+	executor    *CommandExecutor
+	middlewares []Middleware
+}
+
+func (r *rpcEndpointService) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+func (r rpcEndpointService) GetMiddlewares() []Middleware {
+	return r.middlewares
 }
 
 func newRpcEndpointService() *rpcEndpointService {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/digitalmonsters/go-common/swagger"
 	"github.com/digitalmonsters/go-common/translation"
 	"github.com/digitalmonsters/go-common/wrappers/auth_go"
 	"github.com/valyala/fasthttp"
@@ -18,6 +19,8 @@ type ServiceCommand struct {
 	requireIdentityValidation bool
 	allowBanned               bool
 	obj                       string
+	apiDescription            *swagger.ApiDescription
+	commandOptions
 }
 
 func NewServiceCommand(methodName string, fn CommandFunc, forceLog bool) ICommand {
@@ -64,6 +67,10 @@ func (a ServiceCommand) GetFn() CommandFunc {
 	return a.fn
 }
 
+func (a ServiceCommand) getApiDescription() *swagger.ApiDescription {
+	return a.apiDescription
+}
+
 func (a ServiceCommand) GetPath() string {
 	return a.GetMethodName()
 }
@@ -0,0 +1,175 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"sync/atomic"
+	"time"
+)
+
+type ReadinessProbe interface {
+	NotReady()
+}
+
+type inFlightTracker struct {
+	counters map[EndpointType]*int64
+}
+
+func newInFlightTracker() *inFlightTracker {
+	t := &inFlightTracker{
+		counters: map[EndpointType]*int64{},
+	}
+
+	// keys are fixed here, so map is only read concurrently
+	for _, e := range []EndpointType{EndpointRest, EndpointRpcPublic, EndpointRpcAdmin, EndpointRpcAdminLegacy,
+		EndpointRpcService} {
+		t.counters[e] = new(int64)
+	}
+
+	return t
+}
+
+func (t *inFlightTracker) start(endpoint EndpointType) func() {
+	counter, ok := t.counters[endpoint]
+
+	if !ok {
+		return func() {}
+	}
+
+	atomic.AddInt64(counter, 1)
+
+	return func() {
+		atomic.AddInt64(counter, -1)
+	}
+}
+
+func (t *inFlightTracker) snapshot() (map[EndpointType]int64, int64) {
+	result := map[EndpointType]int64{}
+	var total int64
+
+	for e, counter := range t.counters {
+		v := atomic.LoadInt64(counter)
+		result[e] = v
+		total += v
+	}
+
+	return result, total
+}
+
+// WithReadinessProbe is marked as not ready at the start of Shutdown, so balancer stops sending new traffic
+func (r *HttpRouter) WithReadinessProbe(probe ReadinessProbe) *HttpRouter {
+	r.readinessProbe = probe
+
+	return r
+}
+
+// WithShutdownDrainDelay keeps serving requests for delay after readiness probe is marked as not ready,
+// so balancers have time to notice it before the listener is closed
+func (r *HttpRouter) WithShutdownDrainDelay(delay time.Duration) *HttpRouter {
+	r.shutdownDrainDelay = delay
+
+	return r
+}
+
+func (r *HttpRouter) GetInFlightRequests() map[EndpointType]int64 {
+	result, _ := r.inFlight.snapshot()
+
+	return result
+}
+
+// Shutdown marks readiness probe as not ready, waits for drain delay, stops accepting connections and waits
+// for in-flight commands until ctx is done.
+// Returns error with amount of still running commands if ctx expires first.
+func (r *HttpRouter) Shutdown(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&r.shuttingDown, 0, 1) {
+		return nil
+	}
+
+	if r.readinessProbe != nil {
+		r.readinessProbe.NotReady()
+	}
+
+	if r.shutdownDrainDelay > 0 {
+		log.Info().Msgf("Http Server waits [%v] before shutdown", r.shutdownDrainDelay)
+
+		select {
+		case <-time.After(r.shutdownDrainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	byEndpoint, total := r.inFlight.snapshot()
+
+	log.Info().Msgf("Http Server shutting down. In-flight requests [%v] %v. Closed streams [%v]", total, byEndpoint,
+		r.streams.closeAll())
+
+	if r.srv == nil {
+		return nil
+	}
+
+	srvDone := make(chan error, 1)
+
+	go func() {
+		srvDone <- r.srv.Shutdown()
+	}()
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	var srvErr error
+	srvClosed := false
+
+	for {
+		if srvClosed {
+			if _, total = r.inFlight.snapshot(); total == 0 {
+				return srvErr
+			}
+		}
+
+		select {
+		case srvErr = <-srvDone:
+			srvClosed = true
+		case <-ticker.C:
+		case <-ctx.Done():
+			byEndpoint, total = r.inFlight.snapshot()
+
+			return errors.New(fmt.Sprintf("http server shutdown interrupted with [%v] in-flight requests %v",
+				total, byEndpoint))
+		}
+	}
+}
+
+type HttpRouterSubApplication struct {
+	router          *HttpRouter
+	port            int
+	shutdownTimeout time.Duration
+}
+
+// NewHttpRouterSubApplication allows to register router in application.BaseApplication
+func NewHttpRouterSubApplication(router *HttpRouter, port int, shutdownTimeout time.Duration) *HttpRouterSubApplication {
+	return &HttpRouterSubApplication{
+		router:          router,
+		port:            port,
+		shutdownTimeout: shutdownTimeout,
+	}
+}
+
+func (s *HttpRouterSubApplication) Init(subAppLogger zerolog.Logger) error {
+	s.router.StartAsync(s.port)
+
+	return nil
+}
+
+func (s *HttpRouterSubApplication) Name() string {
+	return "http_router"
+}
+
+func (s *HttpRouterSubApplication) Close() error {
+	ctx, cancelFn := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancelFn()
+
+	return s.router.Shutdown(ctx)
+}
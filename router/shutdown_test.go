@@ -0,0 +1,169 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testReadinessProbe struct {
+	notReady int32
+}
+
+func (p *testReadinessProbe) NotReady() {
+	atomic.StoreInt32(&p.notReady, 1)
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	defer func() {
+		_ = l.Close()
+	}()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestShutdownWaitsForInFlight(t *testing.T) {
+	release := make(chan struct{})
+	probe := &testReadinessProbe{}
+
+	r := NewRouter("", nil).WithReadinessProbe(probe)
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(NewServiceCommand("slow",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			<-release
+
+			return "done", nil
+		}, false)))
+
+	port := freePort(t)
+	r.StartAsync(port)
+
+	time.Sleep(100 * time.Millisecond)
+
+	respCh := make(chan int, 1)
+
+	go func() {
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+
+		req.SetRequestURI(fmt.Sprintf("http://127.0.0.1:%v/rpc-service", port))
+		req.Header.SetMethod("POST")
+		req.SetBodyString(`{"method":"slow","id":"1"}`)
+
+		if err := fasthttp.Do(req, resp); err != nil {
+			respCh <- 0
+			return
+		}
+
+		respCh <- resp.StatusCode()
+	}()
+
+	assert.Eventually(t, func() bool {
+		return r.GetInFlightRequests()[EndpointRpcService] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	shutdownErr := make(chan error, 1)
+
+	go func() {
+		ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelFn()
+
+		shutdownErr <- r.Shutdown(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&probe.notReady) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+
+	assert.Nil(t, <-shutdownErr)
+	assert.Equal(t, 200, <-respCh)
+	assert.Equal(t, int64(0), r.GetInFlightRequests()[EndpointRpcService])
+}
+
+func TestShutdownReportsInFlightOnTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(NewServiceCommand("stuck",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			<-release
+
+			return nil, nil
+		}, false)))
+
+	go doRequest(r, "POST", "/rpc-service", `{"method":"stuck","id":"1"}`, nil)
+
+	assert.Eventually(t, func() bool {
+		return r.GetInFlightRequests()[EndpointRpcService] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	r.srv = &fasthttp.Server{Handler: r.Handler()}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelFn()
+
+	err := r.Shutdown(ctx)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "[1] in-flight")
+}
+
+func TestShutdownServesDuringDrainDelay(t *testing.T) {
+	probe := &testReadinessProbe{}
+
+	r := NewRouter("", nil).WithReadinessProbe(probe).
+		WithShutdownDrainDelay(500 * time.Millisecond)
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(NewServiceCommand("fast",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			return "done", nil
+		}, false)))
+
+	port := freePort(t)
+	r.StartAsync(port)
+
+	time.Sleep(100 * time.Millisecond)
+
+	shutdownErr := make(chan error, 1)
+
+	go func() {
+		ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelFn()
+
+		shutdownErr <- r.Shutdown(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&probe.notReady) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+
+	req.SetRequestURI(fmt.Sprintf("http://127.0.0.1:%v/rpc-service", port))
+	req.Header.SetMethod("POST")
+	req.SetBodyString(`{"method":"fast","id":"1"}`)
+
+	assert.Nil(t, fasthttp.Do(req, resp))
+	assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+
+	select {
+	case err := <-shutdownErr:
+		assert.Nil(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("shutdown did not finish")
+	}
+}
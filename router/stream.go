@@ -0,0 +1,440 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+	"go.elastic.co/apm"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	streamConnections = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "router_stream_connections",
+		Help: "Number of open stream connections",
+	}, []string{"path", "transport"})
+	streamMessagesSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "router_stream_messages_sent_total",
+		Help: "Number of messages sent to stream clients",
+	}, []string{"path", "transport"})
+	streamMessagesDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "router_stream_messages_dropped_total",
+		Help: "Number of hub messages dropped because client buffer was full",
+	}, []string{"path", "transport"})
+)
+
+type streamMetrics struct {
+	connections prometheus.Gauge
+	sent        prometheus.Counter
+	dropped     prometheus.Counter
+}
+
+type streamRegistry struct {
+	mutex   sync.Mutex
+	clients map[*streamClient]struct{}
+}
+
+func (s *streamRegistry) add(client *streamClient) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.clients[client] = struct{}{}
+}
+
+func (s *streamRegistry) remove(client *streamClient) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.clients, client)
+}
+
+func (s *streamRegistry) closeAll() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for client := range s.clients {
+		client.cancelFn()
+	}
+
+	return len(s.clients)
+}
+
+type streamErrorMessage struct {
+	Error streamError `json:"error"`
+}
+
+type streamError struct {
+	Code    error_codes.ErrorCode `json:"code"`
+	Message string                `json:"message"`
+}
+
+func (r *HttpRouter) RegisterStreamCmd(cmd *StreamCommand) error {
+	r.endpointRegistratorMutex.Lock()
+	defer r.endpointRegistratorMutex.Unlock()
+
+	if _, ok := r.streamCommands[cmd.path]; ok {
+		return errors.New(fmt.Sprintf("stream command [%v] already registered", cmd.path))
+	}
+
+	r.streamCommands[cmd.path] = cmd
+
+	func() {
+		defer func() {
+			_ = recover() // options handler can be already registered by rest command
+		}()
+
+		r.realRouter.OPTIONS(cmd.path, func(ctx *fasthttp.RequestCtx) {
+			r.handlePreflight(ctx, EndpointRest)
+		})
+	}()
+
+	r.realRouter.GET(cmd.path, func(ctx *fasthttp.RequestCtx) {
+		r.handleStream(ctx, cmd)
+	})
+
+	return nil
+}
+
+func (r *HttpRouter) handleStream(httpCtx *fasthttp.RequestCtx, cmd *StreamCommand) {
+	r.setCors(httpCtx, EndpointRest)
+
+	apmTransaction := apm_helper.StartNewApmTransaction(fmt.Sprintf("[STREAM] [%v]", cmd.path), "stream", nil, nil)
+
+	// request ctx can not be used after handler returns, so stream is not bound to it
+	executionCtx := boilerplate.CreateCustomContext(context.Background(), apmTransaction, log.Logger)
+
+	if cmd.transport == StreamTransportWebSocket && !isWebSocketUpgrade(httpCtx) {
+		r.writeStreamError(httpCtx, executionCtx, apmTransaction, error_codes.NewErrorWithCodeRef(
+			errors.New("websocket upgrade required"), error_codes.GenericValidationError))
+
+		return
+	}
+
+	if cmd.transport == StreamTransportWebSocket {
+		if err := r.validateWebSocketHandshake(httpCtx); err != nil {
+			r.writeStreamError(httpCtx, executionCtx, apmTransaction, err)
+
+			if err.GetCode() == error_codes.GenericValidationError {
+				httpCtx.Response.Header.Set("Sec-WebSocket-Version", websocketVersion)
+				httpCtx.Response.SetStatusCode(fasthttp.StatusUpgradeRequired)
+			}
+
+			return
+		}
+	}
+
+	executionData, err := r.authorizeStream(httpCtx, executionCtx, cmd)
+
+	if err != nil {
+		r.writeStreamError(httpCtx, executionCtx, apmTransaction, err)
+
+		return
+	}
+
+	streamCtx, cancelFn := context.WithCancel(executionCtx)
+	executionData.Context = streamCtx
+
+	labels := prometheus.Labels{"path": cmd.path, "transport": string(cmd.transport)}
+	client := &streamClient{
+		out:    make(chan interface{}, cmd.bufferSize),
+		userId: executionData.UserId,
+		metrics: streamMetrics{
+			connections: streamConnections.With(labels),
+			sent:        streamMessagesSent.With(labels),
+			dropped:     streamMessagesDropped.With(labels),
+		},
+		cancelFn: cancelFn,
+	}
+
+	if cmd.transport == StreamTransportWebSocket {
+		httpCtx.Response.SetStatusCode(fasthttp.StatusSwitchingProtocols)
+		httpCtx.Response.Header.Set(fasthttp.HeaderUpgrade, "websocket")
+		httpCtx.Response.Header.Set(fasthttp.HeaderConnection, "Upgrade")
+		httpCtx.Response.Header.Set("Sec-WebSocket-Accept",
+			websocketAcceptKey(string(httpCtx.Request.Header.Peek("Sec-WebSocket-Key"))))
+
+		httpCtx.Hijack(func(conn net.Conn) {
+			r.runWebSocket(conn, cmd, client, executionData)
+		})
+
+		return
+	}
+
+	httpCtx.Response.Header.SetContentType("text/event-stream")
+	httpCtx.Response.Header.Set(fasthttp.HeaderCacheControl, "no-cache")
+	httpCtx.Response.Header.Set(fasthttp.HeaderContentEncoding, "identity") // disables buffering compression
+	httpCtx.Response.Header.Set("X-Accel-Buffering", "no")
+
+	httpCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		r.runSse(w, cmd, client, executionData)
+	})
+}
+
+// validateWebSocketHandshake browsers do not apply cors to websockets, so origin is checked by the server
+func (r *HttpRouter) validateWebSocketHandshake(httpCtx *fasthttp.RequestCtx) *error_codes.ErrorWithCode {
+	if version := string(httpCtx.Request.Header.Peek("Sec-WebSocket-Version")); version != websocketVersion {
+		return error_codes.NewErrorWithCodeRef(errors.New(fmt.Sprintf("unsupported websocket version [%v]", version)),
+			error_codes.GenericValidationError)
+	}
+
+	origin := string(httpCtx.Request.Header.Peek(fasthttp.HeaderOrigin))
+
+	if len(origin) > 0 && !r.getCorsPolicy(EndpointRest).isOriginAllowed(origin) {
+		return error_codes.NewErrorWithCodeRef(errors.New(fmt.Sprintf("websocket origin [%v] is not allowed", origin)),
+			error_codes.Forbidden)
+	}
+
+	return nil
+}
+
+func (r *HttpRouter) authorizeStream(httpCtx *fasthttp.RequestCtx, ctx context.Context,
+	cmd *StreamCommand) (MethodExecutionData, *error_codes.ErrorWithCode) {
+	values := map[string]interface{}{}
+
+	httpCtx.VisitUserValues(func(key []byte, v interface{}) {
+		values[string(key)] = v
+	})
+
+	httpCtx.QueryArgs().VisitAll(func(key, value []byte) {
+		if _, ok := values[string(key)]; !ok {
+			values[string(key)] = string(value)
+		}
+	})
+
+	ctx = withCorrelationId(httpCtx, ctx)
+
+	executionData := MethodExecutionData{
+		ApmTransaction: apm.TransactionFromContext(ctx),
+		Context:        ctx,
+		UserIp:         common.GetRealIp(httpCtx),
+		FullUrl:        httpCtx.URI().String(),
+		Language:       translation.DefaultUserLanguage,
+		DeviceId:       string(httpCtx.Request.Header.Peek("device-id")),
+		httpCtx:        httpCtx,
+		command:        cmd,
+		endpointType:   EndpointRest,
+		getUserValueFn: func(key string) interface{} {
+			return values[key]
+		},
+	}
+
+	// authorization, rate limits, toggles, audit and custom middlewares run the same way as for rest commands
+	authorized := executionData
+
+	_, err := r.buildMiddlewareChain(cmd, r.restMiddlewares,
+		func(request []byte, data MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			authorized = data
+
+			return nil, nil
+		})(nil, executionData)
+
+	if err != nil {
+		return executionData, err
+	}
+
+	// contexts of middlewares (timeouts, request caches) are request scoped, stream lives longer.
+	// Request ctx can not be used after handler returns.
+	authorized.Context = wrappers.WithUserId(ctx, authorized.UserId)
+	authorized.httpCtx = nil
+
+	return authorized, nil
+}
+
+func (r *HttpRouter) writeStreamError(httpCtx *fasthttp.RequestCtx, ctx context.Context, apmTransaction *apm.Transaction,
+	err *error_codes.ErrorWithCode) {
+	defer apmTransaction.End()
+
+	apm_helper.LogError(err.GetError(), ctx)
+
+	r.writeRestError(httpCtx, err)
+}
+
+type streamWriter struct {
+	message   func(payload []byte) error
+	heartbeat func() error
+}
+
+// runStream pumps client.out to the writer until client disconnects, router shuts down or handler finishes
+func (r *HttpRouter) runStream(cmd *StreamCommand, client *streamClient, executionData MethodExecutionData,
+	incoming <-chan []byte, writer streamWriter) {
+	ctx := executionData.Context
+
+	client.metrics.connections.Inc()
+	r.streams.add(client)
+
+	if cmd.hub != nil {
+		cmd.hub.subscribe(client)
+	}
+
+	defer func() {
+		if cmd.hub != nil {
+			cmd.hub.unsubscribe(client)
+		}
+
+		r.streams.remove(client)
+		client.cancelFn()
+		client.metrics.connections.Dec()
+
+		if executionData.ApmTransaction != nil {
+			executionData.ApmTransaction.End()
+		}
+	}()
+
+	handlerDone := make(chan *error_codes.ErrorWithCode, 1)
+
+	if cmd.run != nil {
+		go func() {
+			defer func() {
+				if rec := recover(); rec != nil {
+					handlerDone <- error_codes.NewErrorWithCodeRef(r.recoveredToError(rec), error_codes.GenericPanicError)
+				}
+			}()
+
+			handlerDone <- cmd.run(executionData, incoming, client.out)
+		}()
+	}
+
+	heartbeat := cmd.heartbeat
+
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	write := func(msg interface{}) bool {
+		payload, err := json.Marshal(msg)
+
+		if err != nil {
+			apm_helper.LogError(errors.WithStack(err), ctx)
+
+			return true
+		}
+
+		if err = writer.message(payload); err != nil {
+			return false
+		}
+
+		client.metrics.sent.Inc()
+
+		return true
+	}
+
+	for {
+		select {
+		case msg := <-client.out:
+			if !write(msg) {
+				return
+			}
+		case <-ticker.C:
+			if err := writer.heartbeat(); err != nil {
+				return
+			}
+		case err := <-handlerDone:
+			for len(client.out) > 0 {
+				if !write(<-client.out) {
+					return
+				}
+			}
+
+			if err != nil {
+				apm_helper.LogError(err.GetError(), ctx)
+
+				write(streamErrorMessage{Error: streamError{Code: err.GetCode(), Message: err.GetMessage()}})
+
+				return
+			}
+
+			if cmd.hub == nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *HttpRouter) runSse(w *bufio.Writer, cmd *StreamCommand, client *streamClient, executionData MethodExecutionData) {
+	r.runStream(cmd, client, executionData, nil, streamWriter{
+		message: func(payload []byte) error {
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", payload); err != nil {
+				return err
+			}
+
+			return w.Flush()
+		},
+		heartbeat: func() error {
+			if _, err := w.WriteString(": ping\n\n"); err != nil {
+				return err
+			}
+
+			return w.Flush()
+		},
+	})
+}
+
+func (r *HttpRouter) runWebSocket(conn net.Conn, cmd *StreamCommand, client *streamClient,
+	executionData MethodExecutionData) {
+	var writeMutex sync.Mutex
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+
+	writeFrame := func(opcode byte, payload []byte) error {
+		writeMutex.Lock()
+		defer writeMutex.Unlock()
+
+		return writeWebSocketFrame(writer, opcode, payload)
+	}
+
+	incoming := make(chan []byte, cmd.bufferSize)
+
+	go func() {
+		defer func() {
+			close(incoming)
+			client.cancelFn()
+		}()
+
+		for {
+			message, err := readWebSocketMessage(reader, func(payload []byte) error {
+				return writeFrame(wsOpPong, payload)
+			})
+
+			if err != nil {
+				return
+			}
+
+			select {
+			case incoming <- message:
+			case <-executionData.Context.Done():
+				return
+			}
+		}
+	}()
+
+	r.runStream(cmd, client, executionData, incoming, streamWriter{
+		message: func(payload []byte) error {
+			return writeFrame(wsOpText, payload)
+		},
+		heartbeat: func() error {
+			return writeFrame(wsOpPing, nil)
+		},
+	})
+
+	_ = writeFrame(wsOpClose, nil)
+}
@@ -0,0 +1,214 @@
+package router
+
+import (
+	"context"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"time"
+)
+
+type StreamTransport string
+
+const (
+	StreamTransportSse       = StreamTransport("sse")
+	StreamTransportWebSocket = StreamTransport("websocket")
+)
+
+const (
+	defaultStreamHeartbeat  = 15 * time.Second
+	defaultStreamBufferSize = 64
+)
+
+// StreamFunc runs while client is connected. Messages written to send are serialized to json and pushed to the client,
+// send blocks when client buffer is full. incoming receives client messages (websocket only, nil for sse).
+// executionData.Context is cancelled when client disconnects.
+type StreamFunc[T any] func(executionData MethodExecutionData, incoming <-chan []byte, send chan<- T) *error_codes.ErrorWithCode
+
+type streamRunFunc func(executionData MethodExecutionData, incoming <-chan []byte, out chan<- interface{}) *error_codes.ErrorWithCode
+
+type StreamCommand struct {
+	path                      string
+	transport                 StreamTransport
+	forceLog                  bool
+	accessLevel               common.AccessLevel
+	requireIdentityValidation bool
+	allowBanned               bool
+	isAdmin                   bool
+	obj                       string
+	heartbeat                 time.Duration
+	bufferSize                int
+	hub                       *StreamHub
+	run                       streamRunFunc
+}
+
+type StreamCommandBuilder struct {
+	cmd StreamCommand
+}
+
+// NewStreamCommand creates server-sent events command served by GET on path
+func NewStreamCommand[T any](path string, fn StreamFunc[T]) StreamCommandBuilder {
+	return newStreamCommandBuilder(path, StreamTransportSse, fn)
+}
+
+func NewWebSocketCommand[T any](path string, fn StreamFunc[T]) StreamCommandBuilder {
+	return newStreamCommandBuilder(path, StreamTransportWebSocket, fn)
+}
+
+func newStreamCommandBuilder[T any](path string, transport StreamTransport, fn StreamFunc[T]) StreamCommandBuilder {
+	return StreamCommandBuilder{cmd: StreamCommand{
+		path:        path,
+		transport:   transport,
+		accessLevel: common.AccessLevelPublic,
+		heartbeat:   defaultStreamHeartbeat,
+		bufferSize:  defaultStreamBufferSize,
+		run:         wrapStreamFn(fn),
+	}}
+}
+
+func wrapStreamFn[T any](fn StreamFunc[T]) streamRunFunc {
+	if fn == nil {
+		return nil
+	}
+
+	return func(executionData MethodExecutionData, incoming <-chan []byte, out chan<- interface{}) *error_codes.ErrorWithCode {
+		send := make(chan T)
+		done := make(chan struct{})
+
+		defer close(done)
+
+		go func() {
+			for {
+				select {
+				case v, ok := <-send:
+					if !ok {
+						return
+					}
+
+					select {
+					case out <- v:
+					case <-executionData.Context.Done():
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		return fn(executionData, incoming, send)
+	}
+}
+
+func (b StreamCommandBuilder) ForceLog() StreamCommandBuilder {
+	b.cmd.forceLog = true
+
+	return b
+}
+
+func (b StreamCommandBuilder) AllowBanned() StreamCommandBuilder {
+	b.cmd.allowBanned = true
+
+	return b
+}
+
+func (b StreamCommandBuilder) RequireIdentityValidation() StreamCommandBuilder {
+	b.cmd.requireIdentityValidation = true
+
+	return b
+}
+
+// Admin switches auth to the same logic as AdminCommand
+func (b StreamCommandBuilder) Admin(accessLevel common.AccessLevel, rbacObj string) StreamCommandBuilder {
+	b.cmd.isAdmin = true
+	b.cmd.accessLevel = accessLevel
+	b.cmd.obj = rbacObj
+	b.cmd.requireIdentityValidation = true
+
+	return b
+}
+
+func (b StreamCommandBuilder) WithHeartbeat(heartbeat time.Duration) StreamCommandBuilder {
+	b.cmd.heartbeat = heartbeat
+
+	return b
+}
+
+// WithBufferSize sets amount of messages queued per connection before send blocks and hub messages are dropped,
+// size <= 0 means default size
+func (b StreamCommandBuilder) WithBufferSize(size int) StreamCommandBuilder {
+	if size <= 0 {
+		size = defaultStreamBufferSize
+	}
+
+	b.cmd.bufferSize = size
+
+	return b
+}
+
+// WithHub subscribes every connection to hub broadcasts
+func (b StreamCommandBuilder) WithHub(hub *StreamHub) StreamCommandBuilder {
+	b.cmd.hub = hub
+
+	return b
+}
+
+func (b StreamCommandBuilder) Build() *StreamCommand {
+	return &b.cmd
+}
+
+func (s StreamCommand) CanExecute(httpCtx *fasthttp.RequestCtx, ctx context.Context, auth auth_go.IAuthGoWrapper,
+	userValidator UserExecutorValidator) (int64, bool, bool, translation.Language, *rpc.ExtendedLocalRpcError) {
+	if s.isAdmin {
+		return AdminCommand{accessLevel: s.accessLevel, obj: s.obj}.CanExecute(httpCtx, ctx, auth, userValidator)
+	}
+
+	return publicCanExecuteLogic(httpCtx, s.requireIdentityValidation, s.allowBanned, userValidator)
+}
+
+func (s StreamCommand) RequireIdentityValidation() bool {
+	return s.requireIdentityValidation
+}
+
+func (s StreamCommand) AllowBanned() bool {
+	return s.allowBanned
+}
+
+func (s StreamCommand) AccessLevel() common.AccessLevel {
+	return s.accessLevel
+}
+
+func (s StreamCommand) GetMethodName() string {
+	return s.path
+}
+
+func (s StreamCommand) GetFn() CommandFunc {
+	return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return nil, error_codes.NewErrorWithCodeRef(errors.New("stream command can not be executed as request"),
+			error_codes.GenericServerError)
+	}
+}
+
+func (s StreamCommand) ForceLog() bool {
+	return s.forceLog
+}
+
+func (s StreamCommand) GetPath() string {
+	return s.path
+}
+
+func (s StreamCommand) GetHttpMethod() string {
+	return string(MethodGet)
+}
+
+func (s StreamCommand) GetObj() string {
+	return s.obj
+}
+
+func (s StreamCommand) GetTransport() StreamTransport {
+	return s.transport
+}
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"context"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/kafka_listener"
+	"github.com/segmentio/kafka-go"
+	"sync"
+)
+
+type streamClient struct {
+	out      chan interface{}
+	userId   int64
+	metrics  streamMetrics
+	cancelFn context.CancelFunc
+}
+
+// StreamHub fans out events to every connection of stream commands registered with WithHub
+type StreamHub struct {
+	mutex   sync.RWMutex
+	clients map[*streamClient]struct{}
+}
+
+func NewStreamHub() *StreamHub {
+	return &StreamHub{
+		clients: map[*streamClient]struct{}{},
+	}
+}
+
+func (h *StreamHub) subscribe(client *streamClient) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.clients[client] = struct{}{}
+}
+
+func (h *StreamHub) unsubscribe(client *streamClient) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.clients, client)
+}
+
+func (h *StreamHub) ConnectionsCount() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return len(h.clients)
+}
+
+// Publish sends payload to all clients, or only to connections of userIds when they are set.
+// Never blocks: message is dropped for clients with full buffer.
+func (h *StreamHub) Publish(payload interface{}, userIds ...int64) {
+	var targets map[int64]bool
+
+	if len(userIds) > 0 {
+		targets = map[int64]bool{}
+
+		for _, id := range userIds {
+			targets[id] = true
+		}
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for client := range h.clients {
+		if targets != nil && !targets[client.userId] {
+			continue
+		}
+
+		select {
+		case client.out <- payload:
+		default:
+			client.metrics.dropped.Inc()
+		}
+	}
+}
+
+// StreamHubMapFn converts kafka message to payload and target users. Empty userIds means all clients.
+type StreamHubMapFn func(message kafka.Message) (payload interface{}, userIds []int64, err error)
+
+// NewKafkaCommand returns kafka_listener command which publishes every message to the hub
+func (h *StreamHub) NewKafkaCommand(fancyName string, mapFn StreamHubMapFn) kafka_listener.ICommand {
+	return kafka_listener.NewCommand(fancyName, func(executionData kafka_listener.ExecutionData,
+		request ...kafka.Message) []kafka.Message {
+		for _, message := range request {
+			payload, userIds, err := mapFn(message)
+
+			if err != nil {
+				apm_helper.LogError(err, executionData.Context)
+
+				continue
+			}
+
+			h.Publish(payload, userIds...)
+		}
+
+		return request
+	}, false)
+}
+
+// ListenKafka starts listener publishing topic messages to the hub. Every pod should use own configuration.GroupId,
+// otherwise clients connected to other pods will miss events.
+func (h *StreamHub) ListenKafka(configuration boilerplate.KafkaListenerConfiguration, mapFn StreamHubMapFn,
+	ctx context.Context) kafka_listener.IKafkaListener {
+	return kafka_listener.NewSingleListener(configuration, h.NewKafkaCommand(configuration.Topic, mapFn), ctx).
+		ListenAsync()
+}
@@ -0,0 +1,241 @@
+package router
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type streamTestEvent struct {
+	Value int `json:"value"`
+}
+
+func startStreamTestRouter(t *testing.T, commands ...*StreamCommand) (*HttpRouter, int) {
+	r := NewRouter("", nil)
+
+	for _, cmd := range commands {
+		assert.Nil(t, r.RegisterStreamCmd(cmd))
+	}
+
+	port := freePort(t)
+	r.StartAsync(port)
+
+	time.Sleep(100 * time.Millisecond)
+
+	return r, port
+}
+
+func TestSseStream(t *testing.T) {
+	cmd := NewStreamCommand("/events", func(executionData MethodExecutionData, incoming <-chan []byte,
+		send chan<- streamTestEvent) *error_codes.ErrorWithCode {
+		for i := 1; i <= 3; i++ {
+			send <- streamTestEvent{Value: i}
+		}
+
+		return nil
+	}).Build()
+
+	_, port := startStreamTestRouter(t, cmd)
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+
+	req.SetRequestURI(fmt.Sprintf("http://127.0.0.1:%v/events", port))
+	req.Header.Set(fasthttp.HeaderAcceptEncoding, "gzip, br")
+
+	assert.Nil(t, fasthttp.DoTimeout(req, resp, 5*time.Second))
+	assert.Equal(t, "text/event-stream", string(resp.Header.ContentType()))
+	assert.Equal(t, "data: {\"value\":1}\n\ndata: {\"value\":2}\n\ndata: {\"value\":3}\n\n", string(resp.Body()))
+}
+
+func TestStreamAuthError(t *testing.T) {
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.RegisterStreamCmd(NewStreamCommand[streamTestEvent]("/private", nil).
+		RequireIdentityValidation().Build()))
+
+	ctx := doRequest(r, "GET", "/private", "", nil)
+
+	assert.Equal(t, 401, ctx.Response.StatusCode())
+	assert.Contains(t, string(ctx.Response.Body()), "requires identity validation")
+}
+
+func TestStreamRunsRouterMiddlewares(t *testing.T) {
+	r := NewRouter("", nil)
+
+	r.Use(func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			return nil, error_codes.NewErrorWithCodeRef(errors.New("rejected by middleware"), error_codes.Forbidden)
+		}
+	})
+
+	assert.Nil(t, r.RegisterStreamCmd(NewStreamCommand[streamTestEvent]("/events", nil).Build()))
+
+	ctx := doRequest(r, "GET", "/events", "", nil)
+
+	assert.Equal(t, 403, ctx.Response.StatusCode())
+	assert.Contains(t, string(ctx.Response.Body()), "rejected by middleware")
+}
+
+func TestWebSocketHandshakeValidation(t *testing.T) {
+	r := NewRouter("", nil).WithCorsPolicy(CorsPolicy{CorsOrigins: CorsOrigins{Origins: []string{"https://app.example.com"}}})
+
+	assert.Nil(t, r.RegisterStreamCmd(NewWebSocketCommand[string]("/ws", nil).Build()))
+
+	headers := map[string]string{
+		fasthttp.HeaderConnection: "Upgrade",
+		fasthttp.HeaderUpgrade:    "websocket",
+		"Sec-WebSocket-Key":       "dGhlIHNhbXBsZSBub25jZQ==",
+		"Sec-WebSocket-Version":   "8",
+	}
+
+	ctx := doRequest(r, "GET", "/ws", "", headers)
+
+	assert.Equal(t, fasthttp.StatusUpgradeRequired, ctx.Response.StatusCode())
+	assert.Equal(t, "13", string(ctx.Response.Header.Peek("Sec-WebSocket-Version")))
+
+	headers["Sec-WebSocket-Version"] = "13"
+	headers[fasthttp.HeaderOrigin] = "https://evil.example.com"
+
+	ctx = doRequest(r, "GET", "/ws", "", headers)
+
+	assert.Equal(t, 403, ctx.Response.StatusCode())
+}
+
+func TestStreamNegativeBufferSize(t *testing.T) {
+	cmd := NewStreamCommand[streamTestEvent]("/events", nil).WithBufferSize(-1).Build()
+
+	assert.Equal(t, defaultStreamBufferSize, cmd.bufferSize)
+}
+
+func TestWebSocketEchoAndHub(t *testing.T) {
+	hub := NewStreamHub()
+
+	cmd := NewWebSocketCommand("/ws", func(executionData MethodExecutionData, incoming <-chan []byte,
+		send chan<- string) *error_codes.ErrorWithCode {
+		for message := range incoming {
+			send <- "echo:" + string(message)
+		}
+
+		return nil
+	}).WithHub(hub).Build()
+
+	r, port := startStreamTestRouter(t, cmd)
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%v", port))
+	assert.Nil(t, err)
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	_, err = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: localhost\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"))
+	assert.Nil(t, err)
+
+	reader := bufio.NewReader(conn)
+
+	var headers []string
+
+	for {
+		line, err := reader.ReadString('\n')
+		assert.Nil(t, err)
+
+		if line == "\r\n" {
+			break
+		}
+
+		headers = append(headers, strings.TrimSpace(line))
+	}
+
+	assert.Equal(t, "HTTP/1.1 101 Switching Protocols", headers[0])
+	assert.Contains(t, headers, "Sec-Websocket-Accept: s3pPLMBiTxaQ9kYGzzhZRbK+xOo=")
+
+	writeMaskedTestFrame(t, conn, wsOpText, []byte("hello"))
+	assert.Equal(t, `"echo:hello"`, readTestFrame(t, reader))
+
+	assert.Eventually(t, func() bool {
+		return hub.ConnectionsCount() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	hub.Publish(streamTestEvent{Value: 7}, 100500)
+	hub.Publish(streamTestEvent{Value: 8})
+	assert.Equal(t, `{"value":8}`, readTestFrame(t, reader))
+
+	writeMaskedTestFrame(t, conn, wsOpClose, nil)
+
+	assert.Eventually(t, func() bool {
+		r.streams.mutex.Lock()
+		defer r.streams.mutex.Unlock()
+
+		return hub.ConnectionsCount() == 0 && len(r.streams.clients) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamHubDropsOnFullBuffer(t *testing.T) {
+	hub := NewStreamHub()
+	client := &streamClient{
+		out:    make(chan interface{}, 1),
+		userId: 1,
+		metrics: streamMetrics{
+			dropped: prometheus.NewCounter(prometheus.CounterOpts{Name: "test_dropped"}),
+		},
+	}
+
+	hub.subscribe(client)
+
+	hub.Publish("first")
+	hub.Publish("second")
+	hub.Publish("other user", 2)
+
+	assert.Equal(t, 1, len(client.out))
+	assert.Equal(t, "first", <-client.out)
+}
+
+func writeMaskedTestFrame(t *testing.T, conn net.Conn, opcode byte, payload []byte) {
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := conn.Write(frame)
+	assert.Nil(t, err)
+}
+
+func readTestFrame(t *testing.T, reader *bufio.Reader) string {
+	var head [2]byte
+
+	_, err := io.ReadFull(reader, head[:])
+	assert.Nil(t, err)
+
+	length := int(head[1] & 0x7F)
+
+	if length == 126 {
+		var ext [2]byte
+
+		_, err = io.ReadFull(reader, ext[:])
+		assert.Nil(t, err)
+
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+
+	payload := make([]byte, length)
+
+	_, err = io.ReadFull(reader, payload)
+	assert.Nil(t, err)
+
+	return string(payload)
+}
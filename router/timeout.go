@@ -0,0 +1,118 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/pkg/errors"
+	"strconv"
+	"time"
+)
+
+type EndpointType string
+
+const (
+	EndpointRest           = EndpointType("rest")
+	EndpointRpcPublic      = EndpointType("rpc")
+	EndpointRpcAdmin       = EndpointType("rpc-admin")
+	EndpointRpcAdminLegacy = EndpointType("rpc-admin-legacy")
+	EndpointRpcService     = EndpointType("rpc-service")
+)
+
+func defaultEndpointTimeouts() map[EndpointType]time.Duration {
+	return map[EndpointType]time.Duration{
+		EndpointRest:           1 * time.Minute,
+		EndpointRpcPublic:      1 * time.Minute,
+		EndpointRpcService:     1 * time.Minute,
+		EndpointRpcAdmin:       5 * time.Minute,
+		EndpointRpcAdminLegacy: 5 * time.Minute,
+	}
+}
+
+// WithEndpointTimeout sets default timeout for commands without own timeout. Zero disables the deadline.
+func (r *HttpRouter) WithEndpointTimeout(endpoint EndpointType, timeout time.Duration) *HttpRouter {
+	r.endpointTimeouts[endpoint] = timeout
+
+	return r
+}
+
+func WithTimeout(cmd ICommand, timeout time.Duration) ICommand {
+	if holder, ok := cmd.(commandOptionsHolder); ok {
+		holder.getCommandOptions().timeout = timeout
+	}
+
+	return cmd
+}
+
+func (r RestCommandBuilder) WithTimeout(timeout time.Duration) RestCommandBuilder {
+	r.cmd.timeout = timeout
+
+	return r
+}
+
+func (o commandOptions) GetTimeout() time.Duration {
+	return o.timeout
+}
+
+func (r *HttpRouter) getCommandTimeout(cmd ICommand, endpoint EndpointType) time.Duration {
+	if timeout := getCommandOptions(cmd).timeout; timeout > 0 {
+		return timeout
+	}
+
+	return r.endpointTimeouts[endpoint]
+}
+
+// TimeoutMiddleware applies command timeout, shortened by remaining budget sent by the caller in
+// common.RequestTimeoutHeader. Commands should pass executionData.Context to downstream calls to give up in time.
+func (r *HttpRouter) TimeoutMiddleware() Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			timeout := r.getCommandTimeout(executionData.command, executionData.endpointType)
+
+			if executionData.httpCtx != nil {
+				if raw := executionData.httpCtx.Request.Header.Peek(common.RequestTimeoutHeader); len(raw) > 0 {
+					if budgetMs, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+						budget := time.Duration(budgetMs) * time.Millisecond
+
+						if budget <= 0 {
+							return nil, error_codes.NewErrorWithCodeRef(errors.New("caller deadline already exceeded"),
+								error_codes.Timeout)
+						}
+
+						if timeout <= 0 || budget < timeout {
+							timeout = budget
+						}
+					}
+				}
+			}
+
+			if timeout <= 0 {
+				return next(request, executionData)
+			}
+
+			ctx, cancelFn := context.WithTimeout(executionData.Context, timeout)
+			defer cancelFn()
+
+			executionData.Context = ctx
+
+			resp, err := next(request, executionData)
+
+			// a result which came late is still returned, the command may have already committed its changes
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && isDeadlineError(err) {
+				return nil, error_codes.NewErrorWithCodeRef(errors.New(fmt.Sprintf("command deadline of [%v] exceeded",
+					timeout.String())), error_codes.Timeout)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+func isDeadlineError(err *error_codes.ErrorWithCode) bool {
+	if errors.Is(err.GetError(), context.DeadlineExceeded) {
+		return true
+	}
+
+	return err.GetCode() == error_codes.Timeout || err.GetCode() == error_codes.GenericTimeoutError
+}
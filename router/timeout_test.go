@@ -0,0 +1,60 @@
+package router
+
+import (
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCommandTimeout(t *testing.T) {
+	r := NewRouter("", nil)
+
+	var remaining time.Duration
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(WithTimeout(NewServiceCommand("slow",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			deadline, _ := executionData.Context.Deadline()
+			remaining = time.Until(deadline)
+
+			<-executionData.Context.Done()
+
+			return nil, error_codes.NewErrorWithCodeRef(executionData.Context.Err(), error_codes.GenericServerError)
+		}, false), 50*time.Millisecond)))
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(WithTimeout(NewServiceCommand("committed",
+		func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			<-executionData.Context.Done()
+
+			return "late", nil
+		}, false), 10*time.Millisecond)))
+
+	var resp rpc.RpcResponseInternal
+
+	ctx := doRequest(r, "POST", "/rpc-service", `{"method":"slow","id":"1"}`, nil)
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, error_codes.Timeout, resp.Error.Code)
+	assert.True(t, remaining <= 50*time.Millisecond)
+
+	resp = rpc.RpcResponseInternal{}
+	ctx = doRequest(r, "POST", "/rpc-service", `{"method":"slow","id":"1"}`,
+		map[string]string{common.RequestTimeoutHeader: "10"})
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, error_codes.Timeout, resp.Error.Code)
+	assert.True(t, remaining <= 10*time.Millisecond)
+
+	resp = rpc.RpcResponseInternal{}
+	ctx = doRequest(r, "POST", "/rpc-service", `{"method":"slow","id":"1"}`,
+		map[string]string{common.RequestTimeoutHeader: "0"})
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, error_codes.Timeout, resp.Error.Code)
+
+	resp = rpc.RpcResponseInternal{}
+	ctx = doRequest(r, "POST", "/rpc-service", `{"method":"committed","id":"1"}`, nil)
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Nil(t, resp.Error)
+	assert.JSONEq(t, `"late"`, string(resp.Result))
+}
@@ -0,0 +1,145 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/jwt"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"strconv"
+)
+
+const (
+	defaultTokenHeader      = "Authorization"
+	defaultAdminTokenHeader = "Authorization-Admin"
+	defaultUserIdClaim      = "user_id"
+	defaultGuestClaim       = "guest"
+)
+
+// TokenAuthConfig enables in-process token verification instead of trusting mesh authz headers
+type TokenAuthConfig struct {
+	Verifier *jwt.Verifier
+	// Header defaults to "Authorization" and to "Authorization-Admin" for admin commands, "Bearer " prefix is optional
+	Header string
+	// UserIdClaim defaults to "user_id", "sub" is used when the claim is missing
+	UserIdClaim string
+	// GuestClaim defaults to "guest"
+	GuestClaim string
+}
+
+// WithEndpointTokenAuth verifies tokens of the endpoint requests in the router. X-Ext-Authz-Check-Result, User-Id
+// and Admin-Id headers sent by the client are ignored for such endpoint, and admin commands do not call forward-auth.
+// Admin stream commands use EndpointRpcAdmin config.
+func (r *HttpRouter) WithEndpointTokenAuth(endpoint EndpointType, config TokenAuthConfig) *HttpRouter {
+	if len(config.UserIdClaim) == 0 {
+		config.UserIdClaim = defaultUserIdClaim
+	}
+
+	if len(config.GuestClaim) == 0 {
+		config.GuestClaim = defaultGuestClaim
+	}
+
+	r.endpointTokenAuth[endpoint] = &config
+
+	return r
+}
+
+func usesAdminIdentity(cmd ICommand) bool {
+	switch c := cmd.(type) {
+	case *AdminCommand:
+		return true
+	case *StreamCommand:
+		return c.isAdmin
+	}
+
+	return false
+}
+
+// tokenAuthEndpoint admin streams are served next to rest commands, but verified with admin tokens
+func tokenAuthEndpoint(endpoint EndpointType, cmd ICommand) EndpointType {
+	if c, ok := cmd.(*StreamCommand); ok && c.isAdmin {
+		return EndpointRpcAdmin
+	}
+
+	return endpoint
+}
+
+// applyTokenAuth replaces identity headers with the ones from verified token, so CanExecute of every
+// command type works the same way as behind the mesh authz
+func (r *HttpRouter) applyTokenAuth(httpCtx *fasthttp.RequestCtx, ctx context.Context, endpoint EndpointType,
+	cmd ICommand) *error_codes.ErrorWithCode {
+	config, ok := r.endpointTokenAuth[endpoint]
+
+	if !ok {
+		return nil
+	}
+
+	isAdmin := usesAdminIdentity(cmd)
+	headerName := config.Header
+
+	if len(headerName) == 0 {
+		headerName = defaultTokenHeader
+
+		if isAdmin {
+			headerName = defaultAdminTokenHeader
+		}
+	}
+
+	token := bytes.TrimSpace(httpCtx.Request.Header.Peek(headerName))
+
+	if len(token) > 7 && bytes.EqualFold(token[:7], []byte("bearer ")) {
+		token = bytes.TrimSpace(token[7:])
+	}
+
+	tokenStr := string(token)
+
+	header := &httpCtx.Request.Header
+
+	header.Del("X-Ext-Authz-Check-Result")
+	header.Del("User-Id")
+	header.Del("Admin-Id")
+	header.Del("Is-Guest")
+	header.Del(defaultAdminTokenHeader)
+
+	if len(tokenStr) == 0 {
+		return nil
+	}
+
+	claims, err := config.Verifier.Verify(ctx, tokenStr)
+
+	if err != nil {
+		code := error_codes.InvalidJwtToken
+
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			code = error_codes.ExpiredJwtToken
+		}
+
+		return error_codes.NewErrorWithCodeRef(errors.Wrap(err, "can not verify token"), code)
+	}
+
+	userId, ok := claims.Int64(config.UserIdClaim)
+
+	if !ok {
+		userId, ok = claims.Int64("sub")
+	}
+
+	if !ok || userId <= 0 {
+		return error_codes.NewErrorWithCodeRef(errors.New("token does not contain user id"),
+			error_codes.InvalidJwtToken)
+	}
+
+	header.Set("X-Ext-Authz-Check-Result", "allowed")
+
+	if isAdmin {
+		header.Set("Admin-Id", strconv.FormatInt(userId, 10))
+	} else {
+		header.Set("User-Id", strconv.FormatInt(userId, 10))
+	}
+
+	if isGuest, ok := claims.Bool(config.GuestClaim); ok {
+		header.Set("Is-Guest", strconv.FormatBool(isGuest))
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/jwt"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testUserValidator struct {
+}
+
+func (v testUserValidator) Validate(userId int64, ctx context.Context) (*UserExecutorValidationResponse, error) {
+	return &UserExecutorValidationResponse{Id: userId, Language: translation.DefaultUserLanguage}, nil
+}
+
+var tokenAuthSecret = []byte("test-secret")
+
+func signTestToken(t *testing.T, claims map[string]interface{}) string {
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+	}
+
+	token, err := jwt.Sign(jwt.HS256, "", tokenAuthSecret, claims)
+	assert.Nil(t, err)
+
+	return token
+}
+
+func newTokenAuthRouter(t *testing.T) *HttpRouter {
+	verifier := jwt.NewVerifier(jwt.Config{
+		Keys:       jwt.NewStaticKeys(jwt.Key{Material: tokenAuthSecret}),
+		Algorithms: []jwt.Algorithm{jwt.HS256},
+		Audience:   []string{"api"},
+	})
+
+	r := NewRouter("", nil).WithUserExecutorValidator(testUserValidator{}).
+		WithEndpointTokenAuth(EndpointRpcPublic, TokenAuthConfig{Verifier: verifier}).
+		WithEndpointTokenAuth(EndpointRpcAdmin, TokenAuthConfig{Verifier: verifier})
+
+	whoAmI := func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		return fmt.Sprintf("%v:%v", executionData.UserId, executionData.IsGuest), nil
+	}
+
+	assert.Nil(t, r.GetRpcPublicEndpoint().RegisterRpcCommand(NewCommand("whoami", whoAmI, false, false)))
+	assert.Nil(t, r.GetRpcAdminEndpoint().RegisterRpcCommand(NewAdminCommand("whoami", whoAmI,
+		common.AccessLevelPublic, "users")))
+
+	return r
+}
+
+func rpcResult(t *testing.T, body []byte) (string, int) {
+	var resp struct {
+		Result string        `json:"result"`
+		Error  *rpc.RpcError `json:"error"`
+	}
+
+	assert.Nil(t, json.Unmarshal(body, &resp))
+
+	if resp.Error != nil {
+		return "", int(resp.Error.Code)
+	}
+
+	return resp.Result, 0
+}
+
+func TestTokenAuthPublic(t *testing.T) {
+	r := newTokenAuthRouter(t)
+	request := `{"method":"whoami","id":"1"}`
+
+	token := signTestToken(t, map[string]interface{}{"user_id": 15, "guest": true, "aud": "api"})
+
+	result, code := rpcResult(t, doRequest(r, "POST", "/rpc", request,
+		map[string]string{"Authorization": "Bearer " + token}).Response.Body())
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "15:true", result)
+
+	// mesh headers are not trusted when token auth is enabled
+	result, _ = rpcResult(t, doRequest(r, "POST", "/rpc", request,
+		map[string]string{"X-Ext-Authz-Check-Result": "allowed", "User-Id": "99"}).Response.Body())
+	assert.Equal(t, "0:false", result)
+
+	// sub is used when user_id claim is missing
+	token = signTestToken(t, map[string]interface{}{"sub": "16", "aud": "api"})
+
+	result, _ = rpcResult(t, doRequest(r, "POST", "/rpc", request,
+		map[string]string{"Authorization": token}).Response.Body())
+	assert.Equal(t, "16:false", result)
+
+	token = signTestToken(t, map[string]interface{}{"user_id": 15, "aud": "other"})
+
+	_, code = rpcResult(t, doRequest(r, "POST", "/rpc", request,
+		map[string]string{"Authorization": "Bearer " + token}).Response.Body())
+	assert.Equal(t, int(error_codes.InvalidJwtToken), code)
+
+	token = signTestToken(t, map[string]interface{}{"user_id": 15, "aud": "api",
+		"exp": time.Now().Add(-time.Hour).Unix()})
+
+	_, code = rpcResult(t, doRequest(r, "POST", "/rpc", request,
+		map[string]string{"Authorization": "Bearer " + token}).Response.Body())
+	assert.Equal(t, int(error_codes.ExpiredJwtToken), code)
+}
+
+func TestTokenAuthAdmin(t *testing.T) {
+	r := newTokenAuthRouter(t)
+	request := `{"method":"whoami","id":"1"}`
+
+	token := signTestToken(t, map[string]interface{}{"user_id": 7, "aud": "api"})
+
+	result, code := rpcResult(t, doRequest(r, "POST", "/rpc-admin", request,
+		map[string]string{"Authorization-Admin": token}).Response.Body())
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "7:false", result)
+
+	_, code = rpcResult(t, doRequest(r, "POST", "/rpc-admin", request, adminHeaders).Response.Body())
+	assert.Equal(t, int(error_codes.MissingJwtToken), code)
+}
@@ -0,0 +1,392 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/swagger"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type TypedCommandFunc[Req any, Resp any] func(request Req, executionData MethodExecutionData) (Resp, *error_codes.ErrorWithCode)
+
+type apiDescriptionProvider interface {
+	getApiDescription() *swagger.ApiDescription
+}
+
+func NewTypedCommand[Req any, Resp any](methodName string, fn TypedCommandFunc[Req, Resp], forceLog bool,
+	requireIdentityValidation bool) ICommand {
+	cmd := NewCommand(methodName, wrapTypedCommandFn(fn, false), forceLog, requireIdentityValidation).(*Command)
+	cmd.apiDescription = newTypedApiDescription[Req, Resp](false)
+	cmd.redactPolicy = newTypedRedactPolicy[Req, Resp]("params", "result")
+
+	return cmd
+}
+
+func NewTypedAdminCommand[Req any, Resp any](methodName string, fn TypedCommandFunc[Req, Resp],
+	accessLevel common.AccessLevel, rbacObj string) ICommand {
+	cmd := NewAdminCommand(methodName, wrapTypedCommandFn(fn, false), accessLevel, rbacObj).(*AdminCommand)
+	cmd.apiDescription = newTypedApiDescription[Req, Resp](false)
+	cmd.redactPolicy = newTypedRedactPolicy[Req, Resp]("params", "result")
+
+	return cmd
+}
+
+func NewTypedServiceCommand[Req any, Resp any](methodName string, fn TypedCommandFunc[Req, Resp], forceLog bool) ICommand {
+	cmd := NewServiceCommand(methodName, wrapTypedCommandFn(fn, false), forceLog).(*ServiceCommand)
+	cmd.apiDescription = newTypedApiDescription[Req, Resp](false)
+	cmd.redactPolicy = newTypedRedactPolicy[Req, Resp]("params", "result")
+
+	return cmd
+}
+
+// NewTypedRestCommand binds fields tagged with `path`, `query` or `header` from MethodExecutionData.GetUserValue
+// on top of the decoded json body
+func NewTypedRestCommand[Req any, Resp any](fn TypedCommandFunc[Req, Resp], path string, httpMethod HttpMethodType) RestCommandBuilder {
+	builder := NewRestCommand(wrapTypedCommandFn(fn, true), path, httpMethod)
+	builder.cmd.apiDescription = newTypedApiDescription[Req, Resp](true)
+	builder.cmd.redactPolicy = newTypedRedactPolicy[Req, Resp]("", "data")
+
+	return builder
+}
+
+func wrapTypedCommandFn[Req any, Resp any](fn TypedCommandFunc[Req, Resp], bindUserValues bool) CommandFunc {
+	return func(request []byte, executionData MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+		var req Req
+
+		if err := decodeTypedRequest(&req, request, executionData, bindUserValues); err != nil {
+			return nil, err
+		}
+
+		resp, err := fn(req, executionData)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if isNilValue(resp) {
+			return nil, nil
+		}
+
+		return resp, nil
+	}
+}
+
+func decodeTypedRequest(target interface{}, request []byte, executionData MethodExecutionData,
+	bindUserValues bool) *error_codes.ErrorWithCode {
+	if trimmed := strings.TrimSpace(string(request)); len(trimmed) > 0 && trimmed != "null" {
+		if err := json.Unmarshal(request, target); err != nil {
+			return error_codes.NewErrorWithCodeRef(errors.Wrap(err, "can not unmarshal request"),
+				error_codes.GenericMappingError)
+		}
+	}
+
+	if bindUserValues {
+		if fieldErrors := bindTypedRequest(reflect.ValueOf(target), executionData); len(fieldErrors) > 0 {
+			return error_codes.NewErrorWithCodeAndData(errors.New("can not bind request parameters"),
+				error_codes.GenericValidationError, map[string]interface{}{
+					"fields": fieldErrors,
+				})
+		}
+	}
+
+	if fieldErrors := validateStruct(target); len(fieldErrors) > 0 {
+		return error_codes.NewErrorWithCodeAndData(errors.New("request validation failed"),
+			error_codes.GenericValidationError, map[string]interface{}{
+				"fields": fieldErrors,
+			})
+	}
+
+	return nil
+}
+
+var bindingTags = []string{"path", "query", "header"}
+
+func getBindingTag(field reflect.StructField) (string, swagger.ParameterPosition) {
+	for _, tag := range bindingTags {
+		if v := field.Tag.Get(tag); len(v) > 0 && v != "-" {
+			return v, swagger.ParameterPosition(tag)
+		}
+	}
+
+	return "", ""
+}
+
+func bindTypedRequest(val reflect.Value, executionData MethodExecutionData) []FieldValidationError {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			if !val.CanSet() {
+				return nil
+			}
+
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var result []FieldValidationError
+
+	t := val.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous {
+			result = append(result, bindTypedRequest(val.Field(i), executionData)...)
+			continue
+		}
+
+		key, _ := getBindingTag(field)
+
+		if len(key) == 0 {
+			continue
+		}
+
+		rawValue := executionData.GetUserValue(key)
+
+		if rawValue == nil {
+			continue
+		}
+
+		var strValue string
+
+		switch v := rawValue.(type) {
+		case string:
+			strValue = v
+		case []byte:
+			strValue = string(v)
+		default:
+			strValue = fmt.Sprint(v)
+		}
+
+		if err := setFieldFromString(val.Field(i), strValue); err != nil {
+			result = append(result, FieldValidationError{
+				Field: key,
+				Rule:  "type",
+			})
+		}
+	}
+
+	return result
+}
+
+func setFieldFromString(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+
+		if err := setFieldFromString(ptr.Elem(), value); err != nil {
+			return err
+		}
+
+		field.Set(ptr)
+
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(value, 10, field.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(value, 10, field.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(value, field.Type().Bits())
+
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(v)
+	case reflect.Slice:
+		var parts []string
+
+		if len(value) > 0 {
+			parts = strings.Split(value, ",")
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+
+		for i, p := range parts {
+			if err := setFieldFromString(slice.Index(i), strings.TrimSpace(p)); err != nil {
+				return err
+			}
+		}
+
+		field.Set(slice)
+	default:
+		return errors.New(fmt.Sprintf("binding is not supported for kind [%v]", field.Kind()))
+	}
+
+	return nil
+}
+
+func newTypedApiDescription[Req any, Resp any](isRest bool) *swagger.ApiDescription {
+	var req Req
+	var resp Resp
+
+	desc := &swagger.ApiDescription{
+		Request:  req,
+		Response: resp,
+	}
+
+	if !isRest {
+		return desc
+	}
+
+	reqType := reflect.TypeOf(&req).Elem()
+
+	for reqType.Kind() == reflect.Ptr {
+		reqType = reqType.Elem()
+	}
+
+	if reqType.Kind() != reflect.Struct {
+		return desc
+	}
+
+	hasBodyFields := false
+
+	for i := 0; i < reqType.NumField(); i++ {
+		field := reqType.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		key, position := getBindingTag(field)
+
+		if len(key) == 0 {
+			if jsonFieldName(field) != "-" {
+				hasBodyFields = true
+			}
+
+			continue
+		}
+
+		fieldType := field.Type
+
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		desc.AdditionalSwaggerParameters = append(desc.AdditionalSwaggerParameters, swagger.ParameterDescription{
+			Name:     key,
+			In:       position,
+			Required: position == swagger.ParameterInPath || strings.Contains(field.Tag.Get("validate"), "required"),
+			Type:     swaggerParameterType(fieldType.Kind()),
+		})
+	}
+
+	if !hasBodyFields {
+		desc.Request = nil
+	}
+
+	return desc
+}
+
+func swaggerParameterType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice:
+		return "array"
+	}
+
+	return "string"
+}
+
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// GetApiDescriptions returns apiDef merged with descriptions of typed commands, keys are lower cased paths
+func (r *HttpRouter) GetApiDescriptions(apiDef map[string]swagger.ApiDescription) map[string]swagger.ApiDescription {
+	return r.getTypedApiDescriptions(apiDef)
+}
+
+func (r *HttpRouter) getTypedApiDescriptions(apiDef map[string]swagger.ApiDescription) map[string]swagger.ApiDescription {
+	result := map[string]swagger.ApiDescription{}
+
+	for k, v := range apiDef {
+		result[strings.ToLower(k)] = v
+	}
+
+	var allCommands []ICommand
+
+	for _, c := range r.restCommands {
+		allCommands = append(allCommands, c)
+	}
+
+	for _, endpoint := range []IRpcEndpoint{r.rpcEndpointPublic, r.rpcEndpointAdmin, r.rpcEndpointService,
+		r.rpcEndpointAdminLegacy} {
+		if endpoint != nil {
+			allCommands = append(allCommands, endpoint.GetRegisteredCommands()...)
+		}
+	}
+
+	for _, c := range allCommands {
+		provider, ok := c.(apiDescriptionProvider)
+
+		if !ok {
+			continue
+		}
+
+		desc := provider.getApiDescription()
+		key := strings.ToLower(c.GetPath())
+
+		if _, exists := result[key]; desc == nil || exists { // explicit descriptions always win
+			continue
+		}
+
+		result[key] = *desc
+	}
+
+	return result
+}
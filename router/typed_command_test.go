@@ -0,0 +1,96 @@
+package router
+
+import (
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/digitalmonsters/go-common/swagger"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type typedTestRequest struct {
+	Name  string  `json:"name" validate:"required,max=5"`
+	Kind  string  `json:"kind" validate:"oneof=a b"`
+	Ids   []int64 `json:"ids" validate:"min=1"`
+	Inner *struct {
+		Value int `json:"value" validate:"min=10"`
+	} `json:"inner"`
+}
+
+type typedTestResponse struct {
+	Name string `json:"name"`
+}
+
+type typedRestRequest struct {
+	Id     int64    `path:"id" validate:"min=1"`
+	Limit  int      `query:"limit"`
+	Tags   []string `query:"tags"`
+	Device string   `header:"device-id"`
+}
+
+func TestTypedRpcCommand(t *testing.T) {
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.GetRpcServiceEndpoint().RegisterRpcCommand(NewTypedServiceCommand("typed",
+		func(request typedTestRequest, executionData MethodExecutionData) (typedTestResponse, *error_codes.ErrorWithCode) {
+			return typedTestResponse{Name: request.Name}, nil
+		}, false)))
+
+	ctx := doRequest(r, "POST", "/rpc-service", `{"method":"typed","params":{"name":"abc","kind":"a","ids":[1]},"id":"1"}`, nil)
+
+	var resp rpc.RpcResponseInternal
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Nil(t, resp.Error)
+	assert.JSONEq(t, `{"name":"abc"}`, string(resp.Result))
+
+	ctx = doRequest(r, "POST", "/rpc-service", `{"method":"typed","params":{"name":"abcdef","kind":"c","inner":{"value":1}},"id":"1"}`, nil)
+
+	resp = rpc.RpcResponseInternal{}
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, error_codes.GenericValidationError, resp.Error.Code)
+
+	fields, _ := json.Marshal(resp.Error.Data["fields"])
+	assert.JSONEq(t, `[{"field":"name","rule":"max=5"},{"field":"kind","rule":"oneof=a b"},{"field":"ids","rule":"min=1"},{"field":"inner.value","rule":"min=10"}]`,
+		string(fields))
+
+	ctx = doRequest(r, "POST", "/rpc-service", `{"method":"typed","params":{"name":1},"id":"1"}`, nil)
+
+	resp = rpc.RpcResponseInternal{}
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.Equal(t, error_codes.GenericMappingError, resp.Error.Code)
+}
+
+func TestTypedRestCommand(t *testing.T) {
+	r := NewRouter("", nil)
+
+	assert.Nil(t, r.RegisterRestCmd(NewTypedRestCommand(func(request typedRestRequest,
+		executionData MethodExecutionData) (typedRestRequest, *error_codes.ErrorWithCode) {
+		return request, nil
+	}, "/items/{id}", MethodGet).Build()))
+
+	ctx := doRequest(r, "GET", "/items/15?limit=3&tags=a,b", "", map[string]string{"device-id": "dev"})
+
+	var resp genericRestResponse
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.True(t, resp.Success)
+
+	data, _ := json.Marshal(resp.Data)
+	assert.JSONEq(t, `{"Id":15,"Limit":3,"Tags":["a","b"],"Device":"dev"}`, string(data))
+
+	ctx = doRequest(r, "GET", "/items/abc", "", nil)
+
+	resp = genericRestResponse{}
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &resp))
+	assert.False(t, resp.Success)
+	assert.Equal(t, int(error_codes.GenericValidationError), ctx.Response.StatusCode())
+	assert.NotNil(t, resp.ErrorData["fields"])
+
+	docs := r.getTypedApiDescriptions(map[string]swagger.ApiDescription{})
+	desc := docs["/items/{id}"]
+
+	assert.Nil(t, desc.Request)
+	assert.Len(t, desc.AdditionalSwaggerParameters, 4)
+	assert.Equal(t, swagger.ParameterInPath, desc.AdditionalSwaggerParameters[0].In)
+	assert.True(t, desc.AdditionalSwaggerParameters[0].Required)
+}
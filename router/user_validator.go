@@ -2,15 +2,38 @@ package router
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/kafka_listener"
 	"github.com/digitalmonsters/go-common/translation"
 	"github.com/digitalmonsters/go-common/wrappers/auth_go"
-	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/segmentio/kafka-go"
 	"gopkg.in/guregu/null.v4"
+	"sync"
 	"time"
 )
 
+const (
+	defaultUserValidatorMaxEntries = 100000
+	defaultUserValidatorTtl        = 2 * time.Minute
+)
+
+var (
+	userValidatorCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "router_user_validator_requests_total",
+		Help: "User validator lookups by result: hit, miss, fail_open, error",
+	}, []string{"result"})
+	userValidatorInvalidations = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "router_user_validator_invalidations_total",
+		Help: "Number of cached users updated or removed by events",
+	})
+)
+
 type UserExecutorValidationResponse struct {
 	Id         int64                `json:"id"`
 	Deleted    bool                 `json:"deleted"`
@@ -24,53 +47,260 @@ type UserExecutorValidator interface {
 	Validate(userId int64, ctx context.Context) (*UserExecutorValidationResponse, error)
 }
 
+type UserValidatorFailPolicy string
+
+const (
+	// UserValidatorFailClosed rejects requests when auth-go is unreachable
+	UserValidatorFailClosed = UserValidatorFailPolicy("closed")
+	// UserValidatorFailOpen uses expired cache entry, or treats user as not banned when there is none
+	UserValidatorFailOpen = UserValidatorFailPolicy("open")
+)
+
+type UserExecutorValidatorConfig struct {
+	MaxEntries int
+	Ttl        time.Duration
+	FailPolicy UserValidatorFailPolicy
+}
+
 type DefaultUserExecutorValidator struct {
 	wrapper auth_go.IAuthGoWrapper
-	cache   *cache.Cache
+	cache   *lruCache[int64, UserExecutorValidationResponse]
+	cfg     UserExecutorValidatorConfig
+
+	fetchMutex sync.Mutex
+	fetching   map[int64]*userFetchState
+}
+
+// userFetchState tracks changes of user while it is fetched, so stale response does not overwrite them
+type userFetchState struct {
+	refs       int
+	generation uint64
 }
 
 func NewDefaultUserExecutorValidator(wrapper auth_go.IAuthGoWrapper) UserExecutorValidator {
+	return NewUserExecutorValidator(wrapper, UserExecutorValidatorConfig{})
+}
+
+func NewUserExecutorValidator(wrapper auth_go.IAuthGoWrapper,
+	cfg UserExecutorValidatorConfig) *DefaultUserExecutorValidator {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultUserValidatorMaxEntries
+	}
+
+	if cfg.Ttl <= 0 {
+		cfg.Ttl = defaultUserValidatorTtl
+	}
+
+	if len(cfg.FailPolicy) == 0 {
+		cfg.FailPolicy = UserValidatorFailClosed
+	}
+
 	return &DefaultUserExecutorValidator{
-		wrapper: wrapper,
-		cache:   cache.New(4*time.Minute, 5*time.Minute),
+		wrapper:  wrapper,
+		cache:    newLruCache[int64, UserExecutorValidationResponse](cfg.MaxEntries),
+		cfg:      cfg,
+		fetching: map[int64]*userFetchState{},
 	}
 }
 
-func (v DefaultUserExecutorValidator) Validate(userId int64, ctx context.Context) (*UserExecutorValidationResponse, error) {
-	key := fmt.Sprint(userId)
+func (v *DefaultUserExecutorValidator) fetch(userIds []int64,
+	ctx context.Context) (map[int64]UserExecutorValidationResponse, error) {
+	generations := v.startFetch(userIds)
+	defer v.finishFetch(userIds)
 
-	if val, ok := v.cache.Get(key); ok {
-		m := val.(UserExecutorValidationResponse)
+	usersResp := <-v.wrapper.InternalGetUsersForValidation(userIds, ctx, false)
 
-		return &m, nil
+	if usersResp.Error != nil {
+		return nil, errors.Wrap(usersResp.Error.ToError(), "can not get user info from auth service")
 	}
 
-	usersResp := <-v.wrapper.InternalGetUsersForValidation([]int64{userId}, ctx, false)
+	result := map[int64]UserExecutorValidationResponse{}
 
-	if usersResp.Error != nil {
-		err := errors.Wrap(usersResp.Error.ToError(), "can not get user info from auth service")
+	v.fetchMutex.Lock()
+	defer v.fetchMutex.Unlock()
+
+	for userId, user := range usersResp.Response {
+		resp := UserExecutorValidationResponse{
+			Id:         user.Id,
+			Deleted:    user.Deleted,
+			BannedTill: user.BannedTill,
+			Guest:      user.Guest,
+			Verified:   user.Verified,
+			Language:   user.Language,
+		}
+
+		// user was changed or invalidated while request was in flight, so response can be stale
+		if state, ok := v.fetching[userId]; !ok || state.generation == generations[userId] {
+			v.cache.Set(userId, resp, v.cfg.Ttl)
+		}
+
+		result[userId] = resp
+	}
+
+	return result, nil
+}
+
+func (v *DefaultUserExecutorValidator) startFetch(userIds []int64) map[int64]uint64 {
+	v.fetchMutex.Lock()
+	defer v.fetchMutex.Unlock()
+
+	generations := make(map[int64]uint64, len(userIds))
+
+	for _, userId := range userIds {
+		state, ok := v.fetching[userId]
+
+		if !ok {
+			state = &userFetchState{}
+			v.fetching[userId] = state
+		}
+
+		state.refs++
+		generations[userId] = state.generation
+	}
+
+	return generations
+}
+
+func (v *DefaultUserExecutorValidator) finishFetch(userIds []int64) {
+	v.fetchMutex.Lock()
+	defer v.fetchMutex.Unlock()
+
+	for _, userId := range userIds {
+		if state, ok := v.fetching[userId]; ok {
+			if state.refs--; state.refs <= 0 {
+				delete(v.fetching, userId)
+			}
+		}
+	}
+}
+
+// markChanged makes in-flight fetches of users skip the cache
+func (v *DefaultUserExecutorValidator) markChanged(userIds ...int64) {
+	v.fetchMutex.Lock()
+	defer v.fetchMutex.Unlock()
+
+	for _, userId := range userIds {
+		if state, ok := v.fetching[userId]; ok {
+			state.generation++
+		}
+	}
+}
+
+func (v *DefaultUserExecutorValidator) Validate(userId int64, ctx context.Context) (*UserExecutorValidationResponse, error) {
+	if val, ok := v.cache.Get(userId); ok {
+		userValidatorCacheRequests.WithLabelValues("hit").Inc()
 
-		return nil, err
+		return &val, nil
 	}
 
-	user, ok := usersResp.Response[userId]
+	userValidatorCacheRequests.WithLabelValues("miss").Inc()
+
+	users, err := v.fetch([]int64{userId}, ctx)
+
+	if err != nil {
+		if v.cfg.FailPolicy != UserValidatorFailOpen {
+			userValidatorCacheRequests.WithLabelValues("error").Inc()
+
+			return nil, err
+		}
+
+		userValidatorCacheRequests.WithLabelValues("fail_open").Inc()
+		apm_helper.LogError(err, ctx)
+
+		if stale, ok := v.cache.GetStale(userId); ok {
+			return &stale, nil
+		}
+
+		return &UserExecutorValidationResponse{
+			Id:       userId,
+			Language: translation.DefaultUserLanguage,
+		}, nil
+	}
+
+	user, ok := users[userId]
 
 	if !ok {
-		err := errors.WithStack(errors.New("have no such user info"))
+		return nil, errors.WithStack(errors.New("have no such user info"))
+	}
+
+	return &user, nil
+}
+
+// Prefetch loads users which are not cached yet with a single request
+func (v *DefaultUserExecutorValidator) Prefetch(userIds []int64, ctx context.Context) error {
+	var missing []int64
 
-		return nil, err
+	for _, userId := range userIds {
+		if _, ok := v.cache.Get(userId); !ok {
+			missing = append(missing, userId)
+		}
 	}
 
-	resp := UserExecutorValidationResponse{
-		Id:         user.Id,
-		Deleted:    user.Deleted,
-		BannedTill: user.BannedTill,
-		Guest:      user.Guest,
-		Verified:   user.Verified,
-		Language:   user.Language,
+	if len(missing) == 0 {
+		return nil
 	}
 
-	v.cache.Set(key, resp, 2*time.Minute)
+	_, err := v.fetch(missing, ctx)
+
+	return err
+}
+
+func (v *DefaultUserExecutorValidator) Invalidate(userIds ...int64) {
+	v.markChanged(userIds...)
+
+	for _, userId := range userIds {
+		if v.cache.Delete(userId) {
+			userValidatorInvalidations.Inc()
+		}
+	}
+}
+
+// ApplyUserEvent updates cached user with ban, delete, guest and verify status from the event.
+// Users which are not cached are ignored.
+func (v *DefaultUserExecutorValidator) ApplyUserEvent(event eventsourcing.UserEvent) {
+	v.markChanged(event.UserId)
+
+	updated := v.cache.Update(event.UserId, func(value UserExecutorValidationResponse) UserExecutorValidationResponse {
+		value.Deleted = event.Deleted || event.CrudOperation == eventsourcing.ChangeEventTypeDeleted
+		value.BannedTill = event.BannedTill
+		value.Guest = event.Guest
+		value.Verified = event.Verified
+
+		if len(event.Language) > 0 {
+			value.Language = event.Language
+		}
+
+		return value
+	})
+
+	if updated {
+		userValidatorInvalidations.Inc()
+	}
+}
+
+// NewKafkaCommand returns kafka_listener command which applies eventsourcing.UserEvent messages to the cache
+func (v *DefaultUserExecutorValidator) NewKafkaCommand(fancyName string) kafka_listener.ICommand {
+	return kafka_listener.NewCommand(fancyName, func(executionData kafka_listener.ExecutionData,
+		request ...kafka.Message) []kafka.Message {
+		for _, message := range request {
+			var event eventsourcing.UserEvent
+
+			if err := json.Unmarshal(message.Value, &event); err != nil {
+				apm_helper.LogError(errors.Wrap(err, "can not parse user event"), executionData.Context)
+
+				continue
+			}
+
+			v.ApplyUserEvent(event)
+		}
+
+		return request
+	}, false)
+}
 
-	return &resp, nil
+// ListenKafka starts listener of user events topic. Every pod should use own configuration.GroupId,
+// otherwise other pods will keep stale entries.
+func (v *DefaultUserExecutorValidator) ListenKafka(configuration boilerplate.KafkaListenerConfiguration,
+	ctx context.Context) kafka_listener.IKafkaListener {
+	return kafka_listener.NewSingleListener(configuration, v.NewKafkaCommand(configuration.Topic), ctx).ListenAsync()
 }
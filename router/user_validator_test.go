@@ -0,0 +1,162 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/kafka_listener"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+	"testing"
+	"time"
+)
+
+type testAuthGo struct {
+	auth_go.AuthGoWrapperMock
+	requests [][]int64
+	failing  bool
+}
+
+func newTestAuthGo() *testAuthGo {
+	w := &testAuthGo{}
+
+	w.InternalGetUsersForValidationFn = func(userIds []int64, ctx context.Context,
+		forceLog bool) chan wrappers.GenericResponseChan[map[int64]auth_go.UserForValidator] {
+		w.requests = append(w.requests, userIds)
+
+		ch := make(chan wrappers.GenericResponseChan[map[int64]auth_go.UserForValidator], 1)
+
+		if w.failing {
+			ch <- wrappers.GenericResponseChan[map[int64]auth_go.UserForValidator]{
+				Error: &rpc.RpcError{Message: "unreachable"},
+			}
+		} else {
+			users := map[int64]auth_go.UserForValidator{}
+
+			for _, id := range userIds {
+				users[id] = auth_go.UserForValidator{Id: id, Language: "en"}
+			}
+
+			ch <- wrappers.GenericResponseChan[map[int64]auth_go.UserForValidator]{Response: users}
+		}
+
+		close(ch)
+
+		return ch
+	}
+
+	return w
+}
+
+func TestUserValidatorEvents(t *testing.T) {
+	authGo := newTestAuthGo()
+	validator := NewUserExecutorValidator(authGo, UserExecutorValidatorConfig{})
+
+	resp, err := validator.Validate(1, context.Background())
+	assert.Nil(t, err)
+	assert.False(t, resp.BannedTill.Valid)
+
+	event, _ := json.Marshal(eventsourcing.UserEvent{UserId: 1, BannedTill: null.TimeFrom(time.Now().Add(time.Hour)),
+		Verified: true})
+
+	validator.NewKafkaCommand("users").Execute(kafka_listener.ExecutionData{Context: context.Background()},
+		kafka.Message{Value: event}, kafka.Message{Value: []byte("not json")})
+
+	resp, err = validator.Validate(1, context.Background())
+	assert.Nil(t, err)
+	assert.True(t, resp.BannedTill.Valid)
+	assert.True(t, resp.Verified)
+	assert.Len(t, authGo.requests, 1)
+
+	validator.ApplyUserEvent(eventsourcing.UserEvent{UserId: 1,
+		BaseChangeEvent: eventsourcing.NewBaseChangeEvent(eventsourcing.ChangeEventTypeDeleted)})
+
+	resp, _ = validator.Validate(1, context.Background())
+	assert.True(t, resp.Deleted)
+
+	validator.Invalidate(1)
+
+	_, _ = validator.Validate(1, context.Background())
+	assert.Len(t, authGo.requests, 2)
+}
+
+func TestUserValidatorPrefetchAndEviction(t *testing.T) {
+	authGo := newTestAuthGo()
+	validator := NewUserExecutorValidator(authGo, UserExecutorValidatorConfig{MaxEntries: 2})
+
+	assert.Nil(t, validator.Prefetch([]int64{1, 2}, context.Background()))
+	assert.Nil(t, validator.Prefetch([]int64{1, 2}, context.Background()))
+	assert.Equal(t, [][]int64{{1, 2}}, authGo.requests)
+
+	_, _ = validator.Validate(1, context.Background()) // 2 becomes the oldest
+	_, _ = validator.Validate(3, context.Background())
+
+	assert.Equal(t, 2, validator.cache.Len())
+
+	_, _ = validator.Validate(1, context.Background())
+	_, _ = validator.Validate(2, context.Background())
+
+	assert.Equal(t, [][]int64{{1, 2}, {3}, {2}}, authGo.requests)
+}
+
+func TestUserValidatorFailPolicy(t *testing.T) {
+	authGo := newTestAuthGo()
+	closed := NewUserExecutorValidator(authGo, UserExecutorValidatorConfig{})
+	open := NewUserExecutorValidator(authGo, UserExecutorValidatorConfig{FailPolicy: UserValidatorFailOpen})
+
+	_, _ = open.Validate(1, context.Background())
+	open.ApplyUserEvent(eventsourcing.UserEvent{UserId: 1, Guest: true})
+	open.cache.now = func() time.Time {
+		return time.Now().Add(time.Hour)
+	}
+
+	authGo.failing = true
+
+	_, err := closed.Validate(1, context.Background())
+	assert.NotNil(t, err)
+
+	resp, err := open.Validate(1, context.Background()) // expired entry
+	assert.Nil(t, err)
+	assert.True(t, resp.Guest)
+
+	resp, err = open.Validate(2, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), resp.Id)
+	assert.False(t, resp.BannedTill.Valid)
+}
+
+func TestUserValidatorEventDuringFetch(t *testing.T) {
+	authGo := newTestAuthGo()
+	validator := NewUserExecutorValidator(authGo, UserExecutorValidatorConfig{})
+
+	fetchFn := authGo.InternalGetUsersForValidationFn
+	authGo.InternalGetUsersForValidationFn = func(userIds []int64, ctx context.Context,
+		forceLog bool) chan wrappers.GenericResponseChan[map[int64]auth_go.UserForValidator] {
+		ch := fetchFn(userIds, ctx, forceLog)
+
+		validator.ApplyUserEvent(eventsourcing.UserEvent{UserId: 1, Deleted: true})
+
+		return ch
+	}
+
+	resp, err := validator.Validate(1, context.Background())
+	assert.Nil(t, err)
+	assert.False(t, resp.Deleted)
+
+	authGo.InternalGetUsersForValidationFn = fetchFn
+
+	_, ok := validator.cache.Get(1)
+	assert.False(t, ok)
+
+	_, err = validator.Validate(1, context.Background())
+	assert.Nil(t, err)
+
+	_, ok = validator.cache.Get(1)
+	assert.True(t, ok)
+	assert.Len(t, authGo.requests, 2)
+	assert.Empty(t, validator.fetching)
+}
@@ -0,0 +1,187 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// supported rules for `validate` tag: required, min=N, max=N, len=N, oneof=a b c
+// min, max and len are compared with the value for numbers and with the length for strings, slices and maps
+
+type FieldValidationError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+}
+
+func validateStruct(input interface{}) []FieldValidationError {
+	var result []FieldValidationError
+
+	validateValue(reflect.ValueOf(input), "", &result)
+
+	return result
+}
+
+func validateValue(val reflect.Value, prefix string, result *[]FieldValidationError) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		t := val.Type()
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldName := jsonFieldName(field)
+
+			if fieldName == "-" {
+				continue
+			}
+
+			if field.Anonymous {
+				validateValue(val.Field(i), prefix, result)
+				continue
+			}
+
+			fullName := fieldName
+
+			if len(prefix) > 0 {
+				fullName = fmt.Sprintf("%v.%v", prefix, fieldName)
+			}
+
+			fieldValue := val.Field(i)
+
+			if rules := field.Tag.Get("validate"); len(rules) > 0 && rules != "-" {
+				if failedRule := validateRules(fieldValue, rules); len(failedRule) > 0 {
+					*result = append(*result, FieldValidationError{
+						Field: fullName,
+						Rule:  failedRule,
+					})
+
+					continue
+				}
+			}
+
+			validateValue(fieldValue, fullName, result)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			validateValue(val.Index(i), fmt.Sprintf("%v[%v]", prefix, i), result)
+		}
+	}
+}
+
+func validateRules(val reflect.Value, rules string) string {
+	isNil := false
+
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			isNil = true
+			break
+		}
+
+		val = val.Elem()
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+
+		if len(rule) == 0 {
+			continue
+		}
+
+		name := rule
+		arg := ""
+
+		if idx := strings.Index(rule, "="); idx > 0 {
+			name = rule[:idx]
+			arg = rule[idx+1:]
+		}
+
+		if name == "required" {
+			if isNil || val.IsZero() {
+				return rule
+			}
+
+			continue
+		}
+
+		if isNil { // optional value is not set, nothing to validate
+			return ""
+		}
+
+		switch name {
+		case "min", "max", "len":
+			limit, err := strconv.ParseFloat(arg, 64)
+
+			if err != nil {
+				return rule
+			}
+
+			current, ok := measureValue(val)
+
+			if !ok {
+				return rule
+			}
+
+			if (name == "min" && current < limit) || (name == "max" && current > limit) ||
+				(name == "len" && current != limit) {
+				return rule
+			}
+		case "oneof":
+			found := false
+			current := fmt.Sprint(val.Interface())
+
+			for _, option := range strings.Fields(arg) {
+				if option == current {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return rule
+			}
+		}
+	}
+
+	return ""
+}
+
+func measureValue(val reflect.Value) (float64, bool) {
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), true
+	case reflect.String:
+		return float64(len([]rune(val.String()))), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(val.Len()), true
+	}
+
+	return 0, false
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	if js := field.Tag.Get("json"); len(js) > 0 {
+		if name := strings.Split(js, ",")[0]; len(name) > 0 {
+			return name
+		}
+	}
+
+	return field.Name
+}
@@ -0,0 +1,163 @@
+package router
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+	"io"
+	"strings"
+)
+
+// minimal RFC 6455 server side implementation, enough for json text messages
+
+const websocketGuid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const websocketVersion = "13"
+
+const websocketMaxMessageSize = 1024 * 1024
+
+const (
+	wsOpContinuation = byte(0x0)
+	wsOpText         = byte(0x1)
+	wsOpBinary       = byte(0x2)
+	wsOpClose        = byte(0x8)
+	wsOpPing         = byte(0x9)
+	wsOpPong         = byte(0xA)
+)
+
+func isWebSocketUpgrade(ctx *fasthttp.RequestCtx) bool {
+	return strings.Contains(strings.ToLower(string(ctx.Request.Header.Peek(fasthttp.HeaderConnection))), "upgrade") &&
+		strings.EqualFold(string(ctx.Request.Header.Peek(fasthttp.HeaderUpgrade)), "websocket") &&
+		len(ctx.Request.Header.Peek("Sec-WebSocket-Key")) > 0
+}
+
+func websocketAcceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + websocketGuid))
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func writeWebSocketFrame(w *bufio.Writer, opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	length := len(payload)
+
+	switch {
+	case length < 126:
+		header = append(header, byte(length))
+	case length <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err := w.Write(payload); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(w.Flush())
+}
+
+func readWebSocketFrame(r *bufio.Reader) (fin bool, opcode byte, payload []byte, err error) {
+	var head [2]byte
+
+	if _, err = io.ReadFull(r, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = head[0]&0x80 != 0
+	opcode = head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if length > websocketMaxMessageSize {
+		return false, 0, nil, errors.New(fmt.Sprintf("websocket frame is too large [%v]", length))
+	}
+
+	if !masked {
+		return false, 0, nil, errors.New("client websocket frames should be masked")
+	}
+
+	var mask [4]byte
+
+	if _, err = io.ReadFull(r, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload = make([]byte, length)
+
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// readWebSocketMessage joins fragmented frames and answers pings with writePong
+func readWebSocketMessage(r *bufio.Reader, writePong func(payload []byte) error) ([]byte, error) {
+	var message []byte
+
+	for {
+		fin, opcode, payload, err := readWebSocketFrame(r)
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case wsOpPing:
+			if err = writePong(payload); err != nil {
+				return nil, err
+			}
+
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			return nil, io.EOF
+		case wsOpText, wsOpBinary, wsOpContinuation:
+			message = append(message, payload...)
+
+			if len(message) > websocketMaxMessageSize {
+				return nil, errors.New("websocket message is too large")
+			}
+		}
+
+		if fin {
+			return message, nil
+		}
+	}
+}
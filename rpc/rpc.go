@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"github.com/digitalmonsters/go-common/error_codes"
 	"github.com/pkg/errors"
+	"io"
 	"strings"
+	"time"
 )
 
 //goland:noinspection ALL
 type RpcRequest struct {
-	Method  string          `json:"method"`
-	Params  json.RawMessage `json:"params"`
-	Id      string          `json:"id"`
-	JsonRpc string          `json:"jsonrpc"`
+	Method         string          `json:"method"`
+	Params         json.RawMessage `json:"params"`
+	Id             string          `json:"id"`
+	JsonRpc        string          `json:"jsonrpc"`
+	IdempotencyKey string          `json:"idempotency_key,omitempty"`
 }
 
 //goland:noinspection ALL
@@ -50,12 +53,14 @@ type RpcResponseInternal struct {
 
 //goland:noinspection ALL
 type RpcError struct {
-	Code        error_codes.ErrorCode  `json:"code"`
-	Message     string                 `json:"message"`
-	Data        map[string]interface{} `json:"data"`
-	Stack       string                 `json:"stack"`
-	Hostname    string                 `json:"hostname"`
-	ServiceName string                 `json:"-"`
+	Code           error_codes.ErrorCode  `json:"code"`
+	BusinessCode   int                    `json:"business_code,omitempty"`
+	TranslationKey string                 `json:"translation_key,omitempty"`
+	Message        string                 `json:"message"`
+	Data           map[string]interface{} `json:"data"`
+	Stack          string                 `json:"stack"`
+	Hostname       string                 `json:"hostname"`
+	ServiceName    string                 `json:"-"`
 }
 
 func (r *RpcError) ToError() error {
@@ -71,6 +76,10 @@ func (r *RpcError) ToError() error {
 
 	builder.WriteString(fmt.Sprintf("] replied with code [%v] and message [%v]", int(r.Code), r.Message))
 
+	if r.BusinessCode != 0 {
+		return errors.WithStack(error_codes.NewBusinessError(builder.String(), r.BusinessCode))
+	}
+
 	return errors.New(builder.String())
 }
 
@@ -80,3 +89,15 @@ type CustomFile struct {
 	MimeType                     string `json:"mime_type"`
 	ContentDispositionFirstParam string `json:"content_disposition_first_param"`
 }
+
+// StreamingFile is written by rest router without loading Content into memory, with support of Range, If-Range
+// and conditional requests. Content is closed after response is written, when it implements io.Closer
+type StreamingFile struct {
+	Content                      io.ReadSeeker `json:"-"`
+	Size                         int64         `json:"size"` // detected with Seek when zero
+	Filename                     string        `json:"filename"`
+	MimeType                     string        `json:"mime_type"`
+	ContentDispositionFirstParam string        `json:"content_disposition_first_param"`
+	ETag                         string        `json:"etag"` // quoted, generated from Size and LastModified when empty
+	LastModified                 time.Time     `json:"last_modified"`
+}
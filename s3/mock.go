@@ -1,6 +1,7 @@
 package s3
 
 import (
+	"io"
 	"time"
 )
 
@@ -9,6 +10,8 @@ type UploaderMock struct {
 	PutObjectSignedUrlFn func(path string, urlExpiration time.Duration, acl string) (string, error)
 	GetObjectSizeFn      func(path string) (int64, error)
 	UploadObjectFn       func(path string, data []byte, contentType string) error
+	UploadObjectStreamFn func(path string, body io.Reader, contentType string) error
+	OpenObjectFn         func(path string) (*ObjectReader, error)
 }
 
 func (u *UploaderMock) GetObjectSignedUrl(path string, urlExpiration time.Duration) (string, error) {
@@ -23,6 +26,12 @@ func (u *UploaderMock) GetObjectSize(path string) (int64, error) {
 func (u *UploaderMock) UploadObject(path string, data []byte, contentType string) error {
 	return u.UploadObjectFn(path, data, contentType)
 }
+func (u *UploaderMock) UploadObjectStream(path string, body io.Reader, contentType string) error {
+	return u.UploadObjectStreamFn(path, body, contentType)
+}
+func (u *UploaderMock) OpenObject(path string) (*ObjectReader, error) {
+	return u.OpenObjectFn(path)
+}
 
 func GetMock() IUploader { // for compiler errors
 	return &UploaderMock{}
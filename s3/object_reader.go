@@ -0,0 +1,124 @@
+package s3
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"io"
+	"time"
+)
+
+// ObjectReader downloads object with ranged GetObject requests, so seeking does not read skipped bytes
+type ObjectReader struct {
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+	offset       int64
+	body         io.ReadCloser
+	openFn       func(offset int64) (io.ReadCloser, error)
+}
+
+func NewObjectReader(size int64, openFn func(offset int64) (io.ReadCloser, error)) *ObjectReader {
+	return &ObjectReader{
+		Size:   size,
+		openFn: openFn,
+	}
+}
+
+func (o *ObjectReader) Read(p []byte) (int, error) {
+	if o.offset >= o.Size {
+		return 0, io.EOF
+	}
+
+	if o.body == nil {
+		body, err := o.openFn(o.offset)
+
+		if err != nil {
+			return 0, err
+		}
+
+		o.body = body
+	}
+
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+
+	return n, err
+}
+
+func (o *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	var target int64
+
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = o.offset + offset
+	case io.SeekEnd:
+		target = o.Size + offset
+	default:
+		return 0, errors.New(fmt.Sprintf("invalid whence [%v]", whence))
+	}
+
+	if target < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if target != o.offset {
+		if err := o.Close(); err != nil {
+			return 0, err
+		}
+
+		o.offset = target
+	}
+
+	return target, nil
+}
+
+func (o *ObjectReader) Close() error {
+	if o.body == nil {
+		return nil
+	}
+
+	err := o.body.Close()
+	o.body = nil
+
+	return err
+}
+
+func (u *Uploader) OpenObject(path string) (*ObjectReader, error) {
+	client, err := u.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(u.config.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reader := NewObjectReader(aws.Int64Value(head.ContentLength), func(offset int64) (io.ReadCloser, error) {
+		object, err := client.GetObject(&s3.GetObjectInput{
+			Bucket:  aws.String(u.config.Bucket),
+			Key:     aws.String(path),
+			Range:   aws.String(fmt.Sprintf("bytes=%v-", offset)),
+			IfMatch: head.ETag,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return object.Body, nil
+	})
+
+	reader.ContentType = aws.StringValue(head.ContentType)
+	reader.ETag = aws.StringValue(head.ETag)
+	reader.LastModified = aws.TimeValue(head.LastModified)
+
+	return reader, nil
+}
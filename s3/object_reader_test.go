@@ -0,0 +1,44 @@
+package s3
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestObjectReaderSeek(t *testing.T) {
+	content := []byte("0123456789")
+
+	var opened []int64
+
+	reader := NewObjectReader(int64(len(content)), func(offset int64) (io.ReadCloser, error) {
+		opened = append(opened, offset)
+
+		return io.NopCloser(bytes.NewReader(content[offset:])), nil
+	})
+
+	buf := make([]byte, 3)
+
+	_, err := io.ReadFull(reader, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "012", string(buf))
+
+	_, err = reader.Seek(3, io.SeekStart) // current position, no new request
+	assert.Nil(t, err)
+
+	_, err = io.ReadFull(reader, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "345", string(buf))
+
+	pos, err := reader.Seek(-2, io.SeekEnd)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8), pos)
+
+	rest, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "89", string(rest))
+
+	assert.Equal(t, []int64{0, 8}, opened)
+	assert.Nil(t, reader.Close())
+}
@@ -5,7 +5,9 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/digitalmonsters/go-common/boilerplate"
+	"io"
 	"time"
 )
 
@@ -14,6 +16,8 @@ type IUploader interface {
 	PutObjectSignedUrl(path string, urlExpiration time.Duration, acl string) (string, error)
 	GetObjectSize(path string) (int64, error)
 	UploadObject(path string, data []byte, contentType string) error
+	UploadObjectStream(path string, body io.Reader, contentType string) error
+	OpenObject(path string) (*ObjectReader, error)
 }
 
 type Uploader struct {
@@ -105,6 +109,21 @@ func (u *Uploader) UploadObject(path string, data []byte, contentType string) er
 	return err
 }
 
+// UploadObjectStream uses multipart upload, so body is never held in memory as a whole
+func (u *Uploader) UploadObjectStream(path string, body io.Reader, contentType string) error {
+	client, err := u.getClient()
+	if err != nil {
+		return err
+	}
+	_, err = s3manager.NewUploaderWithClient(client).Upload(&s3manager.UploadInput{
+		Body:        body,
+		Key:         aws.String(path),
+		Bucket:      aws.String(u.config.Bucket),
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
 func (u *Uploader) getClient() (*s3.S3, error) {
 	if u.session == nil {
 		if sess, err := session.NewSession(&aws.Config{Region: aws.String(u.config.Region)}); err != nil {
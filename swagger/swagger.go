@@ -21,8 +21,10 @@ type IApiCommand interface {
 type ParameterPosition string
 
 const (
-	ParameterInQuery = ParameterPosition("query")
-	ParameterInPath  = ParameterPosition("path")
+	ParameterInQuery    = ParameterPosition("query")
+	ParameterInPath     = ParameterPosition("path")
+	ParameterInHeader   = ParameterPosition("header")
+	ParameterInFormData = ParameterPosition("formData") // Type "file" for file parts
 )
 
 type ParameterDescription struct {
@@ -43,6 +45,16 @@ type ApiDescription struct {
 	Tags                        []string
 }
 
+type RateLimitDescription struct {
+	Key       string `json:"key"`
+	Limit     int    `json:"limit"`
+	PeriodSec int    `json:"period_sec"`
+}
+
+type IRateLimitedApiCommand interface {
+	GetRateLimitDescriptions() []RateLimitDescription
+}
+
 type ConstantDescription struct {
 	Ref    interface{}
 	Values []string
@@ -340,6 +352,10 @@ func buildPath(commands []IApiCommand, apiDescriptions map[string]ApiDescription
 			}
 
 			for _, item := range notV.AdditionalSwaggerParameters {
+				if item.In == ParameterInFormData {
+					methodInfo["consumes"] = []string{"multipart/form-data"}
+				}
+
 				finalParameters = append(finalParameters, map[string]interface{}{
 					"name":        item.Name,
 					"in":          string(item.In),
@@ -353,6 +369,21 @@ func buildPath(commands []IApiCommand, apiDescriptions map[string]ApiDescription
 			methodInfo["parameters"] = finalParameters
 		}
 
+		if rl, ok := cmd.(IRateLimitedApiCommand); ok {
+			if limits := rl.GetRateLimitDescriptions(); len(limits) > 0 {
+				methodInfo["x-rate-limits"] = limits
+
+				var parts []string
+
+				for _, l := range limits {
+					parts = append(parts, fmt.Sprintf("%v per %vs by %v", l.Limit, l.PeriodSec, l.Key))
+				}
+
+				methodInfo["description"] = fmt.Sprintf("%v\n\nRate limits: %v", methodInfo["description"],
+					strings.Join(parts, ", "))
+			}
+		}
+
 		if !hasResponse {
 			methodInfo["responses"] = map[string]interface{}{
 				"200": map[string]interface{}{
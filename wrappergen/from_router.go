@@ -0,0 +1,230 @@
+package wrappergen
+
+import (
+	"fmt"
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/go-common/swagger"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type specBuilder struct {
+	spec          Spec
+	localPackages map[string]bool
+	declared      map[string]bool
+	imports       map[string]bool
+}
+
+// SpecFromRouter builds spec from commands registered in the endpoint, request and response types are taken
+// from typed commands and apiDef (same map as for RegisterDocs). Structs declared in the package of requests and
+// responses are copied into spec, types of other packages are imported.
+// Package, Name and DefaultUrl are taken from base.
+func SpecFromRouter(r *router.HttpRouter, apiDef map[string]swagger.ApiDescription, endpoint router.IRpcEndpoint,
+	base Spec) (Spec, error) {
+	descriptions := r.GetApiDescriptions(apiDef)
+
+	b := &specBuilder{
+		spec:          base,
+		localPackages: map[string]bool{},
+		declared:      map[string]bool{},
+		imports:       map[string]bool{},
+	}
+
+	for _, imp := range base.Imports {
+		b.imports[imp] = true
+	}
+
+	for _, t := range base.Types {
+		b.declared[t.Name] = true
+	}
+
+	type command struct {
+		method string
+		desc   swagger.ApiDescription
+	}
+
+	var commands []command
+
+	for _, cmd := range endpoint.GetRegisteredCommands() {
+		desc, ok := descriptions[strings.ToLower(cmd.GetPath())]
+
+		if !ok || desc.Request == nil {
+			log.Warn().Msgf("[wrappergen] command [%v] has no request description, skipping", cmd.GetMethodName())
+
+			continue
+		}
+
+		commands = append(commands, command{method: cmd.GetMethodName(), desc: desc})
+
+		for _, v := range []interface{}{desc.Request, desc.Response} {
+			if t := namedType(v); t != nil && t.Kind() == reflect.Struct {
+				b.localPackages[t.PkgPath()] = true
+			}
+		}
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].method < commands[j].method
+	})
+
+	for _, c := range commands {
+		m, err := b.method(c.method, c.desc)
+
+		if err != nil {
+			return Spec{}, err
+		}
+
+		b.spec.Methods = append(b.spec.Methods, m)
+	}
+
+	b.spec.Imports = nil
+
+	for imp := range b.imports {
+		b.spec.Imports = append(b.spec.Imports, imp)
+	}
+
+	sort.Strings(b.spec.Imports)
+
+	return b.spec, b.spec.withDefaults().Validate()
+}
+
+func namedType(v interface{}) reflect.Type {
+	return derefType(reflect.TypeOf(v))
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func (b *specBuilder) method(rpcMethod string, desc swagger.ApiDescription) (Method, error) {
+	reqType := namedType(desc.Request)
+
+	if reqType.Kind() != reflect.Struct {
+		return Method{}, errors.New(fmt.Sprintf("request of method [%v] should be struct", rpcMethod))
+	}
+
+	m := Method{
+		Name:        goName(Field{Name: rpcMethod}),
+		RpcMethod:   rpcMethod,
+		Description: desc.MethodDescription,
+	}
+
+	if name := strings.TrimSuffix(reqType.Name(), "Request"); len(name) > 0 && name != reqType.Name() {
+		m.Name = strings.ToUpper(name[:1]) + name[1:]
+	}
+
+	if m.RpcMethod == m.Name {
+		m.RpcMethod = ""
+	}
+
+	m.Params = b.fields(reqType)
+
+	if desc.Response != nil {
+		m.Response = b.typeExpr(reflect.TypeOf(desc.Response))
+	}
+
+	return m, nil
+}
+
+func (b *specBuilder) fields(t reflect.Type) []Field {
+	var result []Field
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+
+		if jsonName == "-" {
+			continue
+		}
+
+		if f.Anonymous && len(jsonName) == 0 { // fields of embedded structs are promoted even when struct is unexported
+			if embedded := derefType(f.Type); embedded.Kind() == reflect.Struct {
+				result = append(result, b.fields(embedded)...)
+
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if len(jsonName) == 0 {
+			jsonName = f.Name
+		}
+
+		field := Field{Name: jsonName, Type: b.typeExpr(f.Type)}
+
+		if goName(field) != f.Name {
+			field.GoName = f.Name
+		}
+
+		result = append(result, field)
+	}
+
+	return result
+}
+
+func (b *specBuilder) typeExpr(t reflect.Type) string {
+	if len(t.Name()) > 0 {
+		if len(t.PkgPath()) == 0 {
+			return t.Name()
+		}
+
+		if !b.localPackages[t.PkgPath()] {
+			b.addImport(t)
+
+			return t.String()
+		}
+
+		if t.Kind() != reflect.Struct { // local named basic types, like enums, are replaced by underlying type
+			return b.underlyingExpr(t)
+		}
+
+		if !b.declared[t.Name()] {
+			b.declared[t.Name()] = true
+			b.spec.Types = append(b.spec.Types, Type{Name: t.Name()})
+
+			index := len(b.spec.Types) - 1
+			b.spec.Types[index].Fields = b.fields(t)
+		}
+
+		return t.Name()
+	}
+
+	return b.underlyingExpr(t)
+}
+
+func (b *specBuilder) underlyingExpr(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + b.typeExpr(t.Elem())
+	case reflect.Slice:
+		return "[]" + b.typeExpr(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%v]%v", t.Len(), b.typeExpr(t.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%v]%v", b.typeExpr(t.Key()), b.typeExpr(t.Elem()))
+	case reflect.Interface, reflect.Struct, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return "interface{}"
+	default:
+		return t.Kind().String()
+	}
+}
+
+func (b *specBuilder) addImport(t reflect.Type) {
+	name, _, _ := strings.Cut(t.String(), ".")
+
+	if parseImport(t.PkgPath()).alias == name {
+		b.imports[t.PkgPath()] = true
+	} else {
+		b.imports[name+" "+t.PkgPath()] = true
+	}
+}
@@ -0,0 +1,89 @@
+package wrappergen
+
+import (
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/go-common/swagger"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+	"strings"
+	"testing"
+)
+
+type paging struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+type GetUsersRequest struct {
+	paging
+	UserIDs  []int64 `json:"user_ids"`
+	internal string
+	Skip     string `json:"-"`
+}
+
+type userStatus int
+
+type User struct {
+	Id        int64      `json:"id"`
+	Status    userStatus `json:"status"`
+	Friends   []*User    `json:"friends"`
+	DeletedAt null.Time  `json:"deleted_at"`
+}
+
+type notifyRequest struct {
+	UserId int64 `json:"user_id"`
+}
+
+func TestSpecFromRouter(t *testing.T) {
+	r := router.NewRouter("", nil)
+	endpoint := r.GetRpcServiceEndpoint()
+
+	assert.Nil(t, endpoint.RegisterRpcCommand(router.NewTypedServiceCommand("InternalGetUsers",
+		func(request GetUsersRequest, executionData router.MethodExecutionData) (map[int64]User, *error_codes.ErrorWithCode) {
+			return nil, nil
+		}, false)))
+	assert.Nil(t, endpoint.RegisterRpcCommand(router.NewServiceCommand("notify",
+		func(request []byte, executionData router.MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			return nil, nil
+		}, false)))
+	assert.Nil(t, endpoint.RegisterRpcCommand(router.NewServiceCommand("untyped",
+		func(request []byte, executionData router.MethodExecutionData) (interface{}, *error_codes.ErrorWithCode) {
+			return nil, nil
+		}, false)))
+
+	spec, err := SpecFromRouter(r, map[string]swagger.ApiDescription{
+		"notify": {Request: notifyRequest{}, MethodDescription: "sends notification"},
+	}, endpoint, Spec{Package: "users", Name: "Users", DefaultUrl: "http://users"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"gopkg.in/guregu/null.v4"}, spec.Imports)
+	assert.Len(t, spec.Methods, 2)
+
+	getUsers := spec.Methods[0]
+	assert.Equal(t, "GetUsers", getUsers.Name)
+	assert.Equal(t, "internalgetusers", getUsers.RpcMethod) // router keeps method names lower cased
+	assert.Equal(t, "map[int64]User", getUsers.Response)
+	assert.Equal(t, []Field{
+		{Name: "limit", Type: "int"},
+		{Name: "offset", Type: "int"},
+		{Name: "user_ids", GoName: "UserIDs", Type: "[]int64"},
+	}, getUsers.Params)
+
+	notify := spec.Methods[1]
+	assert.Equal(t, "Notify", notify.Name)
+	assert.Equal(t, "notify", notify.RpcMethod)
+	assert.Equal(t, "sends notification", notify.Description)
+	assert.Empty(t, notify.Response)
+
+	assert.Equal(t, []Type{{Name: "User", Fields: []Field{
+		{Name: "id", Type: "int64"},
+		{Name: "status", Type: "int"},
+		{Name: "friends", Type: "[]*User"},
+		{Name: "deleted_at", Type: "null.Time"},
+	}}}, spec.Types)
+
+	files, err := Generate(spec)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(files[WrapperFileName]), "GetUsers(limit int, offset int, userIDs []int64"))
+}
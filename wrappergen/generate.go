@@ -0,0 +1,283 @@
+package wrappergen
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const generatedHeader = "// Code generated by wrappergen. DO NOT EDIT.\n\n"
+
+const (
+	WrapperFileName = "wrapper_gen.go"
+	MockFileName    = "mock_gen.go"
+	TypesFileName   = "types_gen.go"
+)
+
+var funcs = template.FuncMap{
+	"imports": func() string { return "" }, // replaced in render
+	"goName":  goName,
+	"argName": argName,
+	"request": requestTypeName,
+	"args": func(m Method) string {
+		var parts []string
+
+		for _, p := range m.Params {
+			parts = append(parts, fmt.Sprintf("%v %v", argName(p), p.Type))
+		}
+
+		return strings.Join(append(parts, "ctx context.Context", "forceLog bool"), ", ")
+	},
+	"argNames": func(m Method) string {
+		var parts []string
+
+		for _, p := range m.Params {
+			parts = append(parts, argName(p))
+		}
+
+		return strings.Join(append(parts, "ctx", "forceLog"), ", ")
+	},
+	"timeout": func(m Method) string {
+		if m.TimeoutSec > 0 {
+			return fmt.Sprintf("%v*time.Second", m.TimeoutSec)
+		}
+
+		return "w.defaultTimeout"
+	},
+	"comment": func(text string) string {
+		if len(text) == 0 {
+			return ""
+		}
+
+		return "// " + strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n// ") + "\n"
+	},
+}
+
+var wrapperTemplate = template.Must(template.New("wrapper").Funcs(funcs).Parse(`package {{.Package}}
+
+import (
+	"context"
+	"fmt"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"github.com/rs/zerolog/log"
+	"time"
+	{{imports}}
+)
+
+type I{{.Name}}Wrapper interface {
+{{- range .Methods}}
+	{{.Name}}({{args .}}) chan wrappers.GenericResponseChan[{{.Response}}]
+{{- end}}
+}
+
+type {{.Name}}Wrapper struct {
+	baseWrapper    *wrappers.BaseWrapper
+	defaultTimeout time.Duration
+	apiUrl         string
+	serviceName    string
+}
+
+func New{{.Name}}Wrapper(config boilerplate.WrapperConfig) I{{.Name}}Wrapper {
+	timeout := {{.TimeoutSec}} * time.Second
+
+	if config.TimeoutSec > 0 {
+		timeout = time.Duration(config.TimeoutSec) * time.Second
+	}
+
+	if len(config.ApiUrl) == 0 {
+		config.ApiUrl = "{{.DefaultUrl}}"
+
+		log.Warn().Msgf("Api Url is missing for {{.Name}}. Setting as default : %v", config.ApiUrl)
+	}
+
+	return &{{.Name}}Wrapper{
+		baseWrapper:    wrappers.GetBaseWrapper(),
+		defaultTimeout: timeout,
+		apiUrl:         fmt.Sprintf("%v/{{.Endpoint}}", common.StripSlashFromUrl(config.ApiUrl)),
+		serviceName:    "{{.ServiceName}}",
+	}
+}
+{{range .Methods}}
+{{comment .Description}}func (w *{{$.Name}}Wrapper) {{.Name}}({{args .}}) chan wrappers.GenericResponseChan[{{.Response}}] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[{{.Response}}](ctx, w.baseWrapper, w.apiUrl, "{{.RpcMethod}}",
+		{{request .}}{
+		{{- range .Params}}
+			{{goName .}}: {{argName .}},
+		{{- end}}
+		}, map[string]string{}, {{timeout .}}, w.serviceName, forceLog)
+}
+{{end}}`))
+
+var mockTemplate = template.Must(template.New("mock").Funcs(funcs).Parse(`package {{.Package}}
+
+import (
+	"context"
+	"github.com/digitalmonsters/go-common/wrappers"
+	{{imports}}
+)
+
+type {{.Name}}WrapperMock struct {
+{{- range .Methods}}
+	{{.Name}}Fn func({{args .}}) chan wrappers.GenericResponseChan[{{.Response}}]
+{{- end}}
+}
+{{range .Methods}}
+func (m *{{$.Name}}WrapperMock) {{.Name}}({{args .}}) chan wrappers.GenericResponseChan[{{.Response}}] {
+	return m.{{.Name}}Fn({{argNames .}})
+}
+{{end}}
+func GetMock() I{{.Name}}Wrapper { // for compiler errors
+	return &{{.Name}}WrapperMock{}
+}
+`))
+
+var typesTemplate = template.Must(template.New("types").Funcs(funcs).Parse(`package {{.Package}}
+{{with imports}}
+import (
+	{{.}}
+)
+{{end}}
+{{- range .Types}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{goName .}} {{.Type}} ` + "`" + `json:"{{.Name}}"` + "`" + `
+{{- end}}
+}
+{{end}}
+{{- range .Methods}}
+type {{request .}} struct {
+{{- range .Params}}
+	{{goName .}} {{.Type}} ` + "`" + `json:"{{.Name}}"` + "`" + `
+{{- end}}
+}
+{{end}}`))
+
+type importSpec struct {
+	alias    string
+	path     string
+	explicit bool
+}
+
+func parseImport(value string) importSpec {
+	value = strings.TrimSpace(value)
+
+	if alias, path, found := strings.Cut(value, " "); found {
+		return importSpec{alias: alias, path: strings.Trim(strings.TrimSpace(path), "\""), explicit: true}
+	}
+
+	path := strings.Trim(value, "\"")
+	name := path[strings.LastIndex(path, "/")+1:]
+
+	if versionSuffix := regexp.MustCompile(`\.v\d+$`); versionSuffix.MatchString(name) { // gopkg.in/guregu/null.v4
+		name = versionSuffix.ReplaceAllString(name, "")
+	}
+
+	return importSpec{alias: strings.ReplaceAll(name, "-", "_"), path: path}
+}
+
+// usedImports keeps spec imports referenced by the code, so generated files do not have unused imports
+func usedImports(spec Spec, code string) string {
+	var lines []string
+
+	for _, value := range spec.Imports {
+		imp := parseImport(value)
+
+		if !regexp.MustCompile(`\b` + regexp.QuoteMeta(imp.alias) + `\.`).MatchString(code) {
+			continue
+		}
+
+		if imp.explicit {
+			lines = append(lines, fmt.Sprintf("%v %q", imp.alias, imp.path))
+		} else {
+			lines = append(lines, fmt.Sprintf("%q", imp.path))
+		}
+	}
+
+	sort.Strings(lines)
+
+	return strings.Join(lines, "\n\t")
+}
+
+func render(tmpl *template.Template, spec Spec) ([]byte, error) {
+	execute := func(imports string) (string, error) {
+		var buf bytes.Buffer
+
+		t := template.Must(tmpl.Clone()).Funcs(template.FuncMap{"imports": func() string { return imports }})
+
+		if err := t.Execute(&buf, spec); err != nil {
+			return "", errors.WithStack(err)
+		}
+
+		return buf.String(), nil
+	}
+
+	code, err := execute("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if code, err = execute(usedImports(spec, code)); err != nil {
+		return nil, err
+	}
+
+	formatted, err := format.Source([]byte(generatedHeader + code))
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "can not format generated %v", tmpl.Name())
+	}
+
+	return formatted, nil
+}
+
+// Generate returns content of wrapper, mock and types files by their names
+func Generate(spec Spec) (map[string][]byte, error) {
+	spec = spec.withDefaults()
+
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := map[string][]byte{}
+
+	for name, tmpl := range map[string]*template.Template{
+		WrapperFileName: wrapperTemplate,
+		MockFileName:    mockTemplate,
+		TypesFileName:   typesTemplate,
+	} {
+		content, err := render(tmpl, spec)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result[name] = content
+	}
+
+	return result, nil
+}
+
+func GenerateToDir(spec Spec, dir string) error {
+	files, err := Generate(spec)
+
+	if err != nil {
+		return err
+	}
+
+	for name, content := range files {
+		if err = os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package wrappergen
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var testSpec = Spec{
+	Package:    "likes",
+	Name:       "Likes",
+	DefaultUrl: "http://likes",
+	Imports:    []string{"gopkg.in/guregu/null.v4", "github.com/shopspring/decimal"},
+	Types: []Type{{Name: "Like", Fields: []Field{
+		{Name: "user_id", Type: "int64"},
+		{Name: "created_at", Type: "null.Time"},
+	}}},
+	Methods: []Method{
+		{Name: "GetLikes", RpcMethod: "InternalGetLikes", Params: []Field{
+			{Name: "user_ids", Type: "[]int64"},
+			{Name: "type", Type: "string"},
+		}, Response: "map[int64][]Like"},
+		{Name: "Ping", TimeoutSec: 1},
+	},
+}
+
+func TestGenerate(t *testing.T) {
+	files, err := Generate(testSpec)
+
+	assert.Nil(t, err)
+	assert.Len(t, files, 3)
+
+	wrapper := string(files[WrapperFileName])
+	assert.True(t, strings.HasPrefix(wrapper, "// Code generated by wrappergen. DO NOT EDIT."))
+	assert.Contains(t, wrapper, "GetLikes(userIds []int64, typeValue string, ctx context.Context, forceLog bool) "+
+		"chan wrappers.GenericResponseChan[map[int64][]Like]")
+	assert.Contains(t, wrapper, `"InternalGetLikes"`)
+	assert.Contains(t, wrapper, "1*time.Second")
+	assert.Contains(t, wrapper, `serviceName:    "likes"`)
+	assert.Contains(t, wrapper, `fmt.Sprintf("%v/rpc-service", common.StripSlashFromUrl(config.ApiUrl))`)
+
+	mock := string(files[MockFileName])
+	assert.Contains(t, mock, "GetLikesFn func(userIds []int64, typeValue string")
+	assert.Contains(t, mock, "return m.PingFn(ctx, forceLog)")
+	assert.Contains(t, mock, "func GetMock() ILikesWrapper")
+
+	types := string(files[TypesFileName])
+	assert.Contains(t, types, `"gopkg.in/guregu/null.v4"`)
+	assert.NotContains(t, types, "decimal")
+	assert.Contains(t, types, "type GetLikesRequest struct")
+	assert.Contains(t, types, "`json:\"user_ids\"`")
+}
+
+func TestGenerateInvalidSpec(t *testing.T) {
+	_, err := Generate(Spec{Package: "likes", Name: "Likes"})
+	assert.NotNil(t, err)
+
+	spec := testSpec
+	spec.Types = []Type{{Name: "PingRequest"}}
+
+	_, err = Generate(spec)
+	assert.NotNil(t, err)
+}
+
+func TestArgName(t *testing.T) {
+	assert.Equal(t, "userId", argName(Field{Name: "user_id"}))
+	assert.Equal(t, "typeValue", argName(Field{Name: "type"}))
+	assert.Equal(t, "userID", argName(Field{Name: "user_id", GoName: "UserID"}))
+}
@@ -0,0 +1,203 @@
+// Package wrappergen generates wrapper client, mock and request/response types of a service from a json spec.
+// Spec is either written by hand or produced by the service itself with SpecFromRouter.
+//
+// Usage in a wrapper package:
+//
+//	//go:generate go run github.com/digitalmonsters/go-common/cmd/wrappergen -spec wrapper.json
+package wrappergen
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"os"
+	"strings"
+)
+
+const (
+	defaultEndpoint   = "rpc-service"
+	defaultTimeoutSec = 5
+)
+
+type Spec struct {
+	Package     string `json:"package"`
+	Name        string `json:"name"`
+	ServiceName string `json:"service_name"`
+	DefaultUrl  string `json:"default_url"`
+	// Endpoint is "rpc-service" by default
+	Endpoint   string `json:"endpoint"`
+	TimeoutSec int    `json:"timeout_sec"`
+	// Imports used by field types, "path" or "alias path"
+	Imports []string `json:"imports,omitempty"`
+	Types   []Type   `json:"types,omitempty"`
+	Methods []Method `json:"methods"`
+}
+
+type Type struct {
+	Name   string  `json:"name"`
+	Fields []Field `json:"fields"`
+}
+
+// Field Name is json name, GoName is built from it when empty
+type Field struct {
+	Name   string `json:"name"`
+	GoName string `json:"go_name,omitempty"`
+	Type   string `json:"type"`
+}
+
+type Method struct {
+	Name string `json:"name"`
+	// RpcMethod is Name by default
+	RpcMethod   string `json:"rpc_method,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Params are fields of the request, they become method arguments in the same order
+	Params []Field `json:"params,omitempty"`
+	// Response is go type of the result
+	Response   string `json:"response"`
+	TimeoutSec int    `json:"timeout_sec,omitempty"`
+}
+
+func LoadSpec(path string) (Spec, error) {
+	var spec Spec
+
+	b, err := os.ReadFile(path)
+
+	if err != nil {
+		return spec, errors.WithStack(err)
+	}
+
+	if err = json.Unmarshal(b, &spec); err != nil {
+		return spec, errors.Wrapf(err, "can not parse spec %v", path)
+	}
+
+	return spec, nil
+}
+
+func WriteSpec(path string, spec Spec) error {
+	b, err := json.MarshalIndent(spec, "", "  ")
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.WriteFile(path, append(b, '\n'), 0644))
+}
+
+func (s Spec) withDefaults() Spec {
+	if len(s.Endpoint) == 0 {
+		s.Endpoint = defaultEndpoint
+	}
+
+	if s.TimeoutSec <= 0 {
+		s.TimeoutSec = defaultTimeoutSec
+	}
+
+	if len(s.ServiceName) == 0 {
+		s.ServiceName = s.Package
+	}
+
+	s.Methods = append([]Method{}, s.Methods...)
+
+	for i, m := range s.Methods {
+		if len(m.RpcMethod) == 0 {
+			s.Methods[i].RpcMethod = m.Name
+		}
+
+		if len(m.Response) == 0 {
+			s.Methods[i].Response = "interface{}"
+		}
+	}
+
+	return s
+}
+
+func (s Spec) Validate() error {
+	if len(s.Package) == 0 || len(s.Name) == 0 {
+		return errors.New("package and name are required")
+	}
+
+	if len(s.DefaultUrl) == 0 {
+		return errors.New("default_url is required")
+	}
+
+	names := map[string]bool{}
+
+	for _, t := range s.Types {
+		if names[t.Name] {
+			return errors.New(fmt.Sprintf("type [%v] is declared twice", t.Name))
+		}
+
+		if err := validateFields(t.Name, t.Fields); err != nil {
+			return err
+		}
+
+		names[t.Name] = true
+	}
+
+	for _, m := range s.Methods {
+		if len(m.Name) == 0 {
+			return errors.New("method name is required")
+		}
+
+		if err := validateFields(m.Name, m.Params); err != nil {
+			return err
+		}
+
+		if names[requestTypeName(m)] {
+			return errors.New(fmt.Sprintf("type [%v] is declared twice", requestTypeName(m)))
+		}
+
+		names[requestTypeName(m)] = true
+	}
+
+	return nil
+}
+
+func validateFields(owner string, fields []Field) error {
+	for _, f := range fields {
+		if len(goName(f)) == 0 || len(f.Type) == 0 {
+			return errors.New(fmt.Sprintf("field of [%v] should have name and type", owner))
+		}
+	}
+
+	return nil
+}
+
+func requestTypeName(m Method) string {
+	return m.Name + "Request"
+}
+
+// reservedNames can not be used as arguments of generated methods, they are keywords, receivers and packages
+var reservedNames = map[string]bool{"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true, "range": true,
+	"return": true, "select": true, "struct": true, "switch": true, "type": true, "var": true,
+	"ctx": true, "forceLog": true, "w": true, "m": true, "context": true, "wrappers": true, "time": true}
+
+// goName converts json name to exported go name the same way as the hand written wrappers, "user_id" -> "UserId"
+func goName(f Field) string {
+	if len(f.GoName) > 0 {
+		return f.GoName
+	}
+
+	var b strings.Builder
+
+	for _, part := range strings.FieldsFunc(f.Name, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || r == ' '
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+
+	return b.String()
+}
+
+func argName(f Field) string {
+	name := goName(f)
+	name = strings.ToLower(name[:1]) + name[1:]
+
+	if reservedNames[name] {
+		name += "Value"
+	}
+
+	return name
+}
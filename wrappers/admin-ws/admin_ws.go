@@ -1,6 +1,7 @@
 package admin_ws
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/digitalmonsters/go-common/boilerplate"
@@ -17,6 +18,14 @@ type IAdminWsWrapper interface {
 	SendMessage(event EventType, message interface{}, transaction *apm.Transaction, forceLog bool) chan SendMessageResponseCh
 }
 
+// IAdminWsWrapperWithContext has context-first versions of IAdminWsWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IAdminWsWrapper implementation
+type IAdminWsWrapperWithContext interface {
+	IAdminWsWrapper
+
+	SendMessageWithContext(event EventType, message interface{}, ctx context.Context, forceLog bool) chan SendMessageResponseCh
+}
+
 type AdminWsWrapper struct {
 	defaultTimeout time.Duration
 	apiUrl         string
@@ -24,7 +33,7 @@ type AdminWsWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
 }
 
-func NewAdminWsWrapper(config boilerplate.WrapperConfig) IAdminWsWrapper {
+func NewAdminWsWrapper(config boilerplate.WrapperConfig) IAdminWsWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -45,7 +54,13 @@ func NewAdminWsWrapper(config boilerplate.WrapperConfig) IAdminWsWrapper {
 	}
 }
 
+// Deprecated: use SendMessageWithContext
 func (w *AdminWsWrapper) SendMessage(event EventType, message interface{}, transaction *apm.Transaction,
+	forceLog bool) chan SendMessageResponseCh {
+	return w.SendMessageWithContext(event, message, apm.ContextWithTransaction(context.Background(), transaction), forceLog)
+}
+
+func (w *AdminWsWrapper) SendMessageWithContext(event EventType, message interface{}, ctx context.Context,
 	forceLog bool) chan SendMessageResponseCh {
 	respCh := make(chan SendMessageResponseCh, 2)
 
@@ -65,10 +80,10 @@ func (w *AdminWsWrapper) SendMessage(event EventType, message interface{}, trans
 		return respCh
 	}
 
-	rpcInternalResponseCh := w.baseWrapper.SendRpcRequest(w.apiUrl, "SendMessage", SendMessageRequest{
+	rpcInternalResponseCh := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "SendMessage", SendMessageRequest{
 		Event:   event,
 		Message: messageMarshaled,
-	}, map[string]string{}, w.defaultTimeout, transaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		resp := <-rpcInternalResponseCh
@@ -80,3 +95,21 @@ func (w *AdminWsWrapper) SendMessage(event EventType, message interface{}, trans
 
 	return respCh
 }
+
+// WithContext returns w when it already implements IAdminWsWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IAdminWsWrapper) IAdminWsWrapperWithContext {
+	if c, ok := w.(IAdminWsWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IAdminWsWrapper: w}
+}
+
+type contextAdapter struct {
+	IAdminWsWrapper
+}
+
+func (a contextAdapter) SendMessageWithContext(event EventType, message interface{}, ctx context.Context, forceLog bool) chan SendMessageResponseCh {
+	return a.IAdminWsWrapper.SendMessage(event, message, apm.TransactionFromContext(ctx), forceLog)
+}
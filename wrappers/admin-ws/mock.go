@@ -1,17 +1,27 @@
 package admin_ws
 
 import (
+	"context"
 	"go.elastic.co/apm"
 )
 
 type AdminWsWrapperMock struct {
-	SendMessageFn func(event EventType, message interface{}, transaction *apm.Transaction, forceLog bool) chan SendMessageResponseCh
+	SendMessageFn            func(event EventType, message interface{}, transaction *apm.Transaction, forceLog bool) chan SendMessageResponseCh
+	SendMessageWithContextFn func(event EventType, message interface{}, ctx context.Context, forceLog bool) chan SendMessageResponseCh
 }
 
 func (w *AdminWsWrapperMock) SendMessage(event EventType, message interface{}, transaction *apm.Transaction, forceLog bool) chan SendMessageResponseCh {
 	return w.SendMessageFn(event, message, transaction, forceLog)
 }
 
-func GetMock() IAdminWsWrapper { // for compiler errors
+func (w *AdminWsWrapperMock) SendMessageWithContext(event EventType, message interface{}, ctx context.Context, forceLog bool) chan SendMessageResponseCh {
+	if w.SendMessageWithContextFn != nil {
+		return w.SendMessageWithContextFn(event, message, ctx, forceLog)
+	}
+
+	return w.SendMessageFn(event, message, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func GetMock() IAdminWsWrapperWithContext { // for compiler errors
 	return &AdminWsWrapperMock{}
 }
@@ -7,7 +7,6 @@ import (
 	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/go-common/wrappers"
 	"github.com/rs/zerolog/log"
-	"go.elastic.co/apm"
 	"time"
 )
 
@@ -47,10 +46,10 @@ func NewAdsManagerWrapper(config boilerplate.WrapperConfig) IAdsManagerWrapper {
 
 func (w *AdsManagerWrapper) GetAdsContentForUser(userId int64, contentIdsToMix []int64, contentIdsToIgnore []int64,
 	ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetAdsContentForUserResponse] {
-	return wrappers.ExecuteRpcRequestAsync[GetAdsContentForUserResponse](w.baseWrapper, w.apiUrl, "GetAdsContentForUser",
+	return wrappers.ExecuteRpcRequestAsyncWithContext[GetAdsContentForUserResponse](ctx, w.baseWrapper, w.apiUrl, "GetAdsContentForUser",
 		GetAdsContentForUserRequest{
 			UserId:             userId,
 			ContentIdsToMix:    contentIdsToMix,
 			ContentIdsToIgnore: contentIdsToIgnore,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
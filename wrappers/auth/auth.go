@@ -25,6 +25,16 @@ type IAuthWrapper interface {
 		forceLog bool) chan GenerateTokenResponseChan
 }
 
+// IAuthWrapperWithContext has context-first versions of IAuthWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IAuthWrapper implementation
+type IAuthWrapperWithContext interface {
+	IAuthWrapper
+
+	ParseTokenWithContext(token string, ignoreExpiration bool, ctx context.Context, forceLog bool) chan AuthParseTokenResponseChan
+	ParseNewAdminTokenWithContext(token string, ignoreExpiration bool, ctx context.Context, forceLog bool) chan AuthParseTokenResponseChan
+	GenerateTokenWithContext(userId int64, isGuest bool, meta MetaData, ctx context.Context, forceLog bool) chan GenerateTokenResponseChan
+}
+
 type AuthWrapper struct {
 	defaultTimeout time.Duration
 	apiUrl         string
@@ -32,7 +42,7 @@ type AuthWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
 }
 
-func NewAuthWrapper(config boilerplate.WrapperConfig) IAuthWrapper {
+func NewAuthWrapper(config boilerplate.WrapperConfig) IAuthWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -53,17 +63,23 @@ func NewAuthWrapper(config boilerplate.WrapperConfig) IAuthWrapper {
 	}
 }
 
+// Deprecated: use ParseTokenWithContext
 func (w *AuthWrapper) ParseToken(token string, ignoreExpiration bool, apmTransaction *apm.Transaction,
+	forceLog bool) chan AuthParseTokenResponseChan {
+	return w.ParseTokenWithContext(token, ignoreExpiration, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *AuthWrapper) ParseTokenWithContext(token string, ignoreExpiration bool, ctx context.Context,
 	forceLog bool) chan AuthParseTokenResponseChan {
 	resChan := make(chan AuthParseTokenResponseChan, 2)
 
 	go func() {
-		rpcInternalResponse := <-w.baseWrapper.SendRequestWithRpcResponse(fmt.Sprintf("%v/token/parse", w.apiUrl),
+		rpcInternalResponse := <-w.baseWrapper.SendRequestWithRpcResponseWithContext(ctx, fmt.Sprintf("%v/token/parse", w.apiUrl),
 			"unpack jwt",
 			AuthParseTokenRequest{
 				Token:            token,
 				IgnoreExpiration: ignoreExpiration,
-			}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+			}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 		finalResponse := AuthParseTokenResponseChan{
 			Error: rpcInternalResponse.Error,
@@ -87,17 +103,23 @@ func (w *AuthWrapper) ParseToken(token string, ignoreExpiration bool, apmTransac
 	return resChan
 }
 
+// Deprecated: use ParseNewAdminTokenWithContext
 func (w *AuthWrapper) ParseNewAdminToken(token string, ignoreExpiration bool, apmTransaction *apm.Transaction,
+	forceLog bool) chan AuthParseTokenResponseChan {
+	return w.ParseNewAdminTokenWithContext(token, ignoreExpiration, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *AuthWrapper) ParseNewAdminTokenWithContext(token string, ignoreExpiration bool, ctx context.Context,
 	forceLog bool) chan AuthParseTokenResponseChan {
 	resChan := make(chan AuthParseTokenResponseChan, 2)
 
 	go func() {
-		rpcInternalResponse := <-w.baseWrapper.SendRequestWithRpcResponse(fmt.Sprintf("%v/token-admin/parse", w.apiUrl),
+		rpcInternalResponse := <-w.baseWrapper.SendRequestWithRpcResponseWithContext(ctx, fmt.Sprintf("%v/token-admin/parse", w.apiUrl),
 			"unpack new admin jwt",
 			AuthParseTokenRequest{
 				Token:            token,
 				IgnoreExpiration: ignoreExpiration,
-			}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+			}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 		finalResponse := AuthParseTokenResponseChan{
 			Error: rpcInternalResponse.Error,
@@ -121,7 +143,13 @@ func (w *AuthWrapper) ParseNewAdminToken(token string, ignoreExpiration bool, ap
 	return resChan
 }
 
+// Deprecated: use GenerateTokenWithContext
 func (w *AuthWrapper) GenerateToken(userId int64, isGuest bool, meta MetaData, apmTransaction *apm.Transaction,
+	forceLog bool) chan GenerateTokenResponseChan {
+	return w.GenerateTokenWithContext(userId, isGuest, meta, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *AuthWrapper) GenerateTokenWithContext(userId int64, isGuest bool, meta MetaData, ctx context.Context,
 	forceLog bool) chan GenerateTokenResponseChan {
 	resChan := make(chan GenerateTokenResponseChan, 2)
 
@@ -145,11 +173,11 @@ func (w *AuthWrapper) GenerateToken(userId int64, isGuest bool, meta MetaData, a
 			i++
 		}
 
-		rpcInternalResponse := <-w.baseWrapper.SendRequestWithRpcResponseFromAnyService(link,
+		rpcInternalResponse := <-w.baseWrapper.SendRequestWithRpcResponseFromAnyServiceWithContext(ctx, link,
 			"GET",
 			"application/json",
 			"generate token",
-			meta, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+			meta, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 		finalResponse := GenerateTokenResponseChan{
 			Error: rpcInternalResponse.Error,
@@ -205,3 +233,29 @@ func (w *AuthWrapper) GenerateNewAdminToken(userId int64, ctx context.Context,
 
 	return resChan
 }
+
+// WithContext returns w when it already implements IAuthWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IAuthWrapper) IAuthWrapperWithContext {
+	if c, ok := w.(IAuthWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IAuthWrapper: w}
+}
+
+type contextAdapter struct {
+	IAuthWrapper
+}
+
+func (a contextAdapter) ParseTokenWithContext(token string, ignoreExpiration bool, ctx context.Context, forceLog bool) chan AuthParseTokenResponseChan {
+	return a.IAuthWrapper.ParseToken(token, ignoreExpiration, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) ParseNewAdminTokenWithContext(token string, ignoreExpiration bool, ctx context.Context, forceLog bool) chan AuthParseTokenResponseChan {
+	return a.IAuthWrapper.ParseNewAdminToken(token, ignoreExpiration, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GenerateTokenWithContext(userId int64, isGuest bool, meta MetaData, ctx context.Context, forceLog bool) chan GenerateTokenResponseChan {
+	return a.IAuthWrapper.GenerateToken(userId, isGuest, meta, apm.TransactionFromContext(ctx), forceLog)
+}
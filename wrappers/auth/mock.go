@@ -8,10 +8,13 @@ import (
 type AuthWrapperMock struct {
 	ParseTokenFn func(token string, ignoreExpiration bool, apmTransaction *apm.Transaction,
 		forceLog bool) chan AuthParseTokenResponseChan
-	ParseNewAdminTokenFn func(token string, ignoreExpiration bool, apmTransaction *apm.Transaction,
+	ParseTokenWithContextFn func(token string, ignoreExpiration bool, ctx context.Context, forceLog bool) chan AuthParseTokenResponseChan
+	ParseNewAdminTokenFn    func(token string, ignoreExpiration bool, apmTransaction *apm.Transaction,
 		forceLog bool) chan AuthParseTokenResponseChan
-	GenerateTokenFn         func(userId int64, isGuest bool, meta MetaData, apmTransaction *apm.Transaction, forceLog bool) chan GenerateTokenResponseChan
-	GenerateNewAdminTokenFn func(userId int64, ctx context.Context, forceLog bool) chan GenerateTokenResponseChan
+	ParseNewAdminTokenWithContextFn func(token string, ignoreExpiration bool, ctx context.Context, forceLog bool) chan AuthParseTokenResponseChan
+	GenerateTokenFn                 func(userId int64, isGuest bool, meta MetaData, apmTransaction *apm.Transaction, forceLog bool) chan GenerateTokenResponseChan
+	GenerateTokenWithContextFn      func(userId int64, isGuest bool, meta MetaData, ctx context.Context, forceLog bool) chan GenerateTokenResponseChan
+	GenerateNewAdminTokenFn         func(userId int64, ctx context.Context, forceLog bool) chan GenerateTokenResponseChan
 }
 
 func (w *AuthWrapperMock) ParseToken(token string, ignoreExpiration bool, apmTransaction *apm.Transaction,
@@ -19,21 +22,45 @@ func (w *AuthWrapperMock) ParseToken(token string, ignoreExpiration bool, apmTra
 	return w.ParseTokenFn(token, ignoreExpiration, apmTransaction, forceLog)
 }
 
+func (w *AuthWrapperMock) ParseTokenWithContext(token string, ignoreExpiration bool, ctx context.Context, forceLog bool) chan AuthParseTokenResponseChan {
+	if w.ParseTokenWithContextFn != nil {
+		return w.ParseTokenWithContextFn(token, ignoreExpiration, ctx, forceLog)
+	}
+
+	return w.ParseTokenFn(token, ignoreExpiration, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *AuthWrapperMock) ParseNewAdminToken(token string, ignoreExpiration bool, apmTransaction *apm.Transaction,
 	forceLog bool) chan AuthParseTokenResponseChan {
 	return w.ParseNewAdminTokenFn(token, ignoreExpiration, apmTransaction, forceLog)
 }
 
+func (w *AuthWrapperMock) ParseNewAdminTokenWithContext(token string, ignoreExpiration bool, ctx context.Context, forceLog bool) chan AuthParseTokenResponseChan {
+	if w.ParseNewAdminTokenWithContextFn != nil {
+		return w.ParseNewAdminTokenWithContextFn(token, ignoreExpiration, ctx, forceLog)
+	}
+
+	return w.ParseNewAdminTokenFn(token, ignoreExpiration, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *AuthWrapperMock) GenerateToken(userId int64, isGuest bool, meta MetaData, apmTransaction *apm.Transaction,
 	forceLog bool) chan GenerateTokenResponseChan {
 	return w.GenerateTokenFn(userId, isGuest, meta, apmTransaction, forceLog)
 }
 
+func (w *AuthWrapperMock) GenerateTokenWithContext(userId int64, isGuest bool, meta MetaData, ctx context.Context, forceLog bool) chan GenerateTokenResponseChan {
+	if w.GenerateTokenWithContextFn != nil {
+		return w.GenerateTokenWithContextFn(userId, isGuest, meta, ctx, forceLog)
+	}
+
+	return w.GenerateTokenFn(userId, isGuest, meta, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *AuthWrapperMock) GenerateNewAdminToken(userId int64, ctx context.Context,
 	forceLog bool) chan GenerateTokenResponseChan {
 	return w.GenerateNewAdminTokenFn(userId, ctx, forceLog)
 }
 
-func GetMock() IAuthWrapper { // for compiler errors
+func GetMock() IAuthWrapperWithContext { // for compiler errors
 	return &AuthWrapperMock{}
 }
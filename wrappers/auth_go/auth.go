@@ -24,6 +24,21 @@ type IAuthGoWrapper interface {
 	IsGuest(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[IsGuestResponse]
 	GetUsersRegistrationType(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SocialProviderType]
 	InternalGetUsersForValidation(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserForValidator]
+	InternalGetAdminPermissions(adminId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AdminPermissions]
+}
+
+// IAuthGoWrapperWithContext has context-first versions of IAuthGoWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IAuthGoWrapper implementation
+type IAuthGoWrapperWithContext interface {
+	IAuthGoWrapper
+
+	CheckAdminPermissionsWithContext(userId int64, obj string, ctx context.Context, forceLog bool) chan CheckAdminPermissionsResponseChan
+	CheckLegacyAdminWithContext(userId int64, ctx context.Context, forceLog bool) chan CheckLegacyAdminResponseChan
+	GetAdminIdsFilterByEmailWithContext(adminIds []int64, searchQuery string, ctx context.Context, forceLog bool) chan GetAdminIdsFilterByEmailResponseChan
+	GetAdminsInfoByIdWithContext(adminIds []int64, ctx context.Context, forceLog bool) chan GetAdminsInfoByIdResponseChan
+	AddNewUserWithContext(req eventsourcing.UserEvent, ctx context.Context, forceLog bool) chan AddUserResponseChan
+	IsGuestWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[IsGuestResponse]
+	GetUsersRegistrationTypeWithContext(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SocialProviderType]
 }
 
 type AuthGoWrapper struct {
@@ -33,7 +48,7 @@ type AuthGoWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
 }
 
-func NewAuthGoWrapper(config boilerplate.WrapperConfig) IAuthGoWrapper {
+func NewAuthGoWrapper(config boilerplate.WrapperConfig) IAuthGoWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -54,17 +69,27 @@ func NewAuthGoWrapper(config boilerplate.WrapperConfig) IAuthGoWrapper {
 	}
 }
 
+// Deprecated: use IsGuestWithContext
 func (u AuthGoWrapper) IsGuest(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[IsGuestResponse] {
-	return wrappers.ExecuteRpcRequestAsync[IsGuestResponse](u.baseWrapper, u.apiUrl, "IsGuest", IsGuestRequest{
+	return u.IsGuestWithContext(userId, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (u AuthGoWrapper) IsGuestWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[IsGuestResponse] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[IsGuestResponse](ctx, u.baseWrapper, u.apiUrl, "IsGuest", IsGuestRequest{
 		UserId: userId,
-	}, map[string]string{}, u.defaultTimeout, apmTransaction, u.serviceName, forceLog)
+	}, map[string]string{}, u.defaultTimeout, u.serviceName, forceLog)
 }
 
+// Deprecated: use AddNewUserWithContext
 func (u AuthGoWrapper) AddNewUser(req eventsourcing.UserEvent, apmTransaction *apm.Transaction, forceLog bool) chan AddUserResponseChan {
+	return u.AddNewUserWithContext(req, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (u AuthGoWrapper) AddNewUserWithContext(req eventsourcing.UserEvent, ctx context.Context, forceLog bool) chan AddUserResponseChan {
 	respCh := make(chan AddUserResponseChan, 2)
 
-	respChan := u.baseWrapper.SendRpcRequest(u.apiUrl, "AddNewUser",
-		req, map[string]string{}, u.defaultTimeout, apmTransaction, u.serviceName, forceLog)
+	respChan := u.baseWrapper.SendRpcRequestWithContext(ctx, u.apiUrl, "AddNewUser",
+		req, map[string]string{}, u.defaultTimeout, u.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -99,12 +124,17 @@ func (u AuthGoWrapper) AddNewUser(req eventsourcing.UserEvent, apmTransaction *a
 	return respCh
 }
 
+// Deprecated: use CheckLegacyAdminWithContext
 func (u *AuthGoWrapper) CheckLegacyAdmin(userId int64, transaction *apm.Transaction, forceLog bool) chan CheckLegacyAdminResponseChan {
+	return u.CheckLegacyAdminWithContext(userId, apm.ContextWithTransaction(context.Background(), transaction), forceLog)
+}
+
+func (u *AuthGoWrapper) CheckLegacyAdminWithContext(userId int64, ctx context.Context, forceLog bool) chan CheckLegacyAdminResponseChan {
 	respCh := make(chan CheckLegacyAdminResponseChan, 2)
 
-	rpcInternalResponseCh := u.baseWrapper.SendRpcRequest(u.apiUrl, "CheckLegacyAdmin", CheckLegacyAdminRequest{
+	rpcInternalResponseCh := u.baseWrapper.SendRpcRequestWithContext(ctx, u.apiUrl, "CheckLegacyAdmin", CheckLegacyAdminRequest{
 		UserId: userId,
-	}, map[string]string{}, u.defaultTimeout, transaction, u.serviceName, forceLog)
+	}, map[string]string{}, u.defaultTimeout, u.serviceName, forceLog)
 
 	go func() {
 		resp := <-rpcInternalResponseCh
@@ -130,14 +160,20 @@ func (u *AuthGoWrapper) CheckLegacyAdmin(userId int64, transaction *apm.Transact
 	return respCh
 }
 
+// Deprecated: use CheckAdminPermissionsWithContext
 func (u *AuthGoWrapper) CheckAdminPermissions(userId int64, obj string, transaction *apm.Transaction,
+	forceLog bool) chan CheckAdminPermissionsResponseChan {
+	return u.CheckAdminPermissionsWithContext(userId, obj, apm.ContextWithTransaction(context.Background(), transaction), forceLog)
+}
+
+func (u *AuthGoWrapper) CheckAdminPermissionsWithContext(userId int64, obj string, ctx context.Context,
 	forceLog bool) chan CheckAdminPermissionsResponseChan {
 	respCh := make(chan CheckAdminPermissionsResponseChan, 2)
 
-	rpcInternalResponseCh := u.baseWrapper.SendRpcRequest(u.apiUrl, "CheckUserAdminPermissions", CheckAdminPermissionsRequest{
+	rpcInternalResponseCh := u.baseWrapper.SendRpcRequestWithContext(ctx, u.apiUrl, "CheckUserAdminPermissions", CheckAdminPermissionsRequest{
 		UserId: userId,
 		Object: obj,
-	}, map[string]string{}, u.defaultTimeout, transaction, u.serviceName, forceLog)
+	}, map[string]string{}, u.defaultTimeout, u.serviceName, forceLog)
 
 	go func() {
 
@@ -165,13 +201,18 @@ func (u *AuthGoWrapper) CheckAdminPermissions(userId int64, obj string, transact
 	return respCh
 }
 
+// Deprecated: use GetAdminIdsFilterByEmailWithContext
 func (u AuthGoWrapper) GetAdminIdsFilterByEmail(adminIds []int64, searchQuery string, apmTransaction *apm.Transaction, forceLog bool) chan GetAdminIdsFilterByEmailResponseChan {
+	return u.GetAdminIdsFilterByEmailWithContext(adminIds, searchQuery, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (u AuthGoWrapper) GetAdminIdsFilterByEmailWithContext(adminIds []int64, searchQuery string, ctx context.Context, forceLog bool) chan GetAdminIdsFilterByEmailResponseChan {
 	respCh := make(chan GetAdminIdsFilterByEmailResponseChan, 2)
 
-	respChan := u.baseWrapper.SendRpcRequest(u.apiUrl, "GetAdminIdsFilterByEmail", GetAdminIdsFilterByEmailRequest{
+	respChan := u.baseWrapper.SendRpcRequestWithContext(ctx, u.apiUrl, "GetAdminIdsFilterByEmail", GetAdminIdsFilterByEmailRequest{
 		AdminIds:    adminIds,
 		SearchQuery: searchQuery,
-	}, map[string]string{}, u.defaultTimeout, apmTransaction, u.serviceName, forceLog)
+	}, map[string]string{}, u.defaultTimeout, u.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -206,12 +247,17 @@ func (u AuthGoWrapper) GetAdminIdsFilterByEmail(adminIds []int64, searchQuery st
 	return respCh
 }
 
+// Deprecated: use GetAdminsInfoByIdWithContext
 func (u AuthGoWrapper) GetAdminsInfoById(adminIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetAdminsInfoByIdResponseChan {
+	return u.GetAdminsInfoByIdWithContext(adminIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (u AuthGoWrapper) GetAdminsInfoByIdWithContext(adminIds []int64, ctx context.Context, forceLog bool) chan GetAdminsInfoByIdResponseChan {
 	respCh := make(chan GetAdminsInfoByIdResponseChan, 2)
 
-	respChan := u.baseWrapper.SendRpcRequest(u.apiUrl, "GetAdminsInfoById", GetAdminsInfoByIdRequest{
+	respChan := u.baseWrapper.SendRpcRequestWithContext(ctx, u.apiUrl, "GetAdminsInfoById", GetAdminsInfoByIdRequest{
 		AdminIds: adminIds,
-	}, map[string]string{}, u.defaultTimeout, apmTransaction, u.serviceName, forceLog)
+	}, map[string]string{}, u.defaultTimeout, u.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -246,15 +292,69 @@ func (u AuthGoWrapper) GetAdminsInfoById(adminIds []int64, apmTransaction *apm.T
 	return respCh
 }
 
+// Deprecated: use GetUsersRegistrationTypeWithContext
 func (u AuthGoWrapper) GetUsersRegistrationType(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SocialProviderType] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]SocialProviderType](u.baseWrapper, u.apiUrl, "GetUsersRegistrationType", GetUsersRegistrationTypeRequest{
+	return u.GetUsersRegistrationTypeWithContext(userIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (u AuthGoWrapper) GetUsersRegistrationTypeWithContext(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SocialProviderType] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]SocialProviderType](ctx, u.baseWrapper, u.apiUrl, "GetUsersRegistrationType", GetUsersRegistrationTypeRequest{
 		UserIds: userIds,
-	}, map[string]string{}, u.defaultTimeout, apmTransaction, u.serviceName, forceLog)
+	}, map[string]string{}, u.defaultTimeout, u.serviceName, forceLog)
 }
 
 func (u AuthGoWrapper) InternalGetUsersForValidation(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserForValidator] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]UserForValidator](u.baseWrapper,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]UserForValidator](ctx, u.baseWrapper,
 		u.apiUrl, "InternalGetUsersForValidation", InternalGetUsersForValidatorFromCacheRequest{
 			UserIds: userIds,
-		}, map[string]string{}, 5*time.Second, apm.TransactionFromContext(ctx), u.serviceName, forceLog)
+		}, map[string]string{}, 5*time.Second, u.serviceName, forceLog)
+}
+
+func (u AuthGoWrapper) InternalGetAdminPermissions(adminId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AdminPermissions] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[AdminPermissions](ctx, u.baseWrapper,
+		u.apiUrl, "InternalGetAdminPermissions", GetAdminPermissionsRequest{
+			AdminId: adminId,
+		}, map[string]string{}, u.defaultTimeout, u.serviceName, forceLog)
+}
+
+// WithContext returns w when it already implements IAuthGoWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IAuthGoWrapper) IAuthGoWrapperWithContext {
+	if c, ok := w.(IAuthGoWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IAuthGoWrapper: w}
+}
+
+type contextAdapter struct {
+	IAuthGoWrapper
+}
+
+func (a contextAdapter) CheckAdminPermissionsWithContext(userId int64, obj string, ctx context.Context, forceLog bool) chan CheckAdminPermissionsResponseChan {
+	return a.IAuthGoWrapper.CheckAdminPermissions(userId, obj, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) CheckLegacyAdminWithContext(userId int64, ctx context.Context, forceLog bool) chan CheckLegacyAdminResponseChan {
+	return a.IAuthGoWrapper.CheckLegacyAdmin(userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetAdminIdsFilterByEmailWithContext(adminIds []int64, searchQuery string, ctx context.Context, forceLog bool) chan GetAdminIdsFilterByEmailResponseChan {
+	return a.IAuthGoWrapper.GetAdminIdsFilterByEmail(adminIds, searchQuery, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetAdminsInfoByIdWithContext(adminIds []int64, ctx context.Context, forceLog bool) chan GetAdminsInfoByIdResponseChan {
+	return a.IAuthGoWrapper.GetAdminsInfoById(adminIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) AddNewUserWithContext(req eventsourcing.UserEvent, ctx context.Context, forceLog bool) chan AddUserResponseChan {
+	return a.IAuthGoWrapper.AddNewUser(req, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) IsGuestWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[IsGuestResponse] {
+	return a.IAuthGoWrapper.IsGuest(userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetUsersRegistrationTypeWithContext(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SocialProviderType] {
+	return a.IAuthGoWrapper.GetUsersRegistrationType(userIds, apm.TransactionFromContext(ctx), forceLog)
 }
@@ -8,48 +8,116 @@ import (
 )
 
 type AuthGoWrapperMock struct {
-	CheckAdminPermissionsFn         func(userId int64, obj string, transaction *apm.Transaction, forceLog bool) chan CheckAdminPermissionsResponseChan
-	CheckLegacyAdminFn              func(userId int64, transaction *apm.Transaction, forceLog bool) chan CheckLegacyAdminResponseChan
-	GetAdminIdsFilterByEmailFn      func(adminIds []int64, searchQuery string, apmTransaction *apm.Transaction, forceLog bool) chan GetAdminIdsFilterByEmailResponseChan
-	GetAdminsInfoByIdFn             func(adminIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetAdminsInfoByIdResponseChan
-	AddNewUserFn                    func(req eventsourcing.UserEvent, apmTransaction *apm.Transaction, forceLog bool) chan AddUserResponseChan
-	IsGuestFn                       func(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[IsGuestResponse]
-	GetUsersRegistrationTypeFn      func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SocialProviderType]
-	InternalGetUsersForValidationFn func(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserForValidator]
+	CheckAdminPermissionsFn               func(userId int64, obj string, transaction *apm.Transaction, forceLog bool) chan CheckAdminPermissionsResponseChan
+	CheckAdminPermissionsWithContextFn    func(userId int64, obj string, ctx context.Context, forceLog bool) chan CheckAdminPermissionsResponseChan
+	CheckLegacyAdminFn                    func(userId int64, transaction *apm.Transaction, forceLog bool) chan CheckLegacyAdminResponseChan
+	CheckLegacyAdminWithContextFn         func(userId int64, ctx context.Context, forceLog bool) chan CheckLegacyAdminResponseChan
+	GetAdminIdsFilterByEmailFn            func(adminIds []int64, searchQuery string, apmTransaction *apm.Transaction, forceLog bool) chan GetAdminIdsFilterByEmailResponseChan
+	GetAdminIdsFilterByEmailWithContextFn func(adminIds []int64, searchQuery string, ctx context.Context, forceLog bool) chan GetAdminIdsFilterByEmailResponseChan
+	GetAdminsInfoByIdFn                   func(adminIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetAdminsInfoByIdResponseChan
+	GetAdminsInfoByIdWithContextFn        func(adminIds []int64, ctx context.Context, forceLog bool) chan GetAdminsInfoByIdResponseChan
+	AddNewUserFn                          func(req eventsourcing.UserEvent, apmTransaction *apm.Transaction, forceLog bool) chan AddUserResponseChan
+	AddNewUserWithContextFn               func(req eventsourcing.UserEvent, ctx context.Context, forceLog bool) chan AddUserResponseChan
+	IsGuestFn                             func(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[IsGuestResponse]
+	IsGuestWithContextFn                  func(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[IsGuestResponse]
+	GetUsersRegistrationTypeFn            func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SocialProviderType]
+	GetUsersRegistrationTypeWithContextFn func(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SocialProviderType]
+	InternalGetUsersForValidationFn       func(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserForValidator]
+	InternalGetAdminPermissionsFn         func(adminId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AdminPermissions]
 }
 
 func (w *AuthGoWrapperMock) InternalGetUsersForValidation(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserForValidator] {
 	return w.InternalGetUsersForValidationFn(userIds, ctx, forceLog)
 }
 
+func (w *AuthGoWrapperMock) InternalGetAdminPermissions(adminId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AdminPermissions] {
+	return w.InternalGetAdminPermissionsFn(adminId, ctx, forceLog)
+}
+
 func (w *AuthGoWrapperMock) IsGuest(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[IsGuestResponse] {
 	return w.IsGuestFn(userId, apmTransaction, forceLog)
 }
 
+func (w *AuthGoWrapperMock) IsGuestWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[IsGuestResponse] {
+	if w.IsGuestWithContextFn != nil {
+		return w.IsGuestWithContextFn(userId, ctx, forceLog)
+	}
+
+	return w.IsGuestFn(userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *AuthGoWrapperMock) AddNewUser(req eventsourcing.UserEvent, apmTransaction *apm.Transaction, forceLog bool) chan AddUserResponseChan {
 	return w.AddNewUserFn(req, apmTransaction, forceLog)
 }
 
+func (w *AuthGoWrapperMock) AddNewUserWithContext(req eventsourcing.UserEvent, ctx context.Context, forceLog bool) chan AddUserResponseChan {
+	if w.AddNewUserWithContextFn != nil {
+		return w.AddNewUserWithContextFn(req, ctx, forceLog)
+	}
+
+	return w.AddNewUserFn(req, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *AuthGoWrapperMock) CheckLegacyAdmin(userId int64, transaction *apm.Transaction, forceLog bool) chan CheckLegacyAdminResponseChan {
 	return w.CheckLegacyAdminFn(userId, transaction, forceLog)
 }
 
+func (w *AuthGoWrapperMock) CheckLegacyAdminWithContext(userId int64, ctx context.Context, forceLog bool) chan CheckLegacyAdminResponseChan {
+	if w.CheckLegacyAdminWithContextFn != nil {
+		return w.CheckLegacyAdminWithContextFn(userId, ctx, forceLog)
+	}
+
+	return w.CheckLegacyAdminFn(userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *AuthGoWrapperMock) CheckAdminPermissions(userId int64, obj string, transaction *apm.Transaction, forceLog bool) chan CheckAdminPermissionsResponseChan {
 	return w.CheckAdminPermissionsFn(userId, obj, transaction, forceLog)
 }
 
+func (w *AuthGoWrapperMock) CheckAdminPermissionsWithContext(userId int64, obj string, ctx context.Context, forceLog bool) chan CheckAdminPermissionsResponseChan {
+	if w.CheckAdminPermissionsWithContextFn != nil {
+		return w.CheckAdminPermissionsWithContextFn(userId, obj, ctx, forceLog)
+	}
+
+	return w.CheckAdminPermissionsFn(userId, obj, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *AuthGoWrapperMock) GetAdminIdsFilterByEmail(adminIds []int64, searchQuery string, apmTransaction *apm.Transaction, forceLog bool) chan GetAdminIdsFilterByEmailResponseChan {
 	return w.GetAdminIdsFilterByEmailFn(adminIds, searchQuery, apmTransaction, forceLog)
 }
 
+func (w *AuthGoWrapperMock) GetAdminIdsFilterByEmailWithContext(adminIds []int64, searchQuery string, ctx context.Context, forceLog bool) chan GetAdminIdsFilterByEmailResponseChan {
+	if w.GetAdminIdsFilterByEmailWithContextFn != nil {
+		return w.GetAdminIdsFilterByEmailWithContextFn(adminIds, searchQuery, ctx, forceLog)
+	}
+
+	return w.GetAdminIdsFilterByEmailFn(adminIds, searchQuery, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *AuthGoWrapperMock) GetAdminsInfoById(adminIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetAdminsInfoByIdResponseChan {
 	return w.GetAdminsInfoByIdFn(adminIds, apmTransaction, forceLog)
 }
 
+func (w *AuthGoWrapperMock) GetAdminsInfoByIdWithContext(adminIds []int64, ctx context.Context, forceLog bool) chan GetAdminsInfoByIdResponseChan {
+	if w.GetAdminsInfoByIdWithContextFn != nil {
+		return w.GetAdminsInfoByIdWithContextFn(adminIds, ctx, forceLog)
+	}
+
+	return w.GetAdminsInfoByIdFn(adminIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *AuthGoWrapperMock) GetUsersRegistrationType(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SocialProviderType] {
 	return w.GetUsersRegistrationTypeFn(userIds, apmTransaction, forceLog)
 }
 
-func GetMock() IAuthGoWrapper { // for compiler errors
+func (w *AuthGoWrapperMock) GetUsersRegistrationTypeWithContext(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SocialProviderType] {
+	if w.GetUsersRegistrationTypeWithContextFn != nil {
+		return w.GetUsersRegistrationTypeWithContextFn(userIds, ctx, forceLog)
+	}
+
+	return w.GetUsersRegistrationTypeFn(userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func GetMock() IAuthGoWrapperWithContext { // for compiler errors
 	return &AuthGoWrapperMock{}
 }
@@ -1,6 +1,7 @@
 package auth_go
 
 import (
+	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/go-common/eventsourcing"
 	"github.com/digitalmonsters/go-common/rpc"
 	"github.com/digitalmonsters/go-common/translation"
@@ -100,3 +101,15 @@ type UserForValidator struct {
 	Verified   bool                 `json:"verified"`
 	Language   translation.Language `json:"language"`
 }
+
+type GetAdminPermissionsRequest struct {
+	AdminId int64 `json:"admin_id"`
+}
+
+// AdminPermissions has the highest granted access level per rbac object, "*" object applies to all of them
+type AdminPermissions struct {
+	AdminId      int64                         `json:"admin_id"`
+	IsSuperAdmin bool                          `json:"is_super_admin"`
+	Roles        []string                      `json:"roles"`
+	Permissions  map[string]common.AccessLevel `json:"permissions"`
+}
@@ -1,6 +1,7 @@
 package base_api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/digitalmonsters/go-common/boilerplate"
@@ -18,6 +19,14 @@ type IBaseApiWrapper interface {
 	GetCountriesWithAgeLimit(apmTransaction *apm.Transaction, forceLog bool) chan GetCountriesWithAgeLimitResponseChan
 }
 
+// IBaseApiWrapperWithContext has context-first versions of IBaseApiWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IBaseApiWrapper implementation
+type IBaseApiWrapperWithContext interface {
+	IBaseApiWrapper
+
+	GetCountriesWithAgeLimitWithContext(ctx context.Context, forceLog bool) chan GetCountriesWithAgeLimitResponseChan
+}
+
 //goland:noinspection GoNameStartsWithPackageName
 type BaseApiWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
@@ -27,7 +36,7 @@ type BaseApiWrapper struct {
 	cache          *cache.Cache
 }
 
-func NewBaseApiWrapper(config boilerplate.WrapperConfig) IBaseApiWrapper {
+func NewBaseApiWrapper(config boilerplate.WrapperConfig) IBaseApiWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -51,7 +60,13 @@ func NewBaseApiWrapper(config boilerplate.WrapperConfig) IBaseApiWrapper {
 
 var countriesCacheKey = "countries_with_age_limit"
 
+// Deprecated: use GetCountriesWithAgeLimitWithContext
 func (w *BaseApiWrapper) GetCountriesWithAgeLimit(apmTransaction *apm.Transaction,
+	forceLog bool) chan GetCountriesWithAgeLimitResponseChan {
+	return w.GetCountriesWithAgeLimitWithContext(apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *BaseApiWrapper) GetCountriesWithAgeLimitWithContext(ctx context.Context,
 	forceLog bool) chan GetCountriesWithAgeLimitResponseChan {
 	resChan := make(chan GetCountriesWithAgeLimitResponseChan, 2)
 
@@ -71,11 +86,11 @@ func (w *BaseApiWrapper) GetCountriesWithAgeLimit(apmTransaction *apm.Transactio
 			}
 		}
 
-		rpcInternalResponse := <-w.baseWrapper.SendRequestWithRpcResponseFromNodeJsService(fmt.Sprintf("%v/mobile/v1/location/getCountriesWithAgeLimit", w.apiUrl),
+		rpcInternalResponse := <-w.baseWrapper.SendRequestWithRpcResponseFromNodeJsServiceWithContext(ctx, fmt.Sprintf("%v/mobile/v1/location/getCountriesWithAgeLimit", w.apiUrl),
 			"GET",
 			"application/json",
 			"get countries",
-			nil, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+			nil, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 		finalResponse.Error = rpcInternalResponse.Error
 
@@ -99,3 +114,21 @@ func (w *BaseApiWrapper) GetCountriesWithAgeLimit(apmTransaction *apm.Transactio
 
 	return resChan
 }
+
+// WithContext returns w when it already implements IBaseApiWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IBaseApiWrapper) IBaseApiWrapperWithContext {
+	if c, ok := w.(IBaseApiWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IBaseApiWrapper: w}
+}
+
+type contextAdapter struct {
+	IBaseApiWrapper
+}
+
+func (a contextAdapter) GetCountriesWithAgeLimitWithContext(ctx context.Context, forceLog bool) chan GetCountriesWithAgeLimitResponseChan {
+	return a.IBaseApiWrapper.GetCountriesWithAgeLimit(apm.TransactionFromContext(ctx), forceLog)
+}
@@ -1,17 +1,28 @@
 package base_api
 
-import "go.elastic.co/apm"
+import (
+	"context"
+	"go.elastic.co/apm"
+)
 
 //goland:noinspection ALL
 type BaseApiWrapperMock struct {
-	GetCountriesWithAgeLimitFn func(apmTransaction *apm.Transaction, forceLog bool) chan GetCountriesWithAgeLimitResponseChan
+	GetCountriesWithAgeLimitFn            func(apmTransaction *apm.Transaction, forceLog bool) chan GetCountriesWithAgeLimitResponseChan
+	GetCountriesWithAgeLimitWithContextFn func(ctx context.Context, forceLog bool) chan GetCountriesWithAgeLimitResponseChan
 }
 
 func (m *BaseApiWrapperMock) GetCountriesWithAgeLimit(apmTransaction *apm.Transaction, forceLog bool) chan GetCountriesWithAgeLimitResponseChan {
 	return m.GetCountriesWithAgeLimitFn(apmTransaction, forceLog)
 }
 
-func GetMock() IBaseApiWrapper { // for compiler errors
-	return &BaseApiWrapperMock{}
+func (m *BaseApiWrapperMock) GetCountriesWithAgeLimitWithContext(ctx context.Context, forceLog bool) chan GetCountriesWithAgeLimitResponseChan {
+	if m.GetCountriesWithAgeLimitWithContextFn != nil {
+		return m.GetCountriesWithAgeLimitWithContextFn(ctx, forceLog)
+	}
+
+	return m.GetCountriesWithAgeLimitFn(apm.TransactionFromContext(ctx), forceLog)
 }
 
+func GetMock() IBaseApiWrapperWithContext { // for compiler errors
+	return &BaseApiWrapperMock{}
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
-	"go.elastic.co/apm"
 
 	"github.com/digitalmonsters/go-common/boilerplate"
 	"github.com/digitalmonsters/go-common/common"
@@ -55,8 +54,8 @@ func (w *BotFactoryWrapper) SetSuperInfluencer(
 	userId int64,
 	forceLog bool,
 ) chan wrappers.GenericResponseChan[SetSuperInfluencerResponse] {
-	return wrappers.ExecuteRpcRequestAsync[SetSuperInfluencerResponse](w.baseWrapper, w.apiUrl, "SetSuperInfluencer",
+	return wrappers.ExecuteRpcRequestAsyncWithContext[SetSuperInfluencerResponse](ctx, w.baseWrapper, w.apiUrl, "SetSuperInfluencer",
 		SetSuperInfluencerRequest{
 			UserId: userId,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
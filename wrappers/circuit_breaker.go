@@ -0,0 +1,428 @@
+package wrappers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/ops"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/valyala/fasthttp"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCircuitBreakerWindow           = 10 * time.Second
+	defaultCircuitBreakerMinRequests      = 20
+	defaultCircuitBreakerFailureRate      = 0.5
+	defaultCircuitBreakerOpenDuration     = 30 * time.Second
+	defaultCircuitBreakerHalfOpenRequests = 3
+	circuitBreakerBuckets                 = 10
+)
+
+var ErrCircuitBreakerOpen = errors.New("circuit breaker is open")
+
+var (
+	circuitBreakerStateGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "wrappers_circuit_breaker_state",
+		Help: "State of circuit breaker per service, 0 - closed, 1 - half open, 2 - open",
+	}, []string{"service"})
+	circuitBreakerRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wrappers_circuit_breaker_rejected_total",
+		Help: "Requests rejected by open circuit breaker",
+	}, []string{"service"})
+	circuitBreakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wrappers_circuit_breaker_transitions_total",
+		Help: "State changes of circuit breakers",
+	}, []string{"service", "state"})
+)
+
+type CircuitBreakerState int
+
+const (
+	CircuitBreakerClosed CircuitBreakerState = iota
+	CircuitBreakerHalfOpen
+	CircuitBreakerOpen
+)
+
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerHalfOpen:
+		return "half-open"
+	case CircuitBreakerOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerFallback result is returned to rpc callers instead of error while breaker is open.
+// request is params of rpc request.
+type CircuitBreakerFallback func(ctx context.Context, methodName string, request interface{}) (interface{}, error)
+
+type CircuitBreakerConfig struct {
+	// Window is a period for failure rate, 10s by default
+	Window time.Duration
+	// MinRequests in the window before breaker can open, 20 by default
+	MinRequests int
+	// FailureRate opens breaker, 0.5 by default. Timeouts, connection errors and 5xx statuses are failures
+	FailureRate float64
+	// OpenDuration before half open state, 30s by default
+	OpenDuration time.Duration
+	// HalfOpenRequests are probe requests in half open state, all of them should succeed to close breaker
+	HalfOpenRequests int
+	Fallback         CircuitBreakerFallback
+}
+
+func (c CircuitBreakerConfig) withDefaults() CircuitBreakerConfig {
+	if c.Window < circuitBreakerBuckets {
+		c.Window = defaultCircuitBreakerWindow
+	}
+
+	if c.MinRequests <= 0 {
+		c.MinRequests = defaultCircuitBreakerMinRequests
+	}
+
+	if c.FailureRate <= 0 || c.FailureRate > 1 {
+		c.FailureRate = defaultCircuitBreakerFailureRate
+	}
+
+	if c.OpenDuration <= 0 {
+		c.OpenDuration = defaultCircuitBreakerOpenDuration
+	}
+
+	if c.HalfOpenRequests <= 0 {
+		c.HalfOpenRequests = defaultCircuitBreakerHalfOpenRequests
+	}
+
+	return c
+}
+
+type circuitBreakerBucket struct {
+	index    int64
+	requests int
+	failures int
+}
+
+type CircuitBreakerStats struct {
+	Service     string     `json:"service"`
+	State       string     `json:"state"`
+	Requests    int        `json:"requests"`
+	Failures    int        `json:"failures"`
+	FailureRate float64    `json:"failure_rate"`
+	OpenedAt    *time.Time `json:"opened_at,omitempty"`
+	Window      string     `json:"window"`
+}
+
+type CircuitBreaker struct {
+	service string
+	cfg     CircuitBreakerConfig
+	mutex   sync.Mutex
+	state   CircuitBreakerState
+	// generation changes with state, so results of requests started in previous state are ignored
+	generation        uint64
+	openedAt          time.Time
+	buckets           [circuitBreakerBuckets]circuitBreakerBucket
+	halfOpenInFlight  int
+	halfOpenSucceeded int
+	now               func() time.Time
+}
+
+func NewCircuitBreaker(service string, cfg CircuitBreakerConfig) *CircuitBreaker {
+	circuitBreakerStateGauge.WithLabelValues(service).Set(float64(CircuitBreakerClosed))
+
+	return &CircuitBreaker{
+		service: service,
+		cfg:     cfg.withDefaults(),
+		now:     time.Now,
+	}
+}
+
+func (c *CircuitBreaker) State() CircuitBreakerState {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.checkOpenTimeout()
+
+	return c.state
+}
+
+func (c *CircuitBreaker) config() CircuitBreakerConfig {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.cfg
+}
+
+// Reset closes breaker and clears its window
+func (c *CircuitBreaker) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.setState(CircuitBreakerClosed)
+}
+
+func (c *CircuitBreaker) Stats() CircuitBreakerStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.checkOpenTimeout()
+
+	requests, failures := c.windowCounts()
+
+	stats := CircuitBreakerStats{
+		Service:  c.service,
+		State:    c.state.String(),
+		Requests: requests,
+		Failures: failures,
+		Window:   c.cfg.Window.String(),
+	}
+
+	if requests > 0 {
+		stats.FailureRate = float64(failures) / float64(requests)
+	}
+
+	if c.state != CircuitBreakerClosed {
+		openedAt := c.openedAt
+		stats.OpenedAt = &openedAt
+	}
+
+	return stats
+}
+
+// allow returns false while breaker is open. When true, result of the request should be passed to record
+// with returned generation.
+func (c *CircuitBreaker) allow() (uint64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.checkOpenTimeout()
+
+	switch c.state {
+	case CircuitBreakerOpen:
+		circuitBreakerRejected.WithLabelValues(c.service).Inc()
+
+		return c.generation, false
+	case CircuitBreakerHalfOpen:
+		if c.halfOpenInFlight+c.halfOpenSucceeded >= c.cfg.HalfOpenRequests {
+			circuitBreakerRejected.WithLabelValues(c.service).Inc()
+
+			return c.generation, false
+		}
+
+		c.halfOpenInFlight++
+	}
+
+	return c.generation, true
+}
+
+func (c *CircuitBreaker) record(generation uint64, success bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	switch c.state {
+	case CircuitBreakerHalfOpen:
+		c.halfOpenInFlight--
+
+		if !success {
+			c.setState(CircuitBreakerOpen)
+		} else if c.halfOpenSucceeded++; c.halfOpenSucceeded >= c.cfg.HalfOpenRequests {
+			c.setState(CircuitBreakerClosed)
+		}
+	case CircuitBreakerClosed:
+		bucket := c.currentBucket()
+		bucket.requests++
+
+		if !success {
+			bucket.failures++
+		}
+
+		if requests, failures := c.windowCounts(); requests >= c.cfg.MinRequests &&
+			float64(failures)/float64(requests) >= c.cfg.FailureRate {
+			c.setState(CircuitBreakerOpen)
+		}
+	}
+}
+
+// release frees the half-open slot of the request without recording its result, it's used when
+// the caller cancelled the request and the service health is unknown
+func (c *CircuitBreaker) release(generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation == c.generation && c.state == CircuitBreakerHalfOpen {
+		c.halfOpenInFlight--
+	}
+}
+
+func (c *CircuitBreaker) checkOpenTimeout() {
+	if c.state == CircuitBreakerOpen && c.now().Sub(c.openedAt) >= c.cfg.OpenDuration {
+		c.setState(CircuitBreakerHalfOpen)
+	}
+}
+
+func (c *CircuitBreaker) setState(state CircuitBreakerState) {
+	if state == CircuitBreakerOpen {
+		c.openedAt = c.now()
+	}
+
+	if state == CircuitBreakerClosed {
+		c.buckets = [circuitBreakerBuckets]circuitBreakerBucket{}
+	}
+
+	c.halfOpenInFlight = 0
+	c.halfOpenSucceeded = 0
+	c.generation++
+
+	if c.state != state {
+		circuitBreakerTransitions.WithLabelValues(c.service, state.String()).Inc()
+	}
+
+	c.state = state
+	circuitBreakerStateGauge.WithLabelValues(c.service).Set(float64(state))
+}
+
+func (c *CircuitBreaker) bucketIndex() int64 {
+	return c.now().UnixNano() / int64(c.cfg.Window/circuitBreakerBuckets)
+}
+
+func (c *CircuitBreaker) currentBucket() *circuitBreakerBucket {
+	index := c.bucketIndex()
+	bucket := &c.buckets[index%circuitBreakerBuckets]
+
+	if bucket.index != index {
+		*bucket = circuitBreakerBucket{index: index}
+	}
+
+	return bucket
+}
+
+func (c *CircuitBreaker) windowCounts() (int, int) {
+	index := c.bucketIndex()
+	requests, failures := 0, 0
+
+	for _, bucket := range c.buckets {
+		if bucket.index > index-circuitBreakerBuckets {
+			requests += bucket.requests
+			failures += bucket.failures
+		}
+	}
+
+	return requests, failures
+}
+
+type circuitBreakers struct {
+	mutex sync.RWMutex
+	items map[string]*CircuitBreaker
+}
+
+// WithCircuitBreaker enables circuit breaker for requests to the service, or updates config of existing one.
+// While it is open, requests fail fast with error_codes.ServiceUnavailable or get result of cfg.Fallback.
+func (b *BaseWrapper) WithCircuitBreaker(externalServiceName string, cfg CircuitBreakerConfig) *BaseWrapper {
+	b.circuitBreakers.mutex.Lock()
+	defer b.circuitBreakers.mutex.Unlock()
+
+	if b.circuitBreakers.items == nil {
+		b.circuitBreakers.items = map[string]*CircuitBreaker{}
+	}
+
+	if breaker, ok := b.circuitBreakers.items[externalServiceName]; ok { // keep state when wrapper is created again
+		breaker.mutex.Lock()
+		breaker.cfg = cfg.withDefaults()
+		breaker.mutex.Unlock()
+
+		return b
+	}
+
+	b.circuitBreakers.items[externalServiceName] = NewCircuitBreaker(externalServiceName, cfg)
+
+	return b
+}
+
+// WithDefaultCircuitBreaker enables circuit breaker for the service unless it is already configured,
+// wrappers use it, so services can override config with WithCircuitBreaker before or after creating them
+func (b *BaseWrapper) WithDefaultCircuitBreaker(externalServiceName string, cfg CircuitBreakerConfig) *BaseWrapper {
+	if b.GetCircuitBreaker(externalServiceName) != nil {
+		return b
+	}
+
+	return b.WithCircuitBreaker(externalServiceName, cfg)
+}
+
+func (b *BaseWrapper) GetCircuitBreaker(externalServiceName string) *CircuitBreaker {
+	b.circuitBreakers.mutex.RLock()
+	defer b.circuitBreakers.mutex.RUnlock()
+
+	return b.circuitBreakers.items[externalServiceName]
+}
+
+func (b *BaseWrapper) GetCircuitBreakersStats() []CircuitBreakerStats {
+	b.circuitBreakers.mutex.RLock()
+	defer b.circuitBreakers.mutex.RUnlock()
+
+	result := make([]CircuitBreakerStats, 0, len(b.circuitBreakers.items))
+
+	for _, breaker := range b.circuitBreakers.items {
+		result = append(result, breaker.Stats())
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Service < result[j].Service
+	})
+
+	return result
+}
+
+func (b *BaseWrapper) circuitBreakerFallback(ctx context.Context, externalServiceName string, methodName string,
+	request interface{}) (rpc.RpcResponseInternal, bool) {
+	breaker := b.GetCircuitBreaker(externalServiceName)
+
+	if breaker == nil {
+		return rpc.RpcResponseInternal{}, false
+	}
+
+	cfg := breaker.config()
+
+	if cfg.Fallback == nil {
+		return rpc.RpcResponseInternal{}, false
+	}
+
+	if rpcRequest, ok := request.(rpc.RpcRequestInternal); ok {
+		request = rpcRequest.Params
+	}
+
+	result, err := cfg.Fallback(ctx, methodName, request)
+
+	if err != nil {
+		return rpc.RpcResponseInternal{}, false
+	}
+
+	data, err := json.Marshal(result)
+
+	if err != nil {
+		return rpc.RpcResponseInternal{}, false
+	}
+
+	return rpc.RpcResponseInternal{JsonRpc: "2.0", Result: data}, true
+}
+
+// RegisterCircuitBreakersDebug adds /debug/circuit-breakers page with state of breakers to the private server
+func (b *BaseWrapper) RegisterCircuitBreakersDebug(server *ops.PrivateHttpServer) {
+	server.Handle(fasthttp.MethodGet, "/debug/circuit-breakers", b.CircuitBreakersDebugHandler())
+}
+
+func (b *BaseWrapper) CircuitBreakersDebugHandler() fasthttp.RequestHandler {
+	return func(httpCtx *fasthttp.RequestCtx) {
+		data, _ := json.Marshal(b.GetCircuitBreakersStats())
+
+		httpCtx.Response.Header.SetContentType("application/json")
+		httpCtx.Response.SetBodyRaw(data)
+	}
+}
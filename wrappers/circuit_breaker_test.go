@@ -0,0 +1,150 @@
+package wrappers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerStates(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker("breaker-states", CircuitBreakerConfig{MinRequests: 4, HalfOpenRequests: 2})
+	breaker.now = func() time.Time { return now }
+
+	for _, success := range []bool{true, false, true, false} {
+		generation, allowed := breaker.allow()
+		assert.True(t, allowed)
+		breaker.record(generation, success)
+	}
+
+	assert.Equal(t, CircuitBreakerOpen, breaker.State())
+
+	_, allowed := breaker.allow()
+	assert.False(t, allowed)
+
+	now = now.Add(defaultCircuitBreakerOpenDuration)
+	assert.Equal(t, CircuitBreakerHalfOpen, breaker.State())
+
+	first, allowed := breaker.allow()
+	assert.True(t, allowed)
+	second, allowed := breaker.allow()
+	assert.True(t, allowed)
+	_, allowed = breaker.allow()
+	assert.False(t, allowed) // only 2 probes
+
+	breaker.record(first, true)
+	breaker.record(second, false)
+	assert.Equal(t, CircuitBreakerOpen, breaker.State())
+
+	now = now.Add(defaultCircuitBreakerOpenDuration)
+
+	for i := 0; i < 2; i++ {
+		generation, allowed := breaker.allow()
+		assert.True(t, allowed)
+		breaker.record(generation, true)
+	}
+
+	assert.Equal(t, CircuitBreakerClosed, breaker.State())
+	assert.Equal(t, 0, breaker.Stats().Requests)
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker("breaker-window", CircuitBreakerConfig{MinRequests: 2, Window: time.Second})
+	breaker.now = func() time.Time { return now }
+
+	generation, _ := breaker.allow()
+	breaker.record(generation, false)
+
+	now = now.Add(2 * time.Second) // previous failure is out of the window
+
+	generation, _ = breaker.allow()
+	breaker.record(generation, false)
+
+	assert.Equal(t, CircuitBreakerClosed, breaker.State())
+	assert.Equal(t, 1, breaker.Stats().Failures)
+}
+
+func TestCircuitBreakerFailFast(t *testing.T) {
+	var calls int32
+
+	url := startTestRpcServer(t, func(ctx *fasthttp.RequestCtx) {
+		atomic.AddInt32(&calls, 1)
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+	})
+
+	b := GetBaseWrapper().WithCircuitBreaker("breaker-test", CircuitBreakerConfig{MinRequests: 2})
+
+	for i := 0; i < 3; i++ {
+		resp := <-ExecuteRpcRequestAsyncWithContext[map[string]int](context.Background(), b, url, "GetItems",
+			nil, nil, time.Second, "breaker-test", false)
+
+		assert.NotNil(t, resp.Error)
+
+		if i == 2 {
+			assert.Equal(t, error_codes.ServiceUnavailable, resp.Error.Code)
+		}
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	b.WithCircuitBreaker("breaker-test", CircuitBreakerConfig{MinRequests: 2,
+		Fallback: func(ctx context.Context, methodName string, request interface{}) (interface{}, error) {
+			return map[string]int{methodName: request.(map[string]int)["a"]}, nil
+		}})
+
+	resp := <-ExecuteRpcRequestAsyncWithContext[map[string]int](context.Background(), b, url, "GetItems",
+		map[string]int{"a": 5}, nil, time.Second, "breaker-test", false)
+
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, map[string]int{"getitems": 5}, resp.Response)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	httpCtx := &fasthttp.RequestCtx{}
+	b.CircuitBreakersDebugHandler()(httpCtx)
+
+	var stats []CircuitBreakerStats
+	assert.Nil(t, json.Unmarshal(httpCtx.Response.Body(), &stats))
+
+	for _, s := range stats {
+		if s.Service == "breaker-test" {
+			assert.Equal(t, "open", s.State)
+			assert.NotNil(t, s.OpenedAt)
+		}
+	}
+}
+
+func TestCircuitBreakerFallbackReconfigure(t *testing.T) {
+	b := GetBaseWrapper().WithCircuitBreaker("breaker-reconfigure-test", CircuitBreakerConfig{})
+
+	fallback := func(ctx context.Context, methodName string, request interface{}) (interface{}, error) {
+		return methodName, nil
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 100; i++ {
+			b.WithCircuitBreaker("breaker-reconfigure-test", CircuitBreakerConfig{Fallback: fallback})
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if resp, ok := b.circuitBreakerFallback(context.Background(), "breaker-reconfigure-test", "GetItems",
+			nil); ok {
+			assert.Equal(t, `"GetItems"`, string(resp.Result))
+		}
+	}
+
+	<-done
+
+	_, ok := b.circuitBreakerFallback(context.Background(), "breaker-reconfigure-test", "GetItems", nil)
+	assert.True(t, ok)
+}
@@ -1,6 +1,7 @@
 package comment
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/digitalmonsters/go-common/boilerplate"
@@ -17,6 +18,14 @@ type ICommentWrapper interface {
 	GetCommentsInfoById(commentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetCommentsInfoByIdResponseChan
 }
 
+// ICommentWrapperWithContext has context-first versions of ICommentWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any ICommentWrapper implementation
+type ICommentWrapperWithContext interface {
+	ICommentWrapper
+
+	GetCommentsInfoByIdWithContext(commentIds []int64, ctx context.Context, forceLog bool) chan GetCommentsInfoByIdResponseChan
+}
+
 type CommentWrapper struct {
 	defaultTimeout time.Duration
 	apiUrl         string
@@ -24,7 +33,7 @@ type CommentWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
 }
 
-func NewCommentWrapper(config boilerplate.WrapperConfig) ICommentWrapper {
+func NewCommentWrapper(config boilerplate.WrapperConfig) ICommentWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -45,12 +54,17 @@ func NewCommentWrapper(config boilerplate.WrapperConfig) ICommentWrapper {
 	}
 }
 
+// Deprecated: use GetCommentsInfoByIdWithContext
 func (u CommentWrapper) GetCommentsInfoById(commentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetCommentsInfoByIdResponseChan {
+	return u.GetCommentsInfoByIdWithContext(commentIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (u CommentWrapper) GetCommentsInfoByIdWithContext(commentIds []int64, ctx context.Context, forceLog bool) chan GetCommentsInfoByIdResponseChan {
 	respCh := make(chan GetCommentsInfoByIdResponseChan, 2)
 
-	respChan := u.baseWrapper.SendRpcRequest(u.apiUrl, "GetCommentsInfoById", GetCommentsInfoByIdRequest{
+	respChan := u.baseWrapper.SendRpcRequestWithContext(ctx, u.apiUrl, "GetCommentsInfoById", GetCommentsInfoByIdRequest{
 		CommentIds: commentIds,
-	}, map[string]string{}, u.defaultTimeout, apmTransaction, u.serviceName, forceLog)
+	}, map[string]string{}, u.defaultTimeout, u.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -84,3 +98,21 @@ func (u CommentWrapper) GetCommentsInfoById(commentIds []int64, apmTransaction *
 
 	return respCh
 }
+
+// WithContext returns w when it already implements ICommentWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w ICommentWrapper) ICommentWrapperWithContext {
+	if c, ok := w.(ICommentWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{ICommentWrapper: w}
+}
+
+type contextAdapter struct {
+	ICommentWrapper
+}
+
+func (a contextAdapter) GetCommentsInfoByIdWithContext(commentIds []int64, ctx context.Context, forceLog bool) chan GetCommentsInfoByIdResponseChan {
+	return a.ICommentWrapper.GetCommentsInfoById(commentIds, apm.TransactionFromContext(ctx), forceLog)
+}
@@ -1,17 +1,27 @@
 package comment
 
 import (
+	"context"
 	"go.elastic.co/apm"
 )
 
 type CommentWrapperMock struct {
-	GetCommentsInfoByIdFn func(commentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetCommentsInfoByIdResponseChan
+	GetCommentsInfoByIdFn            func(commentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetCommentsInfoByIdResponseChan
+	GetCommentsInfoByIdWithContextFn func(commentIds []int64, ctx context.Context, forceLog bool) chan GetCommentsInfoByIdResponseChan
 }
 
 func (w *CommentWrapperMock) GetCommentsInfoById(commentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetCommentsInfoByIdResponseChan {
 	return w.GetCommentsInfoByIdFn(commentIds, apmTransaction, forceLog)
 }
 
-func GetMock() ICommentWrapper { // for compiler errors
+func (w *CommentWrapperMock) GetCommentsInfoByIdWithContext(commentIds []int64, ctx context.Context, forceLog bool) chan GetCommentsInfoByIdResponseChan {
+	if w.GetCommentsInfoByIdWithContextFn != nil {
+		return w.GetCommentsInfoByIdWithContextFn(commentIds, ctx, forceLog)
+	}
+
+	return w.GetCommentsInfoByIdFn(commentIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func GetMock() ICommentWrapperWithContext { // for compiler errors
 	return &CommentWrapperMock{}
 }
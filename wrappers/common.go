@@ -8,21 +8,26 @@ import (
 	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/go-common/error_codes"
 	"github.com/digitalmonsters/go-common/nodejs"
+	"github.com/digitalmonsters/go-common/redact"
 	"github.com/digitalmonsters/go-common/rpc"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/valyala/fasthttp"
 	"go.elastic.co/apm"
+	"go.elastic.co/apm/module/apmhttp"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 )
 
 type BaseWrapper struct {
-	client   *fasthttp.Client
-	hostName string
+	client          *fasthttp.Client
+	hostName        string
+	retryPolicies   retryPolicies
+	circuitBreakers circuitBreakers
 }
 
 var mutex sync.Mutex
@@ -54,6 +59,7 @@ func (b *BaseWrapper) GetHostName() string {
 	return b.hostName
 }
 
+// Deprecated: use SendRequestWithRpcResponseFromNodeJsServiceWithContext
 func (b *BaseWrapper) SendRequestWithRpcResponseFromNodeJsService(url string, httpMethod string, contentType string,
 	methodName string, request interface{}, headers map[string]string, timeout time.Duration, apmTransaction *apm.Transaction,
 	externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
@@ -63,6 +69,16 @@ func (b *BaseWrapper) SendRequestWithRpcResponseFromNodeJsService(url string, ht
 	)
 }
 
+func (b *BaseWrapper) SendRequestWithRpcResponseFromNodeJsServiceWithContext(ctx context.Context, url string,
+	httpMethod string, contentType string, methodName string, request interface{}, headers map[string]string,
+	timeout time.Duration, externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
+
+	return b.GetRpcResponseFromNodeJsServiceWithContext(
+		ctx, url, request, httpMethod, contentType, methodName, headers, timeout, externalServiceName, forceLog,
+	)
+}
+
+// Deprecated: use SendRequestWithRpcResponseFromAnyServiceWithContext
 func (b *BaseWrapper) SendRequestWithRpcResponseFromAnyService(url string, httpMethod string, contentType string,
 	methodName string, request interface{}, headers map[string]string, timeout time.Duration, apmTransaction *apm.Transaction,
 	externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
@@ -72,21 +88,46 @@ func (b *BaseWrapper) SendRequestWithRpcResponseFromAnyService(url string, httpM
 	)
 }
 
+func (b *BaseWrapper) SendRequestWithRpcResponseFromAnyServiceWithContext(ctx context.Context, url string,
+	httpMethod string, contentType string, methodName string, request interface{}, headers map[string]string,
+	timeout time.Duration, externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
+
+	return b.GetRpcResponseFromAnyServiceWithContext(
+		ctx, url, request, httpMethod, contentType, methodName, headers, timeout, externalServiceName, forceLog,
+	)
+}
+
+// Deprecated: use SendRequestWithRpcResponseWithContext
 func (b *BaseWrapper) SendRequestWithRpcResponse(url string, methodName string, request interface{}, headers map[string]string, timeout time.Duration,
 	apmTransaction *apm.Transaction, externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
 
 	return b.GetRpcResponse(url, request, methodName, headers, timeout, apmTransaction, externalServiceName, forceLog)
 }
 
+func (b *BaseWrapper) SendRequestWithRpcResponseWithContext(ctx context.Context, url string, methodName string,
+	request interface{}, headers map[string]string, timeout time.Duration, externalServiceName string,
+	forceLog bool) chan rpc.RpcResponseInternal {
+
+	return b.GetRpcResponseWithContext(ctx, url, request, methodName, headers, timeout, externalServiceName, forceLog)
+}
+
+// Deprecated: use SendRpcRequestWithContext
 func (b *BaseWrapper) SendRpcRequest(url string, methodName string, request interface{}, headers map[string]string, timeout time.Duration,
 	apmTransaction *apm.Transaction, externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
+	return b.SendRpcRequestWithContext(apm.ContextWithTransaction(context.Background(), apmTransaction), url, methodName,
+		request, headers, timeout, externalServiceName, forceLog)
+}
+
+// SendRpcRequestWithContext respects ctx deadline and forwards the remaining budget to the remote service
+func (b *BaseWrapper) SendRpcRequestWithContext(ctx context.Context, url string, methodName string, request interface{},
+	headers map[string]string, timeout time.Duration, externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
 	name := strings.ToLower(methodName)
-	return b.GetRpcResponse(url, rpc.RpcRequestInternal{
+	return b.GetRpcResponseWithContext(ctx, url, rpc.RpcRequestInternal{
 		Method:  name,
 		Params:  request,
 		Id:      "1",
 		JsonRpc: "2.0",
-	}, name, headers, timeout, apmTransaction, externalServiceName, forceLog)
+	}, name, headers, timeout, externalServiceName, forceLog)
 }
 
 type GenericResponseChan[T any] struct {
@@ -94,14 +135,22 @@ type GenericResponseChan[T any] struct {
 	Response T             `json:"response"`
 }
 
+// Deprecated: use ExecuteRpcRequestAsyncWithContext
 func ExecuteRpcRequestAsync[T any](b *BaseWrapper,
 	url string, methodName string, request interface{}, headers map[string]string, timeout time.Duration,
 	apmTransaction *apm.Transaction, externalServiceName string, forceLog bool) chan GenericResponseChan[T] {
+	return ExecuteRpcRequestAsyncWithContext[T](apm.ContextWithTransaction(context.Background(), apmTransaction), b,
+		url, methodName, request, headers, timeout, externalServiceName, forceLog)
+}
+
+func ExecuteRpcRequestAsyncWithContext[T any](ctx context.Context, b *BaseWrapper,
+	url string, methodName string, request interface{}, headers map[string]string, timeout time.Duration,
+	externalServiceName string, forceLog bool) chan GenericResponseChan[T] {
 
 	ch := make(chan GenericResponseChan[T], 2)
 
 	go func() {
-		resp := <-b.SendRpcRequest(url, methodName, request, headers, timeout, apmTransaction, externalServiceName, forceLog)
+		resp := <-b.SendRpcRequestWithContext(ctx, url, methodName, request, headers, timeout, externalServiceName, forceLog)
 
 		result := GenericResponseChan[T]{
 			Error: resp.Error,
@@ -144,7 +193,8 @@ type httpResponseChan struct {
 }
 
 func (b *BaseWrapper) sendHttpRequestAsync(ctx context.Context, url string, methodName string, request interface{},
-	headers map[string]string, forceLog bool, timeout time.Duration, contentType string, httpMethod string) chan httpResponseChan {
+	headers map[string]string, forceLog bool, timeout time.Duration, contentType string, httpMethod string,
+	externalServiceName string) chan httpResponseChan {
 	resultChan := make(chan httpResponseChan, 2)
 
 	result := httpResponseChan{
@@ -191,6 +241,27 @@ func (b *BaseWrapper) sendHttpRequestAsync(ctx context.Context, url string, meth
 			req.Header.Set("X-Requester-Host", b.hostName)
 		}
 
+		if correlationId, ok := CorrelationIdFromContext(ctx); ok {
+			req.Header.Set(common.CorrelationIdHeader, correlationId)
+		}
+
+		if userId, ok := UserIdFromContext(ctx); ok {
+			req.Header.Set(common.OriginUserIdHeader, strconv.FormatInt(userId, 10))
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= 0 {
+			result.error = errors.Wrap(context.DeadlineExceeded, "request deadline exceeded before sending")
+			result.forceLog = true
+
+			return
+		}
+
+		if err := ctx.Err(); err != nil {
+			result.error = errors.Wrap(err, "request cancelled before sending")
+
+			return
+		}
+
 		for k, v := range headers {
 			req.Header.Set(k, v)
 		}
@@ -210,9 +281,53 @@ func (b *BaseWrapper) sendHttpRequestAsync(ctx context.Context, url string, meth
 
 		apm_helper.AddDataToSpanTrance(result.span, req, ctx)
 
-		err := b.client.DoTimeout(req, resp, timeout)
+		if result.span != nil && result.span.Dropped() { // dropped span has trace context of transaction, trace is kept
+			req.Header.Set(apmhttp.W3CTraceparentHeader, apmhttp.FormatTraceparentHeader(result.span.TraceContext()))
+		}
+
+		breaker := b.GetCircuitBreaker(externalServiceName)
+		generation := uint64(0)
+
+		if breaker != nil {
+			var allowed bool
+
+			if generation, allowed = breaker.allow(); !allowed {
+				result.error = errors.Wrap(ErrCircuitBreakerOpen, fmt.Sprintf("service [%v]", externalServiceName))
+
+				return
+			}
+		}
+
+		policy := b.getRetryPolicy(externalServiceName, methodName, headers)
+
+		var err error
+
+		for attempt := 1; ; attempt++ {
+			if attempt > 1 { // fasthttp keeps body of timed out request
+				resp.Reset()
+				req.SetBodyRaw(result.rawBodyRequest)
+			}
+
+			err = b.doAttempt(ctx, req, resp, timeout, attempt, policy != nil)
+
+			if policy == nil || attempt >= policy.MaxAttempts || !policy.shouldRetry(err, resp.StatusCode()) ||
+				!waitBackoff(ctx, policy.Backoff(attempt)) {
+				break
+			}
+
+			wrapperRetries.WithLabelValues(externalServiceName, strings.ToLower(methodName)).Inc()
+		}
 
 		result.statusCode = resp.StatusCode()
+
+		if breaker != nil {
+			if errors.Is(err, context.Canceled) { // the caller gave up, it says nothing about the service
+				breaker.release(generation)
+			} else {
+				breaker.record(generation, err == nil && result.statusCode < fasthttp.StatusInternalServerError)
+			}
+		}
+
 		rawBodyResponse, err2 := common.UnpackFastHttpBody(resp)
 
 		if err2 != nil {
@@ -240,15 +355,113 @@ func (b *BaseWrapper) sendHttpRequestAsync(ctx context.Context, url string, meth
 	return resultChan
 }
 
+// doAttempt sends req once, timeout is limited by ctx deadline. Retried requests get a child span per attempt.
+func (b *BaseWrapper) doAttempt(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response,
+	timeout time.Duration, attempt int, retryable bool) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline)
+
+		if remaining <= 0 {
+			return errors.Wrap(context.DeadlineExceeded, "request deadline exceeded before sending")
+		}
+
+		if remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	req.Header.Set(common.RequestTimeoutHeader, strconv.FormatInt(timeout.Milliseconds(), 10))
+
+	if !retryable {
+		return b.do(ctx, req, resp, timeout)
+	}
+
+	span, _ := apm.StartSpan(ctx, fmt.Sprintf("attempt [%v]", attempt), "rpc_internal.attempt")
+	defer span.End()
+
+	err := b.do(ctx, req, resp, timeout)
+
+	if !span.Dropped() {
+		span.Context.SetHTTPStatusCode(resp.StatusCode())
+
+		if err != nil {
+			span.Context.SetLabel("error", err.Error())
+		}
+	}
+
+	return err
+}
+
+// do stops waiting for the response when ctx is done. fasthttp does not support context, so cancelled request
+// is finished in background with its own copies of req and resp.
+func (b *BaseWrapper) do(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response,
+	timeout time.Duration) error {
+	if ctx.Done() == nil {
+		return b.client.DoTimeout(req, resp, timeout)
+	}
+
+	bgReq := fasthttp.AcquireRequest()
+	bgResp := fasthttp.AcquireResponse()
+
+	req.CopyTo(bgReq)
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- b.client.DoTimeout(bgReq, bgResp, timeout)
+	}()
+
+	release := func() {
+		fasthttp.ReleaseRequest(bgReq)
+		fasthttp.ReleaseResponse(bgResp)
+	}
+
+	select {
+	case err := <-done:
+		bgResp.CopyTo(resp)
+		release()
+
+		return err
+	case <-ctx.Done():
+		go func() {
+			<-done
+			release()
+		}()
+
+		return errors.WithStack(ctx.Err())
+	}
+}
+
+func httpErrorCode(err error) error_codes.ErrorCode {
+	if errors.Is(err, ErrCircuitBreakerOpen) {
+		return error_codes.ServiceUnavailable
+	}
+
+	if errors.Is(err, fasthttp.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return error_codes.GenericTimeoutError
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return error_codes.Timeout
+	}
+
+	return error_codes.GenericServerError
+}
+
+// Deprecated: use GetRpcResponseWithContext
 func (b *BaseWrapper) GetRpcResponse(url string, request interface{}, methodName string, headers map[string]string, timeout time.Duration,
 	apmTransaction *apm.Transaction, externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
-	responseCh := make(chan rpc.RpcResponseInternal, 2)
+	return b.GetRpcResponseWithContext(apm.ContextWithTransaction(context.Background(), apmTransaction), url, request,
+		methodName, headers, timeout, externalServiceName, forceLog)
+}
 
-	ctx := apm.ContextWithTransaction(context.Background(), apmTransaction)
+func (b *BaseWrapper) GetRpcResponseWithContext(ctx context.Context, url string, request interface{}, methodName string,
+	headers map[string]string, timeout time.Duration, externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
+	responseCh := make(chan rpc.RpcResponseInternal, 2)
 
 	go func() {
 		apiResponse := <-b.sendHttpRequestAsync(ctx, url, methodName, request, headers, forceLog, timeout,
-			"application/json", "POST")
+			"application/json", "POST", externalServiceName)
 
 		defer func() {
 			close(responseCh)
@@ -257,16 +470,18 @@ func (b *BaseWrapper) GetRpcResponse(url string, request interface{}, methodName
 				apiResponse.forceLog)
 		}()
 
-		if apiResponse.error != nil { // its timeout, or some internal error, not logical error
-			code := error_codes.GenericServerError
+		if errors.Is(apiResponse.error, ErrCircuitBreakerOpen) {
+			if fallbackResponse, ok := b.circuitBreakerFallback(ctx, externalServiceName, methodName, request); ok {
+				responseCh <- fallbackResponse
 
-			if errors.Is(apiResponse.error, fasthttp.ErrTimeout) {
-				code = error_codes.GenericTimeoutError
+				return
 			}
+		}
 
+		if apiResponse.error != nil { // its timeout, or some internal error, not logical error
 			responseCh <- rpc.RpcResponseInternal{
 				Error: &rpc.RpcError{
-					Code:        code,
+					Code:        httpErrorCode(apiResponse.error),
 					Message:     apiResponse.error.Error(),
 					Stack:       fmt.Sprintf("%+v", apiResponse.error),
 					Data:        nil,
@@ -311,16 +526,22 @@ func (b *BaseWrapper) GetRpcResponse(url string, request interface{}, methodName
 	return responseCh
 }
 
+// Deprecated: use GetRpcResponseFromNodeJsServiceWithContext
 func (b *BaseWrapper) GetRpcResponseFromNodeJsService(url string, request interface{}, httpMethod string,
 	contentType string, methodName string, headers map[string]string, timeout time.Duration, apmTransaction *apm.Transaction,
 	externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
-	responseCh := make(chan rpc.RpcResponseInternal, 2)
+	return b.GetRpcResponseFromNodeJsServiceWithContext(apm.ContextWithTransaction(context.Background(), apmTransaction), url,
+		request, httpMethod, contentType, methodName, headers, timeout, externalServiceName, forceLog)
+}
 
-	ctx := apm.ContextWithTransaction(context.Background(), apmTransaction)
+func (b *BaseWrapper) GetRpcResponseFromNodeJsServiceWithContext(ctx context.Context, url string, request interface{},
+	httpMethod string, contentType string, methodName string, headers map[string]string, timeout time.Duration,
+	externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
+	responseCh := make(chan rpc.RpcResponseInternal, 2)
 
 	go func() {
 		apiResponse := <-b.sendHttpRequestAsync(ctx, url, methodName, request, headers, forceLog, timeout, contentType,
-			httpMethod)
+			httpMethod, externalServiceName)
 
 		defer func() {
 			close(responseCh)
@@ -330,15 +551,9 @@ func (b *BaseWrapper) GetRpcResponseFromNodeJsService(url string, request interf
 		}()
 
 		if apiResponse.error != nil { // its timeout, or some internal error, not logical error
-			code := error_codes.GenericServerError
-
-			if errors.Is(apiResponse.error, fasthttp.ErrTimeout) {
-				code = error_codes.GenericTimeoutError
-			}
-
 			responseCh <- rpc.RpcResponseInternal{
 				Error: &rpc.RpcError{
-					Code:        code,
+					Code:        httpErrorCode(apiResponse.error),
 					Message:     apiResponse.error.Error(),
 					Stack:       fmt.Sprintf("%+v", apiResponse.error),
 					Data:        nil,
@@ -408,16 +623,22 @@ func (b *BaseWrapper) GetRpcResponseFromNodeJsService(url string, request interf
 	return responseCh
 }
 
+// Deprecated: use GetRpcResponseFromAnyServiceWithContext
 func (b *BaseWrapper) GetRpcResponseFromAnyService(url string, request interface{}, httpMethod string,
 	contentType string, methodName string, headers map[string]string, timeout time.Duration, apmTransaction *apm.Transaction,
 	externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
-	responseCh := make(chan rpc.RpcResponseInternal, 2)
+	return b.GetRpcResponseFromAnyServiceWithContext(apm.ContextWithTransaction(context.Background(), apmTransaction), url,
+		request, httpMethod, contentType, methodName, headers, timeout, externalServiceName, forceLog)
+}
 
-	ctx := apm.ContextWithTransaction(context.Background(), apmTransaction)
+func (b *BaseWrapper) GetRpcResponseFromAnyServiceWithContext(ctx context.Context, url string, request interface{},
+	httpMethod string, contentType string, methodName string, headers map[string]string, timeout time.Duration,
+	externalServiceName string, forceLog bool) chan rpc.RpcResponseInternal {
+	responseCh := make(chan rpc.RpcResponseInternal, 2)
 
 	go func() {
 		apiResponse := <-b.sendHttpRequestAsync(ctx, url, methodName, request, headers, forceLog, timeout, contentType,
-			httpMethod)
+			httpMethod, externalServiceName)
 
 		defer func() {
 			close(responseCh)
@@ -427,15 +648,9 @@ func (b *BaseWrapper) GetRpcResponseFromAnyService(url string, request interface
 		}()
 
 		if apiResponse.error != nil { // its timeout, or some internal error, not logical error
-			code := error_codes.GenericServerError
-
-			if errors.Is(apiResponse.error, fasthttp.ErrTimeout) {
-				code = error_codes.GenericTimeoutError
-			}
-
 			responseCh <- rpc.RpcResponseInternal{
 				Error: &rpc.RpcError{
-					Code:        code,
+					Code:        httpErrorCode(apiResponse.error),
 					Message:     apiResponse.error.Error(),
 					Stack:       fmt.Sprintf("%+v", apiResponse.error),
 					Data:        nil,
@@ -488,6 +703,9 @@ func endRpcSpan(rawBodyRequest []byte, rawBodyResponse []byte,
 	finalStatement := ""
 
 	if shouldLog && rqSpan != nil {
+		rawBodyRequest = redact.Json(rawBodyRequest)
+		rawBodyResponse = redact.Json(rawBodyResponse)
+
 		if data, err := json.Marshal(map[string]interface{}{
 			"request":  rawBodyRequest,
 			"response": rawBodyResponse,
@@ -34,6 +34,22 @@ type IContentWrapper interface {
 	GetLastContent(ctx context.Context, userId int64) chan wrappers.GenericResponseChan[[]SimpleContent]
 }
 
+// IContentWrapperWithContext has context-first versions of IContentWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IContentWrapper implementation
+type IContentWrapperWithContext interface {
+	IContentWrapper
+
+	GetInternalWithContext(contentIds []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SimpleContent]
+	GetInternalAdminModelsWithContext(contentIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]frontend.ContentModel]
+	GetTopNotFollowingUsersWithContext(userId int64, limit int, offset int, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetTopNotFollowingUsersResponse]
+	GetHashtagsInternalWithContext(hashtags []string, omitHashtags []string, limit int, offset int, withViews null.Bool, ctx context.Context, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[HashtagResponseData]
+	GetCategoryInternalWithContext(categoryIds []int64, omitCategoryIds []int64, limit int, offset int, onlyParent null.Bool, withViews null.Bool, ctx context.Context, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[CategoryResponseData]
+	GetAllCategoriesWithContext(categoryIds []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]AllCategoriesResponseItem]
+	GetUserBlacklistedCategoriesWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetUserBlacklistedCategoriesResponse]
+	GetUserLikesWithContext(userId int64, limit int, offset int, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[LikedContent]
+	GetConfigPropertiesWithContext(properties []string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[string]string]
+}
+
 //goland:noinspection GoNameStartsWithPackageName
 type ContentWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
@@ -42,7 +58,7 @@ type ContentWrapper struct {
 	serviceName    string
 }
 
-func NewContentWrapper(config boilerplate.WrapperConfig) IContentWrapper {
+func NewContentWrapper(config boilerplate.WrapperConfig) IContentWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -55,66 +71,103 @@ func NewContentWrapper(config boilerplate.WrapperConfig) IContentWrapper {
 		log.Warn().Msgf("Api Url is missing for Content. Setting as default : %v", config.ApiUrl)
 	}
 
+	serviceName := "content"
+
 	return &ContentWrapper{
-		baseWrapper:    wrappers.GetBaseWrapper(),
+		baseWrapper:    wrappers.GetBaseWrapper().WithDefaultCircuitBreaker(serviceName, wrappers.CircuitBreakerConfig{}),
 		defaultTimeout: timeout,
 		apiUrl:         fmt.Sprintf("%v/rpc-service", common.StripSlashFromUrl(config.ApiUrl)),
-		serviceName:    "content",
+		serviceName:    serviceName,
 	}
 }
 
 func (w *ContentWrapper) GetLastContent(ctx context.Context, userId int64) chan wrappers.GenericResponseChan[[]SimpleContent] {
-	return wrappers.ExecuteRpcRequestAsync[[]SimpleContent](w.baseWrapper, w.apiUrl, "GetLastContentInternal", GetLastContentRequest{
+	return wrappers.ExecuteRpcRequestAsyncWithContext[[]SimpleContent](ctx, w.baseWrapper, w.apiUrl, "GetLastContentInternal", GetLastContentRequest{
 		UserId: userId,
-	}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, false)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, false)
 }
 
+// Deprecated: use GetInternalWithContext
 func (w *ContentWrapper) GetInternal(contentIds []int64, includeDeleted bool, apmTransaction *apm.Transaction,
 	forceLog bool) chan wrappers.GenericResponseChan[map[int64]SimpleContent] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]SimpleContent](w.baseWrapper, w.apiUrl, "ContentGetInternal", ContentGetInternalRequest{
+	return w.GetInternalWithContext(contentIds, includeDeleted, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *ContentWrapper) GetInternalWithContext(contentIds []int64, includeDeleted bool, ctx context.Context,
+	forceLog bool) chan wrappers.GenericResponseChan[map[int64]SimpleContent] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]SimpleContent](ctx, w.baseWrapper, w.apiUrl, "ContentGetInternal", ContentGetInternalRequest{
 		ContentIds:     contentIds,
 		IncludeDeleted: includeDeleted,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetInternalAdminModelsWithContext
 func (w *ContentWrapper) GetInternalAdminModels(contentIds []int64, apmTransaction *apm.Transaction,
 	forceLog bool) chan wrappers.GenericResponseChan[map[int64]frontend.ContentModel] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]frontend.ContentModel](w.baseWrapper, w.apiUrl, "ContentGetInternalAdminModels", ContentGetInternalAdminModelsRequest{
+	return w.GetInternalAdminModelsWithContext(contentIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *ContentWrapper) GetInternalAdminModelsWithContext(contentIds []int64, ctx context.Context,
+	forceLog bool) chan wrappers.GenericResponseChan[map[int64]frontend.ContentModel] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]frontend.ContentModel](ctx, w.baseWrapper, w.apiUrl, "ContentGetInternalAdminModels", ContentGetInternalAdminModelsRequest{
 		ContentIds: contentIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetTopNotFollowingUsersWithContext
 func (w *ContentWrapper) GetTopNotFollowingUsers(userId int64, limit int, offset int, apmTransaction *apm.Transaction,
 	forceLog bool) chan wrappers.GenericResponseChan[GetTopNotFollowingUsersResponse] {
+	return w.GetTopNotFollowingUsersWithContext(userId, limit, offset, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *ContentWrapper) GetTopNotFollowingUsersWithContext(userId int64, limit int, offset int, ctx context.Context,
+	forceLog bool) chan wrappers.GenericResponseChan[GetTopNotFollowingUsersResponse] {
 
-	return wrappers.ExecuteRpcRequestAsync[GetTopNotFollowingUsersResponse](w.baseWrapper, w.apiUrl, "GetTopNotFollowingUsers", GetTopNotFollowingUsersRequest{
+	return wrappers.ExecuteRpcRequestAsyncWithContext[GetTopNotFollowingUsersResponse](ctx, w.baseWrapper, w.apiUrl, "GetTopNotFollowingUsers", GetTopNotFollowingUsersRequest{
 		UserId: userId,
 		Limit:  limit,
 		Offset: offset,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetHashtagsInternalWithContext
 func (w *ContentWrapper) GetHashtagsInternal(hashtags []string, omitHashtags []string, limit int, offset int, withViews null.Bool,
 	apmTransaction *apm.Transaction, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[HashtagResponseData] {
-	return wrappers.ExecuteRpcRequestAsync[HashtagResponseData](w.baseWrapper, w.apiUrl, "GetHashtagsInternal", GetHashtagsInternalRequest{
+	return w.GetHashtagsInternalWithContext(hashtags, omitHashtags, limit, offset, withViews, apm.ContextWithTransaction(context.Background(), apmTransaction), shouldHaveValidContent, forceLog)
+}
+
+func (w *ContentWrapper) GetHashtagsInternalWithContext(hashtags []string, omitHashtags []string, limit int, offset int, withViews null.Bool,
+	ctx context.Context, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[HashtagResponseData] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[HashtagResponseData](ctx, w.baseWrapper, w.apiUrl, "GetHashtagsInternal", GetHashtagsInternalRequest{
 		Hashtags:               hashtags,
 		OmitHashtags:           omitHashtags,
 		Limit:                  limit,
 		WithViews:              withViews,
 		Offset:                 offset,
 		ShouldHaveValidContent: shouldHaveValidContent,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetUserBlacklistedCategoriesWithContext
 func (w *ContentWrapper) GetUserBlacklistedCategories(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetUserBlacklistedCategoriesResponse] {
-	return wrappers.ExecuteRpcRequestAsync[GetUserBlacklistedCategoriesResponse](w.baseWrapper, w.apiUrl, "GetUserBlacklistedCategoriesInternal", GetUserBlacklistedCategoriesRequest{
+	return w.GetUserBlacklistedCategoriesWithContext(userId, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *ContentWrapper) GetUserBlacklistedCategoriesWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetUserBlacklistedCategoriesResponse] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[GetUserBlacklistedCategoriesResponse](ctx, w.baseWrapper, w.apiUrl, "GetUserBlacklistedCategoriesInternal", GetUserBlacklistedCategoriesRequest{
 		UserId: userId,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetCategoryInternalWithContext
 func (w *ContentWrapper) GetCategoryInternal(categoryIds []int64, omitCategoryIds []int64, limit int, offset int, onlyParent null.Bool, withViews null.Bool,
 	apmTransaction *apm.Transaction, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[CategoryResponseData] {
-	return wrappers.ExecuteRpcRequestAsync[CategoryResponseData](w.baseWrapper, w.apiUrl, "GetCategoryInternal", GetCategoryInternalRequest{
+	return w.GetCategoryInternalWithContext(categoryIds, omitCategoryIds, limit, offset, onlyParent, withViews, apm.ContextWithTransaction(context.Background(), apmTransaction), shouldHaveValidContent, forceLog)
+}
+
+func (w *ContentWrapper) GetCategoryInternalWithContext(categoryIds []int64, omitCategoryIds []int64, limit int, offset int, onlyParent null.Bool, withViews null.Bool,
+	ctx context.Context, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[CategoryResponseData] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[CategoryResponseData](ctx, w.baseWrapper, w.apiUrl, "GetCategoryInternal", GetCategoryInternalRequest{
 		CategoryIds:            categoryIds,
 		Limit:                  limit,
 		Offset:                 offset,
@@ -122,41 +175,106 @@ func (w *ContentWrapper) GetCategoryInternal(categoryIds []int64, omitCategoryId
 		WithViews:              withViews,
 		OnlyParent:             onlyParent,
 		ShouldHaveValidContent: shouldHaveValidContent,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetAllCategoriesWithContext
 func (w *ContentWrapper) GetAllCategories(categoryIds []int64, includeDeleted bool, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]AllCategoriesResponseItem] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]AllCategoriesResponseItem](w.baseWrapper, w.apiUrl, "GetAllCategories", GetAllCategoriesRequest{
+	return w.GetAllCategoriesWithContext(categoryIds, includeDeleted, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *ContentWrapper) GetAllCategoriesWithContext(categoryIds []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]AllCategoriesResponseItem] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]AllCategoriesResponseItem](ctx, w.baseWrapper, w.apiUrl, "GetAllCategories", GetAllCategoriesRequest{
 		CategoryIds:    categoryIds,
 		IncludeDeleted: includeDeleted,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetUserLikesWithContext
 func (w *ContentWrapper) GetUserLikes(userId int64, limit int, offset int, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[LikedContent] {
-	return wrappers.ExecuteRpcRequestAsync[LikedContent](w.baseWrapper, w.apiUrl, "InternalGetUserLikes", GetUserLikesRequest{
+	return w.GetUserLikesWithContext(userId, limit, offset, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *ContentWrapper) GetUserLikesWithContext(userId int64, limit int, offset int, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[LikedContent] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[LikedContent](ctx, w.baseWrapper, w.apiUrl, "InternalGetUserLikes", GetUserLikesRequest{
 		UserId: userId,
 		Limit:  limit,
 		Offset: offset,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetConfigPropertiesWithContext
 func (w *ContentWrapper) GetConfigProperties(properties []string, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[string]string] {
-	return wrappers.ExecuteRpcRequestAsync[map[string]string](w.baseWrapper, w.apiUrl, "InternalGetConfigValues", GetConfigValuesRequest{Properties: properties},
-		map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	return w.GetConfigPropertiesWithContext(properties, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *ContentWrapper) GetConfigPropertiesWithContext(properties []string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[string]string] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[string]string](ctx, w.baseWrapper, w.apiUrl, "InternalGetConfigValues", GetConfigValuesRequest{Properties: properties},
+		map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w *ContentWrapper) GetRejectReason(ids []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]RejectReason] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]RejectReason](w.baseWrapper, w.apiUrl, "InternalGetContentRejectReason", GetContentRejectReasonRequest{
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]RejectReason](ctx, w.baseWrapper, w.apiUrl, "InternalGetContentRejectReason", GetContentRejectReasonRequest{
 		Ids:            ids,
 		IncludeDeleted: includeDeleted,
-	}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w *ContentWrapper) GetTopUsersInCategories(ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64][]int64] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64][]int64](w.baseWrapper, w.apiUrl, "InternalGetTopUsersInCategories", nil,
-		map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64][]int64](ctx, w.baseWrapper, w.apiUrl, "InternalGetTopUsersInCategories", nil,
+		map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w *ContentWrapper) InsertMusicContent(content MusicContentRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[SimpleContent] {
-	return wrappers.ExecuteRpcRequestAsync[SimpleContent](w.baseWrapper, w.apiUrl, "InsertMusicContentInternal", content, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	return wrappers.ExecuteRpcRequestAsyncWithContext[SimpleContent](ctx, w.baseWrapper, w.apiUrl, "InsertMusicContentInternal", content, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
+}
+
+// WithContext returns w when it already implements IContentWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IContentWrapper) IContentWrapperWithContext {
+	if c, ok := w.(IContentWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IContentWrapper: w}
+}
+
+type contextAdapter struct {
+	IContentWrapper
+}
+
+func (a contextAdapter) GetInternalWithContext(contentIds []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SimpleContent] {
+	return a.IContentWrapper.GetInternal(contentIds, includeDeleted, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetInternalAdminModelsWithContext(contentIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]frontend.ContentModel] {
+	return a.IContentWrapper.GetInternalAdminModels(contentIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetTopNotFollowingUsersWithContext(userId int64, limit int, offset int, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetTopNotFollowingUsersResponse] {
+	return a.IContentWrapper.GetTopNotFollowingUsers(userId, limit, offset, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetHashtagsInternalWithContext(hashtags []string, omitHashtags []string, limit int, offset int, withViews null.Bool, ctx context.Context, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[HashtagResponseData] {
+	return a.IContentWrapper.GetHashtagsInternal(hashtags, omitHashtags, limit, offset, withViews, apm.TransactionFromContext(ctx), shouldHaveValidContent, forceLog)
+}
+
+func (a contextAdapter) GetCategoryInternalWithContext(categoryIds []int64, omitCategoryIds []int64, limit int, offset int, onlyParent null.Bool, withViews null.Bool, ctx context.Context, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[CategoryResponseData] {
+	return a.IContentWrapper.GetCategoryInternal(categoryIds, omitCategoryIds, limit, offset, onlyParent, withViews, apm.TransactionFromContext(ctx), shouldHaveValidContent, forceLog)
+}
+
+func (a contextAdapter) GetAllCategoriesWithContext(categoryIds []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]AllCategoriesResponseItem] {
+	return a.IContentWrapper.GetAllCategories(categoryIds, includeDeleted, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetUserBlacklistedCategoriesWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetUserBlacklistedCategoriesResponse] {
+	return a.IContentWrapper.GetUserBlacklistedCategories(userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetUserLikesWithContext(userId int64, limit int, offset int, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[LikedContent] {
+	return a.IContentWrapper.GetUserLikes(userId, limit, offset, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetConfigPropertiesWithContext(properties []string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[string]string] {
+	return a.IContentWrapper.GetConfigProperties(properties, apm.TransactionFromContext(ctx), forceLog)
 }
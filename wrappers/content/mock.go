@@ -11,22 +11,31 @@ import (
 )
 
 type ContentWrapperMock struct {
-	GetInternalFn             func(contentIds []int64, includeDeleted bool, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SimpleContent]
-	GetInternalAdminModelsFn  func(contentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]frontend.ContentModel]
-	GetTopNotFollowingUsersFn func(userId int64, limit int, offset int, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetTopNotFollowingUsersResponse]
-	GetHashtagsInternalFn     func(hashtags []string, omitHashtags []string, limit int, offset int, withViews null.Bool, apmTransaction *apm.Transaction,
+	GetInternalFn                        func(contentIds []int64, includeDeleted bool, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SimpleContent]
+	GetInternalWithContextFn             func(contentIds []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SimpleContent]
+	GetInternalAdminModelsFn             func(contentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]frontend.ContentModel]
+	GetInternalAdminModelsWithContextFn  func(contentIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]frontend.ContentModel]
+	GetTopNotFollowingUsersFn            func(userId int64, limit int, offset int, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetTopNotFollowingUsersResponse]
+	GetTopNotFollowingUsersWithContextFn func(userId int64, limit int, offset int, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetTopNotFollowingUsersResponse]
+	GetHashtagsInternalFn                func(hashtags []string, omitHashtags []string, limit int, offset int, withViews null.Bool, apmTransaction *apm.Transaction,
 		shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[HashtagResponseData]
+	GetHashtagsInternalWithContextFn func(hashtags []string, omitHashtags []string, limit int, offset int, withViews null.Bool, ctx context.Context, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[HashtagResponseData]
 
 	GetCategoryInternalFn func(categoryIds []int64, omitCategoryIds []int64, limit int, offset int, onlyParent null.Bool, withViews null.Bool, apmTransaction *apm.Transaction,
 		shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[CategoryResponseData]
-	GetAllCategoriesFn             func(categoryIds []int64, includeDeleted bool, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]AllCategoriesResponseItem]
-	GetUserBlacklistedCategoriesFn func(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetUserBlacklistedCategoriesResponse]
-	GetUserLikesFn                 func(userId int64, limit int, offset int, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[LikedContent]
-	GetConfigPropertiesFn          func(properties []string, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[string]string]
-	GetRejectReasonFn              func(ids []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]RejectReason]
-	GetTopUsersInCategoriesFn      func(ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64][]int64]
-	InsertMusicContentFn           func(content MusicContentRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[SimpleContent]
-	GetLastContentFn               func(ctx context.Context, userId int64) chan wrappers.GenericResponseChan[[]SimpleContent]
+	GetCategoryInternalWithContextFn          func(categoryIds []int64, omitCategoryIds []int64, limit int, offset int, onlyParent null.Bool, withViews null.Bool, ctx context.Context, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[CategoryResponseData]
+	GetAllCategoriesFn                        func(categoryIds []int64, includeDeleted bool, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]AllCategoriesResponseItem]
+	GetAllCategoriesWithContextFn             func(categoryIds []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]AllCategoriesResponseItem]
+	GetUserBlacklistedCategoriesFn            func(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetUserBlacklistedCategoriesResponse]
+	GetUserBlacklistedCategoriesWithContextFn func(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetUserBlacklistedCategoriesResponse]
+	GetUserLikesFn                            func(userId int64, limit int, offset int, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[LikedContent]
+	GetUserLikesWithContextFn                 func(userId int64, limit int, offset int, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[LikedContent]
+	GetConfigPropertiesFn                     func(properties []string, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[string]string]
+	GetConfigPropertiesWithContextFn          func(properties []string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[string]string]
+	GetRejectReasonFn                         func(ids []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]RejectReason]
+	GetTopUsersInCategoriesFn                 func(ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64][]int64]
+	InsertMusicContentFn                      func(content MusicContentRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[SimpleContent]
+	GetLastContentFn                          func(ctx context.Context, userId int64) chan wrappers.GenericResponseChan[[]SimpleContent]
 }
 
 func (w *ContentWrapperMock) GetLastContent(ctx context.Context, userId int64) chan wrappers.GenericResponseChan[[]SimpleContent] {
@@ -37,40 +46,112 @@ func (w *ContentWrapperMock) GetInternal(contentIds []int64, includeDeleted bool
 	return w.GetInternalFn(contentIds, includeDeleted, apmTransaction, forceLog)
 }
 
+func (w *ContentWrapperMock) GetInternalWithContext(contentIds []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SimpleContent] {
+	if w.GetInternalWithContextFn != nil {
+		return w.GetInternalWithContextFn(contentIds, includeDeleted, ctx, forceLog)
+	}
+
+	return w.GetInternalFn(contentIds, includeDeleted, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *ContentWrapperMock) GetInternalAdminModels(contentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]frontend.ContentModel] {
 	return w.GetInternalAdminModelsFn(contentIds, apmTransaction, forceLog)
 }
 
+func (w *ContentWrapperMock) GetInternalAdminModelsWithContext(contentIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]frontend.ContentModel] {
+	if w.GetInternalAdminModelsWithContextFn != nil {
+		return w.GetInternalAdminModelsWithContextFn(contentIds, ctx, forceLog)
+	}
+
+	return w.GetInternalAdminModelsFn(contentIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *ContentWrapperMock) GetTopNotFollowingUsers(userId int64, limit int, offset int, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetTopNotFollowingUsersResponse] {
 	return w.GetTopNotFollowingUsersFn(userId, limit, offset, apmTransaction, forceLog)
 }
 
+func (w *ContentWrapperMock) GetTopNotFollowingUsersWithContext(userId int64, limit int, offset int, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetTopNotFollowingUsersResponse] {
+	if w.GetTopNotFollowingUsersWithContextFn != nil {
+		return w.GetTopNotFollowingUsersWithContextFn(userId, limit, offset, ctx, forceLog)
+	}
+
+	return w.GetTopNotFollowingUsersFn(userId, limit, offset, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *ContentWrapperMock) GetHashtagsInternal(hashtags []string, omitHashtags []string, limit int, offset int,
 	withViews null.Bool, apmTransaction *apm.Transaction, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[HashtagResponseData] {
 	return w.GetHashtagsInternalFn(hashtags, omitHashtags, limit, offset, withViews, apmTransaction, shouldHaveValidContent, forceLog)
 }
 
+func (w *ContentWrapperMock) GetHashtagsInternalWithContext(hashtags []string, omitHashtags []string, limit int, offset int, withViews null.Bool, ctx context.Context, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[HashtagResponseData] {
+	if w.GetHashtagsInternalWithContextFn != nil {
+		return w.GetHashtagsInternalWithContextFn(hashtags, omitHashtags, limit, offset, withViews, ctx, shouldHaveValidContent, forceLog)
+	}
+
+	return w.GetHashtagsInternalFn(hashtags, omitHashtags, limit, offset, withViews, apm.TransactionFromContext(ctx), shouldHaveValidContent, forceLog)
+}
+
 func (w *ContentWrapperMock) GetCategoryInternal(categoryIds []int64, omitCategoryIds []int64, limit int, offset int, onlyParent null.Bool, withViews null.Bool,
 	apmTransaction *apm.Transaction, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[CategoryResponseData] {
 	return w.GetCategoryInternalFn(categoryIds, omitCategoryIds, limit, offset, onlyParent, withViews, apmTransaction,
 		shouldHaveValidContent, forceLog)
 }
 
+func (w *ContentWrapperMock) GetCategoryInternalWithContext(categoryIds []int64, omitCategoryIds []int64, limit int, offset int, onlyParent null.Bool, withViews null.Bool, ctx context.Context, shouldHaveValidContent bool, forceLog bool) chan wrappers.GenericResponseChan[CategoryResponseData] {
+	if w.GetCategoryInternalWithContextFn != nil {
+		return w.GetCategoryInternalWithContextFn(categoryIds, omitCategoryIds, limit, offset, onlyParent, withViews, ctx, shouldHaveValidContent, forceLog)
+	}
+
+	return w.GetCategoryInternalFn(categoryIds, omitCategoryIds, limit, offset, onlyParent, withViews, apm.TransactionFromContext(ctx), shouldHaveValidContent, forceLog)
+}
+
 func (w *ContentWrapperMock) GetAllCategories(categoryIds []int64, includeDeleted bool, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[int64]AllCategoriesResponseItem] {
 	return w.GetAllCategoriesFn(categoryIds, includeDeleted, apmTransaction, forceLog)
 }
 
+func (w *ContentWrapperMock) GetAllCategoriesWithContext(categoryIds []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]AllCategoriesResponseItem] {
+	if w.GetAllCategoriesWithContextFn != nil {
+		return w.GetAllCategoriesWithContextFn(categoryIds, includeDeleted, ctx, forceLog)
+	}
+
+	return w.GetAllCategoriesFn(categoryIds, includeDeleted, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *ContentWrapperMock) GetUserBlacklistedCategories(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetUserBlacklistedCategoriesResponse] {
 	return w.GetUserBlacklistedCategoriesFn(userId, apmTransaction, forceLog)
 }
 
+func (w *ContentWrapperMock) GetUserBlacklistedCategoriesWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetUserBlacklistedCategoriesResponse] {
+	if w.GetUserBlacklistedCategoriesWithContextFn != nil {
+		return w.GetUserBlacklistedCategoriesWithContextFn(userId, ctx, forceLog)
+	}
+
+	return w.GetUserBlacklistedCategoriesFn(userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *ContentWrapperMock) GetUserLikes(userId int64, limit int, offset int, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[LikedContent] {
 	return w.GetUserLikesFn(userId, limit, offset, apmTransaction, forceLog)
 }
+
+func (w *ContentWrapperMock) GetUserLikesWithContext(userId int64, limit int, offset int, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[LikedContent] {
+	if w.GetUserLikesWithContextFn != nil {
+		return w.GetUserLikesWithContextFn(userId, limit, offset, ctx, forceLog)
+	}
+
+	return w.GetUserLikesFn(userId, limit, offset, apm.TransactionFromContext(ctx), forceLog)
+}
 func (w *ContentWrapperMock) GetConfigProperties(properties []string, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[string]string] {
 	return w.GetConfigPropertiesFn(properties, apmTransaction, forceLog)
 }
 
+func (w *ContentWrapperMock) GetConfigPropertiesWithContext(properties []string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[string]string] {
+	if w.GetConfigPropertiesWithContextFn != nil {
+		return w.GetConfigPropertiesWithContextFn(properties, ctx, forceLog)
+	}
+
+	return w.GetConfigPropertiesFn(properties, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *ContentWrapperMock) GetRejectReason(ids []int64, includeDeleted bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]RejectReason] {
 	return w.GetRejectReasonFn(ids, includeDeleted, ctx, forceLog)
 }
@@ -83,6 +164,6 @@ func (w *ContentWrapperMock) InsertMusicContent(content MusicContentRequest, ctx
 	return w.InsertMusicContentFn(content, ctx, forceLog)
 }
 
-func GetMock() IContentWrapper { // for compiler errors
+func GetMock() IContentWrapperWithContext { // for compiler errors
 	return &ContentWrapperMock{}
 }
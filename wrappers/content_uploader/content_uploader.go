@@ -17,6 +17,14 @@ type IContentUploaderWrapper interface {
 	UploadContentInternal(url string, contentType string, data []byte, apmTransaction *apm.Transaction, forceLog bool) chan error
 }
 
+// IContentUploaderWrapperWithContext has context-first versions of IContentUploaderWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IContentUploaderWrapper implementation
+type IContentUploaderWrapperWithContext interface {
+	IContentUploaderWrapper
+
+	UploadContentInternalWithContext(url string, contentType string, data []byte, ctx context.Context, forceLog bool) chan error
+}
+
 type ContentUploaderWrapper struct {
 	defaultTimeout time.Duration
 	apiUrl         string
@@ -24,7 +32,7 @@ type ContentUploaderWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
 }
 
-func NewContentUploaderWrapper(config boilerplate.WrapperConfig) IContentUploaderWrapper {
+func NewContentUploaderWrapper(config boilerplate.WrapperConfig) IContentUploaderWrapperWithContext {
 	timeout := 15 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -45,12 +53,16 @@ func NewContentUploaderWrapper(config boilerplate.WrapperConfig) IContentUploade
 	}
 }
 
+// Deprecated: use UploadContentInternalWithContext
 func (w ContentUploaderWrapper) UploadContentInternal(path string, contentType string, data []byte, apmTransaction *apm.Transaction, forceLog bool) chan error {
+	return w.UploadContentInternalWithContext(path, contentType, data, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w ContentUploaderWrapper) UploadContentInternalWithContext(path string, contentType string, data []byte, ctx context.Context, forceLog bool) chan error {
 	resChan := make(chan error, 2)
 
 	go func() {
 		defer close(resChan)
-		ctx := apm.ContextWithTransaction(context.Background(), apmTransaction)
 		resp, err := http_client.DefaultHttpClient.
 			NewRequest(ctx).
 			SetContentType(contentType).
@@ -67,3 +79,21 @@ func (w ContentUploaderWrapper) UploadContentInternal(path string, contentType s
 
 	return resChan
 }
+
+// WithContext returns w when it already implements IContentUploaderWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IContentUploaderWrapper) IContentUploaderWrapperWithContext {
+	if c, ok := w.(IContentUploaderWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IContentUploaderWrapper: w}
+}
+
+type contextAdapter struct {
+	IContentUploaderWrapper
+}
+
+func (a contextAdapter) UploadContentInternalWithContext(url string, contentType string, data []byte, ctx context.Context, forceLog bool) chan error {
+	return a.IContentUploaderWrapper.UploadContentInternal(url, contentType, data, apm.TransactionFromContext(ctx), forceLog)
+}
@@ -1,17 +1,27 @@
 package content_uploader
 
 import (
+	"context"
 	"go.elastic.co/apm"
 )
 
 type ContentUploaderWrapperMock struct {
-	UploadContentInternalFn func(url string, contentType string, data []byte, apmTransaction *apm.Transaction, forceLog bool) chan error
+	UploadContentInternalFn            func(url string, contentType string, data []byte, apmTransaction *apm.Transaction, forceLog bool) chan error
+	UploadContentInternalWithContextFn func(url string, contentType string, data []byte, ctx context.Context, forceLog bool) chan error
 }
 
 func (m ContentUploaderWrapperMock) UploadContentInternal(url string, contentType string, data []byte, apmTransaction *apm.Transaction, forceLog bool) chan error {
 	return m.UploadContentInternalFn(url, contentType, data, apmTransaction, forceLog)
 }
 
-func GetMock() IContentUploaderWrapper { // for compiler errors
+func (m ContentUploaderWrapperMock) UploadContentInternalWithContext(url string, contentType string, data []byte, ctx context.Context, forceLog bool) chan error {
+	if m.UploadContentInternalWithContextFn != nil {
+		return m.UploadContentInternalWithContextFn(url, contentType, data, ctx, forceLog)
+	}
+
+	return m.UploadContentInternalFn(url, contentType, data, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func GetMock() IContentUploaderWrapperWithContext { // for compiler errors
 	return &ContentUploaderWrapperMock{}
 }
@@ -0,0 +1,39 @@
+package wrappers
+
+import (
+	"context"
+)
+
+type userIdContextKey struct{}
+
+type correlationIdContextKey struct{}
+
+// WithUserId stores id of the current user, wrappers send it as common.OriginUserIdHeader
+func WithUserId(ctx context.Context, userId int64) context.Context {
+	if userId <= 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, userIdContextKey{}, userId)
+}
+
+func UserIdFromContext(ctx context.Context) (int64, bool) {
+	userId, ok := ctx.Value(userIdContextKey{}).(int64)
+
+	return userId, ok
+}
+
+// WithCorrelationId stores id of the original request, wrappers send it as common.CorrelationIdHeader
+func WithCorrelationId(ctx context.Context, correlationId string) context.Context {
+	if len(correlationId) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, correlationIdContextKey{}, correlationId)
+}
+
+func CorrelationIdFromContext(ctx context.Context) (string, bool) {
+	correlationId, ok := ctx.Value(correlationIdContextKey{}).(string)
+
+	return correlationId, ok
+}
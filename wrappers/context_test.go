@@ -0,0 +1,64 @@
+package wrappers
+
+import (
+	"context"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"testing"
+	"time"
+)
+
+func TestSendRpcRequestWithContextHeaders(t *testing.T) {
+	headers := make(chan map[string]string, 1)
+
+	url := startTestRpcServer(t, func(ctx *fasthttp.RequestCtx) {
+		headers <- map[string]string{
+			common.OriginUserIdHeader:  string(ctx.Request.Header.Peek(common.OriginUserIdHeader)),
+			common.CorrelationIdHeader: string(ctx.Request.Header.Peek(common.CorrelationIdHeader)),
+		}
+
+		ctx.SetBodyString(`{"jsonrpc":"2.0","id":"1","result":true}`)
+	})
+
+	ctx := WithCorrelationId(WithUserId(context.Background(), 42), "req-1")
+
+	resp := <-GetBaseWrapper().SendRpcRequestWithContext(ctx, url, "Echo", nil, map[string]string{}, time.Second,
+		"ctx-headers", false)
+
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, map[string]string{
+		common.OriginUserIdHeader:  "42",
+		common.CorrelationIdHeader: "req-1",
+	}, <-headers)
+}
+
+func TestSendRpcRequestWithContextCancel(t *testing.T) {
+	url := startTestRpcServer(t, func(ctx *fasthttp.RequestCtx) {
+		time.Sleep(500 * time.Millisecond)
+
+		ctx.SetBodyString(`{"jsonrpc":"2.0","id":"1","result":true}`)
+	})
+
+	b := GetBaseWrapper().WithCircuitBreaker("ctx-cancel", CircuitBreakerConfig{MinRequests: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+
+	resp := <-b.SendRpcRequestWithContext(ctx, url, "Slow", nil, map[string]string{}, 5*time.Second,
+		"ctx-cancel", false)
+
+	assert.Less(t, time.Since(start), 300*time.Millisecond)
+	assert.NotNil(t, resp.Error)
+	assert.Equal(t, error_codes.Timeout, resp.Error.Code)
+	assert.Equal(t, CircuitBreakerClosed, b.GetCircuitBreaker("ctx-cancel").State())
+
+	resp = <-b.SendRpcRequestWithContext(ctx, url, "Slow", nil, map[string]string{}, 5*time.Second,
+		"ctx-cancel", false)
+
+	assert.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "cancelled before sending")
+}
@@ -1,6 +1,7 @@
 package follow
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/digitalmonsters/go-common/boilerplate"
@@ -20,6 +21,17 @@ type IFollowWrapper interface {
 	GetFollowersCount(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetFollowersCountResponseChan
 }
 
+// IFollowWrapperWithContext has context-first versions of IFollowWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IFollowWrapper implementation
+type IFollowWrapperWithContext interface {
+	IFollowWrapper
+
+	GetUserFollowingRelationBulkWithContext(userId int64, requestUserIds []int64, ctx context.Context, forceLog bool) chan GetUserFollowingRelationBulkResponseChan
+	GetUserFollowingRelationWithContext(userId int64, requestUserId int64, ctx context.Context, forceLog bool) chan GetUserFollowingRelationResponseChan
+	GetUserFollowersWithContext(userId int64, pageState string, limit int, ctx context.Context, forceLog bool) chan GetUserFollowersResponseChan
+	GetFollowersCountWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetFollowersCountResponseChan
+}
+
 //goland:noinspection GoNameStartsWithPackageName
 type FollowWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
@@ -28,7 +40,7 @@ type FollowWrapper struct {
 	serviceName    string
 }
 
-func NewFollowWrapper(config boilerplate.WrapperConfig) IFollowWrapper {
+func NewFollowWrapper(config boilerplate.WrapperConfig) IFollowWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -49,13 +61,18 @@ func NewFollowWrapper(config boilerplate.WrapperConfig) IFollowWrapper {
 	}
 }
 
+// Deprecated: use GetUserFollowingRelationBulkWithContext
 func (w *FollowWrapper) GetUserFollowingRelationBulk(userId int64, requestUserIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowingRelationBulkResponseChan {
+	return w.GetUserFollowingRelationBulkWithContext(userId, requestUserIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *FollowWrapper) GetUserFollowingRelationBulkWithContext(userId int64, requestUserIds []int64, ctx context.Context, forceLog bool) chan GetUserFollowingRelationBulkResponseChan {
 	respCh := make(chan GetUserFollowingRelationBulkResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "InternalUserFollowRelationBulk", GetUserFollowingRelationBulkRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "InternalUserFollowRelationBulk", GetUserFollowingRelationBulkRequest{
 		UserId:         userId,
 		RequestUserIds: requestUserIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -92,13 +109,18 @@ func (w *FollowWrapper) GetUserFollowingRelationBulk(userId int64, requestUserId
 	return respCh
 }
 
+// Deprecated: use GetUserFollowingRelationWithContext
 func (w *FollowWrapper) GetUserFollowingRelation(userId int64, requestUserId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowingRelationResponseChan {
+	return w.GetUserFollowingRelationWithContext(userId, requestUserId, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *FollowWrapper) GetUserFollowingRelationWithContext(userId int64, requestUserId int64, ctx context.Context, forceLog bool) chan GetUserFollowingRelationResponseChan {
 	respCh := make(chan GetUserFollowingRelationResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "InternalUserFollowRelation", GetUserFollowingRelationRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "InternalUserFollowRelation", GetUserFollowingRelationRequest{
 		UserId:        userId,
 		RequestUserId: requestUserId,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -134,14 +156,19 @@ func (w *FollowWrapper) GetUserFollowingRelation(userId int64, requestUserId int
 	return respCh
 }
 
+// Deprecated: use GetUserFollowersWithContext
 func (w *FollowWrapper) GetUserFollowers(userId int64, pageState string, limit int, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowersResponseChan {
+	return w.GetUserFollowersWithContext(userId, pageState, limit, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *FollowWrapper) GetUserFollowersWithContext(userId int64, pageState string, limit int, ctx context.Context, forceLog bool) chan GetUserFollowersResponseChan {
 	respCh := make(chan GetUserFollowersResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "InternalGetUserFollowers", GetUserFollowersRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "InternalGetUserFollowers", GetUserFollowersRequest{
 		UserId:    userId,
 		PageState: pageState,
 		Limit:     limit,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -177,12 +204,17 @@ func (w *FollowWrapper) GetUserFollowers(userId int64, pageState string, limit i
 	return respCh
 }
 
+// Deprecated: use GetFollowersCountWithContext
 func (w *FollowWrapper) GetFollowersCount(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetFollowersCountResponseChan {
+	return w.GetFollowersCountWithContext(userIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *FollowWrapper) GetFollowersCountWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetFollowersCountResponseChan {
 	respCh := make(chan GetFollowersCountResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "InternalGetFollowersCount", GetFollowersCountRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "InternalGetFollowersCount", GetFollowersCountRequest{
 		UserIds: userIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -216,3 +248,33 @@ func (w *FollowWrapper) GetFollowersCount(userIds []int64, apmTransaction *apm.T
 
 	return respCh
 }
+
+// WithContext returns w when it already implements IFollowWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IFollowWrapper) IFollowWrapperWithContext {
+	if c, ok := w.(IFollowWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IFollowWrapper: w}
+}
+
+type contextAdapter struct {
+	IFollowWrapper
+}
+
+func (a contextAdapter) GetUserFollowingRelationBulkWithContext(userId int64, requestUserIds []int64, ctx context.Context, forceLog bool) chan GetUserFollowingRelationBulkResponseChan {
+	return a.IFollowWrapper.GetUserFollowingRelationBulk(userId, requestUserIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetUserFollowingRelationWithContext(userId int64, requestUserId int64, ctx context.Context, forceLog bool) chan GetUserFollowingRelationResponseChan {
+	return a.IFollowWrapper.GetUserFollowingRelation(userId, requestUserId, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetUserFollowersWithContext(userId int64, pageState string, limit int, ctx context.Context, forceLog bool) chan GetUserFollowersResponseChan {
+	return a.IFollowWrapper.GetUserFollowers(userId, pageState, limit, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetFollowersCountWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetFollowersCountResponseChan {
+	return a.IFollowWrapper.GetFollowersCount(userIds, apm.TransactionFromContext(ctx), forceLog)
+}
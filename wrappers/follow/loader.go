@@ -0,0 +1,21 @@
+package follow
+
+import (
+	"context"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"time"
+)
+
+// NewFollowingRelationLoader batches GetUserFollowingRelationBulk calls, key Id is request user id
+func NewFollowingRelationLoader(wrapper IFollowWrapper, maxBatch int,
+	wait time.Duration) *wrappers.Loader[wrappers.UserScopedKey, RelationData] {
+	return wrappers.NewUserScopedLoader(func(ctx context.Context, userId int64, requestUserIds []int64) (map[int64]RelationData, error) {
+		resp := <-WithContext(wrapper).GetUserFollowingRelationBulkWithContext(userId, requestUserIds, ctx, false)
+
+		if resp.Error != nil {
+			return nil, resp.Error.ToError()
+		}
+
+		return resp.Data, nil
+	}, maxBatch, wait)
+}
@@ -1,31 +1,70 @@
 package follow
 
-import "go.elastic.co/apm"
+import (
+	"context"
+	"go.elastic.co/apm"
+)
 
 //goland:noinspection GoNameStartsWithPackageName
 type FollowWrapperMock struct {
-	GetUserFollowingRelationBulkFn func(userId int64, requestUserIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowingRelationBulkResponseChan
-	GetUserFollowingRelationFn     func(userId int64, requestUserId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowingRelationResponseChan
-	GetUserFollowersFn             func(userId int64, pageState string, limit int, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowersResponseChan
-	GetFollowersCountFn            func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetFollowersCountResponseChan
+	GetUserFollowingRelationBulkFn            func(userId int64, requestUserIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowingRelationBulkResponseChan
+	GetUserFollowingRelationBulkWithContextFn func(userId int64, requestUserIds []int64, ctx context.Context, forceLog bool) chan GetUserFollowingRelationBulkResponseChan
+	GetUserFollowingRelationFn                func(userId int64, requestUserId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowingRelationResponseChan
+	GetUserFollowingRelationWithContextFn     func(userId int64, requestUserId int64, ctx context.Context, forceLog bool) chan GetUserFollowingRelationResponseChan
+	GetUserFollowersFn                        func(userId int64, pageState string, limit int, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowersResponseChan
+	GetUserFollowersWithContextFn             func(userId int64, pageState string, limit int, ctx context.Context, forceLog bool) chan GetUserFollowersResponseChan
+	GetFollowersCountFn                       func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetFollowersCountResponseChan
+	GetFollowersCountWithContextFn            func(userIds []int64, ctx context.Context, forceLog bool) chan GetFollowersCountResponseChan
 }
 
 func (w *FollowWrapperMock) GetUserFollowingRelationBulk(userId int64, requestUserIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowingRelationBulkResponseChan {
 	return w.GetUserFollowingRelationBulkFn(userId, requestUserIds, apmTransaction, forceLog)
 }
 
+func (w *FollowWrapperMock) GetUserFollowingRelationBulkWithContext(userId int64, requestUserIds []int64, ctx context.Context, forceLog bool) chan GetUserFollowingRelationBulkResponseChan {
+	if w.GetUserFollowingRelationBulkWithContextFn != nil {
+		return w.GetUserFollowingRelationBulkWithContextFn(userId, requestUserIds, ctx, forceLog)
+	}
+
+	return w.GetUserFollowingRelationBulkFn(userId, requestUserIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *FollowWrapperMock) GetUserFollowingRelation(userId int64, requestUserId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowingRelationResponseChan {
 	return w.GetUserFollowingRelationFn(userId, requestUserId, apmTransaction, forceLog)
 }
 
+func (w *FollowWrapperMock) GetUserFollowingRelationWithContext(userId int64, requestUserId int64, ctx context.Context, forceLog bool) chan GetUserFollowingRelationResponseChan {
+	if w.GetUserFollowingRelationWithContextFn != nil {
+		return w.GetUserFollowingRelationWithContextFn(userId, requestUserId, ctx, forceLog)
+	}
+
+	return w.GetUserFollowingRelationFn(userId, requestUserId, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *FollowWrapperMock) GetUserFollowers(userId int64, pageState string, limit int, apmTransaction *apm.Transaction, forceLog bool) chan GetUserFollowersResponseChan {
 	return w.GetUserFollowersFn(userId, pageState, limit, apmTransaction, forceLog)
 }
 
+func (w *FollowWrapperMock) GetUserFollowersWithContext(userId int64, pageState string, limit int, ctx context.Context, forceLog bool) chan GetUserFollowersResponseChan {
+	if w.GetUserFollowersWithContextFn != nil {
+		return w.GetUserFollowersWithContextFn(userId, pageState, limit, ctx, forceLog)
+	}
+
+	return w.GetUserFollowersFn(userId, pageState, limit, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *FollowWrapperMock) GetFollowersCount(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetFollowersCountResponseChan {
 	return w.GetFollowersCountFn(userIds, apmTransaction, forceLog)
 }
 
-func GetMock() IFollowWrapper { // for compiler errors
+func (w *FollowWrapperMock) GetFollowersCountWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetFollowersCountResponseChan {
+	if w.GetFollowersCountWithContextFn != nil {
+		return w.GetFollowersCountWithContextFn(userIds, ctx, forceLog)
+	}
+
+	return w.GetFollowersCountFn(userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func GetMock() IFollowWrapperWithContext { // for compiler errors
 	return &FollowWrapperMock{}
 }
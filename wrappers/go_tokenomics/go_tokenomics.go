@@ -35,7 +35,19 @@ type IGoTokenomicsWrapper interface {
 	WriteOffUserTokensForAd(userId int64, adCampaignId int64, amount decimal.Decimal, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any]
 }
 
-func NewGoTokenomicsWrapper(config boilerplate.WrapperConfig) IGoTokenomicsWrapper {
+// IGoTokenomicsWrapperWithContext has context-first versions of IGoTokenomicsWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IGoTokenomicsWrapper implementation
+type IGoTokenomicsWrapperWithContext interface {
+	IGoTokenomicsWrapper
+
+	GetContentEarningsTotalByContentIdsWithContext(contentIds []int64, ctx context.Context, forceLog bool) chan GetContentEarningsTotalByContentIdsResponseChan
+	GetTokenomicsStatsByUserIdWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetTokenomicsStatsByUserIdResponseChan
+	GetConfigPropertiesWithContext(properties []string, ctx context.Context, forceLog bool) chan GetConfigPropertiesResponseChan
+	GetReferralsInfoWithContext(referrerId int64, referralIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetReferralInfoResponse]
+	GetActivitiesInfoWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetActivitiesInfoResponse]
+}
+
+func NewGoTokenomicsWrapper(config boilerplate.WrapperConfig) IGoTokenomicsWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -48,33 +60,40 @@ func NewGoTokenomicsWrapper(config boilerplate.WrapperConfig) IGoTokenomicsWrapp
 		log.Warn().Msgf("Api Url is missing for GoTokenomics. Setting as default : %v", config.ApiUrl)
 	}
 
+	serviceName := "go tokenomics"
+
 	return &Wrapper{
-		baseWrapper:    wrappers.GetBaseWrapper(),
+		baseWrapper:    wrappers.GetBaseWrapper().WithDefaultCircuitBreaker(serviceName, wrappers.CircuitBreakerConfig{}),
 		defaultTimeout: timeout,
 		apiUrl:         fmt.Sprintf("%v/rpc-service", common.StripSlashFromUrl(config.ApiUrl)),
-		serviceName:    "go tokenomics",
+		serviceName:    serviceName,
 	}
 }
 
 func (w *Wrapper) GetUsersTokenomicsInfo(userIds []int64, filters []filters.Filter, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserTokenomicsInfo] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]UserTokenomicsInfo](w.baseWrapper, w.apiUrl, "GetUsersTokenomicsInfo", GetUsersTokenomicsInfoRequest{
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]UserTokenomicsInfo](ctx, w.baseWrapper, w.apiUrl, "GetUsersTokenomicsInfo", GetUsersTokenomicsInfoRequest{
 		UserIds: userIds,
 		Filters: filters,
-	}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w *Wrapper) GetWithdrawalsAmountsByAdminIds(adminIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]decimal.Decimal] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]decimal.Decimal](w.baseWrapper, w.apiUrl, "GetWithdrawalsAmountsByAdminIds", GetWithdrawalsAmountsByAdminIdsRequest{
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]decimal.Decimal](ctx, w.baseWrapper, w.apiUrl, "GetWithdrawalsAmountsByAdminIds", GetWithdrawalsAmountsByAdminIdsRequest{
 		AdminIds: adminIds,
-	}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetContentEarningsTotalByContentIdsWithContext
 func (w *Wrapper) GetContentEarningsTotalByContentIds(contentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetContentEarningsTotalByContentIdsResponseChan {
+	return w.GetContentEarningsTotalByContentIdsWithContext(contentIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *Wrapper) GetContentEarningsTotalByContentIdsWithContext(contentIds []int64, ctx context.Context, forceLog bool) chan GetContentEarningsTotalByContentIdsResponseChan {
 	respCh := make(chan GetContentEarningsTotalByContentIdsResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetContentEarningsTotal", GetContentEarningsTotalByContentIdsRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetContentEarningsTotal", GetContentEarningsTotalByContentIdsRequest{
 		ContentIds: contentIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -108,12 +127,17 @@ func (w *Wrapper) GetContentEarningsTotalByContentIds(contentIds []int64, apmTra
 	return respCh
 }
 
+// Deprecated: use GetTokenomicsStatsByUserIdWithContext
 func (w *Wrapper) GetTokenomicsStatsByUserId(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetTokenomicsStatsByUserIdResponseChan {
+	return w.GetTokenomicsStatsByUserIdWithContext(userIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *Wrapper) GetTokenomicsStatsByUserIdWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetTokenomicsStatsByUserIdResponseChan {
 	respCh := make(chan GetTokenomicsStatsByUserIdResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetTokenomicsStatsByUserId", GetTokenomicsStatsByUserIdRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetTokenomicsStatsByUserId", GetTokenomicsStatsByUserIdRequest{
 		UserIds: userIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -148,11 +172,16 @@ func (w *Wrapper) GetTokenomicsStatsByUserId(userIds []int64, apmTransaction *ap
 	return respCh
 }
 
+// Deprecated: use GetConfigPropertiesWithContext
 func (w *Wrapper) GetConfigProperties(properties []string, apmTransaction *apm.Transaction, forceLog bool) chan GetConfigPropertiesResponseChan {
+	return w.GetConfigPropertiesWithContext(properties, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *Wrapper) GetConfigPropertiesWithContext(properties []string, ctx context.Context, forceLog bool) chan GetConfigPropertiesResponseChan {
 	respCh := make(chan GetConfigPropertiesResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetConfigProperties", GetConfigPropertiesRequest{Properties: properties},
-		map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetConfigProperties", GetConfigPropertiesRequest{Properties: properties},
+		map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -186,29 +215,73 @@ func (w *Wrapper) GetConfigProperties(properties []string, apmTransaction *apm.T
 	return respCh
 }
 
+// Deprecated: use GetReferralsInfoWithContext
 func (w *Wrapper) GetReferralsInfo(referrerId int64, referralIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetReferralInfoResponse] {
-	return wrappers.ExecuteRpcRequestAsync[GetReferralInfoResponse](w.baseWrapper, w.apiUrl, "GetReferralsInfo", GetReferralInfoRequest{
+	return w.GetReferralsInfoWithContext(referrerId, referralIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *Wrapper) GetReferralsInfoWithContext(referrerId int64, referralIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetReferralInfoResponse] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[GetReferralInfoResponse](ctx, w.baseWrapper, w.apiUrl, "GetReferralsInfo", GetReferralInfoRequest{
 		ReferralIds: referralIds,
 		ReferrerId:  referrerId,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetActivitiesInfoWithContext
 func (w *Wrapper) GetActivitiesInfo(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetActivitiesInfoResponse] {
-	return wrappers.ExecuteRpcRequestAsync[GetActivitiesInfoResponse](w.baseWrapper, w.apiUrl, "GetActivitiesInfo", GetActivitiesInfoRequest{
+	return w.GetActivitiesInfoWithContext(userId, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *Wrapper) GetActivitiesInfoWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetActivitiesInfoResponse] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[GetActivitiesInfoResponse](ctx, w.baseWrapper, w.apiUrl, "GetActivitiesInfo", GetActivitiesInfoRequest{
 		UserId: userId,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w *Wrapper) CreateBotViews(botViews map[int64][]int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any] {
-	return wrappers.ExecuteRpcRequestAsync[any](w.baseWrapper, w.apiUrl, "CreateBotViews", CreateBotViewsRequest{
+	return wrappers.ExecuteRpcRequestAsyncWithContext[any](ctx, w.baseWrapper, w.apiUrl, "CreateBotViews", CreateBotViewsRequest{
 		BotViews: botViews,
-	}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w *Wrapper) WriteOffUserTokensForAd(userId int64, adCampaignId int64, amount decimal.Decimal, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any] {
-	return wrappers.ExecuteRpcRequestAsync[any](w.baseWrapper, w.apiUrl, "WriteOffUserTokensForAd", WriteOffUserTokensForAdRequest{
+	return wrappers.ExecuteRpcRequestAsyncWithContext[any](ctx, w.baseWrapper, w.apiUrl, "WriteOffUserTokensForAd", WriteOffUserTokensForAdRequest{
 		UserId:       userId,
 		AdCampaignId: adCampaignId,
 		Amount:       amount,
-	}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
+}
+
+// WithContext returns w when it already implements IGoTokenomicsWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IGoTokenomicsWrapper) IGoTokenomicsWrapperWithContext {
+	if c, ok := w.(IGoTokenomicsWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IGoTokenomicsWrapper: w}
+}
+
+type contextAdapter struct {
+	IGoTokenomicsWrapper
+}
+
+func (a contextAdapter) GetContentEarningsTotalByContentIdsWithContext(contentIds []int64, ctx context.Context, forceLog bool) chan GetContentEarningsTotalByContentIdsResponseChan {
+	return a.IGoTokenomicsWrapper.GetContentEarningsTotalByContentIds(contentIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetTokenomicsStatsByUserIdWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetTokenomicsStatsByUserIdResponseChan {
+	return a.IGoTokenomicsWrapper.GetTokenomicsStatsByUserId(userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetConfigPropertiesWithContext(properties []string, ctx context.Context, forceLog bool) chan GetConfigPropertiesResponseChan {
+	return a.IGoTokenomicsWrapper.GetConfigProperties(properties, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetReferralsInfoWithContext(referrerId int64, referralIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetReferralInfoResponse] {
+	return a.IGoTokenomicsWrapper.GetReferralsInfo(referrerId, referralIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetActivitiesInfoWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetActivitiesInfoResponse] {
+	return a.IGoTokenomicsWrapper.GetActivitiesInfo(userId, apm.TransactionFromContext(ctx), forceLog)
 }
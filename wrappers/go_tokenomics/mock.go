@@ -9,15 +9,20 @@ import (
 )
 
 type GoTokenomicsWrapperMock struct {
-	GetUsersTokenomicsInfoFn              func(userIds []int64, filters []filters.Filter, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserTokenomicsInfo]
-	GetWithdrawalsAmountsByAdminIdsFn     func(adminIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]decimal.Decimal]
-	GetContentEarningsTotalByContentIdsFn func(contentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetContentEarningsTotalByContentIdsResponseChan
-	GetTokenomicsStatsByUserIdFn          func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetTokenomicsStatsByUserIdResponseChan
-	GetConfigPropertiesFn                 func(properties []string, apmTransaction *apm.Transaction, forceLog bool) chan GetConfigPropertiesResponseChan
-	GetReferralsInfoFn                    func(referrerId int64, referralIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetReferralInfoResponse]
-	GetActivitiesInfoFn                   func(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetActivitiesInfoResponse]
-	CreateBotViewsFn                      func(botViews map[int64][]int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any]
-	WriteOffUserTokensForAdFn             func(userId int64, adCampaignId int64, amount decimal.Decimal, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any]
+	GetUsersTokenomicsInfoFn                         func(userIds []int64, filters []filters.Filter, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserTokenomicsInfo]
+	GetWithdrawalsAmountsByAdminIdsFn                func(adminIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]decimal.Decimal]
+	GetContentEarningsTotalByContentIdsFn            func(contentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetContentEarningsTotalByContentIdsResponseChan
+	GetContentEarningsTotalByContentIdsWithContextFn func(contentIds []int64, ctx context.Context, forceLog bool) chan GetContentEarningsTotalByContentIdsResponseChan
+	GetTokenomicsStatsByUserIdFn                     func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetTokenomicsStatsByUserIdResponseChan
+	GetTokenomicsStatsByUserIdWithContextFn          func(userIds []int64, ctx context.Context, forceLog bool) chan GetTokenomicsStatsByUserIdResponseChan
+	GetConfigPropertiesFn                            func(properties []string, apmTransaction *apm.Transaction, forceLog bool) chan GetConfigPropertiesResponseChan
+	GetConfigPropertiesWithContextFn                 func(properties []string, ctx context.Context, forceLog bool) chan GetConfigPropertiesResponseChan
+	GetReferralsInfoFn                               func(referrerId int64, referralIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetReferralInfoResponse]
+	GetReferralsInfoWithContextFn                    func(referrerId int64, referralIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetReferralInfoResponse]
+	GetActivitiesInfoFn                              func(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetActivitiesInfoResponse]
+	GetActivitiesInfoWithContextFn                   func(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetActivitiesInfoResponse]
+	CreateBotViewsFn                                 func(botViews map[int64][]int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any]
+	WriteOffUserTokensForAdFn                        func(userId int64, adCampaignId int64, amount decimal.Decimal, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any]
 }
 
 func (w *GoTokenomicsWrapperMock) GetUsersTokenomicsInfo(userIds []int64, filters []filters.Filter, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserTokenomicsInfo] {
@@ -32,20 +37,60 @@ func (w *GoTokenomicsWrapperMock) GetContentEarningsTotalByContentIds(contentIds
 	return w.GetContentEarningsTotalByContentIdsFn(contentIds, apmTransaction, forceLog)
 }
 
+func (w *GoTokenomicsWrapperMock) GetContentEarningsTotalByContentIdsWithContext(contentIds []int64, ctx context.Context, forceLog bool) chan GetContentEarningsTotalByContentIdsResponseChan {
+	if w.GetContentEarningsTotalByContentIdsWithContextFn != nil {
+		return w.GetContentEarningsTotalByContentIdsWithContextFn(contentIds, ctx, forceLog)
+	}
+
+	return w.GetContentEarningsTotalByContentIdsFn(contentIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *GoTokenomicsWrapperMock) GetTokenomicsStatsByUserId(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetTokenomicsStatsByUserIdResponseChan {
 	return w.GetTokenomicsStatsByUserIdFn(userIds, apmTransaction, forceLog)
 }
 
+func (w *GoTokenomicsWrapperMock) GetTokenomicsStatsByUserIdWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetTokenomicsStatsByUserIdResponseChan {
+	if w.GetTokenomicsStatsByUserIdWithContextFn != nil {
+		return w.GetTokenomicsStatsByUserIdWithContextFn(userIds, ctx, forceLog)
+	}
+
+	return w.GetTokenomicsStatsByUserIdFn(userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *GoTokenomicsWrapperMock) GetConfigProperties(properties []string, apmTransaction *apm.Transaction, forceLog bool) chan GetConfigPropertiesResponseChan {
 	return w.GetConfigPropertiesFn(properties, apmTransaction, forceLog)
 }
+
+func (w *GoTokenomicsWrapperMock) GetConfigPropertiesWithContext(properties []string, ctx context.Context, forceLog bool) chan GetConfigPropertiesResponseChan {
+	if w.GetConfigPropertiesWithContextFn != nil {
+		return w.GetConfigPropertiesWithContextFn(properties, ctx, forceLog)
+	}
+
+	return w.GetConfigPropertiesFn(properties, apm.TransactionFromContext(ctx), forceLog)
+}
 func (w *GoTokenomicsWrapperMock) GetReferralsInfo(referrerId int64, referralIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetReferralInfoResponse] {
 	return w.GetReferralsInfoFn(referrerId, referralIds, apmTransaction, forceLog)
 }
+
+func (w *GoTokenomicsWrapperMock) GetReferralsInfoWithContext(referrerId int64, referralIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetReferralInfoResponse] {
+	if w.GetReferralsInfoWithContextFn != nil {
+		return w.GetReferralsInfoWithContextFn(referrerId, referralIds, ctx, forceLog)
+	}
+
+	return w.GetReferralsInfoFn(referrerId, referralIds, apm.TransactionFromContext(ctx), forceLog)
+}
 func (w *GoTokenomicsWrapperMock) GetActivitiesInfo(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetActivitiesInfoResponse] {
 	return w.GetActivitiesInfoFn(userId, apmTransaction, forceLog)
 }
 
+func (w *GoTokenomicsWrapperMock) GetActivitiesInfoWithContext(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetActivitiesInfoResponse] {
+	if w.GetActivitiesInfoWithContextFn != nil {
+		return w.GetActivitiesInfoWithContextFn(userId, ctx, forceLog)
+	}
+
+	return w.GetActivitiesInfoFn(userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *GoTokenomicsWrapperMock) CreateBotViews(botViews map[int64][]int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any] {
 	return w.CreateBotViewsFn(botViews, ctx, forceLog)
 }
@@ -54,6 +99,6 @@ func (w *GoTokenomicsWrapperMock) WriteOffUserTokensForAd(userId int64, adCampai
 	return w.WriteOffUserTokensForAdFn(userId, adCampaignId, amount, ctx, forceLog)
 }
 
-func GetMock() IGoTokenomicsWrapper {
+func GetMock() IGoTokenomicsWrapperWithContext {
 	return &GoTokenomicsWrapperMock{}
 }
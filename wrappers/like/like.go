@@ -25,6 +25,18 @@ type ILikeWrapper interface {
 	AddLikesInternal(likeEvents []eventsourcing.LikeEvent, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AddLikesResponse]
 }
 
+// ILikeWrapperWithContext has context-first versions of ILikeWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any ILikeWrapper implementation
+type ILikeWrapperWithContext interface {
+	ILikeWrapper
+
+	GetInternalLikedByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalLikedByUserResponseChan
+	GetInternalDislikedByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalDislikedByUserResponseChan
+	GetInternalSpotReactionsByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalSpotReactionsByUserResponseChan
+	GetLastLikesByUsersWithContext(userIds []int64, limitPerUser int, ctx context.Context, forceLog bool) chan LastLikedByUserResponseChan
+	GetInternalUserLikesWithContext(userId int64, size int, pageState string, ctx context.Context, forceLog bool) chan GetInternalUserLikesResponseChan
+}
+
 //goland:noinspection GoNameStartsWithPackageName
 type LikeWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
@@ -33,7 +45,7 @@ type LikeWrapper struct {
 	serviceName    string
 }
 
-func NewLikeWrapper(config boilerplate.WrapperConfig) ILikeWrapper {
+func NewLikeWrapper(config boilerplate.WrapperConfig) ILikeWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -54,14 +66,18 @@ func NewLikeWrapper(config boilerplate.WrapperConfig) ILikeWrapper {
 	}
 }
 
-func (w *LikeWrapper) GetLastLikesByUsers(userIds []int64, limitPerUser int, apmTransaction *apm.Transaction,
-	forceLog bool) chan LastLikedByUserResponseChan {
+// Deprecated: use GetLastLikesByUsersWithContext
+func (w *LikeWrapper) GetLastLikesByUsers(userIds []int64, limitPerUser int, apmTransaction *apm.Transaction, forceLog bool) chan LastLikedByUserResponseChan {
+	return w.GetLastLikesByUsersWithContext(userIds, limitPerUser, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *LikeWrapper) GetLastLikesByUsersWithContext(userIds []int64, limitPerUser int, ctx context.Context, forceLog bool) chan LastLikedByUserResponseChan {
 	respCh := make(chan LastLikedByUserResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetLastLikesByUsers", GetLatestLikedByUserRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetLastLikesByUsers", GetLatestLikedByUserRequest{
 		LimitPerUser: limitPerUser,
 		UserIds:      userIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -96,13 +112,18 @@ func (w *LikeWrapper) GetLastLikesByUsers(userIds []int64, limitPerUser int, apm
 	return respCh
 }
 
+// Deprecated: use GetInternalLikedByUserWithContext
 func (w *LikeWrapper) GetInternalLikedByUser(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalLikedByUserResponseChan {
+	return w.GetInternalLikedByUserWithContext(contentIds, userId, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *LikeWrapper) GetInternalLikedByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalLikedByUserResponseChan {
 	respCh := make(chan GetInternalLikedByUserResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetInternalLikedByUserBulk", GetInternalLikedByUserRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetInternalLikedByUserBulk", GetInternalLikedByUserRequest{
 		UserId:     userId,
 		ContentIds: contentIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -137,13 +158,18 @@ func (w *LikeWrapper) GetInternalLikedByUser(contentIds []int64, userId int64, a
 	return respCh
 }
 
+// Deprecated: use GetInternalDislikedByUserWithContext
 func (w *LikeWrapper) GetInternalDislikedByUser(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalDislikedByUserResponseChan {
+	return w.GetInternalDislikedByUserWithContext(contentIds, userId, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *LikeWrapper) GetInternalDislikedByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalDislikedByUserResponseChan {
 	respCh := make(chan GetInternalDislikedByUserResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetInternalDislikedByUserBulk", GetInternalDislikedByUserRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetInternalDislikedByUserBulk", GetInternalDislikedByUserRequest{
 		UserId:     userId,
 		ContentIds: contentIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -178,13 +204,18 @@ func (w *LikeWrapper) GetInternalDislikedByUser(contentIds []int64, userId int64
 	return respCh
 }
 
+// Deprecated: use GetInternalSpotReactionsByUserWithContext
 func (w *LikeWrapper) GetInternalSpotReactionsByUser(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalSpotReactionsByUserResponseChan {
+	return w.GetInternalSpotReactionsByUserWithContext(contentIds, userId, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *LikeWrapper) GetInternalSpotReactionsByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalSpotReactionsByUserResponseChan {
 	respCh := make(chan GetInternalSpotReactionsByUserResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetInternalSpotReactionsByUserBulk", GetInternalSpotReactionsByUserRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetInternalSpotReactionsByUserBulk", GetInternalSpotReactionsByUserRequest{
 		UserId:     userId,
 		ContentIds: contentIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -219,14 +250,19 @@ func (w *LikeWrapper) GetInternalSpotReactionsByUser(contentIds []int64, userId
 	return respCh
 }
 
+// Deprecated: use GetInternalUserLikesWithContext
 func (w *LikeWrapper) GetInternalUserLikes(userId int64, size int, pageState string, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalUserLikesResponseChan {
+	return w.GetInternalUserLikesWithContext(userId, size, pageState, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *LikeWrapper) GetInternalUserLikesWithContext(userId int64, size int, pageState string, ctx context.Context, forceLog bool) chan GetInternalUserLikesResponseChan {
 	respCh := make(chan GetInternalUserLikesResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetInternalUserLikes", GetInternalUserLikesRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetInternalUserLikes", GetInternalUserLikesRequest{
 		UserId:    userId,
 		Size:      size,
 		PageState: pageState,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -262,8 +298,42 @@ func (w *LikeWrapper) GetInternalUserLikes(userId int64, size int, pageState str
 }
 
 func (w LikeWrapper) AddLikesInternal(likeEvents []eventsourcing.LikeEvent, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AddLikesResponse] {
-	return wrappers.ExecuteRpcRequestAsync[AddLikesResponse](w.baseWrapper, w.apiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[AddLikesResponse](ctx, w.baseWrapper, w.apiUrl,
 		"AddLikesInternal", AddLikesRequest{
 			LikeEvents: likeEvents,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
+}
+
+// WithContext returns w when it already implements ILikeWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w ILikeWrapper) ILikeWrapperWithContext {
+	if c, ok := w.(ILikeWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{ILikeWrapper: w}
+}
+
+type contextAdapter struct {
+	ILikeWrapper
+}
+
+func (a contextAdapter) GetInternalLikedByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalLikedByUserResponseChan {
+	return a.ILikeWrapper.GetInternalLikedByUser(contentIds, userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetInternalDislikedByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalDislikedByUserResponseChan {
+	return a.ILikeWrapper.GetInternalDislikedByUser(contentIds, userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetInternalSpotReactionsByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalSpotReactionsByUserResponseChan {
+	return a.ILikeWrapper.GetInternalSpotReactionsByUser(contentIds, userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetLastLikesByUsersWithContext(userIds []int64, limitPerUser int, ctx context.Context, forceLog bool) chan LastLikedByUserResponseChan {
+	return a.ILikeWrapper.GetLastLikesByUsers(userIds, limitPerUser, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetInternalUserLikesWithContext(userId int64, size int, pageState string, ctx context.Context, forceLog bool) chan GetInternalUserLikesResponseChan {
+	return a.ILikeWrapper.GetInternalUserLikes(userId, size, pageState, apm.TransactionFromContext(ctx), forceLog)
 }
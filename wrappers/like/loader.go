@@ -0,0 +1,21 @@
+package like
+
+import (
+	"context"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"time"
+)
+
+// NewLikedByUserLoader batches GetInternalLikedByUser calls, key Id is content id
+func NewLikedByUserLoader(wrapper ILikeWrapper, maxBatch int,
+	wait time.Duration) *wrappers.Loader[wrappers.UserScopedKey, bool] {
+	return wrappers.NewUserScopedLoader(func(ctx context.Context, userId int64, contentIds []int64) (map[int64]bool, error) {
+		resp := <-WithContext(wrapper).GetInternalLikedByUserWithContext(contentIds, userId, ctx, false)
+
+		if resp.Error != nil {
+			return nil, resp.Error.ToError()
+		}
+
+		return resp.Data, nil
+	}, maxBatch, wait)
+}
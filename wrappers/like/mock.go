@@ -9,35 +9,80 @@ import (
 
 //goland:noinspection ALL
 type LikeWrapperMock struct {
-	GetInternalLikedByUserFn         func(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalLikedByUserResponseChan
-	GetInternalDislikedByUserFn      func(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalDislikedByUserResponseChan
-	GetInternalSpotReactionsByUserFn func(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalSpotReactionsByUserResponseChan
+	GetInternalLikedByUserFn                    func(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalLikedByUserResponseChan
+	GetInternalLikedByUserWithContextFn         func(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalLikedByUserResponseChan
+	GetInternalDislikedByUserFn                 func(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalDislikedByUserResponseChan
+	GetInternalDislikedByUserWithContextFn      func(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalDislikedByUserResponseChan
+	GetInternalSpotReactionsByUserFn            func(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalSpotReactionsByUserResponseChan
+	GetInternalSpotReactionsByUserWithContextFn func(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalSpotReactionsByUserResponseChan
 
-	GetLastLikesByUsersFn  func(userIds []int64, limitPerUser int, apmTransaction *apm.Transaction, forceLog bool) chan LastLikedByUserResponseChan
-	GetInternalUserLikesFn func(userId int64, size int, pageState string, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalUserLikesResponseChan
-	AddLikesInternalFn     func(likeEvents []eventsourcing.LikeEvent, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AddLikesResponse]
+	GetLastLikesByUsersFn             func(userIds []int64, limitPerUser int, apmTransaction *apm.Transaction, forceLog bool) chan LastLikedByUserResponseChan
+	GetLastLikesByUsersWithContextFn  func(userIds []int64, limitPerUser int, ctx context.Context, forceLog bool) chan LastLikedByUserResponseChan
+	GetInternalUserLikesFn            func(userId int64, size int, pageState string, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalUserLikesResponseChan
+	GetInternalUserLikesWithContextFn func(userId int64, size int, pageState string, ctx context.Context, forceLog bool) chan GetInternalUserLikesResponseChan
+	AddLikesInternalFn                func(likeEvents []eventsourcing.LikeEvent, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AddLikesResponse]
 }
 
 func (w *LikeWrapperMock) GetInternalLikedByUser(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalLikedByUserResponseChan {
 	return w.GetInternalLikedByUserFn(contentIds, userId, apmTransaction, forceLog)
 }
+
+func (w *LikeWrapperMock) GetInternalLikedByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalLikedByUserResponseChan {
+	if w.GetInternalLikedByUserWithContextFn != nil {
+		return w.GetInternalLikedByUserWithContextFn(contentIds, userId, ctx, forceLog)
+	}
+
+	return w.GetInternalLikedByUserFn(contentIds, userId, apm.TransactionFromContext(ctx), forceLog)
+}
 func (w *LikeWrapperMock) GetInternalDislikedByUser(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalDislikedByUserResponseChan {
 	return w.GetInternalDislikedByUserFn(contentIds, userId, apmTransaction, forceLog)
 }
+
+func (w *LikeWrapperMock) GetInternalDislikedByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalDislikedByUserResponseChan {
+	if w.GetInternalDislikedByUserWithContextFn != nil {
+		return w.GetInternalDislikedByUserWithContextFn(contentIds, userId, ctx, forceLog)
+	}
+
+	return w.GetInternalDislikedByUserFn(contentIds, userId, apm.TransactionFromContext(ctx), forceLog)
+}
 func (w *LikeWrapperMock) GetInternalSpotReactionsByUser(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalSpotReactionsByUserResponseChan {
 	return w.GetInternalSpotReactionsByUserFn(contentIds, userId, apmTransaction, forceLog)
 }
 
+func (w *LikeWrapperMock) GetInternalSpotReactionsByUserWithContext(contentIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetInternalSpotReactionsByUserResponseChan {
+	if w.GetInternalSpotReactionsByUserWithContextFn != nil {
+		return w.GetInternalSpotReactionsByUserWithContextFn(contentIds, userId, ctx, forceLog)
+	}
+
+	return w.GetInternalSpotReactionsByUserFn(contentIds, userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *LikeWrapperMock) GetLastLikesByUsers(userIds []int64, limitPerUser int, apmTransaction *apm.Transaction, forceLog bool) chan LastLikedByUserResponseChan {
 	return w.GetLastLikesByUsersFn(userIds, limitPerUser, apmTransaction, forceLog)
 }
+
+func (w *LikeWrapperMock) GetLastLikesByUsersWithContext(userIds []int64, limitPerUser int, ctx context.Context, forceLog bool) chan LastLikedByUserResponseChan {
+	if w.GetLastLikesByUsersWithContextFn != nil {
+		return w.GetLastLikesByUsersWithContextFn(userIds, limitPerUser, ctx, forceLog)
+	}
+
+	return w.GetLastLikesByUsersFn(userIds, limitPerUser, apm.TransactionFromContext(ctx), forceLog)
+}
 func (w *LikeWrapperMock) GetInternalUserLikes(userId int64, size int, pageState string, apmTransaction *apm.Transaction, forceLog bool) chan GetInternalUserLikesResponseChan {
 	return w.GetInternalUserLikesFn(userId, size, pageState, apmTransaction, forceLog)
 }
+
+func (w *LikeWrapperMock) GetInternalUserLikesWithContext(userId int64, size int, pageState string, ctx context.Context, forceLog bool) chan GetInternalUserLikesResponseChan {
+	if w.GetInternalUserLikesWithContextFn != nil {
+		return w.GetInternalUserLikesWithContextFn(userId, size, pageState, ctx, forceLog)
+	}
+
+	return w.GetInternalUserLikesFn(userId, size, pageState, apm.TransactionFromContext(ctx), forceLog)
+}
 func (w *LikeWrapperMock) AddLikesInternal(likeEvents []eventsourcing.LikeEvent, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AddLikesResponse] {
 	return w.AddLikesInternalFn(likeEvents, ctx, forceLog)
 }
 
-func GetMock() ILikeWrapper { // for compiler errors
+func GetMock() ILikeWrapperWithContext { // for compiler errors
 	return &LikeWrapperMock{}
 }
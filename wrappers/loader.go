@@ -0,0 +1,326 @@
+package wrappers
+
+import (
+	"context"
+	"fmt"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/pkg/errors"
+	"go.elastic.co/apm"
+	"sync"
+	"time"
+)
+
+const defaultLoaderWait = 2 * time.Millisecond
+
+// LoaderBatchFn loads values of keys with one bulk request, missing keys are reported as not found
+type LoaderBatchFn[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+type loaderBatch[K comparable, V any] struct {
+	keys       []K
+	seen       map[K]struct{}
+	dispatched bool
+	done       chan struct{}
+	result     map[K]V
+	err        error
+}
+
+// Loader merges concurrent loads within wait window into one batchFn call with unique keys.
+// It is safe to share one loader between requests, results are cached only in request scoped
+// cache created by WithLoaderCache. Batch can contain keys of several requests, so batchFn gets
+// context of its own apm transaction without user or correlation id of any caller.
+type Loader[K comparable, V any] struct {
+	batchFn  LoaderBatchFn[K, V]
+	maxBatch int
+	wait     time.Duration
+	mutex    sync.Mutex
+	pending  *loaderBatch[K, V]
+	inFlight map[K]*loaderBatch[K, V]
+}
+
+// NewLoader creates loader, maxBatch <= 0 means unlimited batch size, wait <= 0 is 2ms
+func NewLoader[K comparable, V any](batchFn LoaderBatchFn[K, V], maxBatch int, wait time.Duration) *Loader[K, V] {
+	if wait <= 0 {
+		wait = defaultLoaderWait
+	}
+
+	return &Loader[K, V]{
+		batchFn:  batchFn,
+		maxBatch: maxBatch,
+		wait:     wait,
+		inFlight: map[K]*loaderBatch[K, V]{},
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, bool, error) {
+	values, err := l.LoadMany(ctx, []K{key})
+
+	value, ok := values[key]
+
+	return value, ok, err
+}
+
+// LoadMany returns found values, keys missing in the result were not found by batchFn
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) (map[K]V, error) {
+	result := make(map[K]V, len(keys))
+	cache := getLoaderCache[K, V](ctx, l)
+
+	var missing []K
+
+	for _, key := range keys {
+		if entry, ok := cache.get(key); ok {
+			if entry.found {
+				result[key] = entry.value
+			}
+
+			continue
+		}
+
+		missing = append(missing, key)
+	}
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	span, _ := apm.StartSpan(ctx, "loader wait", "loader")
+	defer span.End()
+
+	batches := l.enqueue(missing)
+
+	for key, batch := range batches {
+		select {
+		case <-batch.done:
+		case <-ctx.Done():
+			return result, errors.WithStack(ctx.Err())
+		}
+
+		if batch.err != nil {
+			return result, batch.err
+		}
+
+		value, found := batch.result[key]
+
+		if found {
+			result[key] = value
+		}
+
+		cache.set(key, value, found)
+	}
+
+	return result, nil
+}
+
+func (l *Loader[K, V]) enqueue(keys []K) map[K]*loaderBatch[K, V] {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	batches := make(map[K]*loaderBatch[K, V], len(keys))
+
+	for _, key := range keys {
+		if batch, ok := l.inFlight[key]; ok {
+			batches[key] = batch
+
+			continue
+		}
+
+		if l.pending == nil {
+			batch := &loaderBatch[K, V]{
+				seen: map[K]struct{}{},
+				done: make(chan struct{}),
+			}
+
+			l.pending = batch
+
+			time.AfterFunc(l.wait, func() {
+				l.mutex.Lock()
+				defer l.mutex.Unlock()
+
+				l.dispatchLocked(batch)
+			})
+		}
+
+		batch := l.pending
+		batches[key] = batch
+
+		if _, ok := batch.seen[key]; ok {
+			continue
+		}
+
+		batch.seen[key] = struct{}{}
+		batch.keys = append(batch.keys, key)
+
+		if l.maxBatch > 0 && len(batch.keys) >= l.maxBatch {
+			l.dispatchLocked(batch)
+		}
+	}
+
+	return batches
+}
+
+func (l *Loader[K, V]) dispatchLocked(batch *loaderBatch[K, V]) {
+	if batch.dispatched {
+		return
+	}
+
+	batch.dispatched = true
+
+	if l.pending == batch {
+		l.pending = nil
+	}
+
+	for _, key := range batch.keys {
+		l.inFlight[key] = batch
+	}
+
+	go l.run(batch)
+}
+
+func (l *Loader[K, V]) run(batch *loaderBatch[K, V]) {
+	tx := apm_helper.StartNewApmTransaction("loader batch", "loader", nil, nil)
+	tx.Context.SetLabel("keys", len(batch.keys))
+
+	defer func() {
+		if r := recover(); r != nil {
+			batch.err = errors.New(fmt.Sprintf("loader batch panic: %v", r))
+		}
+
+		l.mutex.Lock()
+
+		for _, key := range batch.keys {
+			if l.inFlight[key] == batch {
+				delete(l.inFlight, key)
+			}
+		}
+
+		l.mutex.Unlock()
+
+		close(batch.done)
+
+		tx.End()
+	}()
+
+	batch.result, batch.err = l.batchFn(apm.ContextWithTransaction(context.Background(), tx), batch.keys)
+}
+
+// UserScopedKey is a key of bulk methods, which load items (content, users) in scope of one user,
+// like relations or likes of the user
+type UserScopedKey struct {
+	UserId int64
+	Id     int64
+}
+
+// NewUserScopedLoader calls batchFn concurrently for every user of the batch
+func NewUserScopedLoader[V any](batchFn func(ctx context.Context, userId int64, ids []int64) (map[int64]V, error),
+	maxBatch int, wait time.Duration) *Loader[UserScopedKey, V] {
+	return NewLoader(func(ctx context.Context, keys []UserScopedKey) (map[UserScopedKey]V, error) {
+		idsByUser := map[int64][]int64{}
+
+		for _, key := range keys {
+			idsByUser[key.UserId] = append(idsByUser[key.UserId], key.Id)
+		}
+
+		var wg sync.WaitGroup
+		var mutex sync.Mutex
+		var resultErr error
+
+		result := make(map[UserScopedKey]V, len(keys))
+
+		for userId, ids := range idsByUser {
+			wg.Add(1)
+
+			go func(userId int64, ids []int64) {
+				defer wg.Done()
+
+				values, err := batchFn(ctx, userId, ids)
+
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				if err != nil {
+					resultErr = err
+
+					return
+				}
+
+				for id, value := range values {
+					result[UserScopedKey{UserId: userId, Id: id}] = value
+				}
+			}(userId, ids)
+		}
+
+		wg.Wait()
+
+		return result, resultErr
+	}, maxBatch, wait)
+}
+
+type loaderCacheContextKey struct{}
+
+type loaderCacheEntry[V any] struct {
+	value V
+	found bool
+}
+
+type loaderCache struct {
+	mutex   sync.Mutex
+	loaders map[interface{}]interface{}
+}
+
+// WithLoaderCache attaches request scoped cache of loader results to ctx, router does it for
+// MethodExecutionData.Context
+func WithLoaderCache(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(loaderCacheContextKey{}).(*loaderCache); ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, loaderCacheContextKey{}, &loaderCache{loaders: map[interface{}]interface{}{}})
+}
+
+type loaderRequestCache[K comparable, V any] struct {
+	cache   *loaderCache
+	entries map[K]loaderCacheEntry[V]
+}
+
+func getLoaderCache[K comparable, V any](ctx context.Context, loader *Loader[K, V]) loaderRequestCache[K, V] {
+	cache, ok := ctx.Value(loaderCacheContextKey{}).(*loaderCache)
+
+	if !ok {
+		return loaderRequestCache[K, V]{}
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entries, ok := cache.loaders[loader].(map[K]loaderCacheEntry[V])
+
+	if !ok {
+		entries = map[K]loaderCacheEntry[V]{}
+		cache.loaders[loader] = entries
+	}
+
+	return loaderRequestCache[K, V]{cache: cache, entries: entries}
+}
+
+func (c loaderRequestCache[K, V]) get(key K) (loaderCacheEntry[V], bool) {
+	if c.cache == nil {
+		return loaderCacheEntry[V]{}, false
+	}
+
+	c.cache.mutex.Lock()
+	defer c.cache.mutex.Unlock()
+
+	entry, ok := c.entries[key]
+
+	return entry, ok
+}
+
+func (c loaderRequestCache[K, V]) set(key K, value V, found bool) {
+	if c.cache == nil {
+		return
+	}
+
+	c.cache.mutex.Lock()
+	defer c.cache.mutex.Unlock()
+
+	c.entries[key] = loaderCacheEntry[V]{value: value, found: found}
+}
@@ -0,0 +1,185 @@
+package wrappers
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.elastic.co/apm"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+type loaderCalls struct {
+	mutex sync.Mutex
+	keys  [][]int64
+}
+
+func (c *loaderCalls) batchFn(ctx context.Context, keys []int64) (map[int64]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	sorted := append([]int64{}, keys...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	c.keys = append(c.keys, sorted)
+
+	result := map[int64]string{}
+
+	for _, key := range keys {
+		if key > 0 {
+			result[key] = fmt.Sprintf("user%v", key)
+		}
+	}
+
+	return result, nil
+}
+
+func TestLoaderCoalescesConcurrentLoads(t *testing.T) {
+	calls := &loaderCalls{}
+	loader := NewLoader[int64, string](calls.batchFn, 0, 20*time.Millisecond)
+
+	var wg sync.WaitGroup
+
+	for _, ids := range [][]int64{{1, 2}, {2, 3}, {3, -1}} {
+		wg.Add(1)
+
+		go func(ids []int64) {
+			defer wg.Done()
+
+			values, err := loader.LoadMany(context.Background(), ids)
+
+			assert.Nil(t, err)
+
+			for _, id := range ids {
+				if id > 0 {
+					assert.Equal(t, fmt.Sprintf("user%v", id), values[id])
+				} else {
+					assert.NotContains(t, values, id)
+				}
+			}
+		}(ids)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, [][]int64{{-1, 1, 2, 3}}, calls.keys)
+}
+
+func TestLoaderMaxBatch(t *testing.T) {
+	calls := &loaderCalls{}
+	loader := NewLoader[int64, string](calls.batchFn, 2, time.Second)
+
+	values, err := loader.LoadMany(context.Background(), []int64{1, 2, 3, 4})
+
+	assert.Nil(t, err)
+	assert.Len(t, values, 4)
+	assert.ElementsMatch(t, [][]int64{{1, 2}, {3, 4}}, calls.keys)
+}
+
+func TestLoaderRequestCache(t *testing.T) {
+	calls := &loaderCalls{}
+	loader := NewLoader[int64, string](calls.batchFn, 0, time.Millisecond)
+
+	ctx := WithLoaderCache(context.Background())
+
+	value, found, err := loader.Load(ctx, 1)
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "user1", value)
+
+	_, found, err = loader.Load(ctx, -1)
+	assert.Nil(t, err)
+	assert.False(t, found)
+
+	values, err := loader.LoadMany(ctx, []int64{1, -1})
+	assert.Nil(t, err)
+	assert.Equal(t, map[int64]string{1: "user1"}, values)
+	assert.Len(t, calls.keys, 2) // served from request cache
+
+	_, _, _ = loader.Load(WithLoaderCache(context.Background()), 1)
+	assert.Len(t, calls.keys, 3)
+}
+
+func TestLoaderError(t *testing.T) {
+	fail := true
+	loader := NewLoader[int64, string](func(ctx context.Context, keys []int64) (map[int64]string, error) {
+		if fail {
+			return nil, errors.New("unavailable")
+		}
+
+		return map[int64]string{1: "user1"}, nil
+	}, 0, time.Millisecond)
+
+	ctx := WithLoaderCache(context.Background())
+
+	_, _, err := loader.Load(ctx, 1)
+	assert.NotNil(t, err)
+
+	fail = false
+
+	value, _, err := loader.Load(ctx, 1) // errors are not cached
+	assert.Nil(t, err)
+	assert.Equal(t, "user1", value)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = NewLoader[int64, string](func(ctx context.Context, keys []int64) (map[int64]string, error) {
+		time.Sleep(50 * time.Millisecond)
+
+		return nil, nil
+	}, 0, time.Millisecond).Load(cancelled, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestUserScopedLoader(t *testing.T) {
+	var mutex sync.Mutex
+
+	requests := map[int64][]int64{}
+
+	loader := NewUserScopedLoader(func(ctx context.Context, userId int64, ids []int64) (map[int64]bool, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		requests[userId] = append(requests[userId], ids...)
+
+		result := map[int64]bool{}
+
+		for _, id := range ids {
+			result[id] = id%2 == 0
+		}
+
+		return result, nil
+	}, 0, time.Millisecond)
+
+	values, err := loader.LoadMany(context.Background(), []UserScopedKey{{UserId: 1, Id: 2}, {UserId: 1, Id: 3},
+		{UserId: 2, Id: 2}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[UserScopedKey]bool{{UserId: 1, Id: 2}: true, {UserId: 1, Id: 3}: false,
+		{UserId: 2, Id: 2}: true}, values)
+	assert.Len(t, requests, 2)
+	assert.Len(t, requests[1], 2)
+}
+
+func TestLoaderBatchContextHasNoCallerData(t *testing.T) {
+	loader := NewLoader[int64, bool](func(ctx context.Context, keys []int64) (map[int64]bool, error) {
+		_, hasUser := UserIdFromContext(ctx)
+		_, hasCorrelation := CorrelationIdFromContext(ctx)
+
+		assert.False(t, hasUser)
+		assert.False(t, hasCorrelation)
+		assert.NotNil(t, apm.TransactionFromContext(ctx))
+
+		return map[int64]bool{keys[0]: true}, nil
+	}, 0, 0)
+
+	ctx := WithCorrelationId(WithUserId(context.Background(), 7), "req-1")
+
+	_, found, err := loader.Load(ctx, 1)
+
+	assert.Nil(t, err)
+	assert.True(t, found)
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/go-common/wrappers"
 	"github.com/rs/zerolog/log"
-	"go.elastic.co/apm"
 	"time"
 )
 
@@ -45,8 +44,8 @@ func NewMusicWrapper(config boilerplate.WrapperConfig) IMusicWrapper {
 }
 
 func (w MusicWrapper) GetMusicInternal(ids []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]SimpleMusic] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]SimpleMusic](w.baseWrapper, w.apiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]SimpleMusic](ctx, w.baseWrapper, w.apiUrl,
 		"GetMusicInternal", GetMusicInternalRequests{
 			Ids: ids,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
@@ -6,10 +6,12 @@ import (
 )
 
 type NotificationGatewayWrapperMock struct {
-	SendSmsInternalFn    func(message string, phoneNumber string, apmTransaction *apm.Transaction, forceLog bool) chan SendSmsMessageResponseChan
-	SendEmailInternalFn  func(ccAddresses, toAddresses []string, htmlBody, textBody, subject string, apmTransaction *apm.Transaction, forceLog bool) chan SendEmailMessageResponseChan
-	EnqueuePushForUserFn func(msg []SendPushRequest, ctx context.Context) chan error
-	EnqueueEmailFn       func(msg []SendEmailMessageRequest, ctx context.Context) chan error
+	SendSmsInternalFn              func(message string, phoneNumber string, apmTransaction *apm.Transaction, forceLog bool) chan SendSmsMessageResponseChan
+	SendSmsInternalWithContextFn   func(message string, phoneNumber string, ctx context.Context, forceLog bool) chan SendSmsMessageResponseChan
+	SendEmailInternalFn            func(ccAddresses, toAddresses []string, htmlBody, textBody, subject string, apmTransaction *apm.Transaction, forceLog bool) chan SendEmailMessageResponseChan
+	SendEmailInternalWithContextFn func(ccAddresses, toAddresses []string, htmlBody, textBody, subject string, ctx context.Context, forceLog bool) chan SendEmailMessageResponseChan
+	EnqueuePushForUserFn           func(msg []SendPushRequest, ctx context.Context) chan error
+	EnqueueEmailFn                 func(msg []SendEmailMessageRequest, ctx context.Context) chan error
 }
 
 func (w *NotificationGatewayWrapperMock) EnqueuePushForUser(msg []SendPushRequest, ctx context.Context) chan error {
@@ -24,10 +26,26 @@ func (w *NotificationGatewayWrapperMock) SendSmsInternal(message string, phoneNu
 	return w.SendSmsInternalFn(message, phoneNumber, apmTransaction, forceLog)
 }
 
+func (w *NotificationGatewayWrapperMock) SendSmsInternalWithContext(message string, phoneNumber string, ctx context.Context, forceLog bool) chan SendSmsMessageResponseChan {
+	if w.SendSmsInternalWithContextFn != nil {
+		return w.SendSmsInternalWithContextFn(message, phoneNumber, ctx, forceLog)
+	}
+
+	return w.SendSmsInternalFn(message, phoneNumber, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (w *NotificationGatewayWrapperMock) SendEmailInternal(ccAddresses, toAddresses []string, htmlBody, textBody, subject string, apmTransaction *apm.Transaction, forceLog bool) chan SendEmailMessageResponseChan {
 	return w.SendEmailInternalFn(ccAddresses, toAddresses, htmlBody, textBody, subject, apmTransaction, forceLog)
 }
 
-func GetMock() INotificationGatewayWrapper {
+func (w *NotificationGatewayWrapperMock) SendEmailInternalWithContext(ccAddresses, toAddresses []string, htmlBody, textBody, subject string, ctx context.Context, forceLog bool) chan SendEmailMessageResponseChan {
+	if w.SendEmailInternalWithContextFn != nil {
+		return w.SendEmailInternalWithContextFn(ccAddresses, toAddresses, htmlBody, textBody, subject, ctx, forceLog)
+	}
+
+	return w.SendEmailInternalFn(ccAddresses, toAddresses, htmlBody, textBody, subject, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func GetMock() INotificationGatewayWrapperWithContext {
 	return &NotificationGatewayWrapperMock{}
 }
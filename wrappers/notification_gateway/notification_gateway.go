@@ -29,7 +29,16 @@ type INotificationGatewayWrapper interface {
 	EnqueueEmail(msg []SendEmailMessageRequest, ctx context.Context) chan error
 }
 
-func NewNotificationGatewayWrapper(config boilerplate.WrapperConfig) INotificationGatewayWrapper {
+// INotificationGatewayWrapperWithContext has context-first versions of INotificationGatewayWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any INotificationGatewayWrapper implementation
+type INotificationGatewayWrapperWithContext interface {
+	INotificationGatewayWrapper
+
+	SendSmsInternalWithContext(message string, phoneNumber string, ctx context.Context, forceLog bool) chan SendSmsMessageResponseChan
+	SendEmailInternalWithContext(ccAddresses, toAddresses []string, htmlBody, textBody, subject string, ctx context.Context, forceLog bool) chan SendEmailMessageResponseChan
+}
+
+func NewNotificationGatewayWrapper(config boilerplate.WrapperConfig) INotificationGatewayWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -133,13 +142,18 @@ func (w *Wrapper) EnqueueEmail(msg []SendEmailMessageRequest, ctx context.Contex
 	return ch
 }
 
+// Deprecated: use SendSmsInternalWithContext
 func (w *Wrapper) SendSmsInternal(message string, phoneNumber string, apmTransaction *apm.Transaction, forceLog bool) chan SendSmsMessageResponseChan {
+	return w.SendSmsInternalWithContext(message, phoneNumber, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *Wrapper) SendSmsInternalWithContext(message string, phoneNumber string, ctx context.Context, forceLog bool) chan SendSmsMessageResponseChan {
 	respCh := make(chan SendSmsMessageResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "SendSmsInternal", SendSmsMessageRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "SendSmsInternal", SendSmsMessageRequest{
 		Message:     message,
 		PhoneNumber: phoneNumber,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -157,16 +171,21 @@ func (w *Wrapper) SendSmsInternal(message string, phoneNumber string, apmTransac
 	return respCh
 }
 
+// Deprecated: use SendEmailInternalWithContext
 func (w *Wrapper) SendEmailInternal(ccAddresses, toAddresses []string, htmlBody, textBody, subject string, apmTransaction *apm.Transaction, forceLog bool) chan SendEmailMessageResponseChan {
+	return w.SendEmailInternalWithContext(ccAddresses, toAddresses, htmlBody, textBody, subject, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *Wrapper) SendEmailInternalWithContext(ccAddresses, toAddresses []string, htmlBody, textBody, subject string, ctx context.Context, forceLog bool) chan SendEmailMessageResponseChan {
 	respCh := make(chan SendEmailMessageResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "SendEmailInternal", SendEmailMessageRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "SendEmailInternal", SendEmailMessageRequest{
 		CcAddresses: ccAddresses,
 		ToAddresses: toAddresses,
 		HtmlBody:    htmlBody,
 		TextBody:    textBody,
 		Subject:     subject,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -183,3 +202,25 @@ func (w *Wrapper) SendEmailInternal(ccAddresses, toAddresses []string, htmlBody,
 
 	return respCh
 }
+
+// WithContext returns w when it already implements INotificationGatewayWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w INotificationGatewayWrapper) INotificationGatewayWrapperWithContext {
+	if c, ok := w.(INotificationGatewayWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{INotificationGatewayWrapper: w}
+}
+
+type contextAdapter struct {
+	INotificationGatewayWrapper
+}
+
+func (a contextAdapter) SendSmsInternalWithContext(message string, phoneNumber string, ctx context.Context, forceLog bool) chan SendSmsMessageResponseChan {
+	return a.INotificationGatewayWrapper.SendSmsInternal(message, phoneNumber, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) SendEmailInternalWithContext(ccAddresses, toAddresses []string, htmlBody, textBody, subject string, ctx context.Context, forceLog bool) chan SendEmailMessageResponseChan {
+	return a.INotificationGatewayWrapper.SendEmailInternal(ccAddresses, toAddresses, htmlBody, textBody, subject, apm.TransactionFromContext(ctx), forceLog)
+}
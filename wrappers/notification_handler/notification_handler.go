@@ -147,13 +147,13 @@ func (h *NotificationHandlerWrapper) EnqueueNotificationWithCustomTemplate(title
 }
 
 func (h *NotificationHandlerWrapper) GetNotificationsReadCount(notificationIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]int64] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]int64](h.baseWrapper, h.apiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]int64](ctx, h.baseWrapper, h.apiUrl,
 		"GetNotificationsReadCount", GetNotificationsReadCountRequest{NotificationIds: notificationIds},
-		map[string]string{}, h.defaultTimeout, apm.TransactionFromContext(ctx), h.serviceName, forceLog)
+		map[string]string{}, h.defaultTimeout, h.serviceName, forceLog)
 }
 
 func (h *NotificationHandlerWrapper) DisableUnregisteredTokens(tokens []string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[[]string] {
-	return wrappers.ExecuteRpcRequestAsync[[]string](h.baseWrapper, h.apiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[[]string](ctx, h.baseWrapper, h.apiUrl,
 		"DisableUnregisteredTokens", DisableUnregisteredTokensRequest{Tokens: tokens},
-		map[string]string{}, h.defaultTimeout, apm.TransactionFromContext(ctx), h.serviceName, forceLog)
+		map[string]string{}, h.defaultTimeout, h.serviceName, forceLog)
 }
@@ -0,0 +1,213 @@
+package wrappers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/valyala/fasthttp"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 50 * time.Millisecond
+	defaultRetryMaxBackoff     = 1 * time.Second
+	defaultRetryMultiplier     = 2
+	defaultRetryJitter         = 0.2
+
+	// idempotencyKeyHeader is the same as router.IdempotencyKeyHeader, requests with it are safe to retry
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+var wrapperRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "wrappers_request_retries_total",
+	Help: "Retried requests to other services",
+}, []string{"service", "method"})
+
+// RetryPolicy is applied only to methods declared as safe or idempotent,
+// MaxAttempts includes the first attempt
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter is a fraction of backoff randomly added or subtracted, 0.2 by default
+	Jitter float64
+	// RetryStatusCodes are 502 and 503 by default. Timeouts and connection errors are always retried
+	RetryStatusCodes []int
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultRetryMaxAttempts
+	}
+
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultRetryInitialBackoff
+	}
+
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultRetryMaxBackoff
+	}
+
+	if p.Multiplier < 1 {
+		p.Multiplier = defaultRetryMultiplier
+	}
+
+	if p.Jitter <= 0 {
+		p.Jitter = defaultRetryJitter
+	}
+
+	if len(p.RetryStatusCodes) == 0 {
+		p.RetryStatusCodes = []int{fasthttp.StatusBadGateway, fasthttp.StatusServiceUnavailable}
+	}
+
+	return p
+}
+
+// Backoff returns delay before the next attempt, attempt starts from 1
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+
+	if backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+
+	backoff += backoff * p.Jitter * (rand.Float64()*2 - 1)
+
+	return time.Duration(backoff)
+}
+
+func (p RetryPolicy) shouldRetry(err error, statusCode int) bool {
+	if err != nil {
+		var netErr net.Error
+
+		return errors.Is(err, fasthttp.ErrTimeout) || errors.Is(err, fasthttp.ErrConnectionClosed) ||
+			errors.Is(err, fasthttp.ErrNoFreeConns) || errors.As(err, &netErr)
+	}
+
+	for _, code := range p.RetryStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+type serviceRetryPolicies struct {
+	service *RetryPolicy
+	methods map[string]RetryPolicy
+}
+
+type retryPolicies struct {
+	mutex    sync.RWMutex
+	services map[string]*serviceRetryPolicies
+}
+
+func (r *retryPolicies) get(externalServiceName string) *serviceRetryPolicies {
+	if r.services == nil {
+		r.services = map[string]*serviceRetryPolicies{}
+	}
+
+	policies, ok := r.services[externalServiceName]
+
+	if !ok {
+		policies = &serviceRetryPolicies{methods: map[string]RetryPolicy{}}
+		r.services[externalServiceName] = policies
+	}
+
+	return policies
+}
+
+func (r *retryPolicies) set(externalServiceName string, policy RetryPolicy, idempotentMethods []string) {
+	policy = policy.withDefaults()
+	policies := r.get(externalServiceName)
+	policies.service = &policy
+
+	for _, method := range idempotentMethods {
+		policies.methods[strings.ToLower(method)] = policy
+	}
+}
+
+// WithRetryPolicy retries idempotentMethods of the service with policy. Requests with Idempotency-Key header
+// are retried with the same policy, other methods are sent once.
+func (b *BaseWrapper) WithRetryPolicy(externalServiceName string, policy RetryPolicy,
+	idempotentMethods ...string) *BaseWrapper {
+	b.retryPolicies.mutex.Lock()
+	defer b.retryPolicies.mutex.Unlock()
+
+	b.retryPolicies.set(externalServiceName, policy, idempotentMethods)
+
+	return b
+}
+
+// WithDefaultRetryPolicy is WithRetryPolicy unless the service already has retry policies. Wrappers use it,
+// so policies set by the service before creating them are kept
+func (b *BaseWrapper) WithDefaultRetryPolicy(externalServiceName string, policy RetryPolicy,
+	idempotentMethods ...string) *BaseWrapper {
+	b.retryPolicies.mutex.Lock()
+	defer b.retryPolicies.mutex.Unlock()
+
+	if _, ok := b.retryPolicies.services[externalServiceName]; !ok {
+		b.retryPolicies.set(externalServiceName, policy, idempotentMethods)
+	}
+
+	return b
+}
+
+// WithMethodRetryPolicy declares method as idempotent with own policy, it overrides policy of the service
+func (b *BaseWrapper) WithMethodRetryPolicy(externalServiceName string, methodName string,
+	policy RetryPolicy) *BaseWrapper {
+	b.retryPolicies.mutex.Lock()
+	defer b.retryPolicies.mutex.Unlock()
+
+	b.retryPolicies.get(externalServiceName).methods[strings.ToLower(methodName)] = policy.withDefaults()
+
+	return b
+}
+
+func (b *BaseWrapper) getRetryPolicy(externalServiceName string, methodName string,
+	headers map[string]string) *RetryPolicy {
+	b.retryPolicies.mutex.RLock()
+	defer b.retryPolicies.mutex.RUnlock()
+
+	policies, ok := b.retryPolicies.services[externalServiceName]
+
+	if !ok {
+		return nil
+	}
+
+	if policy, ok := policies.methods[strings.ToLower(methodName)]; ok {
+		return &policy
+	}
+
+	if policies.service != nil && len(headers[idempotencyKeyHeader]) > 0 {
+		return policies.service
+	}
+
+	return nil
+}
+
+// waitBackoff returns false when ctx is done or its deadline comes before the backoff is over
+func waitBackoff(ctx context.Context, backoff time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= backoff {
+		return false
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,133 @@
+package wrappers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func startFlakyRpcServer(t *testing.T, failures int32, fail func(ctx *fasthttp.RequestCtx)) (string, *int32) {
+	var calls int32
+
+	url := startTestRpcServer(t, func(ctx *fasthttp.RequestCtx) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			fail(ctx)
+
+			return
+		}
+
+		var req rpc.RpcRequest
+
+		assert.Nil(t, json.Unmarshal(ctx.PostBody(), &req))
+
+		b, _ := json.Marshal(map[string]interface{}{"id": req.Id, "result": req.Params})
+		ctx.Response.SetBodyRaw(b)
+	})
+
+	return url, &calls
+}
+
+func unavailable(ctx *fasthttp.RequestCtx) {
+	ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+}
+
+func TestRetryIdempotentMethod(t *testing.T) {
+	url, calls := startFlakyRpcServer(t, 2, unavailable)
+
+	b := GetBaseWrapper().WithRetryPolicy("retry-test", testRetryPolicy, "GetItems")
+
+	resp := <-ExecuteRpcRequestAsyncWithContext[map[string]int](context.Background(), b, url, "GetItems",
+		map[string]int{"a": 1}, nil, time.Second, "retry-test", false)
+
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, map[string]int{"a": 1}, resp.Response)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+
+	url, calls = startFlakyRpcServer(t, 5, unavailable)
+
+	resp = <-ExecuteRpcRequestAsyncWithContext[map[string]int](context.Background(), b, url, "GetItems",
+		map[string]int{"a": 1}, nil, time.Second, "retry-test", false)
+
+	assert.NotNil(t, resp.Error)
+	assert.Equal(t, int32(defaultRetryMaxAttempts), atomic.LoadInt32(calls))
+}
+
+func TestRetryNotIdempotentMethod(t *testing.T) {
+	url, calls := startFlakyRpcServer(t, 1, unavailable)
+
+	b := GetBaseWrapper().WithRetryPolicy("retry-test-2", testRetryPolicy, "GetItems")
+
+	resp := <-ExecuteRpcRequestAsyncWithContext[map[string]int](context.Background(), b, url, "CreateItem",
+		nil, nil, time.Second, "retry-test-2", false)
+
+	assert.NotNil(t, resp.Error)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	resp = <-ExecuteRpcRequestAsyncWithContext[map[string]int](context.Background(), b, url, "CreateItem",
+		map[string]int{"a": 2}, map[string]string{idempotencyKeyHeader: "key"}, time.Second, "retry-test-2", false)
+
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, map[string]int{"a": 2}, resp.Response)
+}
+
+func TestRetryTimeout(t *testing.T) {
+	url, calls := startFlakyRpcServer(t, 1, func(ctx *fasthttp.RequestCtx) {
+		time.Sleep(200 * time.Millisecond)
+	})
+
+	b := GetBaseWrapper().WithMethodRetryPolicy("retry-test-3", "GetItems", testRetryPolicy)
+
+	resp := <-ExecuteRpcRequestAsyncWithContext[map[string]int](context.Background(), b, url, "GetItems",
+		map[string]int{"a": 3}, nil, 50*time.Millisecond, "retry-test-3", false)
+
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, map[string]int{"a": 3}, resp.Response) // body is sent again after timeout
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestRetryRespectsDeadline(t *testing.T) {
+	url, calls := startFlakyRpcServer(t, 5, unavailable)
+
+	b := GetBaseWrapper().WithMethodRetryPolicy("retry-test-4", "GetItems",
+		RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	resp := <-ExecuteRpcRequestAsyncWithContext[map[string]int](ctx, b, url, "GetItems",
+		nil, nil, time.Second, "retry-test-4", false)
+
+	assert.NotNil(t, resp.Error)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}.withDefaults()
+
+	for attempt, expected := range map[int]time.Duration{1: 100, 2: 200, 3: 300, 10: 300} {
+		backoff := policy.Backoff(attempt)
+
+		assert.GreaterOrEqual(t, backoff, expected*time.Millisecond*8/10)
+		assert.LessOrEqual(t, backoff, expected*time.Millisecond*12/10)
+	}
+}
+
+func TestDefaultRetryPolicyKeepsServicePolicy(t *testing.T) {
+	b := GetBaseWrapper().WithRetryPolicy("retry-default-test", RetryPolicy{MaxAttempts: 5}, "GetItems")
+
+	b.WithDefaultRetryPolicy("retry-default-test", RetryPolicy{}, "GetItems", "GetOther")
+
+	assert.Equal(t, 5, b.getRetryPolicy("retry-default-test", "GetItems", nil).MaxAttempts)
+	assert.Nil(t, b.getRetryPolicy("retry-default-test", "GetOther", nil))
+
+	b.WithDefaultRetryPolicy("retry-default-other-test", RetryPolicy{}, "GetItems")
+
+	assert.Equal(t, defaultRetryMaxAttempts, b.getRetryPolicy("retry-default-other-test", "GetItems", nil).MaxAttempts)
+}
@@ -0,0 +1,172 @@
+package wrappers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/pkg/errors"
+	"go.elastic.co/apm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RpcBatchCall struct {
+	MethodName string
+	Request    interface{}
+}
+
+// Deprecated: use SendRpcBatchRequestWithContext
+func (b *BaseWrapper) SendRpcBatchRequest(url string, calls []RpcBatchCall, headers map[string]string, timeout time.Duration,
+	apmTransaction *apm.Transaction, externalServiceName string, forceLog bool) chan []rpc.RpcResponseInternal {
+	return b.SendRpcBatchRequestWithContext(apm.ContextWithTransaction(context.Background(), apmTransaction), url, calls,
+		headers, timeout, externalServiceName, forceLog)
+}
+
+// SendRpcBatchRequestWithContext sends all calls as one json-rpc batch. Responses are returned in the same order as calls.
+func (b *BaseWrapper) SendRpcBatchRequestWithContext(ctx context.Context, url string, calls []RpcBatchCall,
+	headers map[string]string, timeout time.Duration, externalServiceName string,
+	forceLog bool) chan []rpc.RpcResponseInternal {
+	responseCh := make(chan []rpc.RpcResponseInternal, 2)
+
+	requests := make([]rpc.RpcRequestInternal, len(calls))
+	methodNames := make([]string, len(calls))
+
+	for i, c := range calls {
+		methodNames[i] = strings.ToLower(c.MethodName)
+
+		requests[i] = rpc.RpcRequestInternal{
+			Method:  methodNames[i],
+			Params:  c.Request,
+			Id:      strconv.Itoa(i + 1),
+			JsonRpc: "2.0",
+		}
+	}
+
+	go func() {
+		results := make([]rpc.RpcResponseInternal, len(calls))
+
+		if len(calls) == 0 {
+			responseCh <- results
+			close(responseCh)
+
+			return
+		}
+
+		apiResponse := <-b.sendHttpRequestAsync(ctx, url, fmt.Sprintf("batch [%v]", strings.Join(methodNames, ",")),
+			requests, headers, forceLog, timeout, "application/json", "POST", externalServiceName)
+
+		defer func() {
+			close(responseCh)
+
+			endRpcSpan(apiResponse.rawBodyRequest, apiResponse.rawBodyResponse, externalServiceName, apiResponse.span,
+				apiResponse.forceLog)
+		}()
+
+		fillAll := func(rpcErr *rpc.RpcError) {
+			for i := range results {
+				copied := *rpcErr
+				results[i] = rpc.RpcResponseInternal{
+					JsonRpc: "2.0",
+					Id:      requests[i].Id,
+					Error:   &copied,
+				}
+			}
+		}
+
+		if apiResponse.error != nil { // its timeout, or some internal error, not logical error
+			fillAll(&rpc.RpcError{
+				Code:        httpErrorCode(apiResponse.error),
+				Message:     apiResponse.error.Error(),
+				Stack:       fmt.Sprintf("%+v", apiResponse.error),
+				Data:        nil,
+				Hostname:    b.hostName,
+				ServiceName: externalServiceName,
+			})
+
+			responseCh <- results
+
+			return
+		}
+
+		var batchResponses []rpc.RpcResponseInternal
+
+		if trimmed := bytes.TrimSpace(apiResponse.rawBodyResponse); len(trimmed) > 0 && trimmed[0] == '{' {
+			// whole batch was rejected (or remote does not support batches), apply the error to every call
+			singleResponse := rpc.RpcResponseInternal{}
+
+			if err := json.Unmarshal(trimmed, &singleResponse); err == nil && singleResponse.Error != nil {
+				apiResponse.forceLog = true
+
+				singleResponse.Error.Message = fmt.Sprintf("remote server [%v] returned rpc error. [%v]",
+					externalServiceName, singleResponse.Error.Message)
+
+				fillAll(singleResponse.Error)
+
+				responseCh <- results
+
+				return
+			}
+		}
+
+		if err := json.Unmarshal(apiResponse.rawBodyResponse, &batchResponses); err != nil {
+			apiResponse.forceLog = true
+
+			wrapped := errors.Wrapf(err, "remote server status code [%v] can not unmarshal to rpc batch response",
+				apiResponse.statusCode)
+
+			fillAll(&rpc.RpcError{
+				Code:        error_codes.GenericMappingError,
+				Message:     wrapped.Error(),
+				Stack:       fmt.Sprintf("%+v", wrapped),
+				Data:        nil,
+				Hostname:    b.hostName,
+				ServiceName: externalServiceName,
+			})
+
+			responseCh <- results
+
+			return
+		}
+
+		byId := make(map[string]rpc.RpcResponseInternal, len(batchResponses))
+
+		for _, resp := range batchResponses {
+			byId[resp.Id] = resp
+		}
+
+		for i, req := range requests {
+			resp, ok := byId[req.Id]
+
+			if !ok {
+				apiResponse.forceLog = true
+
+				resp = rpc.RpcResponseInternal{
+					JsonRpc: "2.0",
+					Id:      req.Id,
+					Error: &rpc.RpcError{
+						Code:        error_codes.GenericMappingError,
+						Message:     fmt.Sprintf("remote server [%v] returned no response for method [%v]", externalServiceName, req.Method),
+						Hostname:    b.hostName,
+						ServiceName: externalServiceName,
+					},
+				}
+			} else if resp.Error != nil {
+				apiResponse.forceLog = true
+
+				resp.Result = nil
+				resp.Error.Message = fmt.Sprintf("remote server [%v] returned rpc error. [%v]", externalServiceName,
+					resp.Error.Message)
+			}
+
+			results[i] = resp
+		}
+
+		responseCh <- results
+	}()
+
+	return responseCh
+}
@@ -0,0 +1,78 @@
+package wrappers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/error_codes"
+	"github.com/digitalmonsters/go-common/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"net"
+	"testing"
+	"time"
+)
+
+func startTestRpcServer(t *testing.T, handler fasthttp.RequestHandler) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	srv := &fasthttp.Server{Handler: handler}
+
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+
+	t.Cleanup(func() {
+		_ = srv.Shutdown()
+	})
+
+	return fmt.Sprintf("http://%v/rpc-service", ln.Addr().String())
+}
+
+func TestSendRpcBatchRequest(t *testing.T) {
+	url := startTestRpcServer(t, func(ctx *fasthttp.RequestCtx) {
+		var requests []rpc.RpcRequest
+
+		assert.Nil(t, json.Unmarshal(ctx.PostBody(), &requests))
+
+		var responses []interface{}
+
+		for i := len(requests) - 1; i >= 0; i-- { // reversed order on purpose
+			if requests[i].Method == "fail" {
+				responses = append(responses, rpc.RpcResponseInternal{Id: requests[i].Id,
+					Error: &rpc.RpcError{Code: error_codes.GenericValidationError, Message: "bad"}})
+				continue
+			}
+
+			responses = append(responses, map[string]interface{}{"id": requests[i].Id, "result": requests[i].Params})
+		}
+
+		b, _ := json.Marshal(responses)
+		ctx.Response.SetBodyRaw(b)
+	})
+
+	resp := <-GetBaseWrapper().SendRpcBatchRequest(url, []RpcBatchCall{
+		{MethodName: "Echo", Request: map[string]int{"a": 1}},
+		{MethodName: "fail", Request: nil},
+		{MethodName: "echo", Request: map[string]int{"a": 3}},
+	}, nil, 3*time.Second, nil, "test", false)
+
+	assert.Len(t, resp, 3)
+	assert.JSONEq(t, `{"a":1}`, string(resp[0].Result))
+	assert.Equal(t, error_codes.GenericValidationError, resp[1].Error.Code)
+	assert.JSONEq(t, `{"a":3}`, string(resp[2].Result))
+}
+
+func TestSendRpcBatchRequestWholeBatchError(t *testing.T) {
+	url := startTestRpcServer(t, func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.SetBodyString(`{"jsonrpc":"2.0","error":{"code":-32600,"message":"rpc batch is empty"}}`)
+	})
+
+	resp := <-GetBaseWrapper().SendRpcBatchRequest(url, []RpcBatchCall{{MethodName: "a"}, {MethodName: "b"}},
+		nil, 3*time.Second, nil, "test", false)
+
+	assert.Len(t, resp, 2)
+	for _, r := range resp {
+		assert.Equal(t, error_codes.InvalidRpcRequestError, r.Error.Code)
+	}
+}
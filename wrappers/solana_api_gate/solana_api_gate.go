@@ -8,7 +8,6 @@ import (
 	"github.com/digitalmonsters/go-common/wrappers"
 	"github.com/patrickmn/go-cache"
 	"github.com/rs/zerolog/log"
-	"go.elastic.co/apm"
 	"time"
 )
 
@@ -53,7 +52,7 @@ func (w SolanaApiGateWrapper) TransferToken(from string, amount string, account
 	ctx context.Context,
 	forceLog bool) chan wrappers.GenericResponseChan[TransactionResponseData] {
 
-	return wrappers.ExecuteRpcRequestAsync[TransactionResponseData](w.baseWrapper, w.apiUrl, "TransferToken", TransferRequest{
+	return wrappers.ExecuteRpcRequestAsyncWithContext[TransactionResponseData](ctx, w.baseWrapper, w.apiUrl, "TransferToken", TransferRequest{
 		From:                    from,
 		Amount:                  amount,
 		WithdrawalTransactionId: withdrawalTransactionId,
@@ -63,13 +62,13 @@ func (w SolanaApiGateWrapper) TransferToken(from string, amount string, account
 		},
 		UserId:  userId,
 		AdminId: adminId,
-	}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w SolanaApiGateWrapper) CreateVesting(from string, to string, amounts string, timestamps string, withdrawalTransactionId int64, userId int64, adminId int64,
 	ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[TransactionResponseData] {
 
-	return wrappers.ExecuteRpcRequestAsync[TransactionResponseData](w.baseWrapper, w.apiUrl, "CreateVesting", CreateVestingRequest{
+	return wrappers.ExecuteRpcRequestAsyncWithContext[TransactionResponseData](ctx, w.baseWrapper, w.apiUrl, "CreateVesting", CreateVestingRequest{
 		From:                    from,
 		To:                      to,
 		Amounts:                 amounts,
@@ -77,10 +76,10 @@ func (w SolanaApiGateWrapper) CreateVesting(from string, to string, amounts stri
 		WithdrawalTransactionId: withdrawalTransactionId,
 		UserId:                  userId,
 		AdminId:                 adminId,
-	}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w SolanaApiGateWrapper) GetTransactionsStatus(withdrawalIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]TransactionDetail] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]TransactionDetail](w.baseWrapper, w.apiUrl, "GetTransactionsStatus",
-		GetTransactionsStatusRequest{WithdrawalIds: withdrawalIds}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]TransactionDetail](ctx, w.baseWrapper, w.apiUrl, "GetTransactionsStatus",
+		GetTransactionsStatusRequest{WithdrawalIds: withdrawalIds}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
@@ -7,7 +7,6 @@ import (
 	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/go-common/wrappers"
 	"github.com/rs/zerolog/log"
-	"go.elastic.co/apm"
 	"time"
 )
 
@@ -49,11 +48,11 @@ func NewTesseractOcrApiWrapper(config boilerplate.WrapperConfig) ITesseractOcrAp
 
 func (w TesseractOcrApiWrapper) RecognizeImageText(imageData []byte, languages []Language, hocrMode bool, trim bool, ctx context.Context,
 	forceLog bool) chan wrappers.GenericResponseChan[RecognizeImageTextResponse] {
-	return wrappers.ExecuteRpcRequestAsync[RecognizeImageTextResponse](w.baseWrapper, w.serviceApiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[RecognizeImageTextResponse](ctx, w.baseWrapper, w.serviceApiUrl,
 		"RecognizeImageText", RecognizeImageTextRequest{
 			ImageData: imageData,
 			Languages: languages,
 			HocrMode:  hocrMode,
 			Trim:      trim,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
@@ -8,8 +8,9 @@ import (
 
 //goland:noinspection ALL
 type UserCategoryWrapperMock struct {
-	GetUserCategorySubscriptionStateBulkFn func(categoryIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserCategorySubscriptionStateResponseChan
-	GetInternalUserCategorySubscriptionsFn func(userId int64, limit int, pageState string,
+	GetUserCategorySubscriptionStateBulkFn            func(categoryIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserCategorySubscriptionStateResponseChan
+	GetUserCategorySubscriptionStateBulkWithContextFn func(categoryIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetUserCategorySubscriptionStateResponseChan
+	GetInternalUserCategorySubscriptionsFn            func(userId int64, limit int, pageState string,
 		ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetInternalUserCategorySubscriptionsResponse]
 }
 
@@ -17,11 +18,19 @@ func (m *UserCategoryWrapperMock) GetUserCategorySubscriptionStateBulk(categoryI
 	return m.GetUserCategorySubscriptionStateBulkFn(categoryIds, userId, apmTransaction, forceLog)
 }
 
+func (m *UserCategoryWrapperMock) GetUserCategorySubscriptionStateBulkWithContext(categoryIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetUserCategorySubscriptionStateResponseChan {
+	if m.GetUserCategorySubscriptionStateBulkWithContextFn != nil {
+		return m.GetUserCategorySubscriptionStateBulkWithContextFn(categoryIds, userId, ctx, forceLog)
+	}
+
+	return m.GetUserCategorySubscriptionStateBulkFn(categoryIds, userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (m *UserCategoryWrapperMock) GetInternalUserCategorySubscriptions(userId int64, limit int, pageState string,
 	ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetInternalUserCategorySubscriptionsResponse] {
 	return m.GetInternalUserCategorySubscriptionsFn(userId, limit, pageState, ctx, forceLog)
 }
 
-func GetMock() IUserCategoryWrapper { // for compiler errors
+func GetMock() IUserCategoryWrapperWithContext { // for compiler errors
 	return &UserCategoryWrapperMock{}
 }
@@ -20,6 +20,14 @@ type IUserCategoryWrapper interface {
 		ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetInternalUserCategorySubscriptionsResponse]
 }
 
+// IUserCategoryWrapperWithContext has context-first versions of IUserCategoryWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IUserCategoryWrapper implementation
+type IUserCategoryWrapperWithContext interface {
+	IUserCategoryWrapper
+
+	GetUserCategorySubscriptionStateBulkWithContext(categoryIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetUserCategorySubscriptionStateResponseChan
+}
+
 //goland:noinspection GoNameStartsWithPackageName
 type UserCategoryWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
@@ -28,7 +36,7 @@ type UserCategoryWrapper struct {
 	serviceName    string
 }
 
-func NewUserCategoryWrapper(config boilerplate.WrapperConfig) IUserCategoryWrapper {
+func NewUserCategoryWrapper(config boilerplate.WrapperConfig) IUserCategoryWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -49,13 +57,18 @@ func NewUserCategoryWrapper(config boilerplate.WrapperConfig) IUserCategoryWrapp
 	}
 }
 
+// Deprecated: use GetUserCategorySubscriptionStateBulkWithContext
 func (w *UserCategoryWrapper) GetUserCategorySubscriptionStateBulk(categoryIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserCategorySubscriptionStateResponseChan {
+	return w.GetUserCategorySubscriptionStateBulkWithContext(categoryIds, userId, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *UserCategoryWrapper) GetUserCategorySubscriptionStateBulkWithContext(categoryIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetUserCategorySubscriptionStateResponseChan {
 	respCh := make(chan GetUserCategorySubscriptionStateResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetInternalUserCategorySubscriptionStateBulk", GetUserCategorySubscriptionStateBulkRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetInternalUserCategorySubscriptionStateBulk", GetUserCategorySubscriptionStateBulkRequest{
 		UserId:      userId,
 		CategoryIds: categoryIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -92,10 +105,28 @@ func (w *UserCategoryWrapper) GetUserCategorySubscriptionStateBulk(categoryIds [
 
 func (w *UserCategoryWrapper) GetInternalUserCategorySubscriptions(userId int64, limit int, pageState string,
 	ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetInternalUserCategorySubscriptionsResponse] {
-	return wrappers.ExecuteRpcRequestAsync[GetInternalUserCategorySubscriptionsResponse](w.baseWrapper, w.apiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[GetInternalUserCategorySubscriptionsResponse](ctx, w.baseWrapper, w.apiUrl,
 		"GetInternalUserCategorySubscriptions", GetInternalUserCategorySubscriptionsRequest{
 			UserId:    userId,
 			Limit:     limit,
 			PageState: pageState,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
+}
+
+// WithContext returns w when it already implements IUserCategoryWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IUserCategoryWrapper) IUserCategoryWrapperWithContext {
+	if c, ok := w.(IUserCategoryWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IUserCategoryWrapper: w}
+}
+
+type contextAdapter struct {
+	IUserCategoryWrapper
+}
+
+func (a contextAdapter) GetUserCategorySubscriptionStateBulkWithContext(categoryIds []int64, userId int64, ctx context.Context, forceLog bool) chan GetUserCategorySubscriptionStateResponseChan {
+	return a.IUserCategoryWrapper.GetUserCategorySubscriptionStateBulk(categoryIds, userId, apm.TransactionFromContext(ctx), forceLog)
 }
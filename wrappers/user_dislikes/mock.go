@@ -1,16 +1,28 @@
 package user_dislikes
 
-import "go.elastic.co/apm"
+import (
+	"context"
+	"go.elastic.co/apm"
+)
 
 //goland:noinspection ALL
 type UserDislikesWrapperMock struct {
-	GetAllUserDislikesFn func(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetAllUserDislikesResponseChan
+	GetAllUserDislikesFn            func(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetAllUserDislikesResponseChan
+	GetAllUserDislikesWithContextFn func(userId int64, ctx context.Context, forceLog bool) chan GetAllUserDislikesResponseChan
 }
 
 func (m *UserDislikesWrapperMock) GetAllUserDislikes(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetAllUserDislikesResponseChan {
 	return m.GetAllUserDislikesFn(userId, apmTransaction, forceLog)
 }
 
-func GetMock() IUserDislikesWrapper { // for compiler errors
+func (m *UserDislikesWrapperMock) GetAllUserDislikesWithContext(userId int64, ctx context.Context, forceLog bool) chan GetAllUserDislikesResponseChan {
+	if m.GetAllUserDislikesWithContextFn != nil {
+		return m.GetAllUserDislikesWithContextFn(userId, ctx, forceLog)
+	}
+
+	return m.GetAllUserDislikesFn(userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func GetMock() IUserDislikesWrapperWithContext { // for compiler errors
 	return &UserDislikesWrapperMock{}
 }
@@ -1,6 +1,7 @@
 package user_dislikes
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/digitalmonsters/go-common/boilerplate"
@@ -17,6 +18,14 @@ type IUserDislikesWrapper interface {
 	GetAllUserDislikes(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetAllUserDislikesResponseChan
 }
 
+// IUserDislikesWrapperWithContext has context-first versions of IUserDislikesWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IUserDislikesWrapper implementation
+type IUserDislikesWrapperWithContext interface {
+	IUserDislikesWrapper
+
+	GetAllUserDislikesWithContext(userId int64, ctx context.Context, forceLog bool) chan GetAllUserDislikesResponseChan
+}
+
 //goland:noinspection GoNameStartsWithPackageName
 type UserDislikesWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
@@ -25,7 +34,7 @@ type UserDislikesWrapper struct {
 	serviceName    string
 }
 
-func NewUserDislikesWrapper(config boilerplate.WrapperConfig) IUserDislikesWrapper {
+func NewUserDislikesWrapper(config boilerplate.WrapperConfig) IUserDislikesWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -46,12 +55,17 @@ func NewUserDislikesWrapper(config boilerplate.WrapperConfig) IUserDislikesWrapp
 	}
 }
 
+// Deprecated: use GetAllUserDislikesWithContext
 func (w *UserDislikesWrapper) GetAllUserDislikes(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetAllUserDislikesResponseChan {
+	return w.GetAllUserDislikesWithContext(userId, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *UserDislikesWrapper) GetAllUserDislikesWithContext(userId int64, ctx context.Context, forceLog bool) chan GetAllUserDislikesResponseChan {
 	respCh := make(chan GetAllUserDislikesResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "InternalGetAllUserDislikes", GetAllUserDislikesRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "InternalGetAllUserDislikes", GetAllUserDislikesRequest{
 		UserId: userId,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -85,3 +99,21 @@ func (w *UserDislikesWrapper) GetAllUserDislikes(userId int64, apmTransaction *a
 
 	return respCh
 }
+
+// WithContext returns w when it already implements IUserDislikesWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IUserDislikesWrapper) IUserDislikesWrapperWithContext {
+	if c, ok := w.(IUserDislikesWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IUserDislikesWrapper: w}
+}
+
+type contextAdapter struct {
+	IUserDislikesWrapper
+}
+
+func (a contextAdapter) GetAllUserDislikesWithContext(userId int64, ctx context.Context, forceLog bool) chan GetAllUserDislikesResponseChan {
+	return a.IUserDislikesWrapper.GetAllUserDislikes(userId, apm.TransactionFromContext(ctx), forceLog)
+}
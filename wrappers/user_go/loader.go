@@ -0,0 +1,20 @@
+package user_go
+
+import (
+	"context"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"time"
+)
+
+// NewUsersLoader batches GetUsers calls, one loader should be shared by all requests of the service
+func NewUsersLoader(wrapper IUserGoWrapper, maxBatch int, wait time.Duration) *wrappers.Loader[int64, UserRecord] {
+	return wrappers.NewLoader(func(ctx context.Context, userIds []int64) (map[int64]UserRecord, error) {
+		resp := <-wrapper.GetUsers(userIds, ctx, false)
+
+		if resp.Error != nil {
+			return nil, resp.Error.ToError()
+		}
+
+		return resp.Response, nil
+	}, maxBatch, wait)
+}
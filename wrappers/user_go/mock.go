@@ -14,24 +14,31 @@ type UserGoWrapperMock struct {
 	GetUsersDetailFn func(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserDetailRecord]
 	GetUserDetailsFn func(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserDetailRecord]
 
-	GetProfileBulkFn                      func(currentUserId int64, userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetProfileBulkResponseChan
-	GetUsersActiveThresholdsFn            func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUsersActiveThresholdsResponseChan
-	GetUserIdsFilterByUsernameFn          func(userIds []int64, searchQuery string, apmTransaction *apm.Transaction, forceLog bool) chan GetUserIdsFilterByUsernameResponseChan
-	GetUsersTagsFn                        func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUsersTagsResponseChan
-	AuthGuestFn                           func(deviceId string, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[AuthGuestResp]
-	GetBlockListFn                        func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[string][]int64]
-	GetUserBlockFn                        func(blockedTo int64, blockedBy int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[UserBlockData]
-	UpdateUserMetadataAfterRegistrationFn func(request UpdateUserMetaDataRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserRecord]
-	ForceResetUserWithNewGuestIdentityFn  func(deviceId string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[ForceResetUserIdentityWithNewGuestResponse]
-	VerifyUserFn                          func(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserRecord]
-	GetAllActiveBotsFn                    func(ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetAllActiveBotsResponse]
-	GetConfigPropertiesInternalFn         func(properties []string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetConfigPropertiesResponseChan]
-	UpdateEmailMarketingFn                func(userId int64, emailMarketing null.String, emailMarketingVerified bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any]
-	GenerateDeeplinkFn                    func(urlPath string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GenerateDeeplinkResponse]
-	CreateExportFn                        func(name string, exportType ExportType, filters interface{}, exportedBy int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[CreateExportResponse]
-	FinalizeExportFn                      func(exportId int64, file null.String, err error, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[FinalizeExportResponse]
-	GetGrandReferrerIdsFn                 func(ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[[]int64]
-	SetSpotsUploadBannedFn                func(userId int64, banned bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any]
+	GetProfileBulkFn                        func(currentUserId int64, userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetProfileBulkResponseChan
+	GetProfileBulkWithContextFn             func(currentUserId int64, userIds []int64, ctx context.Context, forceLog bool) chan GetProfileBulkResponseChan
+	GetUsersActiveThresholdsFn              func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUsersActiveThresholdsResponseChan
+	GetUsersActiveThresholdsWithContextFn   func(userIds []int64, ctx context.Context, forceLog bool) chan GetUsersActiveThresholdsResponseChan
+	GetUserIdsFilterByUsernameFn            func(userIds []int64, searchQuery string, apmTransaction *apm.Transaction, forceLog bool) chan GetUserIdsFilterByUsernameResponseChan
+	GetUserIdsFilterByUsernameWithContextFn func(userIds []int64, searchQuery string, ctx context.Context, forceLog bool) chan GetUserIdsFilterByUsernameResponseChan
+	GetUsersTagsFn                          func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUsersTagsResponseChan
+	GetUsersTagsWithContextFn               func(userIds []int64, ctx context.Context, forceLog bool) chan GetUsersTagsResponseChan
+	AuthGuestFn                             func(deviceId string, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[AuthGuestResp]
+	AuthGuestWithContextFn                  func(deviceId string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AuthGuestResp]
+	GetBlockListFn                          func(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[string][]int64]
+	GetBlockListWithContextFn               func(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[string][]int64]
+	GetUserBlockFn                          func(blockedTo int64, blockedBy int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[UserBlockData]
+	GetUserBlockWithContextFn               func(blockedTo int64, blockedBy int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserBlockData]
+	UpdateUserMetadataAfterRegistrationFn   func(request UpdateUserMetaDataRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserRecord]
+	ForceResetUserWithNewGuestIdentityFn    func(deviceId string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[ForceResetUserIdentityWithNewGuestResponse]
+	VerifyUserFn                            func(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserRecord]
+	GetAllActiveBotsFn                      func(ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetAllActiveBotsResponse]
+	GetConfigPropertiesInternalFn           func(properties []string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetConfigPropertiesResponseChan]
+	UpdateEmailMarketingFn                  func(userId int64, emailMarketing null.String, emailMarketingVerified bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any]
+	GenerateDeeplinkFn                      func(urlPath string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GenerateDeeplinkResponse]
+	CreateExportFn                          func(name string, exportType ExportType, filters interface{}, exportedBy int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[CreateExportResponse]
+	FinalizeExportFn                        func(exportId int64, file null.String, err error, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[FinalizeExportResponse]
+	GetGrandReferrerIdsFn                   func(ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[[]int64]
+	SetSpotsUploadBannedFn                  func(userId int64, banned bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any]
 }
 
 func (m *UserGoWrapperMock) GetUserDetails(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserDetailRecord] {
@@ -62,30 +69,86 @@ func (m *UserGoWrapperMock) GetProfileBulk(currentUserId int64, userIds []int64,
 	return m.GetProfileBulkFn(currentUserId, userIds, apmTransaction, forceLog)
 }
 
+func (m *UserGoWrapperMock) GetProfileBulkWithContext(currentUserId int64, userIds []int64, ctx context.Context, forceLog bool) chan GetProfileBulkResponseChan {
+	if m.GetProfileBulkWithContextFn != nil {
+		return m.GetProfileBulkWithContextFn(currentUserId, userIds, ctx, forceLog)
+	}
+
+	return m.GetProfileBulkFn(currentUserId, userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (m *UserGoWrapperMock) GetUsersActiveThresholds(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUsersActiveThresholdsResponseChan {
 	return m.GetUsersActiveThresholdsFn(userIds, apmTransaction, forceLog)
 }
 
+func (m *UserGoWrapperMock) GetUsersActiveThresholdsWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetUsersActiveThresholdsResponseChan {
+	if m.GetUsersActiveThresholdsWithContextFn != nil {
+		return m.GetUsersActiveThresholdsWithContextFn(userIds, ctx, forceLog)
+	}
+
+	return m.GetUsersActiveThresholdsFn(userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (m *UserGoWrapperMock) GetUserIdsFilterByUsername(userIds []int64, searchQuery string, apmTransaction *apm.Transaction, forceLog bool) chan GetUserIdsFilterByUsernameResponseChan {
 	return m.GetUserIdsFilterByUsernameFn(userIds, searchQuery, apmTransaction, forceLog)
 }
 
+func (m *UserGoWrapperMock) GetUserIdsFilterByUsernameWithContext(userIds []int64, searchQuery string, ctx context.Context, forceLog bool) chan GetUserIdsFilterByUsernameResponseChan {
+	if m.GetUserIdsFilterByUsernameWithContextFn != nil {
+		return m.GetUserIdsFilterByUsernameWithContextFn(userIds, searchQuery, ctx, forceLog)
+	}
+
+	return m.GetUserIdsFilterByUsernameFn(userIds, searchQuery, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (m *UserGoWrapperMock) GetUsersTags(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUsersTagsResponseChan {
 	return m.GetUsersTagsFn(userIds, apmTransaction, forceLog)
 }
 
+func (m *UserGoWrapperMock) GetUsersTagsWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetUsersTagsResponseChan {
+	if m.GetUsersTagsWithContextFn != nil {
+		return m.GetUsersTagsWithContextFn(userIds, ctx, forceLog)
+	}
+
+	return m.GetUsersTagsFn(userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (m *UserGoWrapperMock) AuthGuest(deviceId string, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[AuthGuestResp] {
 	return m.AuthGuestFn(deviceId, apmTransaction, forceLog)
 }
 
+func (m *UserGoWrapperMock) AuthGuestWithContext(deviceId string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AuthGuestResp] {
+	if m.AuthGuestWithContextFn != nil {
+		return m.AuthGuestWithContextFn(deviceId, ctx, forceLog)
+	}
+
+	return m.AuthGuestFn(deviceId, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (m *UserGoWrapperMock) GetBlockList(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[string][]int64] {
 	return m.GetBlockListFn(userIds, apmTransaction, forceLog)
 }
 
+func (m *UserGoWrapperMock) GetBlockListWithContext(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[string][]int64] {
+	if m.GetBlockListWithContextFn != nil {
+		return m.GetBlockListWithContextFn(userIds, ctx, forceLog)
+	}
+
+	return m.GetBlockListFn(userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (m *UserGoWrapperMock) GetUserBlock(blockedTo int64, blockedBy int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[UserBlockData] {
 	return m.GetUserBlockFn(blockedTo, blockedBy, apmTransaction, forceLog)
 }
 
+func (m *UserGoWrapperMock) GetUserBlockWithContext(blockedTo int64, blockedBy int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserBlockData] {
+	if m.GetUserBlockWithContextFn != nil {
+		return m.GetUserBlockWithContextFn(blockedTo, blockedBy, ctx, forceLog)
+	}
+
+	return m.GetUserBlockFn(blockedTo, blockedBy, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (m *UserGoWrapperMock) GetAllActiveBots(ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetAllActiveBotsResponse] {
 	return m.GetAllActiveBotsFn(ctx, forceLog)
 }
@@ -119,6 +182,6 @@ func (m *UserGoWrapperMock) SetSpotsUploadBanned(userId int64, banned bool, ctx
 	return m.SetSpotsUploadBannedFn(userId, banned, ctx, forceLog)
 }
 
-func GetMock() IUserGoWrapper { // for compiler errors
+func GetMock() IUserGoWrapperWithContext { // for compiler errors
 	return &UserGoWrapperMock{}
 }
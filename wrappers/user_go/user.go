@@ -41,6 +41,20 @@ type IUserGoWrapper interface {
 	SetSpotsUploadBanned(userId int64, banned bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any]
 }
 
+// IUserGoWrapperWithContext has context-first versions of IUserGoWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IUserGoWrapper implementation
+type IUserGoWrapperWithContext interface {
+	IUserGoWrapper
+
+	GetProfileBulkWithContext(currentUserId int64, userIds []int64, ctx context.Context, forceLog bool) chan GetProfileBulkResponseChan
+	GetUsersActiveThresholdsWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetUsersActiveThresholdsResponseChan
+	GetUserIdsFilterByUsernameWithContext(userIds []int64, searchQuery string, ctx context.Context, forceLog bool) chan GetUserIdsFilterByUsernameResponseChan
+	GetUsersTagsWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetUsersTagsResponseChan
+	AuthGuestWithContext(deviceId string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AuthGuestResp]
+	GetBlockListWithContext(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[string][]int64]
+	GetUserBlockWithContext(blockedTo int64, blockedBy int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserBlockData]
+}
+
 //goland:noinspection GoNameStartsWithPackageName
 type UserGoWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
@@ -50,7 +64,7 @@ type UserGoWrapper struct {
 	serviceName    string
 }
 
-func NewUserGoWrapper(config boilerplate.WrapperConfig) IUserGoWrapper {
+func NewUserGoWrapper(config boilerplate.WrapperConfig) IUserGoWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -63,27 +77,32 @@ func NewUserGoWrapper(config boilerplate.WrapperConfig) IUserGoWrapper {
 		log.Warn().Msgf("Api Url is missing for UserGo. Setting as default : %v", config.ApiUrl)
 	}
 
+	serviceName := "user-go"
+
 	return &UserGoWrapper{
-		baseWrapper:    wrappers.GetBaseWrapper(),
+		baseWrapper: wrappers.GetBaseWrapper().WithDefaultRetryPolicy(serviceName, wrappers.RetryPolicy{},
+			"GetUsersInternal", "GetUsersDetailsInternal", "GetProfileBulkInternal", "GetUsersActiveThresholds",
+			"GetUserIdsFilterByUsername", "GetUsersTags", "GetBlockListBulkInternal", "GetAllActiveBots",
+			"GetConfigPropertiesInternal", "GetGrandReferrerIds"),
 		defaultTimeout: timeout,
 		serviceApiUrl:  fmt.Sprintf("%v/rpc-service", common.StripSlashFromUrl(config.ApiUrl)),
 		publicApiUrl:   common.StripSlashFromUrl(config.ApiUrl),
-		serviceName:    "user-go",
+		serviceName:    serviceName,
 	}
 }
 
 func (w UserGoWrapper) GetUsers(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserRecord] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]UserRecord](w.baseWrapper, w.serviceApiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]UserRecord](ctx, w.baseWrapper, w.serviceApiUrl,
 		"GetUsersInternal", GetUsersRequest{
 			UserIds: userIds,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) GetUsersDetails(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserDetailRecord] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]UserDetailRecord](w.baseWrapper, w.serviceApiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]UserDetailRecord](ctx, w.baseWrapper, w.serviceApiUrl,
 		"GetUsersDetailsInternal", GetUsersDetailRequest{
 			UserIds: userIds,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) GetUserDetails(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserDetailRecord] {
@@ -118,13 +137,18 @@ func (w UserGoWrapper) GetUserDetails(userId int64, ctx context.Context, forceLo
 	return ch
 }
 
+// Deprecated: use GetProfileBulkWithContext
 func (w UserGoWrapper) GetProfileBulk(currentUserId int64, userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetProfileBulkResponseChan {
+	return w.GetProfileBulkWithContext(currentUserId, userIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w UserGoWrapper) GetProfileBulkWithContext(currentUserId int64, userIds []int64, ctx context.Context, forceLog bool) chan GetProfileBulkResponseChan {
 	respCh := make(chan GetProfileBulkResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.serviceApiUrl, "GetProfileBulkInternal", GetProfileBulkRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.serviceApiUrl, "GetProfileBulkInternal", GetProfileBulkRequest{
 		CurrentUserId: currentUserId,
 		UserIds:       userIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -159,12 +183,17 @@ func (w UserGoWrapper) GetProfileBulk(currentUserId int64, userIds []int64, apmT
 	return respCh
 }
 
+// Deprecated: use GetUsersActiveThresholdsWithContext
 func (w UserGoWrapper) GetUsersActiveThresholds(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUsersActiveThresholdsResponseChan {
+	return w.GetUsersActiveThresholdsWithContext(userIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w UserGoWrapper) GetUsersActiveThresholdsWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetUsersActiveThresholdsResponseChan {
 	respCh := make(chan GetUsersActiveThresholdsResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.serviceApiUrl, "GetUsersActiveThresholds", GetUsersActiveThresholdsRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.serviceApiUrl, "GetUsersActiveThresholds", GetUsersActiveThresholdsRequest{
 		UserIds: userIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -199,13 +228,18 @@ func (w UserGoWrapper) GetUsersActiveThresholds(userIds []int64, apmTransaction
 	return respCh
 }
 
+// Deprecated: use GetUserIdsFilterByUsernameWithContext
 func (w UserGoWrapper) GetUserIdsFilterByUsername(userIds []int64, searchQuery string, apmTransaction *apm.Transaction, forceLog bool) chan GetUserIdsFilterByUsernameResponseChan {
+	return w.GetUserIdsFilterByUsernameWithContext(userIds, searchQuery, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w UserGoWrapper) GetUserIdsFilterByUsernameWithContext(userIds []int64, searchQuery string, ctx context.Context, forceLog bool) chan GetUserIdsFilterByUsernameResponseChan {
 	respCh := make(chan GetUserIdsFilterByUsernameResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.serviceApiUrl, "GetUserIdsFilterByUsername", GetUserIdsFilterByUsernameRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.serviceApiUrl, "GetUserIdsFilterByUsername", GetUserIdsFilterByUsernameRequest{
 		UserIds:     userIds,
 		SearchQuery: searchQuery,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -240,12 +274,17 @@ func (w UserGoWrapper) GetUserIdsFilterByUsername(userIds []int64, searchQuery s
 	return respCh
 }
 
+// Deprecated: use GetUsersTagsWithContext
 func (w UserGoWrapper) GetUsersTags(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUsersTagsResponseChan {
+	return w.GetUsersTagsWithContext(userIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w UserGoWrapper) GetUsersTagsWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetUsersTagsResponseChan {
 	respCh := make(chan GetUsersTagsResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.serviceApiUrl, "GetUsersTags", GetUsersTagsRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.serviceApiUrl, "GetUsersTags", GetUsersTagsRequest{
 		UserIds: userIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -280,99 +319,156 @@ func (w UserGoWrapper) GetUsersTags(userIds []int64, apmTransaction *apm.Transac
 	return respCh
 }
 
+// Deprecated: use AuthGuestWithContext
 func (w *UserGoWrapper) AuthGuest(deviceId string, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[AuthGuestResp] {
-	return wrappers.ExecuteRpcRequestAsync[AuthGuestResp](w.baseWrapper, w.serviceApiUrl, "AuthGuestInternal", AuthGuestRequest{DeviceId: deviceId},
-		map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	return w.AuthGuestWithContext(deviceId, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *UserGoWrapper) AuthGuestWithContext(deviceId string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AuthGuestResp] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[AuthGuestResp](ctx, w.baseWrapper, w.serviceApiUrl, "AuthGuestInternal", AuthGuestRequest{DeviceId: deviceId},
+		map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetBlockListWithContext
 func (w *UserGoWrapper) GetBlockList(userIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[map[string][]int64] {
-	return wrappers.ExecuteRpcRequestAsync[map[string][]int64](w.baseWrapper, w.serviceApiUrl, "GetBlockListBulkInternal", GetBlockListRequest{UserIds: userIds},
-		map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	return w.GetBlockListWithContext(userIds, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *UserGoWrapper) GetBlockListWithContext(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[string][]int64] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[string][]int64](ctx, w.baseWrapper, w.serviceApiUrl, "GetBlockListBulkInternal", GetBlockListRequest{UserIds: userIds},
+		map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
+// Deprecated: use GetUserBlockWithContext
 func (w *UserGoWrapper) GetUserBlock(blockedTo int64, blockedBy int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[UserBlockData] {
-	return wrappers.ExecuteRpcRequestAsync[UserBlockData](w.baseWrapper, w.serviceApiUrl, "GetBlockListBulkInternal", GetUserBlockRequest{
+	return w.GetUserBlockWithContext(blockedTo, blockedBy, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *UserGoWrapper) GetUserBlockWithContext(blockedTo int64, blockedBy int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserBlockData] {
+	return wrappers.ExecuteRpcRequestAsyncWithContext[UserBlockData](ctx, w.baseWrapper, w.serviceApiUrl, "GetBlockListBulkInternal", GetUserBlockRequest{
 		BlockBy:   blockedBy,
 		BlockedTo: blockedTo,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) UpdateUserMetadataAfterRegistration(request UpdateUserMetaDataRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserRecord] {
-	return wrappers.ExecuteRpcRequestAsync[UserRecord](w.baseWrapper, w.serviceApiUrl, "UpdateUserMetadataAfterRegistration", request,
-		map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	return wrappers.ExecuteRpcRequestAsyncWithContext[UserRecord](ctx, w.baseWrapper, w.serviceApiUrl, "UpdateUserMetadataAfterRegistration", request,
+		map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) ForceResetUserWithNewGuestIdentity(deviceId string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[ForceResetUserIdentityWithNewGuestResponse] {
-	return wrappers.ExecuteRpcRequestAsync[ForceResetUserIdentityWithNewGuestResponse](w.baseWrapper, w.serviceApiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[ForceResetUserIdentityWithNewGuestResponse](ctx, w.baseWrapper, w.serviceApiUrl,
 		"ForceResetUserWithNewGuestIdentity", ForceResetUserIdentityWithNewGuestRequest{
 			DeviceId: deviceId,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) VerifyUser(userId int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserRecord] {
-	return wrappers.ExecuteRpcRequestAsync[UserRecord](w.baseWrapper, w.serviceApiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[UserRecord](ctx, w.baseWrapper, w.serviceApiUrl,
 		"VerifyUser", VerifyUserRequest{
 			UserId: userId,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) GetAllActiveBots(ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetAllActiveBotsResponse] {
-	return wrappers.ExecuteRpcRequestAsync[GetAllActiveBotsResponse](w.baseWrapper, w.serviceApiUrl,
-		"GetAllActiveBots", nil, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	return wrappers.ExecuteRpcRequestAsyncWithContext[GetAllActiveBotsResponse](ctx, w.baseWrapper, w.serviceApiUrl,
+		"GetAllActiveBots", nil, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) GetConfigPropertiesInternal(properties []string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[GetConfigPropertiesResponseChan] {
-	return wrappers.ExecuteRpcRequestAsync[GetConfigPropertiesResponseChan](w.baseWrapper, w.serviceApiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[GetConfigPropertiesResponseChan](ctx, w.baseWrapper, w.serviceApiUrl,
 		"GetConfigPropertiesInternal", GetConfigPropertiesRequest{
 			Properties: properties,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) UpdateEmailMarketing(userId int64, emailMarketing null.String, emailMarketingVerified bool,
 	ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any] {
-	return wrappers.ExecuteRpcRequestAsync[any](w.baseWrapper, w.serviceApiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[any](ctx, w.baseWrapper, w.serviceApiUrl,
 		"UpdateEmailMarketing", UpdateEmailMarketingRequest{
 			UserId:                 userId,
 			EmailMarketing:         emailMarketing,
 			EmailMarketingVerified: emailMarketingVerified,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) GenerateDeeplink(urlPath string, ctx context.Context,
 	forceLog bool) chan wrappers.GenericResponseChan[GenerateDeeplinkResponse] {
-	return wrappers.ExecuteRpcRequestAsync[GenerateDeeplinkResponse](w.baseWrapper, w.serviceApiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[GenerateDeeplinkResponse](ctx, w.baseWrapper, w.serviceApiUrl,
 		"GenerateDeeplink", GenerateDeeplinkRequest{
 			UrlPath: urlPath,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) CreateExport(name string, exportType ExportType, filters interface{}, exportedBy int64, ctx context.Context,
 	forceLog bool) chan wrappers.GenericResponseChan[CreateExportResponse] {
-	return wrappers.ExecuteRpcRequestAsync[CreateExportResponse](w.baseWrapper, w.serviceApiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[CreateExportResponse](ctx, w.baseWrapper, w.serviceApiUrl,
 		"CreateExport", CreateExportRequest{
 			Name:       name,
 			Type:       exportType,
 			Filters:    filters,
 			ExportedBy: exportedBy,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) FinalizeExport(exportId int64, file null.String, err error, ctx context.Context,
 	forceLog bool) chan wrappers.GenericResponseChan[FinalizeExportResponse] {
-	return wrappers.ExecuteRpcRequestAsync[FinalizeExportResponse](w.baseWrapper, w.serviceApiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[FinalizeExportResponse](ctx, w.baseWrapper, w.serviceApiUrl,
 		"FinalizeExport", FinalizeExportRequest{
 			ExportId: exportId,
 			File:     file,
 			Error:    err,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) GetGrandReferrerIds(ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[[]int64] {
-	return wrappers.ExecuteRpcRequestAsync[[]int64](w.baseWrapper, w.serviceApiUrl,
-		"GetGrandReferrerIds", nil, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	return wrappers.ExecuteRpcRequestAsyncWithContext[[]int64](ctx, w.baseWrapper, w.serviceApiUrl,
+		"GetGrandReferrerIds", nil, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w UserGoWrapper) SetSpotsUploadBanned(userId int64, banned bool, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[any] {
-	return wrappers.ExecuteRpcRequestAsync[any](w.baseWrapper, w.serviceApiUrl,
-		"SetUserSpotsUploadBanned", SetUserSpotsUploadBanned{Banned: banned, UserId: userId}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+	return wrappers.ExecuteRpcRequestAsyncWithContext[any](ctx, w.baseWrapper, w.serviceApiUrl,
+		"SetUserSpotsUploadBanned", SetUserSpotsUploadBanned{Banned: banned, UserId: userId}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
+}
+
+// WithContext returns w when it already implements IUserGoWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IUserGoWrapper) IUserGoWrapperWithContext {
+	if c, ok := w.(IUserGoWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IUserGoWrapper: w}
+}
+
+type contextAdapter struct {
+	IUserGoWrapper
+}
+
+func (a contextAdapter) GetProfileBulkWithContext(currentUserId int64, userIds []int64, ctx context.Context, forceLog bool) chan GetProfileBulkResponseChan {
+	return a.IUserGoWrapper.GetProfileBulk(currentUserId, userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetUsersActiveThresholdsWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetUsersActiveThresholdsResponseChan {
+	return a.IUserGoWrapper.GetUsersActiveThresholds(userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetUserIdsFilterByUsernameWithContext(userIds []int64, searchQuery string, ctx context.Context, forceLog bool) chan GetUserIdsFilterByUsernameResponseChan {
+	return a.IUserGoWrapper.GetUserIdsFilterByUsername(userIds, searchQuery, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetUsersTagsWithContext(userIds []int64, ctx context.Context, forceLog bool) chan GetUsersTagsResponseChan {
+	return a.IUserGoWrapper.GetUsersTags(userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) AuthGuestWithContext(deviceId string, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AuthGuestResp] {
+	return a.IUserGoWrapper.AuthGuest(deviceId, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetBlockListWithContext(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[string][]int64] {
+	return a.IUserGoWrapper.GetBlockList(userIds, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func (a contextAdapter) GetUserBlockWithContext(blockedTo int64, blockedBy int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[UserBlockData] {
+	return a.IUserGoWrapper.GetUserBlock(blockedTo, blockedBy, apm.TransactionFromContext(ctx), forceLog)
 }
@@ -1,16 +1,28 @@
 package user_hashtag
 
-import "go.elastic.co/apm"
+import (
+	"context"
+	"go.elastic.co/apm"
+)
 
 //goland:noinspection ALL
 type UserHashtagWrapperMock struct {
-	GetUserHashtagSubscriptionStateBulkFn func(hashtags []string, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserHashtagSubscriptionStateResponseChan
+	GetUserHashtagSubscriptionStateBulkFn            func(hashtags []string, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserHashtagSubscriptionStateResponseChan
+	GetUserHashtagSubscriptionStateBulkWithContextFn func(hashtags []string, userId int64, ctx context.Context, forceLog bool) chan GetUserHashtagSubscriptionStateResponseChan
 }
 
 func (m *UserHashtagWrapperMock) GetUserHashtagSubscriptionStateBulk(hashtags []string, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserHashtagSubscriptionStateResponseChan {
 	return m.GetUserHashtagSubscriptionStateBulkFn(hashtags, userId, apmTransaction, forceLog)
 }
 
-func GetMock() IUserHashtagWrapper { // for compiler errors
+func (m *UserHashtagWrapperMock) GetUserHashtagSubscriptionStateBulkWithContext(hashtags []string, userId int64, ctx context.Context, forceLog bool) chan GetUserHashtagSubscriptionStateResponseChan {
+	if m.GetUserHashtagSubscriptionStateBulkWithContextFn != nil {
+		return m.GetUserHashtagSubscriptionStateBulkWithContextFn(hashtags, userId, ctx, forceLog)
+	}
+
+	return m.GetUserHashtagSubscriptionStateBulkFn(hashtags, userId, apm.TransactionFromContext(ctx), forceLog)
+}
+
+func GetMock() IUserHashtagWrapperWithContext { // for compiler errors
 	return &UserHashtagWrapperMock{}
 }
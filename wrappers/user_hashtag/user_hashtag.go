@@ -1,6 +1,7 @@
 package user_hashtag
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/digitalmonsters/go-common/boilerplate"
@@ -17,6 +18,14 @@ type IUserHashtagWrapper interface {
 	GetUserHashtagSubscriptionStateBulk(hashtags []string, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserHashtagSubscriptionStateResponseChan
 }
 
+// IUserHashtagWrapperWithContext has context-first versions of IUserHashtagWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IUserHashtagWrapper implementation
+type IUserHashtagWrapperWithContext interface {
+	IUserHashtagWrapper
+
+	GetUserHashtagSubscriptionStateBulkWithContext(hashtags []string, userId int64, ctx context.Context, forceLog bool) chan GetUserHashtagSubscriptionStateResponseChan
+}
+
 //goland:noinspection GoNameStartsWithPackageName
 type UserHashtagWrapper struct {
 	baseWrapper    *wrappers.BaseWrapper
@@ -25,7 +34,7 @@ type UserHashtagWrapper struct {
 	serviceName    string
 }
 
-func NewUserCategoryWrapper(config boilerplate.WrapperConfig) IUserHashtagWrapper {
+func NewUserCategoryWrapper(config boilerplate.WrapperConfig) IUserHashtagWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -46,13 +55,18 @@ func NewUserCategoryWrapper(config boilerplate.WrapperConfig) IUserHashtagWrappe
 	}
 }
 
+// Deprecated: use GetUserHashtagSubscriptionStateBulkWithContext
 func (w *UserHashtagWrapper) GetUserHashtagSubscriptionStateBulk(hashtags []string, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan GetUserHashtagSubscriptionStateResponseChan {
+	return w.GetUserHashtagSubscriptionStateBulkWithContext(hashtags, userId, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *UserHashtagWrapper) GetUserHashtagSubscriptionStateBulkWithContext(hashtags []string, userId int64, ctx context.Context, forceLog bool) chan GetUserHashtagSubscriptionStateResponseChan {
 	respCh := make(chan GetUserHashtagSubscriptionStateResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetInternalUserHashtagSubscriptionStateBulk", GetUserHashtagSubscriptionStateBulkRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetInternalUserHashtagSubscriptionStateBulk", GetUserHashtagSubscriptionStateBulkRequest{
 		UserId:   userId,
 		Hashtags: hashtags,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -86,3 +100,21 @@ func (w *UserHashtagWrapper) GetUserHashtagSubscriptionStateBulk(hashtags []stri
 
 	return respCh
 }
+
+// WithContext returns w when it already implements IUserHashtagWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IUserHashtagWrapper) IUserHashtagWrapperWithContext {
+	if c, ok := w.(IUserHashtagWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IUserHashtagWrapper: w}
+}
+
+type contextAdapter struct {
+	IUserHashtagWrapper
+}
+
+func (a contextAdapter) GetUserHashtagSubscriptionStateBulkWithContext(hashtags []string, userId int64, ctx context.Context, forceLog bool) chan GetUserHashtagSubscriptionStateResponseChan {
+	return a.IUserHashtagWrapper.GetUserHashtagSubscriptionStateBulk(hashtags, userId, apm.TransactionFromContext(ctx), forceLog)
+}
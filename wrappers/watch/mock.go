@@ -9,6 +9,7 @@ import (
 //goland:noinspection ALL
 type WatchWrapperMock struct {
 	GetLastWatchesByUserFn              func(userIds []int64, limitPerUser int, apmTransaction *apm.Transaction, forceLog bool) chan LastWatcherByUserResponseChan
+	GetLastWatchesByUsersWithContextFn  func(userIds []int64, limitPerUser int, ctx context.Context, forceLog bool) chan LastWatcherByUserResponseChan
 	AddViewsInternalFn                  func(viewEvents []AddViewRecord, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AddViewsResponse]
 	GetUsersTotalTimeWatchingInternalFn func(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]int64]
 }
@@ -17,6 +18,14 @@ func (m *WatchWrapperMock) GetLastWatchesByUsers(userIds []int64, limitPerUser i
 	return m.GetLastWatchesByUserFn(userIds, limitPerUser, apmTransaction, forceLog)
 }
 
+func (m *WatchWrapperMock) GetLastWatchesByUsersWithContext(userIds []int64, limitPerUser int, ctx context.Context, forceLog bool) chan LastWatcherByUserResponseChan {
+	if m.GetLastWatchesByUsersWithContextFn != nil {
+		return m.GetLastWatchesByUsersWithContextFn(userIds, limitPerUser, ctx, forceLog)
+	}
+
+	return m.GetLastWatchesByUserFn(userIds, limitPerUser, apm.TransactionFromContext(ctx), forceLog)
+}
+
 func (m *WatchWrapperMock) AddViewsInternal(viewEvents []AddViewRecord, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AddViewsResponse] {
 	return m.AddViewsInternalFn(viewEvents, ctx, forceLog)
 }
@@ -25,6 +34,6 @@ func (m *WatchWrapperMock) GetUsersTotalTimeWatchingInternal(userIds []int64, ct
 	return m.GetUsersTotalTimeWatchingInternalFn(userIds, ctx, forceLog)
 }
 
-func GetMock() IWatchWrapper { // for compiler errors
+func GetMock() IWatchWrapperWithContext { // for compiler errors
 	return &WatchWrapperMock{}
 }
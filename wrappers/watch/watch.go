@@ -20,6 +20,14 @@ type IWatchWrapper interface {
 	GetUsersTotalTimeWatchingInternal(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]int64]
 }
 
+// IWatchWrapperWithContext has context-first versions of IWatchWrapper methods. Cancellation, deadline and identity of ctx
+// are sent with the request. Use WithContext to get it from any IWatchWrapper implementation
+type IWatchWrapperWithContext interface {
+	IWatchWrapper
+
+	GetLastWatchesByUsersWithContext(userIds []int64, limitPerUser int, ctx context.Context, forceLog bool) chan LastWatcherByUserResponseChan
+}
+
 //goland:noinspection GoNameStartsWithPackageName
 type WatchWrapper struct {
 	apiUrl         string
@@ -28,7 +36,7 @@ type WatchWrapper struct {
 	serviceName    string
 }
 
-func NewWatchWrapper(config boilerplate.WrapperConfig) IWatchWrapper {
+func NewWatchWrapper(config boilerplate.WrapperConfig) IWatchWrapperWithContext {
 	timeout := 5 * time.Second
 
 	if config.TimeoutSec > 0 {
@@ -49,14 +57,20 @@ func NewWatchWrapper(config boilerplate.WrapperConfig) IWatchWrapper {
 	}
 }
 
+// Deprecated: use GetLastWatchesByUsersWithContext
 func (w *WatchWrapper) GetLastWatchesByUsers(userIds []int64, limitPerUser int, apmTransaction *apm.Transaction,
+	forceLog bool) chan LastWatcherByUserResponseChan {
+	return w.GetLastWatchesByUsersWithContext(userIds, limitPerUser, apm.ContextWithTransaction(context.Background(), apmTransaction), forceLog)
+}
+
+func (w *WatchWrapper) GetLastWatchesByUsersWithContext(userIds []int64, limitPerUser int, ctx context.Context,
 	forceLog bool) chan LastWatcherByUserResponseChan {
 	respCh := make(chan LastWatcherByUserResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetLastWatchesByUsers", GetLatestWatchesByUserRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetLastWatchesByUsers", GetLatestWatchesByUserRequest{
 		LimitPerUser: limitPerUser,
 		UserIds:      userIds,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 
 	go func() {
 		defer func() {
@@ -91,13 +105,18 @@ func (w *WatchWrapper) GetLastWatchesByUsers(userIds []int64, limitPerUser int,
 	return respCh
 }
 
+// Deprecated: use GetCategoriesByViewsWithContext
 func (w *WatchWrapper) GetCategoriesByViews(limit int64, offset int64, apmTransaction *apm.Transaction) chan GetCategoriesResponseChan {
+	return w.GetCategoriesByViewsWithContext(limit, offset, apm.ContextWithTransaction(context.Background(), apmTransaction))
+}
+
+func (w *WatchWrapper) GetCategoriesByViewsWithContext(limit int64, offset int64, ctx context.Context) chan GetCategoriesResponseChan {
 	respCh := make(chan GetCategoriesResponseChan, 2)
 
-	respChan := w.baseWrapper.SendRpcRequest(w.apiUrl, "GetCategoriesByViews", GetCategoriesByViewsRequest{
+	respChan := w.baseWrapper.SendRpcRequestWithContext(ctx, w.apiUrl, "GetCategoriesByViews", GetCategoriesByViewsRequest{
 		Limit:  limit,
 		Offset: offset,
-	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, false)
+	}, map[string]string{}, w.defaultTimeout, w.serviceName, false)
 
 	go func() {
 		defer func() {
@@ -132,15 +151,33 @@ func (w *WatchWrapper) GetCategoriesByViews(limit int64, offset int64, apmTransa
 }
 
 func (w WatchWrapper) AddViewsInternal(viewEvents []AddViewRecord, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[AddViewsResponse] {
-	return wrappers.ExecuteRpcRequestAsync[AddViewsResponse](w.baseWrapper, w.apiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[AddViewsResponse](ctx, w.baseWrapper, w.apiUrl,
 		"AddViewsInternal", AddViewsRequest{
 			ViewEvents: viewEvents,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
 }
 
 func (w WatchWrapper) GetUsersTotalTimeWatchingInternal(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]int64] {
-	return wrappers.ExecuteRpcRequestAsync[map[int64]int64](w.baseWrapper, w.apiUrl,
+	return wrappers.ExecuteRpcRequestAsyncWithContext[map[int64]int64](ctx, w.baseWrapper, w.apiUrl,
 		"GetUsersTotalTimeWatchingInternal", GetUsersTotalTimeWatchingInternalRequest{
 			UserIds: userIds,
-		}, map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+		}, map[string]string{}, w.defaultTimeout, w.serviceName, forceLog)
+}
+
+// WithContext returns w when it already implements IWatchWrapperWithContext. Other implementations are adapted,
+// their methods get only apm transaction of ctx
+func WithContext(w IWatchWrapper) IWatchWrapperWithContext {
+	if c, ok := w.(IWatchWrapperWithContext); ok {
+		return c
+	}
+
+	return contextAdapter{IWatchWrapper: w}
+}
+
+type contextAdapter struct {
+	IWatchWrapper
+}
+
+func (a contextAdapter) GetLastWatchesByUsersWithContext(userIds []int64, limitPerUser int, ctx context.Context, forceLog bool) chan LastWatcherByUserResponseChan {
+	return a.IWatchWrapper.GetLastWatchesByUsers(userIds, limitPerUser, apm.TransactionFromContext(ctx), forceLog)
 }